@@ -0,0 +1,166 @@
+package chronos
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Adjustment kinds. Taxes are added to the discounted subtotal, withholding
+// taxes are taken off it, and discounts come off the time and fixed-price
+// lines.
+const (
+	AdjustTax         = "tax"
+	AdjustWithholding = "withholding"
+	AdjustDiscount    = "discount"
+)
+
+// Invoice line kinds besides billed time, which has an empty kind.
+const (
+	LineFixed   = "fixed"   // A fixed-price item
+	LineExpense = "expense" // A cost passed on to the client; never discounted
+)
+
+// Adjustment is a tax, withholding or discount on an invoice. Percent
+// applies to its base; otherwise Amount is a fixed sum, which only discounts
+// may use. A client's terms are the adjustments its new invoices start with.
+type Adjustment struct {
+	ID      int64   `json:"id,omitempty"`
+	Kind    string  `json:"kind"` // AdjustTax, AdjustWithholding or AdjustDiscount
+	Name    string  `json:"name"` // e.g. "VAT"
+	Percent float64 `json:"percent,omitempty"`
+	Amount  float64 `json:"amount,omitempty"`
+}
+
+// String describes the adjustment, e.g. "VAT 20%" or "Discount 50.00".
+func (a *Adjustment) String() string {
+	if a.Percent != 0 {
+		return fmt.Sprintf("%s %s%%", a.Name, strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.4f", a.Percent), "0"), "."))
+	}
+	return fmt.Sprintf("%s %.2f", a.Name, a.Amount)
+}
+
+// Validate checks the adjustment's kind and figures and names it after its
+// kind when it has no name.
+func (a *Adjustment) Validate() error {
+	switch a.Kind {
+	case AdjustTax, AdjustWithholding:
+		if a.Percent <= 0 {
+			return fmt.Errorf("%s %q needs a positive percentage", a.Kind, a.Name)
+		}
+		a.Amount = 0
+	case AdjustDiscount:
+		if (a.Percent <= 0) == (a.Amount <= 0) {
+			return fmt.Errorf("discount %q needs either a positive percentage or a positive amount", a.Name)
+		}
+	default:
+		return fmt.Errorf("unknown adjustment kind %q", a.Kind)
+	}
+	if a.Percent > 100 {
+		return fmt.Errorf("%s %q is over 100%%", a.Kind, a.Name)
+	}
+	a.Name = strings.TrimSpace(a.Name)
+	if a.Name == "" {
+		a.Name = strings.ToUpper(a.Kind[:1]) + a.Kind[1:]
+	}
+	return nil
+}
+
+// AdjustmentAmount is what an adjustment came to on a particular invoice.
+// Discounts and withholding are negative.
+type AdjustmentAmount struct {
+	Kind    string  `json:"kind"`
+	Name    string  `json:"name"`
+	Percent float64 `json:"percent,omitempty"`
+	Amount  float64 `json:"amount"`
+}
+
+// InvoiceTotals breaks an invoice total down. Subtotal is the sum of the
+// lines, of which FixedPrices and Expenses came from items; Taxable is what
+// is left after discounts, which taxes and withholding are worked out on.
+type InvoiceTotals struct {
+	Subtotal    float64            `json:"subtotal"`
+	FixedPrices float64            `json:"fixed_prices,omitempty"`
+	Expenses    float64            `json:"expenses,omitempty"`
+	Discounts   []AdjustmentAmount `json:"discounts,omitempty"`
+	Taxable     float64            `json:"taxable"`
+	Taxes       []AdjustmentAmount `json:"taxes,omitempty"` // Taxes, then withholding
+	Total       float64            `json:"total"`
+}
+
+// CalculateTotals applies adjustments to lines. Discounts apply in order to
+// the time and fixed-price lines, and a fixed discount never takes more than
+// is left of them; expenses are not discounted. Every figure is rounded to
+// cents.
+func CalculateTotals(lines []*InvoiceLine, adjustments []*Adjustment) InvoiceTotals {
+	var t InvoiceTotals
+	var discountable float64
+	for _, l := range lines {
+		t.Subtotal += l.Amount
+		switch l.Kind {
+		case LineExpense:
+			t.Expenses += l.Amount
+		case LineFixed:
+			t.FixedPrices += l.Amount
+			discountable += l.Amount
+		default:
+			discountable += l.Amount
+		}
+	}
+	t.Subtotal, t.Expenses, t.FixedPrices = cents(t.Subtotal), cents(t.Expenses), cents(t.FixedPrices)
+
+	t.Taxable = t.Subtotal
+	for _, a := range adjustments {
+		if a.Kind != AdjustDiscount {
+			continue
+		}
+		off := a.Amount
+		if a.Percent != 0 {
+			off = discountable * a.Percent / 100
+		}
+		off = cents(math.Min(off, discountable))
+		discountable -= off
+		t.Taxable = cents(t.Taxable - off)
+		t.Discounts = append(t.Discounts, AdjustmentAmount{Kind: a.Kind, Name: a.Name, Percent: a.Percent, Amount: -off})
+	}
+
+	t.Total = t.Taxable
+	for _, kind := range []string{AdjustTax, AdjustWithholding} {
+		for _, a := range adjustments {
+			if a.Kind != kind {
+				continue
+			}
+			amount := cents(t.Taxable * a.Percent / 100)
+			if kind == AdjustWithholding {
+				amount = -amount
+			}
+			t.Total = cents(t.Total + amount)
+			t.Taxes = append(t.Taxes, AdjustmentAmount{Kind: a.Kind, Name: a.Name, Percent: a.Percent, Amount: amount})
+		}
+	}
+	return t
+}
+
+// GetClientTerms returns the adjustments new invoices for client start with.
+func GetClientTerms(repo Repository, client string) ([]*Adjustment, error) {
+	return repo.GetClientTerms(client)
+}
+
+// SetClientTerms validates terms and makes them client's, replacing any it
+// had. Empty terms clear them.
+func SetClientTerms(repo Repository, client string, terms []*Adjustment) error {
+	client = strings.TrimSpace(client)
+	if client == "" {
+		return fmt.Errorf("invoice terms need a client")
+	}
+	for _, a := range terms {
+		if err := a.Validate(); err != nil {
+			return err
+		}
+	}
+	return repo.SetClientTerms(client, terms)
+}
+
+func cents(f float64) float64 {
+	return math.Round(f*100) / 100
+}
@@ -0,0 +1,118 @@
+package chronos_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/regiellis/chronos-go/chronos"
+)
+
+func TestCalculateTotals(t *testing.T) {
+	lines := []*chronos.InvoiceLine{
+		{Project: "Apollo", Amount: 800},
+		{Kind: chronos.LineFixed, Description: "Setup", Amount: 200},
+		{Kind: chronos.LineExpense, Description: "Hosting", Amount: 50},
+	}
+	totals := chronos.CalculateTotals(lines, []*chronos.Adjustment{
+		{Kind: chronos.AdjustTax, Name: "VAT", Percent: 20},
+		{Kind: chronos.AdjustDiscount, Name: "Loyalty", Percent: 10},
+		{Kind: chronos.AdjustDiscount, Name: "Credit", Amount: 1000},
+		{Kind: chronos.AdjustWithholding, Name: "IRPF", Percent: 15},
+	})
+	if totals.Subtotal != 1050 || totals.FixedPrices != 200 || totals.Expenses != 50 {
+		t.Errorf("subtotal breakdown: %+v", totals)
+	}
+	// 10% of the 1000 discountable, then the credit is capped at the 900 left.
+	if len(totals.Discounts) != 2 || totals.Discounts[0].Amount != -100 || totals.Discounts[1].Amount != -900 || totals.Taxable != 50 {
+		t.Errorf("discounts: %+v, taxable %v", totals.Discounts, totals.Taxable)
+	}
+	if len(totals.Taxes) != 2 || totals.Taxes[0].Amount != 10 || totals.Taxes[1].Name != "IRPF" || totals.Taxes[1].Amount != -7.5 || totals.Total != 52.5 {
+		t.Errorf("taxes: %+v, total %v", totals.Taxes, totals.Total)
+	}
+
+	if plain := chronos.CalculateTotals(lines[:1], nil); plain.Subtotal != 800 || plain.Taxable != 800 || plain.Total != 800 {
+		t.Errorf("no adjustments should leave the subtotal: %+v", plain)
+	}
+}
+
+func TestAdjustmentValidate(t *testing.T) {
+	for _, a := range []*chronos.Adjustment{
+		{Kind: chronos.AdjustTax},
+		{Kind: chronos.AdjustDiscount, Percent: 10, Amount: 5},
+		{Kind: chronos.AdjustWithholding, Percent: 120},
+		{Kind: "fee", Percent: 5},
+	} {
+		if err := a.Validate(); err == nil {
+			t.Errorf("%+v should be invalid", a)
+		}
+	}
+	a := &chronos.Adjustment{Kind: chronos.AdjustDiscount, Amount: 50}
+	if err := a.Validate(); err != nil || a.Name != "Discount" || a.String() != "Discount 50.00" {
+		t.Errorf("Validate = %v, %+v", err, a)
+	}
+	if s := (&chronos.Adjustment{Name: "GST", Percent: 12.5}).String(); s != "GST 12.5%" {
+		t.Errorf("String = %q", s)
+	}
+}
+
+func TestInvoiceTermsAndItems(t *testing.T) {
+	eachRepository(t, func(t *testing.T, repo chronos.Repository) {
+		terms := []*chronos.Adjustment{{Kind: chronos.AdjustTax, Name: "VAT", Percent: 20}}
+		if err := chronos.SetClientTerms(repo, "Acme", terms); err != nil {
+			t.Fatalf("SetClientTerms failed: %v", err)
+		}
+		if got, err := chronos.GetClientTerms(repo, "acme"); err != nil || len(got) != 1 || got[0].Name != "VAT" {
+			t.Fatalf("GetClientTerms = %v, %v", got, err)
+		}
+
+		e := &chronos.Entry{Project: "Apollo", Client: "Acme", Task: "API", StartTime: time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC), Duration: 120, Billable: true, Rate: 100}
+		if err := chronos.CreateEntry(repo, e); err != nil {
+			t.Fatalf("CreateEntry failed: %v", err)
+		}
+		inv := &chronos.Invoice{Client: "Acme", Lines: chronos.GroupInvoiceLines([]*chronos.Entry{e})}
+		if err := chronos.CreateInvoice(repo, inv); err != nil {
+			t.Fatalf("CreateInvoice failed: %v", err)
+		}
+		if len(inv.Adjustments) != 1 || inv.Total() != 240 {
+			t.Fatalf("new invoice should take the client's terms: %+v, total %v", inv.Adjustments, inv.Total())
+		}
+		// Later changes to the terms leave existing invoices alone.
+		if err := chronos.SetClientTerms(repo, "Acme", nil); err != nil {
+			t.Fatalf("clearing terms failed: %v", err)
+		}
+
+		if _, err := chronos.AddInvoiceItem(repo, inv.ID, &chronos.InvoiceLine{Kind: chronos.LineExpense, Amount: 30}); err == nil {
+			t.Error("an item without a description should be refused")
+		}
+		if _, err := chronos.AddInvoiceItem(repo, inv.ID, &chronos.InvoiceLine{Kind: chronos.LineExpense, Description: "Hosting", Amount: 30}); err != nil {
+			t.Fatalf("AddInvoiceItem failed: %v", err)
+		}
+		discount := []*chronos.Adjustment{{Kind: chronos.AdjustDiscount, Percent: 50}, {Kind: chronos.AdjustTax, Name: "VAT", Percent: 20}}
+		if _, err := chronos.SetInvoiceAdjustments(repo, inv.ID, discount); err != nil {
+			t.Fatalf("SetInvoiceAdjustments failed: %v", err)
+		}
+
+		got, err := chronos.GetInvoiceByID(repo, inv.ID)
+		if err != nil {
+			t.Fatalf("GetInvoiceByID failed: %v", err)
+		}
+		if len(got.Lines) != 2 || got.Lines[1].Kind != chronos.LineExpense || got.Lines[1].Label() != "Expense" || len(got.Adjustments) != 2 {
+			t.Fatalf("items and adjustments not stored: %+v %+v", got.Lines, got.Adjustments)
+		}
+		// (200 - 100 discount + 30 expense) plus 20% VAT.
+		if totals := got.Totals(); totals.Subtotal != 230 || totals.Taxable != 130 || totals.Total != 156 {
+			t.Errorf("totals: %+v", totals)
+		}
+
+		if _, err := chronos.IssueInvoice(repo, inv.ID, chronos.InvoiceNumbering{}, time.Now(), 30); err != nil {
+			t.Fatalf("IssueInvoice failed: %v", err)
+		}
+		if _, err := chronos.AddInvoiceItem(repo, inv.ID, &chronos.InvoiceLine{Kind: chronos.LineFixed, Description: "Late fee", Amount: 10}); !errors.Is(err, chronos.ErrInvoiceStatus) {
+			t.Errorf("items can only go on drafts, got %v", err)
+		}
+		if all, _ := chronos.ListInvoices(repo, nil); len(all) != 1 || len(all[0].Adjustments) != 2 || all[0].Total() != 156 {
+			t.Errorf("ListInvoices should load adjustments: %+v", all)
+		}
+	})
+}
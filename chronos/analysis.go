@@ -13,8 +13,42 @@ func CalculateProjectTotalsByProjectID(entries []*Entry) map[int64]float64 {
 		if entry == nil {
 			continue
 		}
-		duration := entry.EndTime.Sub(entry.StartTime).Minutes()
-		totals[entry.ProjectID] += duration
+		totals[entry.ProjectID] += entry.Minutes()
+	}
+	return totals
+}
+
+// CalculateTotalsBy aggregates total duration in minutes, grouped by the key
+// returned for each entry (e.g. project, client or task name).
+// Entries with an empty key are grouped under "(none)".
+func CalculateTotalsBy(entries []*Entry, key func(*Entry) string) map[string]float64 {
+	totals := make(map[string]float64)
+	for _, entry := range entries {
+		if entry == nil {
+			continue
+		}
+		k := key(entry)
+		if k == "" {
+			k = "(none)"
+		}
+		totals[k] += entry.Minutes()
+	}
+	return totals
+}
+
+// CalculateBilledTotalsBy is CalculateTotalsBy over billed rather than
+// tracked minutes, so rounding rules show up in the totals.
+func CalculateBilledTotalsBy(entries []*Entry, key func(*Entry) string) map[string]float64 {
+	totals := make(map[string]float64)
+	for _, entry := range entries {
+		if entry == nil {
+			continue
+		}
+		k := key(entry)
+		if k == "" {
+			k = "(none)"
+		}
+		totals[k] += entry.BilledMinutes()
 	}
 	return totals
 }
@@ -30,7 +64,7 @@ func CalculateReviewPeriodTotals(entries []*Entry, periodStart time.Time) float6
 		}
 		// Ensure StartTime is valid and after or equal to periodStart
 		if !entry.StartTime.IsZero() && (entry.StartTime.Equal(periodStart) || entry.StartTime.After(periodStart)) {
-			totalDuration += entry.EndTime.Sub(entry.StartTime).Minutes()
+			totalDuration += entry.Minutes()
 		}
 	}
 	return totalDuration
@@ -49,9 +83,15 @@ func SortEntriesByStartTime(entries []*Entry, asc bool) {
 		iValid := entryI != nil && !entryI.StartTime.IsZero()
 		jValid := entryJ != nil && !entryJ.StartTime.IsZero()
 
-		if !iValid && !jValid { return false } // Both invalid, keep order
-		if !iValid { return asc } // Only i is invalid, if asc, i is "less" (comes first)
-		if !jValid { return !asc } // Only j is invalid, if asc, j is "greater" (i comes first)
+		if !iValid && !jValid {
+			return false
+		} // Both invalid, keep order
+		if !iValid {
+			return asc
+		} // Only i is invalid, if asc, i is "less" (comes first)
+		if !jValid {
+			return !asc
+		} // Only j is invalid, if asc, j is "greater" (i comes first)
 
 		// Both are valid
 		if asc {
@@ -61,53 +101,61 @@ func SortEntriesByStartTime(entries []*Entry, asc bool) {
 	})
 }
 
-// IdleGap represents a detected period of inactivity between two entries.
+// IdleGap represents a detected period of inactivity between worked segments.
+// Break is true when the gap is a recorded break inside a single entry.
 type IdleGap struct {
 	StartTime time.Time
 	EndTime   time.Time
 	Duration  time.Duration
+	Break     bool
 }
 
-// DetectIdleGaps identifies periods of inactivity between entries longer than minGapDuration.
-// It sorts the entries by StartTime ASC before processing.
+// DetectIdleGaps identifies periods of inactivity longer than minGapDuration.
+// Entries are split into their worked segments, so breaks recorded with
+// pause/resume show up as gaps (flagged with Break) and overlapping entries
+// never produce false gaps. Open entries are measured up to now.
 func DetectIdleGaps(entries []*Entry, minGapDuration time.Duration) []IdleGap {
-	if len(entries) < 2 {
+	type owned struct {
+		Segment
+		entry *Entry
+	}
+	now := time.Now()
+	var segments []owned
+	for _, entry := range entries {
+		if entry == nil {
+			continue
+		}
+		for _, seg := range entry.Segments(now) {
+			segments = append(segments, owned{Segment: seg, entry: entry})
+		}
+	}
+	if len(segments) < 2 {
 		return nil
 	}
-
-	// Create a defensive copy to sort, ensuring the original slice order is untouched.
-	sortedEntries := make([]*Entry, len(entries))
-	copy(sortedEntries, entries)
-	SortEntriesByStartTime(sortedEntries, true) // true for ascending
+	sort.SliceStable(segments, func(i, j int) bool { return segments[i].StartTime.Before(segments[j].StartTime) })
 
 	var gaps []IdleGap
-	for i := 0; i < len(sortedEntries)-1; i++ {
-		prevEntry := sortedEntries[i]
-		nextEntry := sortedEntries[i+1]
-
-		// Ensure entries and their relevant time fields are valid
-		if prevEntry == nil || nextEntry == nil || prevEntry.EndTime.IsZero() || nextEntry.StartTime.IsZero() {
-			continue 
-		}
-		
-		// A gap occurs only if the next entry starts after the previous one ended.
-		if nextEntry.StartTime.After(prevEntry.EndTime) {
-			gapDuration := nextEntry.StartTime.Sub(prevEntry.EndTime)
+	covered := segments[0]
+	for _, next := range segments[1:] {
+		// A gap occurs only if the next segment starts after everything before it ended.
+		if next.StartTime.After(covered.EndTime) {
+			gapDuration := next.StartTime.Sub(covered.EndTime)
 			if gapDuration >= minGapDuration {
 				gaps = append(gaps, IdleGap{
-					StartTime: prevEntry.EndTime,
-					EndTime:   nextEntry.StartTime,
+					StartTime: covered.EndTime,
+					EndTime:   next.StartTime,
 					Duration:  gapDuration,
+					Break:     next.entry == covered.entry,
 				})
 			}
 		}
+		if next.EndTime.After(covered.EndTime) {
+			covered = next
+		}
 	}
 	return gaps
 }
 
 // Future enhancements could include:
-// - CalculateClientTotals: Would require entries to have ClientID or means to derive it.
-//   (e.g., func CalculateClientTotals(projects []*Project, entries []*Entry) map[int64]float64)
-// - CalculateTaskTotals: Would require parsing Summary field or a dedicated Task field in Entry.
 // - Functions to return lists of top N projects/clients/tasks.
 // - More sophisticated filtering options within calculations.
@@ -11,10 +11,10 @@ import (
 func TestCalculateProjectTotalsByProjectID(t *testing.T) {
 	now := time.Now()
 	entries := []*chronos.Entry{
-		{ProjectID: 1, StartTime: now, EndTime: now.Add(1 * time.Hour)},                      // 60 mins
-		{ProjectID: 2, StartTime: now, EndTime: now.Add(30 * time.Minute)},                    // 30 mins
-		{ProjectID: 1, StartTime: now.Add(2 * time.Hour), EndTime: now.Add(3 * time.Hour)},    // 60 mins
-		{ProjectID: 3, StartTime: now, EndTime: now.Add(0 * time.Minute)},                     // 0 mins
+		{ProjectID: 1, StartTime: now, EndTime: now.Add(1 * time.Hour)},                    // 60 mins
+		{ProjectID: 2, StartTime: now, EndTime: now.Add(30 * time.Minute)},                 // 30 mins
+		{ProjectID: 1, StartTime: now.Add(2 * time.Hour), EndTime: now.Add(3 * time.Hour)}, // 60 mins
+		{ProjectID: 3, StartTime: now, EndTime: now.Add(0 * time.Minute)},                  // 0 mins
 		nil, // test nil entry
 	}
 
@@ -31,19 +31,37 @@ func TestCalculateProjectTotalsByProjectID(t *testing.T) {
 	}
 }
 
+func TestCalculateTotalsBy(t *testing.T) {
+	now := time.Now()
+	entries := []*chronos.Entry{
+		{Client: "Acme", Duration: 45},
+		{Client: "Globex", StartTime: now, EndTime: now.Add(30 * time.Minute)},
+		{Client: "Acme", Duration: 15},
+		{Duration: 10}, // no client
+		nil,
+	}
+
+	expected := map[string]float64{"Acme": 60, "Globex": 30, "(none)": 10}
+	actual := chronos.CalculateTotalsBy(entries, func(e *chronos.Entry) string { return e.Client })
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("CalculateTotalsBy: expected %v, got %v", expected, actual)
+	}
+}
+
 func TestCalculateReviewPeriodTotals(t *testing.T) {
 	now := time.Now()
 	periodStart := now.Add(-24 * time.Hour) // Last 24 hours
 
 	entries := []*chronos.Entry{
 		// Inside period
-		{StartTime: now.Add(-1 * time.Hour), EndTime: now},                                  // 60 mins
-		{StartTime: periodStart, EndTime: periodStart.Add(30 * time.Minute)},               // 30 mins
+		{StartTime: now.Add(-1 * time.Hour), EndTime: now},                   // 60 mins
+		{StartTime: periodStart, EndTime: periodStart.Add(30 * time.Minute)}, // 30 mins
 		// Outside period (before)
 		{StartTime: periodStart.Add(-2 * time.Hour), EndTime: periodStart.Add(-1 * time.Hour)}, // Should be ignored
 		// Partially inside (if logic were more complex, but current logic is based on StartTime >= periodStart)
 		{StartTime: now.Add(1 * time.Hour), EndTime: now.Add(2 * time.Hour)}, // 60 mins (future, but after periodStart)
-		nil, // test nil entry
+		nil,                      // test nil entry
 		{StartTime: time.Time{}}, // test zero time entry
 	}
 
@@ -112,25 +130,36 @@ func TestSortEntriesByStartTime(t *testing.T) {
 			chronos.SortEntriesByStartTime(tt.input, tt.asc)
 			if !reflect.DeepEqual(tt.input, tt.expected) {
 				t.Errorf("SortEntriesByStartTime (%s) incorrect.\nExpected order IDs:", tt.name)
-				for _, e := range tt.expected { if e != nil { t.Logf("  ID: %d, Time: %v", e.ID, e.StartTime) } else { t.Logf("  ID: nil")} }
+				for _, e := range tt.expected {
+					if e != nil {
+						t.Logf("  ID: %d, Time: %v", e.ID, e.StartTime)
+					} else {
+						t.Logf("  ID: nil")
+					}
+				}
 				t.Errorf("Got order IDs:")
-				for _, e := range tt.input { if e != nil { t.Logf("  ID: %d, Time: %v", e.ID, e.StartTime) } else { t.Logf("  ID: nil")} }
+				for _, e := range tt.input {
+					if e != nil {
+						t.Logf("  ID: %d, Time: %v", e.ID, e.StartTime)
+					} else {
+						t.Logf("  ID: nil")
+					}
+				}
 			}
 		})
 	}
 }
 
-
 func TestDetectIdleGaps(t *testing.T) {
 	y2023m1d1 := func(hour, min int) time.Time {
 		return time.Date(2023, 1, 1, hour, min, 0, 0, time.UTC)
 	}
 
 	entries := []*chronos.Entry{
-		{ID: 1, StartTime: y2023m1d1(9, 0), EndTime: y2023m1d1(10, 0)},  // Ends 10:00
+		{ID: 1, StartTime: y2023m1d1(9, 0), EndTime: y2023m1d1(10, 0)},   // Ends 10:00
 		{ID: 2, StartTime: y2023m1d1(10, 30), EndTime: y2023m1d1(11, 0)}, // Starts 10:30 (30 min gap)
-		{ID: 3, StartTime: y2023m1d1(14, 0), EndTime: y2023m1d1(15, 0)}, // Starts 14:00 (3 hour gap after entry 2 ends at 11:00)
-		{ID: 4, StartTime: y2023m1d1(15, 0), EndTime: y2023m1d1(16, 0)}, // Starts 15:00 (0 min gap - consecutive)
+		{ID: 3, StartTime: y2023m1d1(14, 0), EndTime: y2023m1d1(15, 0)},  // Starts 14:00 (3 hour gap after entry 2 ends at 11:00)
+		{ID: 4, StartTime: y2023m1d1(15, 0), EndTime: y2023m1d1(16, 0)},  // Starts 15:00 (0 min gap - consecutive)
 		// Unsorted entry to test sorting
 		{ID: 5, StartTime: y2023m1d1(11, 30), EndTime: y2023m1d1(12, 0)}, // Starts 11:30 (30 min gap after entry 2, before entry 3)
 	}
@@ -165,3 +194,26 @@ func TestDetectIdleGaps(t *testing.T) {
 		t.Errorf("DetectIdleGaps with 1 entry: expected 0 gaps, got %d", len(gaps))
 	}
 }
+
+func TestDetectIdleGaps_Segments(t *testing.T) {
+	at := func(hour, min int) time.Time { return time.Date(2023, 1, 1, hour, min, 0, 0, time.UTC) }
+
+	entries := []*chronos.Entry{
+		// One long entry with a recorded lunch break.
+		{ID: 1, StartTime: at(9, 0), EndTime: at(17, 0), Breaks: []chronos.Break{{StartTime: at(12, 0), EndTime: at(13, 30)}}},
+		// Overlaps the first entry's end; must not create a gap.
+		{ID: 2, StartTime: at(10, 0), EndTime: at(11, 0)},
+		{ID: 3, StartTime: at(19, 0), EndTime: at(20, 0)},
+	}
+
+	gaps := chronos.DetectIdleGaps(entries, time.Hour)
+	if len(gaps) != 2 {
+		t.Fatalf("expected 2 gaps, got %d: %+v", len(gaps), gaps)
+	}
+	if !gaps[0].StartTime.Equal(at(12, 0)) || gaps[0].Duration != 90*time.Minute || !gaps[0].Break {
+		t.Errorf("expected the lunch break as first gap, got %+v", gaps[0])
+	}
+	if !gaps[1].StartTime.Equal(at(17, 0)) || !gaps[1].EndTime.Equal(at(19, 0)) || gaps[1].Break {
+		t.Errorf("expected an untracked 17:00-19:00 gap, got %+v", gaps[1])
+	}
+}
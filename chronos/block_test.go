@@ -1,160 +1,163 @@
 package chronos_test
 
 import (
-	// "errors"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/regiellis/chronos-go/chronos"
-	// "github.com/regiellis/chronos-go/db"
-	// _ "github.com/mattn/go-sqlite3"
 )
 
-// Helper function to setup a test store (currently commented out)
-/*
-func setupTestDBForBlocks(t *testing.T) *db.Store {
-	t.Helper()
-	// store, err := db.NewStore(":memory:")
-	// if err != nil {
-	// 	t.Fatalf("Failed to create in-memory store for block tests: %v", err)
-	// }
-	// // Assumes InitSchema also creates blocks table.
-	// if err := store.InitSchema(); err != nil {
-	// 	t.Fatalf("Failed to init schema: %v", err)
-	// }
-	return nil // Placeholder
-}
-*/
-
 func TestCreateBlock(t *testing.T) {
-	t.Log("NOTE: TestCreateBlock is a placeholder due to DB initialization issues.")
-	// store := setupTestDBForBlocks(t)
-	// if store == nil {
-	// 	t.Skip("Skipping DB-dependent test: store is nil")
-	// }
-
-	now := time.Now()
-	block := &chronos.Block{
-		Name:      "Sprint Q1",
-		Client:    "BigCorp", // Assuming string fields for now
-		Project:   "Phoenix Project", // Assuming string fields for now
-		StartTime: now.Add(-24 * time.Hour),
-		EndTime:   now.Add(14 * 24 * time.Hour), // 2 weeks from yesterday
-		Active:    false, // Will be set by SetActiveBlock typically
-	}
-
-	// err := chronos.CreateBlock(store, block)
-	// if err != nil {
-	// 	t.Errorf("CreateBlock failed: %v", err)
-	// }
-	// if block.ID == 0 {
-	// 	t.Errorf("Expected block ID to be set, got 0")
-	// }
-	// if block.CreatedAt.IsZero() || block.UpdatedAt.IsZero() {
-	// 	t.Errorf("Expected CreatedAt/UpdatedAt to be set")
-	// }
-	// retrieved, _ := chronos.GetBlockByID(store, block.ID)
-	// if retrieved == nil || retrieved.Name != block.Name {
-	//  t.Errorf("Retrieved block mismatch or not found.")
-	// }
-}
-
-func TestGetBlockByID(t *testing.T) {
-	t.Log("NOTE: TestGetBlockByID is a placeholder.")
-	// store := setupTestDBForBlocks(t)
-	// if store == nil { t.SkipNow() }
-	// ... similar to TestGetProjectByID ...
+	eachRepository(t, func(t *testing.T, repo chronos.Repository) {
+		now := time.Now()
+		block := &chronos.Block{
+			Name:      "Sprint Q1",
+			Client:    "BigCorp",
+			Project:   "Phoenix Project",
+			StartTime: now.Add(-24 * time.Hour),
+			EndTime:   now.Add(14 * 24 * time.Hour), // 2 weeks from yesterday
+		}
+		if err := chronos.CreateBlock(repo, block); err != nil {
+			t.Fatalf("CreateBlock failed: %v", err)
+		}
+		if block.ID == 0 {
+			t.Errorf("Expected block ID to be set, got 0")
+		}
+		if block.CreatedAt.IsZero() || block.UpdatedAt.IsZero() {
+			t.Errorf("Expected CreatedAt/UpdatedAt to be set")
+		}
+
+		retrieved, err := chronos.GetBlockByID(repo, block.ID)
+		if err != nil {
+			t.Fatalf("GetBlockByID failed: %v", err)
+		}
+		if retrieved.Name != block.Name || retrieved.Client != block.Client || retrieved.Project != block.Project {
+			t.Errorf("Retrieved block mismatch: %+v", retrieved)
+		}
+		if !retrieved.EndTime.Equal(block.EndTime) {
+			t.Errorf("EndTime mismatch: expected %v, got %v", block.EndTime, retrieved.EndTime)
+		}
+	})
 }
 
 func TestGetBlockByID_NotFound(t *testing.T) {
-	t.Log("NOTE: TestGetBlockByID_NotFound is a placeholder.")
-	// store := setupTestDBForBlocks(t)
-	// if store == nil { t.SkipNow() }
-	// ... similar to TestGetProjectByID_NotFound ...
+	eachRepository(t, func(t *testing.T, repo chronos.Repository) {
+		_, err := chronos.GetBlockByID(repo, 424242)
+		if !errors.Is(err, chronos.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+	})
 }
 
 func TestUpdateBlock(t *testing.T) {
-	t.Log("NOTE: TestUpdateBlock is a placeholder.")
-	// store := setupTestDBForBlocks(t)
-	// if store == nil { t.SkipNow() }
-	// block := &chronos.Block{Name: "Old Name", /* ... */ }
-	// _ = chronos.CreateBlock(store, block)
-	// block.Name = "New Name"
-	// err := chronos.UpdateBlock(store, block)
-	// ... assertions ...
-	_ = time.Now
+	eachRepository(t, func(t *testing.T, repo chronos.Repository) {
+		block := &chronos.Block{Name: "Old Name", StartTime: time.Now()}
+		if err := chronos.CreateBlock(repo, block); err != nil {
+			t.Fatalf("CreateBlock failed: %v", err)
+		}
+		block.Name = "New Name"
+		block.EndTime = time.Now()
+		if err := chronos.UpdateBlock(repo, block); err != nil {
+			t.Fatalf("UpdateBlock failed: %v", err)
+		}
+		updated, err := chronos.GetBlockByID(repo, block.ID)
+		if err != nil {
+			t.Fatalf("GetBlockByID failed: %v", err)
+		}
+		if updated.Name != "New Name" || updated.EndTime.IsZero() {
+			t.Errorf("Block not updated: %+v", updated)
+		}
+	})
 }
 
 func TestDeleteBlock(t *testing.T) {
-	t.Log("NOTE: TestDeleteBlock is a placeholder.")
-	// store := setupTestDBForBlocks(t)
-	// if store == nil { t.SkipNow() }
-	// ... similar to TestDeleteProject ...
+	eachRepository(t, func(t *testing.T, repo chronos.Repository) {
+		block := &chronos.Block{Name: "Doomed", StartTime: time.Now()}
+		if err := chronos.CreateBlock(repo, block); err != nil {
+			t.Fatalf("CreateBlock failed: %v", err)
+		}
+		if err := chronos.DeleteBlock(repo, block.ID); err != nil {
+			t.Fatalf("DeleteBlock failed: %v", err)
+		}
+		if _, err := chronos.GetBlockByID(repo, block.ID); !errors.Is(err, chronos.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound after delete, got %v", err)
+		}
+	})
 }
 
 func TestListBlocks(t *testing.T) {
-	t.Log("NOTE: TestListBlocks is a placeholder.")
-	// store := setupTestDBForBlocks(t)
-	// if store == nil { t.SkipNow() }
-
-	// now := time.Now()
-	// _ = chronos.CreateBlock(store, &chronos.Block{Name: "B1", Client: "C1", Project: "P1", Active: true, StartTime: now})
-	// _ = chronos.CreateBlock(store, &chronos.Block{Name: "B2", Client: "C2", Project: "P2", Active: false, StartTime: now.Add(-1*time.Hour)})
-	// _ = chronos.CreateBlock(store, &chronos.Block{Name: "B3", Client: "C1", Project: "P3", Active: true, StartTime: now.Add(1*time.Hour)})
-
-	// all, _ := chronos.ListBlocks(store, nil)
-	// if len(all) < 3 { t.Errorf("Expected at least 3 blocks, got %d", len(all)) }
-
-	// activeList, _ := chronos.ListBlocks(store, map[string]interface{}{"active": true})
-	// if len(activeList) != 2 { t.Errorf("Expected 2 active blocks, got %d", len(activeList)) }
-	
-	// client1List, _ := chronos.ListBlocks(store, map[string]interface{}{"client": "C1"})
-	// if len(client1List) != 2 { t.Errorf("Expected 2 blocks for client C1, got %d", len(client1List)) }
+	eachRepository(t, func(t *testing.T, repo chronos.Repository) {
+		now := time.Now()
+		for _, b := range []*chronos.Block{
+			{Name: "B1", Client: "C1", Project: "P1", Active: true, StartTime: now},
+			{Name: "B2", Client: "C2", Project: "P2", Active: false, StartTime: now.Add(-1 * time.Hour)},
+			{Name: "B3", Client: "C1", Project: "P3", Active: true, StartTime: now.Add(1 * time.Hour)},
+		} {
+			if err := chronos.CreateBlock(repo, b); err != nil {
+				t.Fatalf("CreateBlock failed: %v", err)
+			}
+		}
+
+		all, err := chronos.ListBlocks(repo, nil)
+		if err != nil {
+			t.Fatalf("ListBlocks failed: %v", err)
+		}
+		if len(all) != 3 || all[0].Name != "B3" || all[2].Name != "B2" {
+			t.Errorf("Expected 3 blocks newest first, got %d", len(all))
+		}
+
+		activeList, _ := chronos.ListBlocks(repo, map[string]interface{}{"active": true})
+		if len(activeList) != 2 {
+			t.Errorf("Expected 2 active blocks, got %d", len(activeList))
+		}
+		client1List, _ := chronos.ListBlocks(repo, map[string]interface{}{"client": "C1"})
+		if len(client1List) != 2 {
+			t.Errorf("Expected 2 blocks for client C1, got %d", len(client1List))
+		}
+	})
 }
 
 func TestGetActiveBlock(t *testing.T) {
-	t.Log("NOTE: TestGetActiveBlock is a placeholder.")
-	// store := setupTestDBForBlocks(t)
-	// if store == nil { t.SkipNow() }
-
-	// now := time.Now()
-	// b1 := &chronos.Block{Name: "B1 Active", Active: true, StartTime: now}
-	// _ = chronos.CreateBlock(store, b1)
-	// _ = chronos.CreateBlock(store, &chronos.Block{Name: "B2 Inactive", Active: false, StartTime: now})
-
-	// activeBlock, err := chronos.GetActiveBlock(store)
-	// if err != nil { t.Fatalf("GetActiveBlock failed: %v", err) }
-	// if activeBlock == nil { t.Fatalf("GetActiveBlock returned nil when one active block exists") }
-	// if activeBlock.ID != b1.ID { t.Errorf("Incorrect active block returned. Expected ID %d, got %d", b1.ID, activeBlock.ID)}
-
-	// // Test when no block is active
-	// _ = chronos.UpdateBlock(store, b1) // Deactivate b1, assuming UpdateBlock changes Active status
-	// b1.Active = false
-	// _ = chronos.UpdateBlock(store, b1)
-	//
-	// noActiveBlock, errNoActive := chronos.GetActiveBlock(store)
-	// if errNoActive != nil && errNoActive != sql.ErrNoRows { // sql.ErrNoRows is expected if no active block
-	//  t.Fatalf("GetActiveBlock when none active failed: %v", errNoActive)
-	// }
-	// if noActiveBlock != nil {t.Errorf("Expected nil when no active block, got %+v", noActiveBlock)}
+	eachRepository(t, func(t *testing.T, repo chronos.Repository) {
+		none, err := chronos.GetActiveBlock(repo)
+		if err != nil || none != nil {
+			t.Fatalf("Expected nil, nil with no blocks; got %+v, %v", none, err)
+		}
+
+		b1 := &chronos.Block{Name: "B1 Active", Active: true, StartTime: time.Now()}
+		if err := chronos.CreateBlock(repo, b1); err != nil {
+			t.Fatalf("CreateBlock failed: %v", err)
+		}
+		_ = chronos.CreateBlock(repo, &chronos.Block{Name: "B2 Inactive", StartTime: time.Now()})
+
+		active, err := chronos.GetActiveBlock(repo)
+		if err != nil {
+			t.Fatalf("GetActiveBlock failed: %v", err)
+		}
+		if active == nil || active.ID != b1.ID {
+			t.Errorf("Expected active block %d, got %+v", b1.ID, active)
+		}
+	})
 }
 
 func TestSetActiveBlock(t *testing.T) {
-	t.Log("NOTE: TestSetActiveBlock is a placeholder.")
-	// store := setupTestDBForBlocks(t)
-	// if store == nil { t.SkipNow() }
-
-	// b1 := &chronos.Block{Name: "B1 to be active", Active: false, StartTime: time.Now()}
-	// b2 := &chronos.Block{Name: "B2 initially active", Active: true, StartTime: time.Now()}
-	// _ = chronos.CreateBlock(store, b1)
-	// _ = chronos.CreateBlock(store, b2)
-
-	// err := chronos.SetActiveBlock(store, b1.ID)
-	// if err != nil { t.Fatalf("SetActiveBlock failed: %v", err) }
-
-	// activeB, _ := chronos.GetActiveBlock(store)
-	// if activeB == nil || activeB.ID != b1.ID { t.Errorf("b1 was not set as active") }
-
-	// b2Updated, _ := chronos.GetBlockByID(store, b2.ID)
-	// if b2Updated.Active { t.Errorf("b2 was not deactivated") }
+	eachRepository(t, func(t *testing.T, repo chronos.Repository) {
+		b1 := &chronos.Block{Name: "B1 to be active", StartTime: time.Now()}
+		b2 := &chronos.Block{Name: "B2 initially active", Active: true, StartTime: time.Now()}
+		_ = chronos.CreateBlock(repo, b1)
+		_ = chronos.CreateBlock(repo, b2)
+
+		if err := chronos.SetActiveBlock(repo, b1.ID); err != nil {
+			t.Fatalf("SetActiveBlock failed: %v", err)
+		}
+		active, _ := chronos.GetActiveBlock(repo)
+		if active == nil || active.ID != b1.ID {
+			t.Errorf("b1 was not set as active")
+		}
+		b2Updated, _ := chronos.GetBlockByID(repo, b2.ID)
+		if b2Updated.Active {
+			t.Errorf("b2 was not deactivated")
+		}
+	})
 }
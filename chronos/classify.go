@@ -0,0 +1,247 @@
+package chronos
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Category is a project, client and task combination seen in past entries.
+type Category struct {
+	ProjectID int64  `json:"project_id"`
+	Project   string `json:"project"`
+	Client    string `json:"client"`
+	Task      string `json:"task"`
+	Count     int    `json:"count"` // Classified entries filed under it
+}
+
+// Classification proposes a category for an entry that is missing its
+// project or task. Source is "history" for the keyword model and "llm" for
+// the model; Confidence is between 0 and 1.
+type Classification struct {
+	EntryID int64 `json:"entry_id"`
+	Category
+	Confidence float64 `json:"confidence"`
+	Source     string  `json:"source"`
+}
+
+// IsUnclassified reports whether an entry lacks a project or a task.
+func IsUnclassified(e *Entry) bool {
+	return e != nil && (strings.TrimSpace(e.Project) == "" || strings.TrimSpace(e.Task) == "")
+}
+
+// Classifier proposes categories for unclassified entries from the words in
+// their summaries and tags, learned from entries that already have a project
+// and task. Words that point at few categories weigh the most.
+type Classifier struct {
+	categories []*category
+	// df counts the categories each word was seen in.
+	df map[string]int
+}
+
+type category struct {
+	Category
+	words map[string]int // Entries of this category containing the word
+}
+
+// NewClassifier learns categories from history; unclassified entries in it
+// are skipped.
+func NewClassifier(history []*Entry) *Classifier {
+	c := &Classifier{df: map[string]int{}}
+	byKey := map[[3]string]*category{}
+	for _, e := range history {
+		if e == nil || IsUnclassified(e) {
+			continue
+		}
+		key := [3]string{strings.ToLower(e.Project), strings.ToLower(e.Client), strings.ToLower(e.Task)}
+		cat, ok := byKey[key]
+		if !ok {
+			cat = &category{Category: Category{Project: e.Project, Client: e.Client, Task: e.Task}, words: map[string]int{}}
+			byKey[key] = cat
+			c.categories = append(c.categories, cat)
+		}
+		cat.Count++
+		if cat.ProjectID == 0 {
+			cat.ProjectID = e.ProjectID
+		}
+		for w := range entryWords(e) {
+			if cat.words[w] == 0 {
+				c.df[w]++
+			}
+			cat.words[w]++
+		}
+	}
+	sort.SliceStable(c.categories, func(i, j int) bool {
+		a, b := c.categories[i], c.categories[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Project != b.Project {
+			return a.Project < b.Project
+		}
+		return a.Task < b.Task
+	})
+	return c
+}
+
+// Categories lists the learned categories, most used first.
+func (c *Classifier) Categories() []Category {
+	out := make([]Category, len(c.categories))
+	for i, cat := range c.categories {
+		out[i] = cat.Category
+	}
+	return out
+}
+
+// Classify proposes a category for e. Only categories agreeing with the
+// fields e already has are considered, and those fields are kept as they
+// are. Without a matching word, an entry with a project or client gets the
+// most used category for it; an empty entry gets no proposal.
+func (c *Classifier) Classify(e *Entry) (Classification, bool) {
+	words := entryWords(e)
+	var candidates []*category
+	for _, cat := range c.categories {
+		if matchesField(e.Project, cat.Project) && matchesField(e.Client, cat.Client) && matchesField(e.Task, cat.Task) {
+			candidates = append(candidates, cat)
+		}
+	}
+	if len(candidates) == 0 {
+		return Classification{}, false
+	}
+
+	var best *category
+	var bestScore, total float64
+	for _, cat := range candidates {
+		var score float64
+		for w := range words {
+			if n := cat.words[w]; n > 0 {
+				idf := math.Log(1 + float64(len(c.categories))/float64(c.df[w]))
+				score += float64(n) / float64(cat.Count) * idf
+			}
+		}
+		total += score
+		if score > bestScore {
+			best, bestScore = cat, score
+		}
+	}
+	confidence := 0.0
+	if best != nil {
+		confidence = bestScore / total
+	} else {
+		if strings.TrimSpace(e.Project) == "" && strings.TrimSpace(e.Client) == "" {
+			return Classification{}, false
+		}
+		// Candidates are sorted by use, so the first is the most frequent.
+		var count int
+		for _, cat := range candidates {
+			count += cat.Count
+		}
+		best = candidates[0]
+		confidence = float64(best.Count) / float64(count)
+	}
+	return classificationFor(e, best.Category, confidence, "history"), true
+}
+
+// classificationFor fills the fields e is missing from cat.
+func classificationFor(e *Entry, cat Category, confidence float64, source string) Classification {
+	keep := func(have, proposed string) string {
+		if strings.TrimSpace(have) != "" {
+			return have
+		}
+		return proposed
+	}
+	out := Classification{
+		EntryID: e.ID,
+		Category: Category{
+			ProjectID: e.ProjectID,
+			Project:   keep(e.Project, cat.Project),
+			Client:    keep(e.Client, cat.Client),
+			Task:      keep(e.Task, cat.Task),
+			Count:     cat.Count,
+		},
+		Confidence: confidence,
+		Source:     source,
+	}
+	if out.ProjectID == 0 && strings.EqualFold(out.Project, cat.Project) {
+		out.ProjectID = cat.ProjectID
+	}
+	return out
+}
+
+// NewClassification proposes project, client and task for e, keeping the
+// fields e already has. It is used for proposals that do not come from a
+// Classifier, such as the LLM's.
+func NewClassification(e *Entry, project, client, task string, confidence float64, source string) Classification {
+	return classificationFor(e, Category{Project: project, Client: client, Task: task}, confidence, source)
+}
+
+func matchesField(have, candidate string) bool {
+	have = strings.TrimSpace(have)
+	return have == "" || strings.EqualFold(have, candidate)
+}
+
+// classifyStopWords are too common in summaries to say anything about them.
+var classifyStopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "from": true, "into": true,
+	"some": true, "more": true, "work": true, "worked": true,
+}
+
+// entryWords returns the distinct lower-cased words of e's summary and tags.
+func entryWords(e *Entry) map[string]bool {
+	words := map[string]bool{}
+	add := func(text string) {
+		for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			if len(w) > 2 && !classifyStopWords[w] {
+				words[w] = true
+			}
+		}
+	}
+	add(e.Summary)
+	for _, tag := range e.Tags {
+		add(tag)
+	}
+	return words
+}
+
+// ApplyClassifications files each classified entry under its proposed
+// project, client and task, filling only the fields the entry is still
+// missing. Entries without a project ID get the ID of the project of that
+// name, when there is one. It returns how many entries were updated.
+func ApplyClassifications(repo Repository, classifications []Classification) (int, error) {
+	projectIDs := map[string]int64{}
+	if projects, err := repo.ListProjects(nil); err == nil {
+		for _, p := range projects {
+			projectIDs[strings.ToLower(p.Name)] = p.ID
+		}
+	}
+	updated := 0
+	for _, c := range classifications {
+		entry, err := repo.GetEntry(c.EntryID)
+		if err != nil {
+			return updated, err
+		}
+		if strings.TrimSpace(entry.Project) == "" {
+			entry.Project = c.Project
+		}
+		if strings.TrimSpace(entry.Client) == "" {
+			entry.Client = c.Client
+		}
+		if strings.TrimSpace(entry.Task) == "" {
+			entry.Task = c.Task
+		}
+		if entry.ProjectID == 0 {
+			entry.ProjectID = c.ProjectID
+			if id, ok := projectIDs[strings.ToLower(entry.Project)]; ok {
+				entry.ProjectID = id
+			}
+		}
+		if err := UpdateEntry(repo, entry); err != nil {
+			return updated, err
+		}
+		updated++
+	}
+	return updated, nil
+}
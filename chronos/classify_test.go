@@ -0,0 +1,70 @@
+package chronos_test
+
+import (
+	"testing"
+
+	"github.com/regiellis/chronos-go/chronos"
+)
+
+func TestClassifier(t *testing.T) {
+	history := []*chronos.Entry{
+		{ID: 1, ProjectID: 7, Project: "Apollo", Client: "Acme", Task: "API", Summary: "Auth endpoints"},
+		{ID: 2, ProjectID: 7, Project: "Apollo", Client: "Acme", Task: "API", Summary: "Token refresh endpoint"},
+		{ID: 3, Project: "Apollo", Client: "Acme", Task: "Design", Summary: "Dashboard mockups", Tags: []string{"figma"}},
+		{ID: 4, Project: "Zeus", Client: "Globex", Task: "Ops", Summary: "Deploy pipeline"},
+		{ID: 5, Project: "Zeus", Client: "Globex", Task: "Ops", Summary: "Deploy hotfix"},
+		{ID: 6, Summary: "Deploy endpoints"}, // Unclassified entries teach nothing
+	}
+	c := chronos.NewClassifier(history)
+	if cats := c.Categories(); len(cats) != 3 || cats[0].Count != 2 || cats[2].Task != "Design" {
+		t.Fatalf("unexpected categories: %+v", cats)
+	}
+
+	got, ok := c.Classify(&chronos.Entry{ID: 10, Summary: "New endpoints for billing"})
+	if !ok || got.EntryID != 10 || got.Project != "Apollo" || got.Task != "API" || got.ProjectID != 7 || got.Source != "history" {
+		t.Errorf("endpoints should go to Apollo / API, got %+v", got)
+	}
+	if got.Confidence <= 0.5 || got.Confidence > 1 {
+		t.Errorf("confidence out of range: %v", got.Confidence)
+	}
+
+	got, ok = c.Classify(&chronos.Entry{Project: "Zeus", Summary: "Figma review"})
+	if !ok || got.Project != "Zeus" || got.Task != "Ops" || got.Client != "Globex" {
+		t.Errorf("a known project should keep its own categories, got %+v", got)
+	}
+
+	got, ok = c.Classify(&chronos.Entry{Project: "Apollo", Task: "Meetings", Summary: "Standup"})
+	if ok {
+		t.Errorf("no category matches an entry's own task, got %+v", got)
+	}
+	if _, ok := c.Classify(&chronos.Entry{Summary: "Lunch"}); ok {
+		t.Error("an entry with no known words should get no proposal")
+	}
+}
+
+func TestApplyClassifications(t *testing.T) {
+	eachRepository(t, func(t *testing.T, repo chronos.Repository) {
+		project := &chronos.Project{Name: "Apollo"}
+		if err := chronos.CreateProject(repo, project); err != nil {
+			t.Fatalf("CreateProject failed: %v", err)
+		}
+		entry := &chronos.Entry{Client: "Acme", Summary: "Auth endpoints", Duration: 30}
+		if err := chronos.CreateEntry(repo, entry); err != nil {
+			t.Fatalf("CreateEntry failed: %v", err)
+		}
+		proposal := chronos.NewClassification(entry, "Apollo", "Other", "API", 0.9, "llm")
+		if n, err := chronos.ApplyClassifications(repo, []chronos.Classification{proposal}); n != 1 || err != nil {
+			t.Fatalf("ApplyClassifications: %d, %v", n, err)
+		}
+		got, err := chronos.GetEntryByID(repo, entry.ID)
+		if err != nil {
+			t.Fatalf("GetEntryByID failed: %v", err)
+		}
+		if got.Project != "Apollo" || got.Task != "API" || got.Client != "Acme" || got.ProjectID != project.ID {
+			t.Errorf("entry not classified: %+v", got)
+		}
+		if chronos.IsUnclassified(got) {
+			t.Error("classified entry still reported as unclassified")
+		}
+	})
+}
@@ -2,8 +2,6 @@ package chronos
 
 import (
 	"time"
-
-	"github.com/regiellis/chronos-go/db"
 )
 
 // Client represents a client in the system.
@@ -15,76 +13,30 @@ type Client struct {
 	UpdatedAt   time.Time `json:"updated_at"`
 }
 
-// CreateClient adds a new client to the database.
-func CreateClient(store *db.Store, client *Client) error {
+// CreateClient adds a new client to the repository.
+func CreateClient(repo Repository, client *Client) error {
 	client.CreatedAt = time.Now()
 	client.UpdatedAt = time.Now()
-
-	query := `
-		INSERT INTO clients (name, contact_info, created_at, updated_at)
-		VALUES (?, ?, ?, ?)`
-	res, err := store.DB.Exec(query, client.Name, client.ContactInfo, client.CreatedAt, client.UpdatedAt)
-	if err != nil {
-		return err
-	}
-	id, err := res.LastInsertId()
-	if err != nil {
-		return err
-	}
-	client.ID = id
-	return nil
+	return repo.CreateClient(client)
 }
 
-// GetClientByID retrieves a client from the database by its ID.
-func GetClientByID(store *db.Store, id int64) (*Client, error) {
-	client := &Client{}
-	query := `
-		SELECT id, name, contact_info, created_at, updated_at
-		FROM clients WHERE id = ?`
-	err := store.DB.QueryRow(query, id).Scan(&client.ID, &client.Name, &client.ContactInfo, &client.CreatedAt, &client.UpdatedAt)
-	if err != nil {
-		return nil, err
-	}
-	return client, nil
+// GetClientByID retrieves a client by its ID.
+func GetClientByID(repo Repository, id int64) (*Client, error) {
+	return repo.GetClient(id)
 }
 
-// UpdateClient updates an existing client in the database.
-func UpdateClient(store *db.Store, client *Client) error {
+// UpdateClient updates an existing client in the repository.
+func UpdateClient(repo Repository, client *Client) error {
 	client.UpdatedAt = time.Now()
-	query := `
-		UPDATE clients
-		SET name = ?, contact_info = ?, updated_at = ?
-		WHERE id = ?`
-	_, err := store.DB.Exec(query, client.Name, client.ContactInfo, client.UpdatedAt, client.ID)
-	return err
+	return repo.UpdateClient(client)
 }
 
-// DeleteClient removes a client from the database by its ID.
-func DeleteClient(store *db.Store, id int64) error {
-	query := "DELETE FROM clients WHERE id = ?"
-	_, err := store.DB.Exec(query, id)
-	return err
+// DeleteClient removes a client by its ID.
+func DeleteClient(repo Repository, id int64) error {
+	return repo.DeleteClient(id)
 }
 
-// ListClients retrieves a list of all clients from the database.
-func ListClients(store *db.Store) ([]*Client, error) {
-	query := `
-		SELECT id, name, contact_info, created_at, updated_at
-		FROM clients`
-	rows, err := store.DB.Query(query)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	clients := []*Client{}
-	for rows.Next() {
-		client := &Client{}
-		err := rows.Scan(&client.ID, &client.Name, &client.ContactInfo, &client.CreatedAt, &client.UpdatedAt)
-		if err != nil {
-			return nil, err
-		}
-		clients = append(clients, client)
-	}
-	return clients, nil
+// ListClients retrieves all clients.
+func ListClients(repo Repository) ([]*Client, error) {
+	return repo.ListClients()
 }
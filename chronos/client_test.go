@@ -1,175 +1,109 @@
 package chronos_test
 
 import (
-	// "errors"
+	"errors"
 	"testing"
-	"time"
 
 	"github.com/regiellis/chronos-go/chronos"
-	// "github.com/regiellis/chronos-go/db"
-	// _ "github.com/mattn/go-sqlite3"
 )
 
-// Helper function to setup a test store (currently commented out)
-/*
-func setupTestDBForClients(t *testing.T) *db.Store {
-	t.Helper()
-	// store, err := db.NewStore(":memory:")
-	// if err != nil {
-	// 	t.Fatalf("Failed to create in-memory store for client tests: %v", err)
-	// }
-	// // IMPORTANT: Assumes InitSchema also creates clients table.
-	// // If not, EnsureClientsTable would be needed here.
-	// if err := store.InitSchema(); err != nil {
-	// 	t.Fatalf("Failed to init schema: %v", err)
-	// }
-	// return store
-	return nil // Placeholder
-}
-*/
-
 func TestCreateClient(t *testing.T) {
-	t.Log("NOTE: TestCreateClient is a placeholder due to DB initialization issues.")
-	// store := setupTestDBForClients(t)
-	// if store == nil {
-	// 	t.Skip("Skipping DB-dependent test: store is nil")
-	// }
-
-	client := &chronos.Client{
-		Name:        "Test Client Inc.",
-		ContactInfo: "contact@testclient.com",
-	}
-
-	// err := chronos.CreateClient(store, client)
-	// if err != nil {
-	// 	t.Errorf("CreateClient failed: %v", err)
-	// }
-	// if client.ID == 0 {
-	// 	t.Errorf("Expected client ID to be set after creation, got 0")
-	// }
-	// if client.CreatedAt.IsZero() {
-	// 	t.Errorf("Expected CreatedAt to be set")
-	// }
-	// if client.UpdatedAt.IsZero() {
-	// 	t.Errorf("Expected UpdatedAt to be set")
-	// }
-
-	// Further verification: GetClientByID
-	// retrievedClient, getErr := chronos.GetClientByID(store, client.ID)
-	// if getErr != nil {
-	//  t.Fatalf("GetClientByID after CreateClient failed: %v", getErr)
-	// }
-	// if retrievedClient.Name != client.Name {
-	//  t.Errorf("Name mismatch: expected %s, got %s", client.Name, retrievedClient.Name)
-	// }
+	eachRepository(t, func(t *testing.T, repo chronos.Repository) {
+		client := &chronos.Client{
+			Name:        "Test Client Inc.",
+			ContactInfo: "contact@testclient.com",
+		}
+		if err := chronos.CreateClient(repo, client); err != nil {
+			t.Fatalf("CreateClient failed: %v", err)
+		}
+		if client.ID == 0 {
+			t.Errorf("Expected client ID to be set after creation, got 0")
+		}
+		if client.CreatedAt.IsZero() || client.UpdatedAt.IsZero() {
+			t.Errorf("Expected CreatedAt/UpdatedAt to be set")
+		}
+
+		// Client names are unique.
+		if err := chronos.CreateClient(repo, &chronos.Client{Name: "Test Client Inc."}); err == nil {
+			t.Errorf("Expected duplicate client name to be rejected")
+		}
+	})
 }
 
 func TestGetClientByID(t *testing.T) {
-	t.Log("NOTE: TestGetClientByID is a placeholder due to DB initialization issues.")
-	// store := setupTestDBForClients(t)
-	// if store == nil {
-	// 	t.Skip("Skipping DB-dependent test: store is nil")
-	// }
-
-	// clientToCreate := &chronos.Client{Name: "Fetchable Client", ContactInfo: "fetch@example.com"}
-	// _ = chronos.CreateClient(store, clientToCreate) // Assume it works
-
-	// retrieved, err := chronos.GetClientByID(store, clientToCreate.ID)
-	// if err != nil {
-	// 	t.Errorf("GetClientByID failed: %v", err)
-	// }
-	// if retrieved == nil {
-	// 	t.Fatalf("GetClientByID returned nil for existing client")
-	// }
-	// if retrieved.Name != clientToCreate.Name {
-	// 	t.Errorf("Name mismatch: expected %s, got %s", clientToCreate.Name, retrieved.Name)
-	// }
+	eachRepository(t, func(t *testing.T, repo chronos.Repository) {
+		created := &chronos.Client{Name: "Fetchable Client", ContactInfo: "fetch@example.com"}
+		if err := chronos.CreateClient(repo, created); err != nil {
+			t.Fatalf("CreateClient failed: %v", err)
+		}
+		retrieved, err := chronos.GetClientByID(repo, created.ID)
+		if err != nil {
+			t.Fatalf("GetClientByID failed: %v", err)
+		}
+		if retrieved.Name != created.Name || retrieved.ContactInfo != created.ContactInfo {
+			t.Errorf("Retrieved client mismatch: %+v", retrieved)
+		}
+	})
 }
 
 func TestGetClientByID_NotFound(t *testing.T) {
-	t.Log("NOTE: TestGetClientByID_NotFound is a placeholder due to DB initialization issues.")
-	// store := setupTestDBForClients(t)
-	// if store == nil {
-	// 	t.Skip("Skipping DB-dependent test: store is nil")
-	// }
-
-	// _, err := chronos.GetClientByID(store, 88888) // Non-existent ID
-	// if err == nil {
-	// 	t.Errorf("Expected an error for non-existent client, got nil")
-	// }
-	// // e.g. if errors.Is(err, chronos.ErrNotFound)
+	eachRepository(t, func(t *testing.T, repo chronos.Repository) {
+		_, err := chronos.GetClientByID(repo, 99999)
+		if !errors.Is(err, chronos.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+	})
 }
 
 func TestUpdateClient(t *testing.T) {
-	t.Log("NOTE: TestUpdateClient is a placeholder due to DB initialization issues.")
-	// store := setupTestDBForClients(t)
-	// if store == nil {
-	// 	t.Skip("Skipping DB-dependent test: store is nil")
-	// }
-
-	// client := &chronos.Client{Name: "Original Client Name", ContactInfo: "original@example.com"}
-	// _ = chronos.CreateClient(store, client)
-
-	// client.Name = "Updated Client Name"
-	// client.ContactInfo = "updated@example.com"
-	// originalUpdatedAt := client.UpdatedAt
-
-	// time.Sleep(10 * time.Millisecond) // Ensure UpdatedAt might change
-
-	// err := chronos.UpdateClient(store, client)
-	// if err != nil {
-	// 	t.Errorf("UpdateClient failed: %v", err)
-	// }
-
-	// updatedClient, _ := chronos.GetClientByID(store, client.ID)
-	// if updatedClient.Name != "Updated Client Name" {
-	// 	t.Errorf("Name not updated")
-	// }
-	// if updatedClient.ContactInfo != "updated@example.com" {
-	// 	t.Errorf("ContactInfo not updated")
-	// }
-	// if updatedClient.UpdatedAt.Equal(originalUpdatedAt) || updatedClient.UpdatedAt.Before(originalUpdatedAt) {
-	//  t.Errorf("UpdatedAt not advanced")
-	// }
-	_ = time.Now // dummy usage
+	eachRepository(t, func(t *testing.T, repo chronos.Repository) {
+		client := &chronos.Client{Name: "Original Name", ContactInfo: "original@example.com"}
+		if err := chronos.CreateClient(repo, client); err != nil {
+			t.Fatalf("CreateClient failed: %v", err)
+		}
+		client.Name = "Updated Name"
+		client.ContactInfo = "updated@example.com"
+		if err := chronos.UpdateClient(repo, client); err != nil {
+			t.Fatalf("UpdateClient failed: %v", err)
+		}
+		updated, err := chronos.GetClientByID(repo, client.ID)
+		if err != nil {
+			t.Fatalf("GetClientByID failed: %v", err)
+		}
+		if updated.Name != "Updated Name" || updated.ContactInfo != "updated@example.com" {
+			t.Errorf("Client not updated: %+v", updated)
+		}
+	})
 }
 
 func TestDeleteClient(t *testing.T) {
-	t.Log("NOTE: TestDeleteClient is a placeholder due to DB initialization issues.")
-	// store := setupTestDBForClients(t)
-	// if store == nil {
-	// 	t.Skip("Skipping DB-dependent test: store is nil")
-	// }
-
-	// client := &chronos.Client{Name: "Client To Delete"}
-	// _ = chronos.CreateClient(store, client)
-
-	// err := chronos.DeleteClient(store, client.ID)
-	// if err != nil {
-	// 	t.Errorf("DeleteClient failed: %v", err)
-	// }
-
-	// _, getErr := chronos.GetClientByID(store, client.ID)
-	// if getErr == nil {
-	// 	t.Errorf("Expected error when getting deleted client, got nil")
-	// }
+	eachRepository(t, func(t *testing.T, repo chronos.Repository) {
+		client := &chronos.Client{Name: "To Be Deleted"}
+		if err := chronos.CreateClient(repo, client); err != nil {
+			t.Fatalf("CreateClient failed: %v", err)
+		}
+		if err := chronos.DeleteClient(repo, client.ID); err != nil {
+			t.Fatalf("DeleteClient failed: %v", err)
+		}
+		if _, err := chronos.GetClientByID(repo, client.ID); !errors.Is(err, chronos.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound after delete, got %v", err)
+		}
+	})
 }
 
 func TestListClients(t *testing.T) {
-	t.Log("NOTE: TestListClients is a placeholder due to DB initialization issues.")
-	// store := setupTestDBForClients(t)
-	// if store == nil {
-	// 	t.Skip("Skipping DB-dependent test: store is nil")
-	// }
-
-	// _ = chronos.CreateClient(store, &chronos.Client{Name: "Client X"})
-	// _ = chronos.CreateClient(store, &chronos.Client{Name: "Client Y"})
-
-	// clients, err := chronos.ListClients(store)
-	// if err != nil {
-	// 	t.Errorf("ListClients failed: %v", err)
-	// }
-	// if len(clients) < 2 { // Check against expected number
-	// 	t.Errorf("ListClients: expected at least 2 clients, got %d", len(clients))
-	// }
+	eachRepository(t, func(t *testing.T, repo chronos.Repository) {
+		for _, name := range []string{"Client A", "Client B"} {
+			if err := chronos.CreateClient(repo, &chronos.Client{Name: name}); err != nil {
+				t.Fatalf("CreateClient failed: %v", err)
+			}
+		}
+		clients, err := chronos.ListClients(repo)
+		if err != nil {
+			t.Fatalf("ListClients failed: %v", err)
+		}
+		if len(clients) != 2 || clients[0].Name != "Client A" {
+			t.Errorf("Expected [Client A, Client B], got %d clients", len(clients))
+		}
+	})
 }
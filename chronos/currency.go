@@ -0,0 +1,325 @@
+package chronos
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultCurrency is the home currency when none is configured.
+const DefaultCurrency = "USD"
+
+// Currency describes how amounts in a currency are written.
+type Currency struct {
+	Code        string
+	Symbol      string
+	Decimals    int
+	SymbolAfter bool // "100.00 kr" rather than "kr100.00"
+}
+
+var currencies = map[string]Currency{
+	"USD": {Code: "USD", Symbol: "$", Decimals: 2},
+	"EUR": {Code: "EUR", Symbol: "€", Decimals: 2},
+	"GBP": {Code: "GBP", Symbol: "£", Decimals: 2},
+	"JPY": {Code: "JPY", Symbol: "¥", Decimals: 0},
+	"CHF": {Code: "CHF", Symbol: "CHF ", Decimals: 2},
+	"CAD": {Code: "CAD", Symbol: "CA$", Decimals: 2},
+	"AUD": {Code: "AUD", Symbol: "A$", Decimals: 2},
+	"NZD": {Code: "NZD", Symbol: "NZ$", Decimals: 2},
+	"INR": {Code: "INR", Symbol: "₹", Decimals: 2},
+	"SEK": {Code: "SEK", Symbol: " kr", Decimals: 2, SymbolAfter: true},
+	"NOK": {Code: "NOK", Symbol: " kr", Decimals: 2, SymbolAfter: true},
+	"DKK": {Code: "DKK", Symbol: " kr.", Decimals: 2, SymbolAfter: true},
+	"PLN": {Code: "PLN", Symbol: " zł", Decimals: 2, SymbolAfter: true},
+}
+
+// LookupCurrency returns how to write amounts in code. Unknown codes are
+// written with two decimals and the code after the amount.
+func LookupCurrency(code string) Currency {
+	code = NormalizeCurrency(code)
+	if c, ok := currencies[code]; ok {
+		return c
+	}
+	return Currency{Code: code, Symbol: " " + code, Decimals: 2, SymbolAfter: true}
+}
+
+// NormalizeCurrency upper-cases a currency code and defaults it to
+// DefaultCurrency.
+func NormalizeCurrency(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return DefaultCurrency
+	}
+	return code
+}
+
+// ValidCurrency reports whether code looks like an ISO 4217 code.
+func ValidCurrency(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// FormatMoney writes amount in code with its symbol, decimals and thousands
+// separators, e.g. "$1,234.50", "-€10.00" or "1,200.00 kr".
+func FormatMoney(amount float64, code string) string {
+	c := LookupCurrency(code)
+	sign := ""
+	if amount < 0 && math.Round(-amount*math.Pow10(c.Decimals)) != 0 {
+		sign = "-"
+	}
+	digits := strconv.FormatFloat(math.Abs(amount), 'f', c.Decimals, 64)
+	whole, frac, _ := strings.Cut(digits, ".")
+	var b strings.Builder
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+	if frac != "" {
+		b.WriteString("." + frac)
+	}
+	if c.SymbolAfter {
+		return sign + b.String() + c.Symbol
+	}
+	return sign + c.Symbol + b.String()
+}
+
+// CurrencySetting makes a project or client bill in a currency. A project's
+// setting beats its client's; everything else bills in the home currency.
+type CurrencySetting struct {
+	ID     int64  `json:"id"`
+	Scope  string `json:"scope"`  // RateProject or RateClient
+	Target string `json:"target"` // Project or client name
+	Code   string `json:"code"`
+}
+
+// SetCurrency makes the project or client named target bill in code,
+// replacing its previous currency.
+func SetCurrency(repo Repository, scope, target, code string) error {
+	if scope != RateProject && scope != RateClient {
+		return fmt.Errorf("currencies are set for a project or client, not %q", scope)
+	}
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return fmt.Errorf("a %s currency needs a %s", scope, scope)
+	}
+	code = NormalizeCurrency(code)
+	if !ValidCurrency(code) {
+		return fmt.Errorf("invalid currency code %q (want three letters, e.g. EUR)", code)
+	}
+	return repo.SetCurrency(&CurrencySetting{Scope: scope, Target: target, Code: code})
+}
+
+// ListCurrencySettings returns every project and client currency.
+func ListCurrencySettings(repo Repository) ([]*CurrencySetting, error) {
+	return repo.ListCurrencySettings()
+}
+
+// ExchangeRate says that from Date on, one unit of From is worth Rate units
+// of To.
+type ExchangeRate struct {
+	ID        int64     `json:"id"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Rate      float64   `json:"rate"`
+	Date      time.Time `json:"date"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateExchangeRate validates and stores an exchange rate.
+func CreateExchangeRate(repo Repository, r *ExchangeRate) error {
+	r.From, r.To = NormalizeCurrency(r.From), NormalizeCurrency(r.To)
+	switch {
+	case !ValidCurrency(r.From) || !ValidCurrency(r.To):
+		return fmt.Errorf("invalid currency pair %s/%s", r.From, r.To)
+	case r.From == r.To:
+		return fmt.Errorf("an exchange rate needs two different currencies")
+	case r.Rate <= 0:
+		return fmt.Errorf("exchange rate %s/%s must be positive", r.From, r.To)
+	}
+	if r.CreatedAt.IsZero() {
+		r.CreatedAt = time.Now()
+	}
+	return repo.CreateExchangeRate(r)
+}
+
+// ListExchangeRates returns every exchange rate, oldest first.
+func ListExchangeRates(repo Repository) ([]*ExchangeRate, error) {
+	return repo.ListExchangeRates()
+}
+
+// ParseExchangeRatesCSV reads exchange rates as date,from,to,rate rows, with
+// dates as YYYY-MM-DD. A header row and blank lines are skipped.
+func ParseExchangeRatesCSV(r io.Reader) ([]*ExchangeRate, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	var rates []*ExchangeRate
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rates, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+		if len(record) != 4 {
+			return nil, fmt.Errorf("line %d: want date,from,to,rate, got %d fields", line, len(record))
+		}
+		date, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(record[0]), time.Local)
+		if err != nil {
+			if line == 1 {
+				continue // Header
+			}
+			return nil, fmt.Errorf("line %d: invalid date %q", line, record[0])
+		}
+		rate, err := strconv.ParseFloat(strings.TrimSpace(record[3]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid rate %q", line, record[3])
+		}
+		rates = append(rates, &ExchangeRate{Date: date, From: record[1], To: record[2], Rate: rate})
+	}
+}
+
+// Currencies knows which currency entries bill in and converts between
+// currencies with the stored exchange rates.
+type Currencies struct {
+	Home     string
+	settings []*CurrencySetting
+	rates    []*ExchangeRate
+}
+
+// NewCurrencies loads the currency settings and exchange rates from repo.
+// home is the currency of everything without a setting and the one Convert
+// totals go to.
+func NewCurrencies(repo Repository, home string) (*Currencies, error) {
+	settings, err := repo.ListCurrencySettings()
+	if err != nil {
+		return nil, err
+	}
+	rates, err := repo.ListExchangeRates()
+	if err != nil {
+		return nil, err
+	}
+	return &Currencies{Home: NormalizeCurrency(home), settings: settings, rates: rates}, nil
+}
+
+// Of returns the currency e bills in: its project's, else its client's,
+// else the home currency.
+func (c *Currencies) Of(e *Entry) string {
+	client := ""
+	for _, s := range c.settings {
+		switch {
+		case s.Scope == RateProject && e.Project != "" && strings.EqualFold(s.Target, e.Project):
+			return s.Code
+		case s.Scope == RateClient && e.Client != "" && strings.EqualFold(s.Target, e.Client):
+			client = s.Code
+		}
+	}
+	if client != "" {
+		return client
+	}
+	return c.Home
+}
+
+// Apply sets Currency on every entry.
+func (c *Currencies) Apply(entries []*Entry) {
+	for _, e := range entries {
+		if e != nil {
+			e.Currency = c.Of(e)
+		}
+	}
+}
+
+// Convert converts amount from one currency to another at the latest rate
+// dated on or before at, using the inverse of a rate entered the other way
+// round if need be. It reports false when there is no such rate.
+func (c *Currencies) Convert(amount float64, from, to string, at time.Time) (float64, bool) {
+	from, to = NormalizeCurrency(from), NormalizeCurrency(to)
+	if from == to {
+		return amount, true
+	}
+	var best *ExchangeRate
+	for _, r := range c.rates {
+		if r.Date.After(at) || !((r.From == from && r.To == to) || (r.From == to && r.To == from)) {
+			continue
+		}
+		if best == nil || !r.Date.Before(best.Date) {
+			best = r
+		}
+	}
+	if best == nil {
+		return 0, false
+	}
+	if best.From == from {
+		return amount * best.Rate, true
+	}
+	return amount / best.Rate, true
+}
+
+// CurrencyTotal is the amount billed in one currency.
+type CurrencyTotal struct {
+	Currency string  `json:"currency"`
+	Amount   float64 `json:"amount"`
+}
+
+// RevenueTotals is billed revenue per currency and converted into the home
+// currency. Unconverted lists the currencies some amounts could not be
+// converted from for lack of an exchange rate; they are left out of Home.
+type RevenueTotals struct {
+	ByCurrency  []CurrencyTotal `json:"by_currency"`
+	HomeCode    string          `json:"home_currency"`
+	Home        float64         `json:"home"`
+	Unconverted []string        `json:"unconverted,omitempty"`
+}
+
+// Revenue totals the amounts of entries, whose Currency must be set, per
+// currency and in the home currency at each entry's date.
+func (c *Currencies) Revenue(entries []*Entry) RevenueTotals {
+	t := RevenueTotals{HomeCode: c.Home}
+	byCode := map[string]float64{}
+	missing := map[string]bool{}
+	for _, e := range entries {
+		if e == nil || e.Amount() == 0 {
+			continue
+		}
+		code := NormalizeCurrency(e.Currency)
+		byCode[code] += e.Amount()
+		at := e.StartTime
+		if at.IsZero() {
+			at = e.CreatedAt
+		}
+		if home, ok := c.Convert(e.Amount(), code, c.Home, at); ok {
+			t.Home += home
+		} else {
+			missing[code] = true
+		}
+	}
+	for code, amount := range byCode {
+		t.ByCurrency = append(t.ByCurrency, CurrencyTotal{Currency: code, Amount: cents(amount)})
+	}
+	sort.Slice(t.ByCurrency, func(i, j int) bool { return t.ByCurrency[i].Currency < t.ByCurrency[j].Currency })
+	for code := range missing {
+		t.Unconverted = append(t.Unconverted, code)
+	}
+	sort.Strings(t.Unconverted)
+	t.Home = cents(t.Home)
+	return t
+}
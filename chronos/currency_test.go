@@ -0,0 +1,121 @@
+package chronos_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/regiellis/chronos-go/chronos"
+)
+
+func TestFormatMoney(t *testing.T) {
+	for _, tc := range []struct {
+		amount float64
+		code   string
+		want   string
+	}{
+		{1234.5, "USD", "$1,234.50"},
+		{-10, "eur", "-€10.00"},
+		{1234567.891, "GBP", "£1,234,567.89"},
+		{1500.4, "JPY", "¥1,500"},
+		{1200, "SEK", "1,200.00 kr"},
+		{99.999, "", "$100.00"},
+		{-0.001, "USD", "$0.00"},
+		{12, "XYZ", "12.00 XYZ"},
+	} {
+		if got := chronos.FormatMoney(tc.amount, tc.code); got != tc.want {
+			t.Errorf("FormatMoney(%v, %q) = %q, want %q", tc.amount, tc.code, got, tc.want)
+		}
+	}
+}
+
+func TestParseExchangeRatesCSV(t *testing.T) {
+	rates, err := chronos.ParseExchangeRatesCSV(strings.NewReader("date,from,to,rate\n2026-01-01,EUR,USD,1.10\n\n2026-02-01, eur, usd, 1.2\n"))
+	if err != nil || len(rates) != 2 {
+		t.Fatalf("ParseExchangeRatesCSV = %v, %v", rates, err)
+	}
+	if rates[1].From != "eur" || rates[1].Rate != 1.2 || rates[1].Date.Month() != time.February {
+		t.Errorf("second rate: %+v", rates[1])
+	}
+	if _, err := chronos.ParseExchangeRatesCSV(strings.NewReader("2026-01-01,EUR,USD,1.1\nsoon,EUR,USD,1.2\n")); err == nil {
+		t.Error("a bad date after the first line should be refused")
+	}
+	if _, err := chronos.ParseExchangeRatesCSV(strings.NewReader("2026-01-01,EUR,USD\n")); err == nil {
+		t.Error("a short row should be refused")
+	}
+}
+
+func TestCurrencies(t *testing.T) {
+	eachRepository(t, func(t *testing.T, repo chronos.Repository) {
+		if err := chronos.SetCurrency(repo, chronos.RateClient, "Acme", "gbp"); err != nil {
+			t.Fatalf("SetCurrency failed: %v", err)
+		}
+		// Setting it again replaces rather than adds.
+		if err := chronos.SetCurrency(repo, chronos.RateClient, "acme", "eur"); err != nil {
+			t.Fatalf("SetCurrency failed: %v", err)
+		}
+		if err := chronos.SetCurrency(repo, chronos.RateProject, "Apollo", "JPY"); err != nil {
+			t.Fatalf("SetCurrency failed: %v", err)
+		}
+		if err := chronos.SetCurrency(repo, chronos.RateClient, "Acme", "euro"); err == nil {
+			t.Error("an invalid code should be refused")
+		}
+		if err := chronos.SetCurrency(repo, chronos.RateBlock, "1", "EUR"); err == nil {
+			t.Error("block currencies should be refused")
+		}
+		settings, err := chronos.ListCurrencySettings(repo)
+		if err != nil || len(settings) != 2 || settings[0].Code != "EUR" || settings[0].ID == 0 {
+			t.Fatalf("ListCurrencySettings = %+v, %v", settings, err)
+		}
+
+		jan := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		for _, r := range []*chronos.ExchangeRate{
+			{From: "EUR", To: "USD", Rate: 1.5, Date: jan},
+			{From: "usd", To: "eur", Rate: 0.8, Date: jan.AddDate(0, 2, 0)},
+		} {
+			if err := chronos.CreateExchangeRate(repo, r); err != nil {
+				t.Fatalf("CreateExchangeRate failed: %v", err)
+			}
+		}
+		if err := chronos.CreateExchangeRate(repo, &chronos.ExchangeRate{From: "EUR", To: "EUR", Rate: 1, Date: jan}); err == nil {
+			t.Error("a rate between the same currency should be refused")
+		}
+		if rates, err := chronos.ListExchangeRates(repo); err != nil || len(rates) != 2 || rates[1].From != "USD" {
+			t.Fatalf("ListExchangeRates = %+v, %v", rates, err)
+		}
+
+		c, err := chronos.NewCurrencies(repo, "usd")
+		if err != nil {
+			t.Fatalf("NewCurrencies failed: %v", err)
+		}
+		acme := &chronos.Entry{Project: "Website", Client: "Acme", StartTime: jan.AddDate(0, 1, 0), Duration: 60, Billable: true, Rate: 100}
+		apollo := &chronos.Entry{Project: "Apollo", Client: "Acme", StartTime: jan, Duration: 60, Billable: true, Rate: 10000}
+		home := &chronos.Entry{Project: "Internal", StartTime: jan, Duration: 60, Billable: true, Rate: 50}
+		late := &chronos.Entry{Project: "Website", Client: "Acme", StartTime: jan.AddDate(0, 3, 0), Duration: 60, Billable: true, Rate: 100}
+		entries := []*chronos.Entry{acme, apollo, home, late}
+		c.Apply(entries)
+		if acme.Currency != "EUR" || apollo.Currency != "JPY" || home.Currency != "USD" {
+			t.Fatalf("currencies: %s %s %s", acme.Currency, apollo.Currency, home.Currency)
+		}
+
+		if got, ok := c.Convert(100, "EUR", "USD", acme.StartTime); !ok || got != 150 {
+			t.Errorf("Convert before the inverse rate = %v, %v", got, ok)
+		}
+		// The later USD/EUR rate is used inverted.
+		if got, ok := c.Convert(100, "EUR", "USD", late.StartTime); !ok || got != 125 {
+			t.Errorf("Convert with the inverse rate = %v, %v", got, ok)
+		}
+		if _, ok := c.Convert(100, "EUR", "USD", jan.AddDate(-1, 0, 0)); ok {
+			t.Error("no rate before the first one should be used")
+		}
+
+		rev := c.Revenue(entries)
+		if len(rev.ByCurrency) != 3 || rev.ByCurrency[0].Currency != "EUR" || rev.ByCurrency[0].Amount != 200 {
+			t.Errorf("revenue by currency: %+v", rev.ByCurrency)
+		}
+		// 50 USD + 150 + 125 from EUR; the yen have no rate.
+		if rev.HomeCode != "USD" || rev.Home != 325 || len(rev.Unconverted) != 1 || rev.Unconverted[0] != "JPY" {
+			t.Errorf("revenue in home currency: %+v", rev)
+		}
+	})
+}
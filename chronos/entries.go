@@ -1,136 +1,77 @@
 package chronos
 
 import (
-	"database/sql"
 	"fmt"
-	"strings"
 	"time"
 
-	"github.com/regiellis/chronos-go/db" // Assumed import path for db.Store
-	// Assuming chronos.Entry is defined in the same package or imported appropriately.
-	// If chronos.Entry is in "github.com/regiellis/chronos-go/chronos", it would be just "Entry" here.
+	"github.com/regiellis/chronos-go/utils"
 )
 
-// CreateEntry adds a new entry to the database.
-// Assumes entry.CreatedAt and entry.UpdatedAt will be set by the caller or here.
-func CreateEntry(store *db.Store, entry *Entry) error {
+func sanitizeEntry(entry *Entry) {
+	entry.Project = utils.SanitizeString(entry.Project)
+	entry.Client = utils.SanitizeString(entry.Client)
+	entry.Task = utils.SanitizeString(entry.Task)
+	entry.Summary = utils.SanitizeDescription(entry.Summary)
+	tags := entry.Tags[:0]
+	for _, tag := range entry.Tags {
+		if tag = utils.SanitizeString(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	entry.Tags = tags
+}
+
+// CreateEntry adds a new entry to the repository.
+// CreatedAt defaults to now; UpdatedAt is always set here.
+func CreateEntry(repo Repository, entry *Entry) error {
 	if entry.CreatedAt.IsZero() {
 		entry.CreatedAt = time.Now()
 	}
 	entry.UpdatedAt = time.Now()
-
-	query := `
-		INSERT INTO entries (block_id, project_id, summary, start_time, end_time, created_at, updated_at, invoiced)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
-	res, err := store.DB.Exec(query, entry.BlockID, entry.ProjectID, entry.Summary, entry.StartTime, entry.EndTime, entry.CreatedAt, entry.UpdatedAt, entry.Invoiced)
-	if err != nil {
-		return fmt.Errorf("CreateEntry: failed to execute insert: %w", err)
-	}
-	id, err := res.LastInsertId()
-	if err != nil {
-		return fmt.Errorf("CreateEntry: failed to get last insert ID: %w", err)
-	}
-	entry.ID = id
-	return nil
+	sanitizeEntry(entry)
+	entry.normalizeTimes()
+	return repo.CreateEntry(entry)
 }
 
-// GetEntryByID retrieves an entry from the database by its ID.
-func GetEntryByID(store *db.Store, id int64) (*Entry, error) {
-	entry := &Entry{}
-	query := `
-		SELECT id, block_id, project_id, summary, start_time, end_time, created_at, updated_at, invoiced
-		FROM entries WHERE id = ?`
-	err := store.DB.QueryRow(query, id).Scan(
-		&entry.ID, &entry.BlockID, &entry.ProjectID, &entry.Summary,
-		&entry.StartTime, &entry.EndTime, &entry.CreatedAt, &entry.UpdatedAt, &entry.Invoiced,
-	)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("GetEntryByID: no entry found with ID %d: %w", id, err)
-		}
-		return nil, fmt.Errorf("GetEntryByID: failed to scan row: %w", err)
-	}
-	return entry, nil
+// GetEntryByID retrieves an entry by its ID. A missing entry wraps ErrNotFound.
+func GetEntryByID(repo Repository, id int64) (*Entry, error) {
+	return repo.GetEntry(id)
 }
 
-// UpdateEntry updates an existing entry in the database.
-func UpdateEntry(store *db.Store, entry *Entry) error {
+// UpdateEntry updates an existing entry in the repository.
+func UpdateEntry(repo Repository, entry *Entry) error {
 	entry.UpdatedAt = time.Now()
-	query := `
-		UPDATE entries
-		SET block_id = ?, project_id = ?, summary = ?, start_time = ?, end_time = ?, updated_at = ?, invoiced = ?
-		WHERE id = ?`
-	_, err := store.DB.Exec(query, entry.BlockID, entry.ProjectID, entry.Summary, entry.StartTime, entry.EndTime, entry.UpdatedAt, entry.Invoiced, entry.ID)
-	if err != nil {
-		return fmt.Errorf("UpdateEntry: failed to execute update: %w", err)
-	}
-	return nil
+	sanitizeEntry(entry)
+	entry.normalizeTimes()
+	return repo.UpdateEntry(entry)
 }
 
-// DeleteEntry removes an entry from the database by its ID.
-func DeleteEntry(store *db.Store, id int64) error {
-	query := "DELETE FROM entries WHERE id = ?"
-	_, err := store.DB.Exec(query, id)
+// DeleteEntry removes an entry by its ID. An entry on a draft, issued or
+// paid invoice is refused (ErrAlreadyInvoiced) until the invoice is voided.
+func DeleteEntry(repo Repository, id int64) error {
+	inv, err := EntryInvoice(repo, id)
 	if err != nil {
-		return fmt.Errorf("DeleteEntry: failed to execute delete: %w", err)
+		return err
 	}
-	return nil
-}
-
-// ListEntries retrieves a list of entries from the database, optionally filtered.
-// Example filters: "block_id", "project_id", "invoiced", "start_date", "end_date"
-func ListEntries(store *db.Store, filters map[string]interface{}) ([]*Entry, error) {
-	baseQuery := "SELECT id, block_id, project_id, summary, start_time, end_time, created_at, updated_at, invoiced FROM entries"
-	var conditions []string
-	var args []interface{}
-
-	for key, value := range filters {
-		switch key {
-		case "block_id":
-			conditions = append(conditions, "block_id = ?")
-			args = append(args, value)
-		case "project_id":
-			conditions = append(conditions, "project_id = ?")
-			args = append(args, value)
-		case "invoiced":
-			conditions = append(conditions, "invoiced = ?")
-			args = append(args, value)
-		case "start_date": // Assumes value is time.Time or string parsable to time
-			conditions = append(conditions, "date(start_time) >= date(?)")
-			args = append(args, value)
-		case "end_date": // Assumes value is time.Time or string parsable to time
-			conditions = append(conditions, "date(start_time) <= date(?)")
-			args = append(args, value)
-		// Add more filters as needed
-		}
+	if inv != nil {
+		return fmt.Errorf("entry %d is on invoice %s; void the invoice to delete it: %w", id, inv.Label(), ErrAlreadyInvoiced)
 	}
+	return repo.DeleteEntry(id)
+}
 
-	query := baseQuery
-	if len(conditions) > 0 {
-		query += " WHERE " + strings.Join(conditions, " AND ")
-	}
-	query += " ORDER BY start_time DESC" // Default ordering
+// ListEntries retrieves entries, newest first, optionally filtered.
+// Supported filters: "block_id", "project_id", "project", "client", "task", "billable",
+// "invoiced", "open", "tag", "start_date", "end_date", "min_duration", "max_duration", "min_rate", "max_rate".
+func ListEntries(repo Repository, filters map[string]interface{}) ([]*Entry, error) {
+	return repo.ListEntries(filters)
+}
 
-	rows, err := store.DB.Query(query, args...)
-	if err != nil {
-		return nil, fmt.Errorf("ListEntries: failed to execute query: %w", err)
-	}
-	defer rows.Close()
+// FindUnbilledEntries returns finished billable entries that have not been invoiced yet.
+func FindUnbilledEntries(repo Repository) ([]*Entry, error) {
+	return repo.ListEntries(map[string]interface{}{"billable": true, "invoiced": false, "open": false})
+}
 
-	entries := []*Entry{}
-	for rows.Next() {
-		entry := &Entry{}
-		err := rows.Scan(
-			&entry.ID, &entry.BlockID, &entry.ProjectID, &entry.Summary,
-			&entry.StartTime, &entry.EndTime, &entry.CreatedAt, &entry.UpdatedAt, &entry.Invoiced,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("ListEntries: failed to scan row: %w", err)
-		}
-		entries = append(entries, entry)
-	}
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("ListEntries: error during rows iteration: %w", err)
-	}
-	return entries, nil
+// MarkEntriesInvoiced flags the given entries as invoiced.
+func MarkEntriesInvoiced(repo Repository, ids []int64) error {
+	return repo.MarkEntriesInvoiced(ids)
 }
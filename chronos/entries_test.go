@@ -1,162 +1,215 @@
 package chronos_test
 
 import (
-	// "errors"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/regiellis/chronos-go/chronos"
-	// "github.com/regiellis/chronos-go/db"
-	// _ "github.com/mattn/go-sqlite3"
 )
 
-// Helper function to setup a test store (currently commented out)
-/*
-func setupTestDBForEntries(t *testing.T) *db.Store {
-	t.Helper()
-	// store, err := db.NewStore(":memory:")
-	// if err != nil {
-	// 	t.Fatalf("Failed to create in-memory store for entry tests: %v", err)
-	// }
-	// // Assumes InitSchema creates entries, projects, blocks tables.
-	// if err := store.InitSchema(); err != nil {
-	// 	t.Fatalf("Failed to init schema: %v", err)
-	// }
-	// // May need to pre-populate dummy project/block if foreign keys are strict
-	// // dummyProject := &chronos.Project{Name: "Test Project For Entry", ClientID: 1, Rate: 0}
-	// // chronos.CreateProject(store, dummyProject)
-	// // dummyBlock := &chronos.Block{Name: "Test Block For Entry", StartTime: time.Now()}
-	// // chronos.CreateBlock(store, dummyBlock)
-	return nil // Placeholder
-}
-*/
-
 func TestCreateEntry(t *testing.T) {
-	t.Log("NOTE: TestCreateEntry is a placeholder due to DB initialization issues.")
-	// store := setupTestDBForEntries(t)
-	// if store == nil {
-	// 	t.Skip("Skipping DB-dependent test: store is nil")
-	// }
-
-	now := time.Now()
-	entry := &chronos.Entry{
-		BlockID:   1, // Assuming block ID 1 exists
-		ProjectID: 1, // Assuming project ID 1 exists
-		Summary:   "Worked on API integration",
-		StartTime: now.Add(-2 * time.Hour),
-		EndTime:   now.Add(-1 * time.Hour),
-		Invoiced:  false,
-	}
-
-	// err := chronos.CreateEntry(store, entry)
-	// if err != nil {
-	// 	t.Errorf("CreateEntry failed: %v", err)
-	// }
-	// if entry.ID == 0 {
-	// 	t.Errorf("Expected entry ID to be set, got 0")
-	// }
-	// if entry.CreatedAt.IsZero() {
-	// 	t.Errorf("Expected CreatedAt to be set")
-	// }
-	// if entry.UpdatedAt.IsZero() {
-	// 	t.Errorf("Expected UpdatedAt to be set")
-	// }
-
-	// retrieved, _ := chronos.GetEntryByID(store, entry.ID)
-	// if retrieved == nil || retrieved.Summary != entry.Summary {
-	//  t.Errorf("Retrieved entry mismatch or not found.")
-	// }
+	eachRepository(t, func(t *testing.T, repo chronos.Repository) {
+		now := time.Now()
+		entry := &chronos.Entry{
+			BlockID:   1,
+			ProjectID: 1,
+			Project:   "Apollo",
+			Client:    "Acme Corp",
+			Task:      "Coding",
+			Summary:   "Worked on API integration",
+			StartTime: now.Add(-2 * time.Hour),
+			EndTime:   now.Add(-1 * time.Hour),
+			Billable:  true,
+			Rate:      80,
+			Invoiced:  false,
+		}
+
+		if err := chronos.CreateEntry(repo, entry); err != nil {
+			t.Fatalf("CreateEntry failed: %v", err)
+		}
+		if entry.ID == 0 {
+			t.Errorf("Expected entry ID to be set, got 0")
+		}
+		if entry.CreatedAt.IsZero() || entry.UpdatedAt.IsZero() {
+			t.Errorf("Expected CreatedAt/UpdatedAt to be set")
+		}
+		if entry.Duration != 60 {
+			t.Errorf("Expected Duration to be derived from start/end (60), got %d", entry.Duration)
+		}
+
+		retrieved, err := chronos.GetEntryByID(repo, entry.ID)
+		if err != nil {
+			t.Fatalf("GetEntryByID failed: %v", err)
+		}
+		if retrieved.Summary != entry.Summary || retrieved.Project != "Apollo" || retrieved.Client != "Acme Corp" || retrieved.Task != "Coding" {
+			t.Errorf("Retrieved entry mismatch: %+v", retrieved)
+		}
+		if !retrieved.Billable || retrieved.Rate != 80 {
+			t.Errorf("Billing fields not persisted: billable=%t rate=%.2f", retrieved.Billable, retrieved.Rate)
+		}
+	})
 }
 
-func TestGetEntryByID(t *testing.T) {
-	t.Log("NOTE: TestGetEntryByID is a placeholder due to DB initialization issues.")
-	// store := setupTestDBForEntries(t)
-	// if store == nil {
-	// 	t.Skip("Skipping DB-dependent test: store is nil")
-	// }
-	// Test logic similar to GetProjectByID / GetClientByID
+func TestCreateEntry_DurationOnly(t *testing.T) {
+	eachRepository(t, func(t *testing.T, repo chronos.Repository) {
+		start := time.Date(2025, 5, 1, 9, 0, 0, 0, time.Local)
+		entry := &chronos.Entry{Project: "Zeus", StartTime: start, Duration: 90}
+		if err := chronos.CreateEntry(repo, entry); err != nil {
+			t.Fatalf("CreateEntry failed: %v", err)
+		}
+		if want := start.Add(90 * time.Minute); !entry.EndTime.Equal(want) {
+			t.Errorf("Expected EndTime %v, got %v", want, entry.EndTime)
+		}
+	})
 }
 
 func TestGetEntryByID_NotFound(t *testing.T) {
-	t.Log("NOTE: TestGetEntryByID_NotFound is a placeholder due to DB initialization issues.")
-	// store := setupTestDBForEntries(t)
-	// if store == nil {
-	// 	t.Skip("Skipping DB-dependent test: store is nil")
-	// }
-	// _, err := chronos.GetEntryByID(store, 77777)
-	// if err == nil { t.Error("Expected error, got nil") }
+	eachRepository(t, func(t *testing.T, repo chronos.Repository) {
+		_, err := chronos.GetEntryByID(repo, 77777)
+		if !errors.Is(err, chronos.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+	})
 }
 
 func TestUpdateEntry(t *testing.T) {
-	t.Log("NOTE: TestUpdateEntry is a placeholder due to DB initialization issues.")
-	// store := setupTestDBForEntries(t)
-	// if store == nil {
-	// 	t.Skip("Skipping DB-dependent test: store is nil")
-	// }
-
-	// entry := &chronos.Entry{ /* ... create one ... */ Summary: "Original Summary" }
-	// _ = chronos.CreateEntry(store, entry)
-
-	// entry.Summary = "Updated Summary"
-	// entry.Invoiced = true
-	// originalUpdatedAt := entry.UpdatedAt
-	// time.Sleep(10 * time.Millisecond)
-
-	// errUpdate := chronos.UpdateEntry(store, entry)
-	// if errUpdate != nil { t.Errorf("UpdateEntry failed: %v", errUpdate) }
-
-	// updatedEntry, _ := chronos.GetEntryByID(store, entry.ID)
-	// if updatedEntry.Summary != "Updated Summary" { t.Error("Summary not updated") }
-	// if !updatedEntry.Invoiced { t.Error("Invoiced status not updated") }
-	// if updatedEntry.UpdatedAt.Equal(originalUpdatedAt) {t.Error("UpdatedAt not advanced")}
-	_ = time.Now
+	eachRepository(t, func(t *testing.T, repo chronos.Repository) {
+		entry := &chronos.Entry{Summary: "Original Summary", StartTime: time.Now(), Duration: 30}
+		if err := chronos.CreateEntry(repo, entry); err != nil {
+			t.Fatalf("CreateEntry failed: %v", err)
+		}
+
+		entry.Summary = "Updated Summary"
+		entry.Invoiced = true
+		if err := chronos.UpdateEntry(repo, entry); err != nil {
+			t.Fatalf("UpdateEntry failed: %v", err)
+		}
+
+		updatedEntry, err := chronos.GetEntryByID(repo, entry.ID)
+		if err != nil {
+			t.Fatalf("GetEntryByID failed: %v", err)
+		}
+		if updatedEntry.Summary != "Updated Summary" {
+			t.Error("Summary not updated")
+		}
+		if !updatedEntry.Invoiced {
+			t.Error("Invoiced status not updated")
+		}
+	})
 }
 
 func TestDeleteEntry(t *testing.T) {
-	t.Log("NOTE: TestDeleteEntry is a placeholder due to DB initialization issues.")
-	// store := setupTestDBForEntries(t)
-	// if store == nil {
-	// 	t.Skip("Skipping DB-dependent test: store is nil")
-	// }
-	// Test logic similar to DeleteProject / DeleteClient
+	eachRepository(t, func(t *testing.T, repo chronos.Repository) {
+		entry := &chronos.Entry{Summary: "To be deleted", StartTime: time.Now(), Duration: 15}
+		if err := chronos.CreateEntry(repo, entry); err != nil {
+			t.Fatalf("CreateEntry failed: %v", err)
+		}
+		if err := chronos.DeleteEntry(repo, entry.ID); err != nil {
+			t.Fatalf("DeleteEntry failed: %v", err)
+		}
+		if _, err := chronos.GetEntryByID(repo, entry.ID); err == nil {
+			t.Error("Expected error when getting deleted entry, got nil")
+		}
+	})
 }
 
 func TestListEntries(t *testing.T) {
-	t.Log("NOTE: TestListEntries is a placeholder due to DB initialization issues.")
-	// store := setupTestDBForEntries(t)
-	// if store == nil {
-	// 	t.Skip("Skipping DB-dependent test: store is nil")
-	// }
-
-	// now := time.Now()
-	// projectID1 := int64(1)
-	// blockID1 := int64(1)
-
-	// _ = chronos.CreateEntry(store, &chronos.Entry{ProjectID: projectID1, BlockID: blockID1, Summary: "E1", StartTime: now.Add(-5*time.Hour), EndTime: now.Add(-4*time.Hour)})
-	// _ = chronos.CreateEntry(store, &chronos.Entry{ProjectID: 2, BlockID: blockID1, Summary: "E2", StartTime: now.Add(-3*time.Hour), EndTime: now.Add(-2*time.Hour), Invoiced: true})
-	// _ = chronos.CreateEntry(store, &chronos.Entry{ProjectID: projectID1, BlockID: 2, Summary: "E3", StartTime: now.Add(-1*time.Hour), EndTime: now})
-
-	// // Test list all
-	// all, _ := chronos.ListEntries(store, nil)
-	// if len(all) < 3 { t.Errorf("Expected at least 3 entries, got %d", len(all)) }
-
-	// // Test filter by ProjectID
-	// p1Entries, _ := chronos.ListEntries(store, map[string]interface{}{"project_id": projectID1})
-	// if len(p1Entries) != 2 { t.Errorf("Expected 2 entries for projectID1, got %d", len(p1Entries)) }
-
-	// // Test filter by BlockID
-	// b1Entries, _ := chronos.ListEntries(store, map[string]interface{}{"block_id": blockID1})
-	// if len(b1Entries) != 2 { t.Errorf("Expected 2 entries for blockID1, got %d", len(b1Entries)) }
-
-	// // Test filter by Invoiced
-	// invoicedEntries, _ := chronos.ListEntries(store, map[string]interface{}{"invoiced": true})
-	// if len(invoicedEntries) != 1 { t.Errorf("Expected 1 invoiced entry, got %d", len(invoicedEntries)) }
-	
-	// // Test filter by date range (start_date, end_date)
-	// dateRangeEntries, _ := chronos.ListEntries(store, map[string]interface{}{
-	//  "start_date": now.Add(-3 * time.Hour), // Inclusive start
-	//  "end_date":   now.Add(-1 * time.Hour), // Inclusive end
-	// })
-	// // Should catch E2 (starts -3h) and E3 (starts -1h)
-	// if len(dateRangeEntries) != 2 { t.Errorf("Expected 2 entries in date range, got %d", len(dateRangeEntries)) }
+	eachRepository(t, func(t *testing.T, repo chronos.Repository) {
+		now := time.Now()
+		mustCreate := func(e *chronos.Entry) {
+			t.Helper()
+			if err := chronos.CreateEntry(repo, e); err != nil {
+				t.Fatalf("CreateEntry failed: %v", err)
+			}
+		}
+		mustCreate(&chronos.Entry{Project: "Apollo", Client: "Acme", BlockID: 1, Summary: "E1", StartTime: now.Add(-5 * time.Hour), EndTime: now.Add(-4 * time.Hour), Billable: true})
+		mustCreate(&chronos.Entry{Project: "Hermes", Client: "Globex", BlockID: 1, Summary: "E2", StartTime: now.Add(-3 * time.Hour), EndTime: now.Add(-2 * time.Hour), Invoiced: true, Billable: true})
+		mustCreate(&chronos.Entry{Project: "Apollo", Client: "Acme", BlockID: 2, Summary: "E3", StartTime: now.Add(-1 * time.Hour), EndTime: now})
+
+		all, err := chronos.ListEntries(repo, nil)
+		if err != nil {
+			t.Fatalf("ListEntries failed: %v", err)
+		}
+		if len(all) != 3 {
+			t.Errorf("Expected 3 entries, got %d", len(all))
+		}
+		if all[0].Summary != "E3" {
+			t.Errorf("Expected newest entry first, got %s", all[0].Summary)
+		}
+
+		cases := []struct {
+			name    string
+			filters map[string]interface{}
+			want    int
+		}{
+			{"project", map[string]interface{}{"project": "Apollo"}, 2},
+			{"client", map[string]interface{}{"client": "Globex"}, 1},
+			{"block", map[string]interface{}{"block_id": 1}, 2},
+			{"invoiced", map[string]interface{}{"invoiced": true}, 1},
+			{"unbilled", map[string]interface{}{"billable": true, "invoiced": false}, 1},
+		}
+		for _, c := range cases {
+			got, err := chronos.ListEntries(repo, c.filters)
+			if err != nil {
+				t.Fatalf("ListEntries(%s) failed: %v", c.name, err)
+			}
+			if len(got) != c.want {
+				t.Errorf("ListEntries(%s): expected %d entries, got %d", c.name, c.want, len(got))
+			}
+		}
+	})
+}
+
+func TestMarkEntriesInvoiced(t *testing.T) {
+	eachRepository(t, func(t *testing.T, repo chronos.Repository) {
+		e1 := &chronos.Entry{Summary: "E1", StartTime: time.Now(), Duration: 30, Billable: true}
+		e2 := &chronos.Entry{Summary: "E2", StartTime: time.Now(), Duration: 30, Billable: true}
+		for _, e := range []*chronos.Entry{e1, e2} {
+			if err := chronos.CreateEntry(repo, e); err != nil {
+				t.Fatalf("CreateEntry failed: %v", err)
+			}
+		}
+		if err := chronos.MarkEntriesInvoiced(repo, []int64{e1.ID}); err != nil {
+			t.Fatalf("MarkEntriesInvoiced failed: %v", err)
+		}
+		unbilled, err := chronos.FindUnbilledEntries(repo)
+		if err != nil {
+			t.Fatalf("FindUnbilledEntries failed: %v", err)
+		}
+		if len(unbilled) != 1 || unbilled[0].ID != e2.ID {
+			t.Errorf("Expected only E2 to remain unbilled, got %+v", unbilled)
+		}
+	})
+}
+
+func TestEntryTags(t *testing.T) {
+	eachRepository(t, func(t *testing.T, repo chronos.Repository) {
+		start := time.Date(2025, 5, 2, 9, 0, 0, 0, time.Local)
+		tagged := &chronos.Entry{Project: "Apollo", StartTime: start, Duration: 30, Tags: []string{"meeting", "client-call"}}
+		plain := &chronos.Entry{Project: "Apollo", StartTime: start.Add(time.Hour), Duration: 15}
+		for _, e := range []*chronos.Entry{tagged, plain} {
+			if err := chronos.CreateEntry(repo, e); err != nil {
+				t.Fatalf("CreateEntry failed: %v", err)
+			}
+		}
+
+		retrieved, err := chronos.GetEntryByID(repo, tagged.ID)
+		if err != nil {
+			t.Fatalf("GetEntryByID failed: %v", err)
+		}
+		if len(retrieved.Tags) != 2 || retrieved.Tags[0] != "meeting" || retrieved.Tags[1] != "client-call" {
+			t.Errorf("Tags not persisted: %v", retrieved.Tags)
+		}
+
+		for tag, want := range map[string]int{"meeting": 1, "client-call": 1, "meet": 0} {
+			entries, err := chronos.ListEntries(repo, map[string]interface{}{"tag": tag})
+			if err != nil {
+				t.Fatalf("ListEntries failed: %v", err)
+			}
+			if len(entries) != want {
+				t.Errorf("tag %q: expected %d entries, got %d", tag, want, len(entries))
+			}
+		}
+	})
 }
@@ -2,17 +2,80 @@ package chronos
 
 import "time"
 
+// Entry is a single piece of tracked work. It is the one entry model shared by
+// the CLI commands, the TUI and every export.
 type Entry struct {
-	ID          int64
-	BlockID     int64
-	Project     string
-	Client      string
-	Task        string
-	Description string
-	Duration    int64
-	EntryTime   time.Time
-	CreatedAt   time.Time
-	Billable    bool
-	Rate        float64
-	Invoiced    bool
+	ID        int64     `json:"id"`
+	BlockID   int64     `json:"block_id"`
+	ProjectID int64     `json:"project_id"`
+	Project   string    `json:"project"`
+	Client    string    `json:"client"`
+	Task      string    `json:"task"`
+	Summary   string    `json:"summary"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"` // Zero while the entry is still open
+	Duration  int64     `json:"duration"` // Minutes
+	Billable  bool      `json:"billable"`
+	Rate      float64   `json:"rate"`
+	Invoiced  bool      `json:"invoiced"`
+	Tags      []string  `json:"tags,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Breaks    []Break   `json:"breaks,omitempty"`         // Loaded by the repository; saved via PauseTimer/ResumeTimer
+	Billed    *float64  `json:"billed_minutes,omitempty"` // Minutes after rounding, set by Rounder.Apply; never stored
+	Currency  string    `json:"currency,omitempty"`       // Set by Currencies.Apply; never stored
+}
+
+// Minutes returns the tracked duration of the entry in minutes.
+// The stored Duration wins; otherwise the worked segments between start and
+// end are summed, so breaks are never counted. Open entries count as 0.
+func (e *Entry) Minutes() float64 {
+	if e.Duration > 0 {
+		return float64(e.Duration)
+	}
+	if e.StartTime.IsZero() || e.EndTime.IsZero() {
+		return 0
+	}
+	return e.Worked(e.EndTime).Minutes()
+}
+
+// Hours returns the tracked duration of the entry in hours.
+func (e *Entry) Hours() float64 {
+	return e.Minutes() / 60.0
+}
+
+// BilledMinutes returns the minutes charged for the entry: its rounded time
+// when a rounding rule has been applied, otherwise the tracked time.
+func (e *Entry) BilledMinutes() float64 {
+	if e.Billed != nil {
+		return *e.Billed
+	}
+	return e.Minutes()
+}
+
+// BilledHours returns the time charged for the entry in hours.
+func (e *Entry) BilledHours() float64 {
+	return e.BilledMinutes() / 60.0
+}
+
+// Amount returns the billable amount of the entry's billed time at its own
+// rate.
+func (e *Entry) Amount() float64 {
+	if !e.Billable {
+		return 0
+	}
+	return e.BilledHours() * e.Rate
+}
+
+// normalizeTimes fills in whichever of Duration and EndTime is missing so the
+// stored entry is always self-consistent.
+func (e *Entry) normalizeTimes() {
+	switch {
+	case e.StartTime.IsZero():
+		return
+	case e.EndTime.IsZero() && e.Duration > 0:
+		e.EndTime = e.StartTime.Add(time.Duration(e.Duration) * time.Minute)
+	case !e.EndTime.IsZero() && e.Duration == 0:
+		e.Duration = int64(e.Worked(e.EndTime).Minutes())
+	}
 }
@@ -0,0 +1,58 @@
+package chronos
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// Interaction kinds, one per assistant command.
+const (
+	KindAsk       = "ask"
+	KindSuggest   = "suggest"
+	KindRemind    = "remind"
+	KindSummarize = "summarize"
+	KindReview    = "review"
+)
+
+// Interaction is one saved assistant exchange. ContextHash identifies the
+// data the answer was based on, so a re-run can tell whether it changed, and
+// BlockID is the block a summary was about.
+type Interaction struct {
+	ID          int64     `json:"id"`
+	Kind        string    `json:"kind"`
+	Question    string    `json:"question"`
+	Answer      string    `json:"answer"`
+	Model       string    `json:"model"`
+	ContextHash string    `json:"context_hash"`
+	BlockID     int64     `json:"block_id,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// SaveInteraction stores an assistant exchange in the history.
+func SaveInteraction(repo Repository, i *Interaction) error {
+	if i.CreatedAt.IsZero() {
+		i.CreatedAt = time.Now()
+	}
+	return repo.SaveInteraction(i)
+}
+
+// GetInteraction retrieves a saved exchange by ID. A missing one wraps ErrNotFound.
+func GetInteraction(repo Repository, id int64) (*Interaction, error) {
+	return repo.GetInteraction(id)
+}
+
+// ListInteractions returns the last limit exchanges, most recent first.
+func ListInteractions(repo Repository, limit int) ([]*Interaction, error) {
+	return repo.ListInteractions(limit)
+}
+
+// ContextHash fingerprints the entries and blocks an answer was based on.
+func ContextHash(entries []*Entry, blocks []*Block) string {
+	h := sha256.New()
+	enc := json.NewEncoder(h)
+	enc.Encode(entries)
+	enc.Encode(blocks)
+	return hex.EncodeToString(h.Sum(nil))
+}
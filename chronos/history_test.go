@@ -0,0 +1,57 @@
+package chronos_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/regiellis/chronos-go/chronos"
+)
+
+func TestInteractionHistory(t *testing.T) {
+	eachRepository(t, func(t *testing.T, repo chronos.Repository) {
+		entries := []*chronos.Entry{{ID: 1, Project: "Apollo", Duration: 60}}
+		hash := chronos.ContextHash(entries, nil)
+		for _, q := range []string{"first?", "second?", "third?"} {
+			i := &chronos.Interaction{Kind: chronos.KindSummarize, Question: q, Answer: "answer to " + q, Model: "llama3", ContextHash: hash, BlockID: 7}
+			if err := chronos.SaveInteraction(repo, i); err != nil {
+				t.Fatalf("SaveInteraction failed: %v", err)
+			}
+			if i.ID == 0 || i.CreatedAt.IsZero() {
+				t.Fatalf("ID and CreatedAt should be set: %+v", i)
+			}
+		}
+
+		list, err := chronos.ListInteractions(repo, 2)
+		if err != nil {
+			t.Fatalf("ListInteractions failed: %v", err)
+		}
+		if len(list) != 2 || list[0].Question != "third?" || list[1].Question != "second?" {
+			t.Fatalf("expected the two latest, newest first: %+v", list)
+		}
+
+		got, err := chronos.GetInteraction(repo, list[1].ID)
+		if err != nil {
+			t.Fatalf("GetInteraction failed: %v", err)
+		}
+		if got.Answer != "answer to second?" || got.Model != "llama3" || got.ContextHash != hash || got.BlockID != 7 || time.Since(got.CreatedAt) > time.Minute {
+			t.Errorf("interaction not stored intact: %+v", got)
+		}
+		if _, err := chronos.GetInteraction(repo, 999); !errors.Is(err, chronos.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+}
+
+func TestContextHash(t *testing.T) {
+	entries := []*chronos.Entry{{ID: 1, Project: "Apollo", Duration: 60}}
+	blocks := []*chronos.Block{{ID: 1, Name: "Sprint"}}
+	hash := chronos.ContextHash(entries, blocks)
+	if hash != chronos.ContextHash(entries, blocks) {
+		t.Fatal("ContextHash should be stable")
+	}
+	entries[0].Duration = 90
+	if hash == chronos.ContextHash(entries, blocks) {
+		t.Error("ContextHash should change with the data")
+	}
+}
@@ -0,0 +1,430 @@
+package chronos
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Invoice statuses. A draft is issued, which gives it its number, and an
+// issued invoice is paid. Drafts and issued invoices can be voided.
+const (
+	InvoiceDraft  = "draft"
+	InvoiceIssued = "issued"
+	InvoicePaid   = "paid"
+	InvoiceVoid   = "void"
+)
+
+// DefaultInvoiceDueDays is how long after issue an invoice falls due.
+const DefaultInvoiceDueDays = 30
+
+// ErrInvoiceStatus is returned (wrapped) when an invoice is not in a status
+// that allows the requested change.
+var ErrInvoiceStatus = errors.New("invalid invoice status")
+
+// ErrAlreadyInvoiced is returned (wrapped) when an entry put on a new invoice
+// is already billed on another.
+var ErrAlreadyInvoiced = errors.New("already invoiced")
+
+// Invoice bills a client for a set of entries, grouped into lines, plus any
+// fixed-price items and expenses, with its taxes and discounts. The entries
+// on a draft or issued invoice are marked invoiced; voiding the invoice
+// releases them.
+type Invoice struct {
+	ID          int64          `json:"id"`
+	Number      string         `json:"number"`   // Empty until issued
+	Sequence    int64          `json:"sequence"` // Position in the numbering; 0 until issued
+	Client      string         `json:"client"`
+	Currency    string         `json:"currency"` // Code the amounts are in
+	BlockID     int64          `json:"block_id"`
+	Status      string         `json:"status"`
+	IssueDate   time.Time      `json:"issue_date"`
+	DueDate     time.Time      `json:"due_date"`
+	PaidAt      time.Time      `json:"paid_at"`
+	Lines       []*InvoiceLine `json:"lines"`
+	Adjustments []*Adjustment  `json:"adjustments,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+// Hours returns the billed time of all lines in hours.
+func (inv *Invoice) Hours() float64 {
+	var minutes float64
+	for _, l := range inv.Lines {
+		minutes += l.Minutes
+	}
+	return minutes / 60.0
+}
+
+// TrackedHours returns the tracked time of all lines in hours.
+func (inv *Invoice) TrackedHours() float64 {
+	var hours float64
+	for _, l := range inv.Lines {
+		hours += l.TrackedHours()
+	}
+	return hours
+}
+
+// Totals breaks the invoice total down into its subtotal, discounts and
+// taxes.
+func (inv *Invoice) Totals() InvoiceTotals {
+	return CalculateTotals(inv.Lines, inv.Adjustments)
+}
+
+// Total returns the amount due: the lines after discounts and taxes.
+func (inv *Invoice) Total() float64 {
+	return inv.Totals().Total
+}
+
+// EntryIDs returns the entries billed on the invoice, line by line.
+func (inv *Invoice) EntryIDs() []int64 {
+	var ids []int64
+	for _, l := range inv.Lines {
+		ids = append(ids, l.EntryIDs...)
+	}
+	return ids
+}
+
+// Label names the invoice by its number, or as a draft by its ID.
+func (inv *Invoice) Label() string {
+	if inv.Number != "" {
+		return inv.Number
+	}
+	return fmt.Sprintf("draft #%d", inv.ID)
+}
+
+// InvoiceNumbering configures how issued invoices are numbered. Format may
+// use {YYYY}, {YY} and {MM} for the issue date and a run of N's in braces
+// for the sequence number, zero-padded to that many digits; a format without
+// one gets "-" and the sequence appended. Sequences continue from the highest
+// issued so far and start at Start.
+type InvoiceNumbering struct {
+	Format string
+	Start  int64
+}
+
+// DefaultInvoiceNumberFormat numbers invoices like INV-2026-0042.
+const DefaultInvoiceNumberFormat = "INV-{YYYY}-{NNNN}"
+
+var sequencePlaceholder = regexp.MustCompile(`\{N+\}`)
+
+// Number formats the invoice number for sequence seq issued on issued.
+func (n InvoiceNumbering) Number(seq int64, issued time.Time) string {
+	format := n.Format
+	if format == "" {
+		format = DefaultInvoiceNumberFormat
+	}
+	number := strings.NewReplacer(
+		"{YYYY}", issued.Format("2006"),
+		"{YY}", issued.Format("06"),
+		"{MM}", issued.Format("01"),
+	).Replace(format)
+	if !sequencePlaceholder.MatchString(number) {
+		return number + "-" + strconv.FormatInt(seq, 10)
+	}
+	return sequencePlaceholder.ReplaceAllStringFunc(number, func(m string) string {
+		return fmt.Sprintf("%0*d", len(m)-2, seq)
+	})
+}
+
+// CreateInvoice saves inv as a draft and marks its entries invoiced, so they
+// cannot go on another invoice. Entries that are already invoiced are
+// refused. An invoice without adjustments gets its client's terms, and one
+// without a currency is in DefaultCurrency.
+func CreateInvoice(repo Repository, inv *Invoice) error {
+	if len(inv.Lines) == 0 {
+		return fmt.Errorf("invoice has no lines")
+	}
+	inv.Currency = NormalizeCurrency(inv.Currency)
+	if !ValidCurrency(inv.Currency) {
+		return fmt.Errorf("invalid currency code %q", inv.Currency)
+	}
+	if inv.Adjustments == nil && inv.Client != "" {
+		terms, err := repo.GetClientTerms(inv.Client)
+		if err != nil {
+			return err
+		}
+		inv.Adjustments = terms
+	}
+	for _, a := range inv.Adjustments {
+		if err := a.Validate(); err != nil {
+			return err
+		}
+	}
+	now := time.Now()
+	inv.Status = InvoiceDraft
+	inv.Number, inv.Sequence = "", 0
+	inv.CreatedAt, inv.UpdatedAt = now, now
+	return repo.CreateInvoice(inv)
+}
+
+// AddInvoiceItem adds a fixed-price item or an expense to a draft.
+func AddInvoiceItem(repo Repository, id int64, line *InvoiceLine) (*Invoice, error) {
+	inv, err := invoiceIn(repo, id, InvoiceDraft)
+	if err != nil {
+		return nil, err
+	}
+	if line.Kind != LineFixed && line.Kind != LineExpense {
+		return nil, fmt.Errorf("only fixed-price items and expenses can be added, not %q", line.Kind)
+	}
+	line.Description = strings.TrimSpace(line.Description)
+	if line.Description == "" {
+		return nil, fmt.Errorf("an invoice item needs a description")
+	}
+	if line.Amount <= 0 {
+		return nil, fmt.Errorf("an invoice item needs a positive amount")
+	}
+	line.Minutes, line.Tracked, line.EntryIDs = 0, 0, nil
+	if err := repo.AddInvoiceLine(inv.ID, line); err != nil {
+		return nil, err
+	}
+	inv.Lines = append(inv.Lines, line)
+	return inv, updateInvoice(repo, inv)
+}
+
+// SetInvoiceAdjustments replaces the taxes and discounts of a draft.
+func SetInvoiceAdjustments(repo Repository, id int64, adjustments []*Adjustment) (*Invoice, error) {
+	inv, err := invoiceIn(repo, id, InvoiceDraft)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range adjustments {
+		if err := a.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	inv.Adjustments = adjustments
+	return inv, updateInvoice(repo, inv)
+}
+
+// GetInvoiceByID retrieves an invoice with its lines. A missing invoice wraps ErrNotFound.
+func GetInvoiceByID(repo Repository, id int64) (*Invoice, error) {
+	return repo.GetInvoice(id)
+}
+
+// ListInvoices retrieves invoices with their lines, newest first, optionally
+// filtered. Supported filters: "status", "client".
+func ListInvoices(repo Repository, filters map[string]interface{}) ([]*Invoice, error) {
+	return repo.ListInvoices(filters)
+}
+
+// IssueInvoice gives a draft the next number in numbering, dates it issued
+// and sets it due dueDays later.
+func IssueInvoice(repo Repository, id int64, numbering InvoiceNumbering, issued time.Time, dueDays int) (*Invoice, error) {
+	inv, err := invoiceIn(repo, id, InvoiceDraft)
+	if err != nil {
+		return nil, err
+	}
+	all, err := repo.ListInvoices(nil)
+	if err != nil {
+		return nil, err
+	}
+	seq := max(numbering.Start, 1)
+	for _, other := range all {
+		seq = max(seq, other.Sequence+1)
+	}
+	inv.Sequence = seq
+	inv.Number = numbering.Number(seq, issued)
+	inv.IssueDate = issued
+	inv.DueDate = issued.AddDate(0, 0, dueDays)
+	inv.Status = InvoiceIssued
+	return inv, updateInvoice(repo, inv)
+}
+
+// MarkInvoicePaid records that an issued invoice was paid on paid.
+func MarkInvoicePaid(repo Repository, id int64, paid time.Time) (*Invoice, error) {
+	inv, err := invoiceIn(repo, id, InvoiceIssued)
+	if err != nil {
+		return nil, err
+	}
+	inv.PaidAt = paid
+	inv.Status = InvoicePaid
+	return inv, updateInvoice(repo, inv)
+}
+
+// VoidInvoice cancels a draft or issued invoice and releases its entries back
+// to unbilled. A voided invoice keeps its number so the sequence has no gaps.
+func VoidInvoice(repo Repository, id int64) (*Invoice, error) {
+	inv, err := invoiceIn(repo, id, InvoiceDraft, InvoiceIssued)
+	if err != nil {
+		return nil, err
+	}
+	inv.Status = InvoiceVoid
+	inv.UpdatedAt = time.Now()
+	if err := repo.VoidInvoice(inv); err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+// EntryInvoice returns the draft, issued or paid invoice that bills an
+// entry, or nil when none does.
+func EntryInvoice(repo Repository, entryID int64) (*Invoice, error) {
+	invoices, err := repo.ListInvoices(nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, inv := range invoices {
+		if inv.Status == InvoiceVoid {
+			continue
+		}
+		for _, id := range inv.EntryIDs() {
+			if id == entryID {
+				return inv, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// ApplyInvoiced reports entries billed on a draft, issued or paid invoice as
+// the invoice billed them, whatever the rates and rounding rules say today:
+// each gets its share of its line's billed time and amount, in proportion to
+// tracked time, and the invoice's currency. Call it after the rates, rounding
+// and currencies have been applied to the rest.
+func ApplyInvoiced(entries []*Entry, invoices []*Invoice) {
+	type billing struct {
+		inv  *Invoice
+		line *InvoiceLine
+	}
+	byEntry := map[int64]billing{}
+	for _, inv := range invoices {
+		if inv.Status == InvoiceVoid {
+			continue
+		}
+		for _, l := range inv.Lines {
+			for _, id := range l.EntryIDs {
+				byEntry[id] = billing{inv, l}
+			}
+		}
+	}
+	for _, e := range entries {
+		if e == nil {
+			continue
+		}
+		b, ok := byEntry[e.ID]
+		if !ok {
+			continue
+		}
+		share := 0.0
+		if tracked := b.line.TrackedHours() * 60; tracked > 0 {
+			share = e.Minutes() / tracked
+		}
+		billed := b.line.Minutes * share
+		e.Billed = &billed
+		if billed > 0 {
+			e.Rate = b.line.Amount * share / (billed / 60)
+		}
+		e.Currency = NormalizeCurrency(b.inv.Currency)
+	}
+}
+
+// invoiceIn loads an invoice and checks that it is in one of statuses.
+func invoiceIn(repo Repository, id int64, statuses ...string) (*Invoice, error) {
+	inv, err := repo.GetInvoice(id)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range statuses {
+		if inv.Status == s {
+			return inv, nil
+		}
+	}
+	return nil, fmt.Errorf("invoice %s is %s, expected %s: %w", inv.Label(), inv.Status, strings.Join(statuses, " or "), ErrInvoiceStatus)
+}
+
+func updateInvoice(repo Repository, inv *Invoice) error {
+	inv.UpdatedAt = time.Now()
+	return repo.UpdateInvoice(inv)
+}
+
+// InvoiceLine is one line of an invoice: the entries of a project and task
+// billed together under a single description, or a fixed-price item or
+// expense with just a description and amount.
+type InvoiceLine struct {
+	ID          int64    `json:"id,omitempty"`
+	Kind        string   `json:"kind,omitempty"` // Empty for billed time, else LineFixed or LineExpense
+	Project     string   `json:"project"`
+	Task        string   `json:"task"`
+	Description string   `json:"description"`
+	Minutes     float64  `json:"minutes"`         // Billed, after rounding
+	Tracked     float64  `json:"tracked_minutes"` // As tracked
+	Amount      float64  `json:"amount"`
+	EntryIDs    []int64  `json:"entry_ids"`
+	Entries     []*Entry `json:"-"`
+}
+
+// Hours returns the line's billed time in hours.
+func (l *InvoiceLine) Hours() float64 {
+	return l.Minutes / 60.0
+}
+
+// TrackedHours returns the line's tracked time in hours. Lines saved before
+// tracked time was kept report their billed time.
+func (l *InvoiceLine) TrackedHours() float64 {
+	if l.Tracked == 0 {
+		return l.Hours()
+	}
+	return l.Tracked / 60.0
+}
+
+// Label names the line as "Project / Task", or just the project. Items
+// are labelled by their kind.
+func (l *InvoiceLine) Label() string {
+	switch {
+	case l.Kind == LineFixed:
+		return "Fixed price"
+	case l.Kind == LineExpense:
+		return "Expense"
+	case l.Task == "":
+		return l.Project
+	}
+	return l.Project + " / " + l.Task
+}
+
+// GroupInvoiceLines groups entries into one line per project and task,
+// sorted by project then task. Each line's description starts as the
+// distinct entry summaries joined with "; ".
+func GroupInvoiceLines(entries []*Entry) []*InvoiceLine {
+	byKey := map[[2]string]*InvoiceLine{}
+	var lines []*InvoiceLine
+	for _, e := range entries {
+		if e == nil {
+			continue
+		}
+		key := [2]string{e.Project, e.Task}
+		line, ok := byKey[key]
+		if !ok {
+			line = &InvoiceLine{Project: e.Project, Task: e.Task}
+			byKey[key] = line
+			lines = append(lines, line)
+		}
+		line.Entries = append(line.Entries, e)
+		line.EntryIDs = append(line.EntryIDs, e.ID)
+		line.Minutes += e.BilledMinutes()
+		line.Tracked += e.Minutes()
+		line.Amount += e.Amount()
+	}
+	for _, line := range lines {
+		seen := map[string]bool{}
+		var summaries []string
+		for _, e := range line.Entries {
+			if s := strings.TrimSpace(e.Summary); s != "" && !seen[strings.ToLower(s)] {
+				seen[strings.ToLower(s)] = true
+				summaries = append(summaries, s)
+			}
+		}
+		line.Description = strings.Join(summaries, "; ")
+	}
+	sort.SliceStable(lines, func(i, j int) bool {
+		if lines[i].Project != lines[j].Project {
+			return lines[i].Project < lines[j].Project
+		}
+		return lines[i].Task < lines[j].Task
+	})
+	return lines
+}
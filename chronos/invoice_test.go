@@ -0,0 +1,172 @@
+package chronos_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/regiellis/chronos-go/chronos"
+)
+
+func TestGroupInvoiceLines(t *testing.T) {
+	entries := []*chronos.Entry{
+		{ID: 1, Project: "Zeus", Task: "Ops", Summary: "Deploy", Duration: 30, Billable: true, Rate: 100},
+		{ID: 2, Project: "Apollo", Task: "API", Summary: "Fixed bug Y", Duration: 60, Billable: true, Rate: 80},
+		{ID: 3, Project: "Apollo", Task: "API", Summary: "fixed bug y", Duration: 30, Billable: true, Rate: 80},
+		{ID: 4, Project: "Apollo", Task: "API", Summary: "Auth endpoints", Duration: 90, Billable: true, Rate: 80},
+		nil,
+	}
+	lines := chronos.GroupInvoiceLines(entries)
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d", len(lines))
+	}
+	api := lines[0]
+	if api.Project != "Apollo" || api.Hours() != 3 || api.Amount != 240 || len(api.EntryIDs) != 3 {
+		t.Errorf("Apollo line not totalled: %+v", api)
+	}
+	if api.Description != "Fixed bug Y; Auth endpoints" {
+		t.Errorf("description should join distinct summaries, got %q", api.Description)
+	}
+	if lines[1].Project != "Zeus" || lines[1].Amount != 50 {
+		t.Errorf("Zeus line: %+v", lines[1])
+	}
+}
+
+func TestInvoiceNumbering(t *testing.T) {
+	issued := time.Date(2026, 3, 9, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		format string
+		seq    int64
+		want   string
+	}{
+		{"", 42, "INV-2026-0042"},
+		{"{YY}{MM}-{NNN}", 7, "2603-007"},
+		{"ACME", 3, "ACME-3"},
+		{"INV-{NN}", 123, "INV-123"},
+	}
+	for _, tt := range tests {
+		if got := (chronos.InvoiceNumbering{Format: tt.format}).Number(tt.seq, issued); got != tt.want {
+			t.Errorf("Number(%q, %d) = %q, want %q", tt.format, tt.seq, got, tt.want)
+		}
+	}
+}
+
+func TestInvoiceLifecycle(t *testing.T) {
+	eachRepository(t, func(t *testing.T, repo chronos.Repository) {
+		start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+		var entries []*chronos.Entry
+		for i, task := range []string{"API", "API", "Design"} {
+			e := &chronos.Entry{Project: "Apollo", Client: "Acme", Task: task, Summary: "Work", StartTime: start.Add(time.Duration(i) * time.Hour), Duration: 60, Billable: true, Rate: 100}
+			if err := chronos.CreateEntry(repo, e); err != nil {
+				t.Fatalf("CreateEntry failed: %v", err)
+			}
+			entries = append(entries, e)
+		}
+
+		inv := &chronos.Invoice{Client: "Acme", Lines: chronos.GroupInvoiceLines(entries)}
+		if err := chronos.CreateInvoice(repo, inv); err != nil {
+			t.Fatalf("CreateInvoice failed: %v", err)
+		}
+		if inv.ID == 0 || inv.Status != chronos.InvoiceDraft || inv.Number != "" || inv.Lines[0].ID == 0 {
+			t.Fatalf("unexpected draft: %+v", inv)
+		}
+		if unbilled, _ := chronos.FindUnbilledEntries(repo); len(unbilled) != 0 {
+			t.Errorf("entries on a draft should be invoiced, %d unbilled", len(unbilled))
+		}
+		if on, err := chronos.EntryInvoice(repo, entries[2].ID); err != nil || on == nil || on.ID != inv.ID {
+			t.Errorf("EntryInvoice = %v, %v, want draft #%d", on, err, inv.ID)
+		}
+		if err := chronos.DeleteEntry(repo, entries[2].ID); !errors.Is(err, chronos.ErrAlreadyInvoiced) || !strings.Contains(err.Error(), inv.Label()) {
+			t.Errorf("deleting an entry on a draft should name the invoice, got %v", err)
+		}
+		if _, err := chronos.GetEntryByID(repo, entries[2].ID); err != nil {
+			t.Errorf("refused delete removed the entry: %v", err)
+		}
+		// The fresh entry stays unbilled when the invoice is refused.
+		fresh := &chronos.Entry{Project: "Beta", Client: "Acme", Task: "Ops", StartTime: start, Duration: 30, Billable: true, Rate: 100}
+		if err := chronos.CreateEntry(repo, fresh); err != nil {
+			t.Fatalf("CreateEntry failed: %v", err)
+		}
+		if err := chronos.CreateInvoice(repo, &chronos.Invoice{Lines: chronos.GroupInvoiceLines([]*chronos.Entry{fresh, entries[0]})}); !errors.Is(err, chronos.ErrAlreadyInvoiced) {
+			t.Errorf("an entry should not go on two invoices, got %v", err)
+		}
+		if unbilled, _ := chronos.FindUnbilledEntries(repo); len(unbilled) != 1 || unbilled[0].ID != fresh.ID {
+			t.Errorf("a refused invoice should mark nothing, %d unbilled", len(unbilled))
+		}
+		if err := chronos.DeleteEntry(repo, fresh.ID); err != nil {
+			t.Fatalf("DeleteEntry failed: %v", err)
+		}
+
+		got, err := chronos.GetInvoiceByID(repo, inv.ID)
+		if err != nil {
+			t.Fatalf("GetInvoiceByID failed: %v", err)
+		}
+		if len(got.Lines) != 2 || len(got.Lines[0].EntryIDs) != 2 || got.Total() != 300 || got.Hours() != 3 {
+			t.Errorf("lines not stored: %+v", got.Lines)
+		}
+
+		numbering := chronos.InvoiceNumbering{Start: 42}
+		issued, err := chronos.IssueInvoice(repo, inv.ID, numbering, start.AddDate(0, 0, 5), 14)
+		if err != nil {
+			t.Fatalf("IssueInvoice failed: %v", err)
+		}
+		if issued.Number != "INV-2026-0042" || issued.Status != chronos.InvoiceIssued || !issued.DueDate.Equal(start.AddDate(0, 0, 19)) {
+			t.Errorf("unexpected issued invoice: %+v", issued)
+		}
+		if _, err := chronos.IssueInvoice(repo, inv.ID, numbering, start, 14); !errors.Is(err, chronos.ErrInvoiceStatus) {
+			t.Errorf("issuing twice should fail with ErrInvoiceStatus, got %v", err)
+		}
+
+		if _, err := chronos.VoidInvoice(repo, inv.ID); err != nil {
+			t.Fatalf("VoidInvoice failed: %v", err)
+		}
+		if unbilled, _ := chronos.FindUnbilledEntries(repo); len(unbilled) != 3 {
+			t.Errorf("voiding should release all 3 entries, %d unbilled", len(unbilled))
+		}
+		if on, err := chronos.EntryInvoice(repo, entries[2].ID); err != nil || on != nil {
+			t.Errorf("a void invoice should not hold its entries, got %v, %v", on, err)
+		}
+
+		again := &chronos.Invoice{Client: "Acme", Lines: chronos.GroupInvoiceLines(entries)}
+		if err := chronos.CreateInvoice(repo, again); err != nil {
+			t.Fatalf("re-invoicing released entries failed: %v", err)
+		}
+		reissued, err := chronos.IssueInvoice(repo, again.ID, numbering, start, 30)
+		if err != nil || reissued.Number != "INV-2026-0043" {
+			t.Errorf("numbers continue after a void, got %v, %v", reissued, err)
+		}
+		if _, err := chronos.MarkInvoicePaid(repo, again.ID, start); err != nil {
+			t.Fatalf("MarkInvoicePaid failed: %v", err)
+		}
+		if _, err := chronos.VoidInvoice(repo, again.ID); !errors.Is(err, chronos.ErrInvoiceStatus) {
+			t.Errorf("a paid invoice cannot be voided, got %v", err)
+		}
+
+		paid, err := chronos.ListInvoices(repo, map[string]interface{}{"status": chronos.InvoicePaid})
+		if err != nil || len(paid) != 1 || paid[0].ID != again.ID || paid[0].PaidAt.IsZero() {
+			t.Errorf("ListInvoices by status: %v, %+v", err, paid)
+		}
+		if all, _ := chronos.ListInvoices(repo, nil); len(all) != 2 || all[0].ID != again.ID {
+			t.Errorf("ListInvoices should list newest first: %+v", all)
+		}
+	})
+}
+
+func TestApplyInvoiced(t *testing.T) {
+	a := &chronos.Entry{ID: 1, Duration: 20, Billable: true, Rate: 999}
+	b := &chronos.Entry{ID: 2, Duration: 40, Billable: true, Rate: 999}
+	voided := &chronos.Entry{ID: 3, Duration: 30, Billable: true, Rate: 50}
+	unbilled := &chronos.Entry{ID: 4, Duration: 30, Billable: true, Rate: 50}
+	invoices := []*chronos.Invoice{
+		{Status: chronos.InvoiceIssued, Currency: "eur", Lines: []*chronos.InvoiceLine{{Minutes: 90, Tracked: 60, Amount: 150, EntryIDs: []int64{1, 2}}}},
+		{Status: chronos.InvoiceVoid, Lines: []*chronos.InvoiceLine{{Minutes: 60, Tracked: 30, Amount: 10, EntryIDs: []int64{3}}}},
+	}
+	chronos.ApplyInvoiced([]*chronos.Entry{a, b, voided, unbilled}, invoices)
+	if a.BilledMinutes() != 30 || b.BilledMinutes() != 60 || a.Amount() != 50 || b.Amount() != 100 || a.Currency != "EUR" {
+		t.Errorf("invoiced entries: %v/%v min, %v/%v, %q; want 30/60 min, 50/100, EUR", a.BilledMinutes(), b.BilledMinutes(), a.Amount(), b.Amount(), a.Currency)
+	}
+	if voided.Billed != nil || voided.Rate != 50 || unbilled.Billed != nil {
+		t.Error("entries on no invoice, or a void one, should be left alone")
+	}
+}
@@ -0,0 +1,924 @@
+package chronos
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryRepository is an in-process Repository. It is safe for concurrent use
+// and is intended for tests, dry-runs and previews that must not touch disk.
+type MemoryRepository struct {
+	mu        sync.Mutex
+	entries   map[int64]*Entry
+	breaks    map[int64][]Break // by entry ID
+	blocks    map[int64]*Block
+	projects  map[int64]*Project
+	clients   map[int64]*Client
+	templates map[string]string
+	history   []*Interaction
+	invoices  map[int64]*Invoice
+	rates     map[int64]*Rate
+	rounding  map[int64]*RoundingRule
+	terms     map[string][]*Adjustment // by lower-cased client
+	currency  map[int64]*CurrencySetting
+	exchange  map[int64]*ExchangeRate
+	nextID    map[string]int64
+}
+
+var _ Repository = (*MemoryRepository)(nil)
+
+// NewMemoryRepository returns an empty in-memory repository.
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		entries:   map[int64]*Entry{},
+		breaks:    map[int64][]Break{},
+		blocks:    map[int64]*Block{},
+		projects:  map[int64]*Project{},
+		clients:   map[int64]*Client{},
+		templates: map[string]string{},
+		invoices:  map[int64]*Invoice{},
+		rates:     map[int64]*Rate{},
+		rounding:  map[int64]*RoundingRule{},
+		terms:     map[string][]*Adjustment{},
+		currency:  map[int64]*CurrencySetting{},
+		exchange:  map[int64]*ExchangeRate{},
+		nextID:    map[string]int64{},
+	}
+}
+
+func (m *MemoryRepository) newID(table string) int64 {
+	m.nextID[table]++
+	return m.nextID[table]
+}
+
+// entryCopy returns a detached copy of a stored entry with its breaks attached.
+func (m *MemoryRepository) entryCopy(e *Entry) *Entry {
+	cp := *e
+	cp.Breaks = append([]Break(nil), m.breaks[e.ID]...)
+	return &cp
+}
+
+// store keeps an entry without its breaks; those live in m.breaks.
+func (m *MemoryRepository) store(entry *Entry) {
+	e := *entry
+	e.Breaks = nil
+	m.entries[e.ID] = &e
+}
+
+// CreateEntry stores a copy of the entry and sets its ID.
+func (m *MemoryRepository) CreateEntry(entry *Entry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry.ID = m.newID("entries")
+	m.store(entry)
+	return nil
+}
+
+// GetEntry returns a copy of the entry with the given ID.
+func (m *MemoryRepository) GetEntry(id int64) (*Entry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[id]
+	if !ok {
+		return nil, fmt.Errorf("GetEntry: no entry found with ID %d: %w", id, ErrNotFound)
+	}
+	return m.entryCopy(e), nil
+}
+
+// UpdateEntry replaces a stored entry. Updating a missing entry is a no-op, as with SQL.
+func (m *MemoryRepository) UpdateEntry(entry *Entry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.entries[entry.ID]; ok {
+		m.store(entry)
+	}
+	return nil
+}
+
+// DeleteEntry removes an entry by its ID.
+func (m *MemoryRepository) DeleteEntry(id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.entries, id)
+	delete(m.breaks, id)
+	return nil
+}
+
+// ListEntries returns copies of matching entries, newest first.
+func (m *MemoryRepository) ListEntries(filters map[string]interface{}) ([]*Entry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entries := []*Entry{}
+	for _, e := range m.entries {
+		cp := m.entryCopy(e)
+		ok, err := matchEntry(cp, filters)
+		if err != nil {
+			return nil, fmt.Errorf("ListEntries: %w", err)
+		}
+		if ok {
+			entries = append(entries, cp)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].StartTime.Equal(entries[j].StartTime) {
+			return entries[i].StartTime.After(entries[j].StartTime)
+		}
+		return entries[i].ID > entries[j].ID
+	})
+	return entries, nil
+}
+
+// MarkEntriesInvoiced flags the given entries as invoiced.
+func (m *MemoryRepository) MarkEntriesInvoiced(ids []int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, id := range ids {
+		if e, ok := m.entries[id]; ok {
+			e.Invoiced = true
+			e.UpdatedAt = time.Now()
+		}
+	}
+	return nil
+}
+
+// CreateBreak stores a break for an existing entry and sets its ID.
+func (m *MemoryRepository) CreateBreak(b *Break) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.entries[b.EntryID]; !ok {
+		return fmt.Errorf("CreateBreak: no entry found with ID %d: %w", b.EntryID, ErrNotFound)
+	}
+	b.ID = m.newID("breaks")
+	m.breaks[b.EntryID] = append(m.breaks[b.EntryID], *b)
+	return nil
+}
+
+// UpdateBreak replaces a stored break.
+func (m *MemoryRepository) UpdateBreak(b *Break) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	breaks := m.breaks[b.EntryID]
+	for i := range breaks {
+		if breaks[i].ID == b.ID {
+			breaks[i] = *b
+		}
+	}
+	return nil
+}
+
+// CreateBlock stores a copy of the block and sets its ID.
+func (m *MemoryRepository) CreateBlock(block *Block) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	block.ID = m.newID("blocks")
+	b := *block
+	m.blocks[b.ID] = &b
+	return nil
+}
+
+// GetBlock returns a copy of the block with the given ID.
+func (m *MemoryRepository) GetBlock(id int64) (*Block, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	b, ok := m.blocks[id]
+	if !ok {
+		return nil, fmt.Errorf("GetBlock: no block found with ID %d: %w", id, ErrNotFound)
+	}
+	cp := *b
+	return &cp, nil
+}
+
+// UpdateBlock replaces a stored block.
+func (m *MemoryRepository) UpdateBlock(block *Block) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.blocks[block.ID]; ok {
+		b := *block
+		m.blocks[b.ID] = &b
+	}
+	return nil
+}
+
+// DeleteBlock removes a block by its ID.
+func (m *MemoryRepository) DeleteBlock(id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.blocks, id)
+	return nil
+}
+
+// ListBlocks returns copies of matching blocks, newest first.
+func (m *MemoryRepository) ListBlocks(filters map[string]interface{}) ([]*Block, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	blocks := []*Block{}
+	for _, b := range m.blocks {
+		ok, err := matchBlock(b, filters)
+		if err != nil {
+			return nil, fmt.Errorf("ListBlocks: %w", err)
+		}
+		if ok {
+			cp := *b
+			blocks = append(blocks, &cp)
+		}
+	}
+	sort.Slice(blocks, func(i, j int) bool {
+		if !blocks[i].StartTime.Equal(blocks[j].StartTime) {
+			return blocks[i].StartTime.After(blocks[j].StartTime)
+		}
+		return blocks[i].ID > blocks[j].ID
+	})
+	return blocks, nil
+}
+
+// GetActiveBlock returns the active block, or nil if there is none.
+func (m *MemoryRepository) GetActiveBlock() (*Block, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var active *Block
+	for _, b := range m.blocks {
+		if b.Active && (active == nil || b.ID < active.ID) {
+			active = b
+		}
+	}
+	if active == nil {
+		return nil, nil
+	}
+	cp := *active
+	return &cp, nil
+}
+
+// SetActiveBlock activates a block and deactivates all others.
+func (m *MemoryRepository) SetActiveBlock(id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	for _, b := range m.blocks {
+		if b.Active || b.ID == id {
+			b.Active = b.ID == id
+			b.UpdatedAt = now
+		}
+	}
+	return nil
+}
+
+// CreateProject stores a copy of the project and sets its ID.
+func (m *MemoryRepository) CreateProject(project *Project) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	project.ID = m.newID("projects")
+	p := *project
+	m.projects[p.ID] = &p
+	return nil
+}
+
+// GetProject returns a copy of the project with the given ID.
+func (m *MemoryRepository) GetProject(id int64) (*Project, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, ok := m.projects[id]
+	if !ok {
+		return nil, fmt.Errorf("GetProject: no project found with ID %d: %w", id, ErrNotFound)
+	}
+	cp := *p
+	return &cp, nil
+}
+
+// UpdateProject replaces a stored project.
+func (m *MemoryRepository) UpdateProject(project *Project) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.projects[project.ID]; ok {
+		p := *project
+		m.projects[p.ID] = &p
+	}
+	return nil
+}
+
+// DeleteProject removes a project by its ID.
+func (m *MemoryRepository) DeleteProject(id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.projects, id)
+	return nil
+}
+
+// ListProjects returns projects in ID order, optionally filtered by clientID.
+func (m *MemoryRepository) ListProjects(clientID *int64) ([]*Project, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	projects := []*Project{}
+	for _, p := range m.projects {
+		if clientID != nil && p.ClientID != *clientID {
+			continue
+		}
+		cp := *p
+		projects = append(projects, &cp)
+	}
+	sort.Slice(projects, func(i, j int) bool { return projects[i].ID < projects[j].ID })
+	return projects, nil
+}
+
+// CreateClient stores a copy of the client and sets its ID.
+// Client names are unique, matching the SQLite index.
+func (m *MemoryRepository) CreateClient(client *Client) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, c := range m.clients {
+		if c.Name == client.Name {
+			return fmt.Errorf("CreateClient: client %q already exists", client.Name)
+		}
+	}
+	client.ID = m.newID("clients")
+	c := *client
+	m.clients[c.ID] = &c
+	return nil
+}
+
+// GetClient returns a copy of the client with the given ID.
+func (m *MemoryRepository) GetClient(id int64) (*Client, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c, ok := m.clients[id]
+	if !ok {
+		return nil, fmt.Errorf("GetClient: no client found with ID %d: %w", id, ErrNotFound)
+	}
+	cp := *c
+	return &cp, nil
+}
+
+// UpdateClient replaces a stored client.
+func (m *MemoryRepository) UpdateClient(client *Client) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.clients[client.ID]; ok {
+		c := *client
+		m.clients[c.ID] = &c
+	}
+	return nil
+}
+
+// DeleteClient removes a client by its ID.
+func (m *MemoryRepository) DeleteClient(id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.clients, id)
+	return nil
+}
+
+// ListClients returns all clients in ID order.
+func (m *MemoryRepository) ListClients() ([]*Client, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	clients := []*Client{}
+	for _, c := range m.clients {
+		cp := *c
+		clients = append(clients, &cp)
+	}
+	sort.Slice(clients, func(i, j int) bool { return clients[i].ID < clients[j].ID })
+	return clients, nil
+}
+
+// SaveTemplate saves or updates an entry template.
+func (m *MemoryRepository) SaveTemplate(name string, entryText string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.templates[name] = entryText
+	return nil
+}
+
+// GetTemplate retrieves an entry template by its name.
+func (m *MemoryRepository) GetTemplate(name string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	text, ok := m.templates[name]
+	if !ok {
+		return "", fmt.Errorf("template '%s': %w", name, ErrNotFound)
+	}
+	return text, nil
+}
+
+// SaveInteraction stores a copy of the exchange and sets its ID.
+func (m *MemoryRepository) SaveInteraction(i *Interaction) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i.ID = m.newID("history")
+	cp := *i
+	m.history = append(m.history, &cp)
+	return nil
+}
+
+// GetInteraction retrieves a copy of a saved exchange by ID.
+func (m *MemoryRepository) GetInteraction(id int64) (*Interaction, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, i := range m.history {
+		if i.ID == id {
+			cp := *i
+			return &cp, nil
+		}
+	}
+	return nil, fmt.Errorf("interaction %d: %w", id, ErrNotFound)
+}
+
+// ListInteractions returns the last limit exchanges, most recent first.
+func (m *MemoryRepository) ListInteractions(limit int) ([]*Interaction, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var list []*Interaction
+	for i := len(m.history) - 1; i >= 0 && len(list) < limit; i-- {
+		cp := *m.history[i]
+		list = append(list, &cp)
+	}
+	return list, nil
+}
+
+// invoiceCopy returns a detached copy of an invoice, its lines and its
+// adjustments.
+func invoiceCopy(inv *Invoice) *Invoice {
+	cp := *inv
+	cp.Lines = make([]*InvoiceLine, len(inv.Lines))
+	for i, l := range inv.Lines {
+		line := *l
+		line.EntryIDs = append([]int64(nil), l.EntryIDs...)
+		line.Entries = nil
+		cp.Lines[i] = &line
+	}
+	cp.Adjustments = adjustmentsCopy(inv.Adjustments)
+	return &cp
+}
+
+func adjustmentsCopy(adjustments []*Adjustment) []*Adjustment {
+	if adjustments == nil {
+		return nil
+	}
+	cp := make([]*Adjustment, len(adjustments))
+	for i, a := range adjustments {
+		adj := *a
+		cp[i] = &adj
+	}
+	return cp
+}
+
+// CreateInvoice stores a copy of the invoice and its lines, sets their IDs
+// and marks the linked entries invoiced. Nothing changes if one is missing or
+// already invoiced.
+func (m *MemoryRepository) CreateInvoice(inv *Invoice) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ids := inv.EntryIDs()
+	for _, id := range ids {
+		e, ok := m.entries[id]
+		if !ok {
+			return fmt.Errorf("CreateInvoice: no entry found with ID %d: %w", id, ErrNotFound)
+		}
+		if e.Invoiced {
+			return fmt.Errorf("CreateInvoice: entry %d is %w", id, ErrAlreadyInvoiced)
+		}
+	}
+	now := time.Now()
+	for _, id := range ids {
+		m.entries[id].Invoiced = true
+		m.entries[id].UpdatedAt = now
+	}
+	inv.ID = m.newID("invoices")
+	for _, l := range inv.Lines {
+		l.ID = m.newID("invoice_lines")
+	}
+	for _, a := range inv.Adjustments {
+		a.ID = m.newID("invoice_adjustments")
+	}
+	m.invoices[inv.ID] = invoiceCopy(inv)
+	return nil
+}
+
+// VoidInvoice sets a stored draft or issued invoice void and releases its
+// entries.
+func (m *MemoryRepository) VoidInvoice(inv *Invoice) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored, ok := m.invoices[inv.ID]
+	if !ok {
+		return fmt.Errorf("VoidInvoice: no invoice found with ID %d: %w", inv.ID, ErrNotFound)
+	}
+	if stored.Status != InvoiceDraft && stored.Status != InvoiceIssued {
+		return fmt.Errorf("VoidInvoice: invoice %s is %s: %w", stored.Label(), stored.Status, ErrInvoiceStatus)
+	}
+	for _, id := range stored.EntryIDs() {
+		if e, ok := m.entries[id]; ok {
+			e.Invoiced = false
+			e.UpdatedAt = inv.UpdatedAt
+		}
+	}
+	stored.Status, stored.UpdatedAt = InvoiceVoid, inv.UpdatedAt
+	return nil
+}
+
+// GetInvoice returns a copy of the invoice with the given ID.
+func (m *MemoryRepository) GetInvoice(id int64) (*Invoice, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	inv, ok := m.invoices[id]
+	if !ok {
+		return nil, fmt.Errorf("GetInvoice: no invoice found with ID %d: %w", id, ErrNotFound)
+	}
+	return invoiceCopy(inv), nil
+}
+
+// UpdateInvoice replaces a stored invoice and its adjustments but keeps its
+// stored lines.
+func (m *MemoryRepository) UpdateInvoice(inv *Invoice) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if stored, ok := m.invoices[inv.ID]; ok {
+		for _, a := range inv.Adjustments {
+			if a.ID == 0 {
+				a.ID = m.newID("invoice_adjustments")
+			}
+		}
+		cp := *inv
+		cp.Lines = stored.Lines
+		cp.Adjustments = adjustmentsCopy(inv.Adjustments)
+		m.invoices[inv.ID] = &cp
+	}
+	return nil
+}
+
+// AddInvoiceLine appends a copy of the line to a stored invoice.
+func (m *MemoryRepository) AddInvoiceLine(invoiceID int64, line *InvoiceLine) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	inv, ok := m.invoices[invoiceID]
+	if !ok {
+		return fmt.Errorf("AddInvoiceLine: no invoice found with ID %d: %w", invoiceID, ErrNotFound)
+	}
+	line.ID = m.newID("invoice_lines")
+	cp := *line
+	cp.EntryIDs = append([]int64(nil), line.EntryIDs...)
+	cp.Entries = nil
+	inv.Lines = append(inv.Lines, &cp)
+	return nil
+}
+
+// GetClientTerms returns copies of a client's invoice terms.
+func (m *MemoryRepository) GetClientTerms(client string) ([]*Adjustment, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return adjustmentsCopy(m.terms[strings.ToLower(client)]), nil
+}
+
+// SetClientTerms replaces a client's invoice terms.
+func (m *MemoryRepository) SetClientTerms(client string, terms []*Adjustment) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(terms) == 0 {
+		delete(m.terms, strings.ToLower(client))
+		return nil
+	}
+	for _, a := range terms {
+		a.ID = m.newID("client_terms")
+	}
+	m.terms[strings.ToLower(client)] = adjustmentsCopy(terms)
+	return nil
+}
+
+// ListInvoices returns copies of matching invoices, newest first.
+func (m *MemoryRepository) ListInvoices(filters map[string]interface{}) ([]*Invoice, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	invoices := []*Invoice{}
+	for _, inv := range m.invoices {
+		ok, err := matchInvoice(inv, filters)
+		if err != nil {
+			return nil, fmt.Errorf("ListInvoices: %w", err)
+		}
+		if ok {
+			invoices = append(invoices, invoiceCopy(inv))
+		}
+	}
+	sort.Slice(invoices, func(i, j int) bool { return invoices[i].ID > invoices[j].ID })
+	return invoices, nil
+}
+
+// CreateRate stores a copy of the rate and sets its ID.
+func (m *MemoryRepository) CreateRate(r *Rate) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r.ID = m.newID("rates")
+	cp := *r
+	m.rates[r.ID] = &cp
+	return nil
+}
+
+// ListRates returns copies of all rates by scope, target and date.
+func (m *MemoryRepository) ListRates() ([]*Rate, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	rates := []*Rate{}
+	for _, r := range m.rates {
+		cp := *r
+		rates = append(rates, &cp)
+	}
+	sort.Slice(rates, func(i, j int) bool { return rates[i].ID < rates[j].ID })
+	sortRates(rates)
+	return rates, nil
+}
+
+// DeleteRate removes a rate.
+func (m *MemoryRepository) DeleteRate(id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.rates, id)
+	return nil
+}
+
+// CreateRoundingRule stores a copy of the rule and sets its ID.
+func (m *MemoryRepository) CreateRoundingRule(r *RoundingRule) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r.ID = m.newID("rounding_rules")
+	cp := *r
+	m.rounding[r.ID] = &cp
+	return nil
+}
+
+// UpdateRoundingRule replaces a stored rule.
+func (m *MemoryRepository) UpdateRoundingRule(r *RoundingRule) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.rounding[r.ID]; ok {
+		cp := *r
+		m.rounding[r.ID] = &cp
+	}
+	return nil
+}
+
+// ListRoundingRules returns copies of all rules by scope and target.
+func (m *MemoryRepository) ListRoundingRules() ([]*RoundingRule, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	rules := []*RoundingRule{}
+	for _, r := range m.rounding {
+		cp := *r
+		rules = append(rules, &cp)
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].Scope != rules[j].Scope {
+			return rules[i].Scope < rules[j].Scope
+		}
+		return rules[i].Target < rules[j].Target
+	})
+	return rules, nil
+}
+
+// DeleteRoundingRule removes a rule.
+func (m *MemoryRepository) DeleteRoundingRule(id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.rounding, id)
+	return nil
+}
+
+// SetCurrency stores a copy of the setting, replacing the one for the same
+// scope and target, and sets its ID.
+func (m *MemoryRepository) SetCurrency(c *CurrencySetting) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, existing := range m.currency {
+		if existing.Scope == c.Scope && strings.EqualFold(existing.Target, c.Target) {
+			delete(m.currency, id)
+		}
+	}
+	c.ID = m.newID("currencies")
+	cp := *c
+	m.currency[c.ID] = &cp
+	return nil
+}
+
+// ListCurrencySettings returns copies of all settings by scope and target.
+func (m *MemoryRepository) ListCurrencySettings() ([]*CurrencySetting, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	settings := []*CurrencySetting{}
+	for _, c := range m.currency {
+		cp := *c
+		settings = append(settings, &cp)
+	}
+	sort.Slice(settings, func(i, j int) bool {
+		if settings[i].Scope != settings[j].Scope {
+			return settings[i].Scope < settings[j].Scope
+		}
+		return settings[i].Target < settings[j].Target
+	})
+	return settings, nil
+}
+
+// CreateExchangeRate stores a copy of the rate and sets its ID.
+func (m *MemoryRepository) CreateExchangeRate(r *ExchangeRate) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r.ID = m.newID("exchange_rates")
+	cp := *r
+	m.exchange[r.ID] = &cp
+	return nil
+}
+
+// ListExchangeRates returns copies of all exchange rates by date.
+func (m *MemoryRepository) ListExchangeRates() ([]*ExchangeRate, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	rates := []*ExchangeRate{}
+	for _, r := range m.exchange {
+		cp := *r
+		rates = append(rates, &cp)
+	}
+	sort.Slice(rates, func(i, j int) bool {
+		if !rates[i].Date.Equal(rates[j].Date) {
+			return rates[i].Date.Before(rates[j].Date)
+		}
+		return rates[i].ID < rates[j].ID
+	})
+	return rates, nil
+}
+
+// matchEntry applies the ListEntries filter keys to a single entry.
+func matchEntry(e *Entry, filters map[string]interface{}) (bool, error) {
+	for key, value := range filters {
+		var ok bool
+		var err error
+		switch key {
+		case "block_id":
+			ok, err = equalInt(e.BlockID, value)
+		case "project_id":
+			ok, err = equalInt(e.ProjectID, value)
+		case "project":
+			ok, err = equalString(e.Project, value)
+		case "client":
+			ok, err = equalString(e.Client, value)
+		case "task":
+			ok, err = equalString(e.Task, value)
+		case "billable":
+			ok, err = equalBool(e.Billable, value)
+		case "invoiced":
+			ok, err = equalBool(e.Invoiced, value)
+		case "open":
+			ok, err = equalBool(e.IsOpen(), value)
+		case "tag":
+			ok, err = hasTag(e.Tags, value)
+		case "start_date", "end_date":
+			ok, err = withinDay(e.StartTime, key, value)
+		case "min_duration", "max_duration":
+			ok, err = withinBound(float64(e.Duration), key == "min_duration", value)
+		case "min_rate", "max_rate":
+			ok, err = withinBound(e.Rate, key == "min_rate", value)
+		default:
+			ok = true
+		}
+		if err != nil {
+			return false, fmt.Errorf("filter %q: %w", key, err)
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// matchBlock applies the ListBlocks filter keys to a single block.
+func matchBlock(b *Block, filters map[string]interface{}) (bool, error) {
+	for key, value := range filters {
+		var ok bool
+		var err error
+		switch key {
+		case "active":
+			ok, err = equalBool(b.Active, value)
+		case "client":
+			ok, err = equalString(b.Client, value)
+		case "project":
+			ok, err = equalString(b.Project, value)
+		case "start_date", "end_date":
+			ok, err = withinDay(b.StartTime, key, value)
+		default:
+			ok = true
+		}
+		if err != nil {
+			return false, fmt.Errorf("filter %q: %w", key, err)
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// matchInvoice applies the ListInvoices filter keys to a single invoice.
+func matchInvoice(inv *Invoice, filters map[string]interface{}) (bool, error) {
+	for key, value := range filters {
+		var ok bool
+		var err error
+		switch key {
+		case "status":
+			ok, err = equalString(inv.Status, value)
+		case "client":
+			ok, err = equalString(inv.Client, value)
+		default:
+			ok = true
+		}
+		if err != nil {
+			return false, fmt.Errorf("filter %q: %w", key, err)
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func equalString(have string, value interface{}) (bool, error) {
+	s, ok := value.(string)
+	if !ok {
+		return false, fmt.Errorf("expected string, got %T", value)
+	}
+	return have == s, nil
+}
+
+func hasTag(tags []string, value interface{}) (bool, error) {
+	want, ok := value.(string)
+	if !ok {
+		return false, fmt.Errorf("expected string, got %T", value)
+	}
+	for _, tag := range tags {
+		if tag == want {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func equalBool(have bool, value interface{}) (bool, error) {
+	b, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("expected bool, got %T", value)
+	}
+	return have == b, nil
+}
+
+func equalInt(have int64, value interface{}) (bool, error) {
+	n, err := toFloat(value)
+	if err != nil {
+		return false, err
+	}
+	return float64(have) == n, nil
+}
+
+func withinBound(have float64, isMin bool, value interface{}) (bool, error) {
+	n, err := toFloat(value)
+	if err != nil {
+		return false, err
+	}
+	if isMin {
+		return have >= n, nil
+	}
+	return have <= n, nil
+}
+
+// withinDay compares by calendar day, like SQLite's date() in the SQL store.
+func withinDay(have time.Time, key string, value interface{}) (bool, error) {
+	var day time.Time
+	switch v := value.(type) {
+	case time.Time:
+		day = v
+	case string:
+		parsed, err := time.ParseInLocation("2006-01-02", v, have.Location())
+		if err != nil {
+			return false, err
+		}
+		day = parsed
+	default:
+		return false, fmt.Errorf("expected time.Time or YYYY-MM-DD string, got %T", value)
+	}
+	haveDay := have.Format("2006-01-02")
+	wantDay := day.Format("2006-01-02")
+	if key == "start_date" {
+		return haveDay >= wantDay, nil
+	}
+	return haveDay <= wantDay, nil
+}
+
+func toFloat(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	default:
+		return 0, fmt.Errorf("expected number, got %T", value)
+	}
+}
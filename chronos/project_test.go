@@ -1,206 +1,109 @@
 package chronos_test
 
 import (
-	// "errors" // For future use with custom errors like ErrNotFound
+	"errors"
 	"testing"
-	"time"
 
 	"github.com/regiellis/chronos-go/chronos"
-	// "github.com/regiellis/chronos-go/db"
-	// _ "github.com/mattn/go-sqlite3"
 )
 
-// Helper function to setup a test store (currently commented out)
-/*
-func setupTestDBForProjects(t *testing.T) *db.Store {
-	t.Helper()
-	// store, err := db.NewStore(":memory:")
-	// if err != nil {
-	// 	t.Fatalf("Failed to create in-memory store for project tests: %v", err)
-	// }
-	// // IMPORTANT: Assumes InitSchema also creates clients and projects tables.
-	// // If not, EnsureClientsTable and EnsureProjectsTable would be needed here.
-	// if err := store.InitSchema(); err != nil {
-	// 	t.Fatalf("Failed to init schema: %v", err)
-	// }
-	// // You might also need to create a dummy client for projects that require a client_id
-	// // dummyClient := &chronos.Client{Name: "Test Client for Project"}
-	// // chronos.CreateClient(store, dummyClient) // Assuming CreateClient works and sets ID
-	return nil // Placeholder
-}
-*/
-
 func TestCreateProject(t *testing.T) {
-	t.Log("NOTE: TestCreateProject is a placeholder due to DB initialization issues.")
-	// store := setupTestDBForProjects(t)
-	// if store == nil {
-	// 	t.Skip("Skipping DB-dependent test: store is nil")
-	// }
-
-	project := &chronos.Project{
-		Name:     "Test Project Alpha",
-		ClientID: 1, // Assuming a client with ID 1 exists, or created in setupTestDBForProjects
-		Rate:     100.50,
-	}
-
-	// err := chronos.CreateProject(store, project)
-	// if err != nil {
-	// 	t.Errorf("CreateProject failed: %v", err)
-	// }
-	// if project.ID == 0 {
-	// 	t.Errorf("Expected project ID to be set after creation, got 0")
-	// }
-	// if project.CreatedAt.IsZero() {
-	// 	t.Errorf("Expected CreatedAt to be set")
-	// }
-	// if project.UpdatedAt.IsZero() {
-	// 	t.Errorf("Expected UpdatedAt to be set")
-	// }
-
-	// Further verification: GetProjectByID
-	// retrievedProject, getErr := chronos.GetProjectByID(store, project.ID)
-	// if getErr != nil {
-	//  t.Fatalf("GetProjectByID after CreateProject failed: %v", getErr)
-	// }
-	// if retrievedProject.Name != project.Name {
-	//  t.Errorf("Name mismatch: expected %s, got %s", project.Name, retrievedProject.Name)
-	// }
+	eachRepository(t, func(t *testing.T, repo chronos.Repository) {
+		project := &chronos.Project{Name: "Test Project Alpha", ClientID: 1, Rate: 75.50}
+		if err := chronos.CreateProject(repo, project); err != nil {
+			t.Fatalf("CreateProject failed: %v", err)
+		}
+		if project.ID == 0 {
+			t.Errorf("Expected project ID to be set after creation, got 0")
+		}
+		if project.CreatedAt.IsZero() || project.UpdatedAt.IsZero() {
+			t.Errorf("Expected CreatedAt/UpdatedAt to be set")
+		}
+	})
 }
 
 func TestGetProjectByID(t *testing.T) {
-	t.Log("NOTE: TestGetProjectByID is a placeholder due to DB initialization issues.")
-	// store := setupTestDBForProjects(t)
-	// if store == nil {
-	// 	t.Skip("Skipping DB-dependent test: store is nil")
-	// }
-
-	// 1. Create a project to fetch
-	// projectToCreate := &chronos.Project{Name: "Fetch Me", ClientID: 1, Rate: 75.0}
-	// _ = chronos.CreateProject(store, projectToCreate) // Assume it works for this test setup
-
-	// 2. Attempt to get it
-	// retrieved, err := chronos.GetProjectByID(store, projectToCreate.ID)
-	// if err != nil {
-	// 	t.Errorf("GetProjectByID failed: %v", err)
-	// }
-	// if retrieved == nil {
-	// 	t.Fatalf("GetProjectByID returned nil for existing project")
-	// }
-	// if retrieved.Name != projectToCreate.Name {
-	// 	t.Errorf("Name mismatch: expected %s, got %s", projectToCreate.Name, retrieved.Name)
-	// }
+	eachRepository(t, func(t *testing.T, repo chronos.Repository) {
+		created := &chronos.Project{Name: "Fetchable Project", ClientID: 2, Rate: 100}
+		if err := chronos.CreateProject(repo, created); err != nil {
+			t.Fatalf("CreateProject failed: %v", err)
+		}
+		retrieved, err := chronos.GetProjectByID(repo, created.ID)
+		if err != nil {
+			t.Fatalf("GetProjectByID failed: %v", err)
+		}
+		if retrieved.Name != created.Name || retrieved.ClientID != 2 || retrieved.Rate != 100 {
+			t.Errorf("Retrieved project mismatch: %+v", retrieved)
+		}
+	})
 }
 
 func TestGetProjectByID_NotFound(t *testing.T) {
-	t.Log("NOTE: TestGetProjectByID_NotFound is a placeholder due to DB initialization issues.")
-	// store := setupTestDBForProjects(t)
-	// if store == nil {
-	// 	t.Skip("Skipping DB-dependent test: store is nil")
-	// }
-
-	// _, err := chronos.GetProjectByID(store, 99999) // Non-existent ID
-	// if err == nil {
-	// 	t.Errorf("Expected an error for non-existent project, got nil")
-	// }
-	// Potentially check for a specific error type, e.g., if chronos defines ErrNotFound
-	// if !errors.Is(err, chronos.ErrNotFound) { // Assuming chronos.ErrNotFound exists
-	//  t.Errorf("Expected ErrNotFound, got %v", err)
-	// }
+	eachRepository(t, func(t *testing.T, repo chronos.Repository) {
+		_, err := chronos.GetProjectByID(repo, 99999)
+		if !errors.Is(err, chronos.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+	})
 }
 
 func TestUpdateProject(t *testing.T) {
-	t.Log("NOTE: TestUpdateProject is a placeholder due to DB initialization issues.")
-	// store := setupTestDBForProjects(t)
-	// if store == nil {
-	// 	t.Skip("Skipping DB-dependent test: store is nil")
-	// }
-	
-	// project := &chronos.Project{Name: "Initial Name", ClientID: 1, Rate: 50.0}
-	// _ = chronos.CreateProject(store, project) // Assume created
-
-	// project.Name = "Updated Project Name"
-	// project.Rate = 120.75
-	// originalUpdatedAt := project.UpdatedAt
-
-	// time.Sleep(10 * time.Millisecond) // Ensure UpdatedAt might change visibly
-
-	// err := chronos.UpdateProject(store, project)
-	// if err != nil {
-	// 	t.Errorf("UpdateProject failed: %v", err)
-	// }
-
-	// updatedProject, _ := chronos.GetProjectByID(store, project.ID)
-	// if updatedProject.Name != "Updated Project Name" {
-	// 	t.Errorf("Name not updated: expected 'Updated Project Name', got '%s'", updatedProject.Name)
-	// }
-	// if updatedProject.Rate != 120.75 {
-	// 	t.Errorf("Rate not updated: expected 120.75, got %.2f", updatedProject.Rate)
-	// }
-	// if updatedProject.UpdatedAt.Equal(originalUpdatedAt) || updatedProject.UpdatedAt.Before(originalUpdatedAt) {
-	//  t.Errorf("UpdatedAt not advanced: original %v, new %v", originalUpdatedAt, updatedProject.UpdatedAt)
-	// }
-	_ = time.Now // dummy usage
+	eachRepository(t, func(t *testing.T, repo chronos.Repository) {
+		project := &chronos.Project{Name: "Original", ClientID: 1, Rate: 50}
+		if err := chronos.CreateProject(repo, project); err != nil {
+			t.Fatalf("CreateProject failed: %v", err)
+		}
+		project.Name = "Renamed"
+		project.Rate = 65
+		if err := chronos.UpdateProject(repo, project); err != nil {
+			t.Fatalf("UpdateProject failed: %v", err)
+		}
+		updated, err := chronos.GetProjectByID(repo, project.ID)
+		if err != nil {
+			t.Fatalf("GetProjectByID failed: %v", err)
+		}
+		if updated.Name != "Renamed" || updated.Rate != 65 {
+			t.Errorf("Project not updated: %+v", updated)
+		}
+	})
 }
 
 func TestDeleteProject(t *testing.T) {
-	t.Log("NOTE: TestDeleteProject is a placeholder due to DB initialization issues.")
-	// store := setupTestDBForProjects(t)
-	// if store == nil {
-	// 	t.Skip("Skipping DB-dependent test: store is nil")
-	// }
-
-	// project := &chronos.Project{Name: "To Be Deleted", ClientID: 1, Rate: 10.0}
-	// _ = chronos.CreateProject(store, project)
-
-	// err := chronos.DeleteProject(store, project.ID)
-	// if err != nil {
-	// 	t.Errorf("DeleteProject failed: %v", err)
-	// }
-
-	// _, getErr := chronos.GetProjectByID(store, project.ID)
-	// if getErr == nil {
-	// 	t.Errorf("Expected error when getting deleted project, but got nil")
-	// }
-	// Add specific check for "not found" error type here.
+	eachRepository(t, func(t *testing.T, repo chronos.Repository) {
+		project := &chronos.Project{Name: "To Be Deleted"}
+		if err := chronos.CreateProject(repo, project); err != nil {
+			t.Fatalf("CreateProject failed: %v", err)
+		}
+		if err := chronos.DeleteProject(repo, project.ID); err != nil {
+			t.Fatalf("DeleteProject failed: %v", err)
+		}
+		if _, err := chronos.GetProjectByID(repo, project.ID); !errors.Is(err, chronos.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound after delete, got %v", err)
+		}
+	})
 }
 
 func TestListProjects(t *testing.T) {
-	t.Log("NOTE: TestListProjects is a placeholder due to DB initialization issues.")
-	// store := setupTestDBForProjects(t)
-	// if store == nil {
-	// 	t.Skip("Skipping DB-dependent test: store is nil")
-	// }
-
-	// clientID1 := int64(1)
-	// clientID2 := int64(2)
-	// // Create some dummy clients if they don't exist from setupTestDBForProjects
-	// // _ = chronos.CreateClient(store, &chronos.Client{Name: "Client For List Test 1"}) // ID should be 1 or adjust
-	// // _ = chronos.CreateClient(store, &chronos.Client{Name: "Client For List Test 2"}) // ID should be 2 or adjust
-
-	// _ = chronos.CreateProject(store, &chronos.Project{Name: "Project A (Client 1)", ClientID: clientID1, Rate: 1.0})
-	// _ = chronos.CreateProject(store, &chronos.Project{Name: "Project B (Client 2)", ClientID: clientID2, Rate: 2.0})
-	// _ = chronos.CreateProject(store, &chronos.Project{Name: "Project C (Client 1)", ClientID: clientID1, Rate: 3.0})
-
-	// // Test listing all projects
-	// allProjects, errAll := chronos.ListProjects(store, nil)
-	// if errAll != nil {
-	// 	t.Errorf("ListProjects (all) failed: %v", errAll)
-	// }
-	// if len(allProjects) < 3 { // Check against expected number based on setup
-	// 	t.Errorf("ListProjects (all): expected at least 3 projects, got %d", len(allProjects))
-	// }
-
-	// // Test listing projects for clientID1
-	// client1Projects, errC1 := chronos.ListProjects(store, &clientID1)
-	// if errC1 != nil {
-	// 	t.Errorf("ListProjects (client 1) failed: %v", errC1)
-	// }
-	// if len(client1Projects) != 2 { // Adjust based on exact setup
-	// 	t.Errorf("ListProjects (client 1): expected 2 projects, got %d", len(client1Projects))
-	// }
-	// for _, p := range client1Projects {
-	// 	if p.ClientID != clientID1 {
-	// 		t.Errorf("ListProjects (client 1): found project with ClientID %d, expected %d", p.ClientID, clientID1)
-	// 	}
-	// }
+	eachRepository(t, func(t *testing.T, repo chronos.Repository) {
+		_ = chronos.CreateProject(repo, &chronos.Project{Name: "P1", ClientID: 1})
+		_ = chronos.CreateProject(repo, &chronos.Project{Name: "P2", ClientID: 2})
+		_ = chronos.CreateProject(repo, &chronos.Project{Name: "P3", ClientID: 1})
+
+		all, err := chronos.ListProjects(repo, nil)
+		if err != nil {
+			t.Fatalf("ListProjects failed: %v", err)
+		}
+		if len(all) != 3 {
+			t.Errorf("Expected 3 projects, got %d", len(all))
+		}
+
+		clientID := int64(1)
+		forClient, err := chronos.ListProjects(repo, &clientID)
+		if err != nil {
+			t.Fatalf("ListProjects(client) failed: %v", err)
+		}
+		if len(forClient) != 2 || forClient[0].Name != "P1" || forClient[1].Name != "P3" {
+			t.Errorf("Expected [P1, P3] for client 1, got %d projects", len(forClient))
+		}
+	})
 }
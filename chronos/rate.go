@@ -0,0 +1,164 @@
+package chronos
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rate levels, from most to least specific. An entry's own rate wins, then
+// its project's, client's and block's, then the default.
+const (
+	RateEntry   = "entry"
+	RateProject = "project"
+	RateClient  = "client"
+	RateBlock   = "block"
+	RateDefault = "default"
+)
+
+// Rate is an hourly rate for a project, client or block, or the default,
+// that applies to work from EffectiveFrom on. Setting a new rate adds one
+// with a later date, so earlier entries keep the rate they were worked at.
+type Rate struct {
+	ID            int64     `json:"id"`
+	Scope         string    `json:"scope"`  // RateProject, RateClient, RateBlock or RateDefault
+	Target        string    `json:"target"` // Project or client name, or block ID; empty for the default
+	Amount        float64   `json:"amount"` // Per hour
+	EffectiveFrom time.Time `json:"effective_from"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// CreateRate validates and stores a rate.
+func CreateRate(repo Repository, r *Rate) error {
+	switch r.Scope {
+	case RateProject, RateClient, RateBlock:
+		if strings.TrimSpace(r.Target) == "" {
+			return fmt.Errorf("a %s rate needs a %s", r.Scope, r.Scope)
+		}
+	case RateDefault:
+		r.Target = ""
+	default:
+		return fmt.Errorf("unknown rate scope %q", r.Scope)
+	}
+	if r.Amount < 0 {
+		return fmt.Errorf("rate must not be negative")
+	}
+	if r.CreatedAt.IsZero() {
+		r.CreatedAt = time.Now()
+	}
+	return repo.CreateRate(r)
+}
+
+// ListRates returns every rate, ordered by scope, target and date.
+func ListRates(repo Repository) ([]*Rate, error) {
+	return repo.ListRates()
+}
+
+// DeleteRate removes a rate by its ID.
+func DeleteRate(repo Repository, id int64) error {
+	return repo.DeleteRate(id)
+}
+
+// RateResolver picks the effective hourly rate of entries. Dated rates are
+// matched by the entry's start time; a project's own Rate and the configured
+// default apply when no dated rate at their level has started yet.
+type RateResolver struct {
+	rates       []*Rate
+	projects    []*Project
+	defaultRate float64
+}
+
+// NewRateResolver loads the rates and projects from repo. defaultRate is the
+// configured default, used when no dated default applies.
+func NewRateResolver(repo Repository, defaultRate float64) (*RateResolver, error) {
+	rates, err := repo.ListRates()
+	if err != nil {
+		return nil, err
+	}
+	projects, err := repo.ListProjects(nil)
+	if err != nil {
+		return nil, err
+	}
+	return &RateResolver{rates: rates, projects: projects, defaultRate: defaultRate}, nil
+}
+
+// Resolve returns the hourly rate for e and the level it came from. Without
+// any rate it returns 0 and "".
+func (r *RateResolver) Resolve(e *Entry) (float64, string) {
+	if e.Rate > 0 {
+		return e.Rate, RateEntry
+	}
+	at := e.StartTime
+	if at.IsZero() {
+		at = e.CreatedAt
+	}
+	if rate, ok := r.dated(RateProject, e.Project, at); ok {
+		return rate, RateProject
+	}
+	for _, p := range r.projects {
+		if p.Rate > 0 && ((e.ProjectID != 0 && p.ID == e.ProjectID) || (e.ProjectID == 0 && e.Project != "" && strings.EqualFold(p.Name, e.Project))) {
+			return p.Rate, RateProject
+		}
+	}
+	if rate, ok := r.dated(RateClient, e.Client, at); ok {
+		return rate, RateClient
+	}
+	if e.BlockID != 0 {
+		if rate, ok := r.dated(RateBlock, strconv.FormatInt(e.BlockID, 10), at); ok {
+			return rate, RateBlock
+		}
+	}
+	if rate, ok := r.dated(RateDefault, "", at); ok {
+		return rate, RateDefault
+	}
+	if r.defaultRate > 0 {
+		return r.defaultRate, RateDefault
+	}
+	return 0, ""
+}
+
+// Apply sets the resolved rate on every entry without one of its own, so
+// Amount reflects it. Nothing is saved.
+func (r *RateResolver) Apply(entries []*Entry) {
+	for _, e := range entries {
+		if e != nil && e.Rate <= 0 {
+			e.Rate, _ = r.Resolve(e)
+		}
+	}
+}
+
+// dated returns the latest rate for scope and target that started by at.
+func (r *RateResolver) dated(scope, target string, at time.Time) (float64, bool) {
+	if scope != RateDefault && target == "" {
+		return 0, false
+	}
+	var best *Rate
+	for _, rate := range r.rates {
+		if rate.Scope != scope || !strings.EqualFold(rate.Target, target) || rate.EffectiveFrom.After(at) {
+			continue
+		}
+		if best == nil || rate.EffectiveFrom.After(best.EffectiveFrom) {
+			best = rate
+		}
+	}
+	if best == nil {
+		return 0, false
+	}
+	return best.Amount, true
+}
+
+// sortRates orders rates by scope, target and date, as ListRates returns them.
+func sortRates(rates []*Rate) {
+	sort.SliceStable(rates, func(i, j int) bool {
+		a, b := rates[i], rates[j]
+		if a.Scope != b.Scope {
+			return a.Scope < b.Scope
+		}
+		if a.Target != b.Target {
+			return a.Target < b.Target
+		}
+		return a.EffectiveFrom.Before(b.EffectiveFrom)
+	})
+}
@@ -0,0 +1,80 @@
+package chronos_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/regiellis/chronos-go/chronos"
+)
+
+func TestRateResolver(t *testing.T) {
+	eachRepository(t, func(t *testing.T, repo chronos.Repository) {
+		jan := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		jun := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+		if err := chronos.CreateProject(repo, &chronos.Project{Name: "Zeus", Rate: 120}); err != nil {
+			t.Fatalf("CreateProject failed: %v", err)
+		}
+		for _, r := range []*chronos.Rate{
+			{Scope: chronos.RateProject, Target: "Apollo", Amount: 100, EffectiveFrom: jan},
+			{Scope: chronos.RateProject, Target: "Apollo", Amount: 110, EffectiveFrom: jun},
+			{Scope: chronos.RateClient, Target: "Acme", Amount: 90, EffectiveFrom: jan},
+			{Scope: chronos.RateBlock, Target: "7", Amount: 70, EffectiveFrom: jan},
+			{Scope: chronos.RateDefault, Amount: 60, EffectiveFrom: jun},
+		} {
+			if err := chronos.CreateRate(repo, r); err != nil {
+				t.Fatalf("CreateRate failed: %v", err)
+			}
+		}
+		if err := chronos.CreateRate(repo, &chronos.Rate{Scope: chronos.RateClient, Amount: 10}); err == nil {
+			t.Error("a client rate without a client should be refused")
+		}
+		rates, err := chronos.ListRates(repo)
+		if err != nil || len(rates) != 5 || rates[0].Scope != chronos.RateBlock || rates[4].Amount != 110 {
+			t.Fatalf("ListRates = %v, %v", rates, err)
+		}
+
+		resolver, err := chronos.NewRateResolver(repo, 50)
+		if err != nil {
+			t.Fatalf("NewRateResolver failed: %v", err)
+		}
+		march, july := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC), time.Date(2026, 7, 1, 9, 0, 0, 0, time.UTC)
+		tests := []struct {
+			name   string
+			entry  chronos.Entry
+			rate   float64
+			source string
+		}{
+			{"entry override", chronos.Entry{Project: "Apollo", Rate: 150, StartTime: march}, 150, chronos.RateEntry},
+			{"project before change", chronos.Entry{Project: "Apollo", StartTime: march}, 100, chronos.RateProject},
+			{"project after change", chronos.Entry{Project: "apollo", StartTime: july}, 110, chronos.RateProject},
+			{"project table", chronos.Entry{Project: "Zeus", Client: "Acme", StartTime: march}, 120, chronos.RateProject},
+			{"client", chronos.Entry{Project: "Hermes", Client: "Acme", BlockID: 7, StartTime: march}, 90, chronos.RateClient},
+			{"block", chronos.Entry{Client: "Initech", BlockID: 7, StartTime: march}, 70, chronos.RateBlock},
+			{"config default", chronos.Entry{StartTime: march}, 50, chronos.RateDefault},
+			{"dated default", chronos.Entry{StartTime: july}, 60, chronos.RateDefault},
+			{"before any project rate", chronos.Entry{Project: "Apollo", StartTime: jan.Add(-time.Hour)}, 50, chronos.RateDefault},
+		}
+		for _, tt := range tests {
+			rate, source := resolver.Resolve(&tt.entry)
+			if rate != tt.rate || source != tt.source {
+				t.Errorf("%s: got %v from %q, want %v from %q", tt.name, rate, source, tt.rate, tt.source)
+			}
+		}
+
+		entries := []*chronos.Entry{{Project: "Apollo", StartTime: march, Duration: 60, Billable: true}, {Rate: 200, StartTime: july, Duration: 30, Billable: true}}
+		resolver.Apply(entries)
+		if entries[0].Amount() != 100 || entries[1].Amount() != 100 {
+			t.Errorf("Apply: amounts %v and %v, want 100 and 100", entries[0].Amount(), entries[1].Amount())
+		}
+	})
+}
+
+func TestRateResolverWithoutRates(t *testing.T) {
+	resolver, err := chronos.NewRateResolver(chronos.NewMemoryRepository(), 0)
+	if err != nil {
+		t.Fatalf("NewRateResolver failed: %v", err)
+	}
+	if rate, source := resolver.Resolve(&chronos.Entry{Project: "Apollo"}); rate != 0 || source != "" {
+		t.Errorf("got %v from %q, want no rate", rate, source)
+	}
+}
@@ -0,0 +1,99 @@
+package chronos
+
+import "errors"
+
+// ErrNotFound is returned (wrapped) by repositories when a record does not exist.
+var ErrNotFound = errors.New("not found")
+
+// Repository is the storage port used by the domain logic in this package.
+// db.Store is the SQLite implementation; MemoryRepository keeps everything
+// in process for tests and dry-runs.
+//
+// Implementations only persist what they are given: timestamps, sanitizing and
+// derived fields are handled by the package-level functions (CreateEntry,
+// CreateBlock, ...) which callers should prefer.
+// List filters use the same keys as the package-level List functions.
+type Repository interface {
+	// Entries
+	CreateEntry(entry *Entry) error
+	GetEntry(id int64) (*Entry, error)
+	UpdateEntry(entry *Entry) error
+	DeleteEntry(id int64) error
+	ListEntries(filters map[string]interface{}) ([]*Entry, error)
+	MarkEntriesInvoiced(ids []int64) error
+
+	// Breaks inside entries. GetEntry and ListEntries load them into Entry.Breaks,
+	// and DeleteEntry removes them with the entry.
+	CreateBreak(b *Break) error
+	UpdateBreak(b *Break) error
+
+	// Blocks
+	CreateBlock(block *Block) error
+	GetBlock(id int64) (*Block, error)
+	UpdateBlock(block *Block) error
+	DeleteBlock(id int64) error
+	ListBlocks(filters map[string]interface{}) ([]*Block, error)
+	GetActiveBlock() (*Block, error)
+	SetActiveBlock(id int64) error
+
+	// Projects
+	CreateProject(project *Project) error
+	GetProject(id int64) (*Project, error)
+	UpdateProject(project *Project) error
+	DeleteProject(id int64) error
+	ListProjects(clientID *int64) ([]*Project, error)
+
+	// Clients
+	CreateClient(client *Client) error
+	GetClient(id int64) (*Client, error)
+	UpdateClient(client *Client) error
+	DeleteClient(id int64) error
+	ListClients() ([]*Client, error)
+
+	// Templates
+	SaveTemplate(name string, entryText string) error
+	GetTemplate(name string) (string, error)
+
+	// Invoices. CreateInvoice stores the lines and their entry links with the
+	// invoice and marks the entries invoiced, all or nothing, refusing entries
+	// that are missing or already invoiced (ErrAlreadyInvoiced); VoidInvoice
+	// likewise sets a draft or issued invoice void and releases its entries
+	// together, refusing other statuses (ErrInvoiceStatus); UpdateInvoice only changes the invoice itself and its
+	// adjustments, and AddInvoiceLine appends a line. GetInvoice and
+	// ListInvoices load the lines and adjustments.
+	CreateInvoice(inv *Invoice) error
+	GetInvoice(id int64) (*Invoice, error)
+	UpdateInvoice(inv *Invoice) error
+	VoidInvoice(inv *Invoice) error
+	ListInvoices(filters map[string]interface{}) ([]*Invoice, error)
+	AddInvoiceLine(invoiceID int64, line *InvoiceLine) error
+
+	// Client invoice terms: the adjustments a client's new invoices start
+	// with. Client names match case-insensitively.
+	GetClientTerms(client string) ([]*Adjustment, error)
+	SetClientTerms(client string, terms []*Adjustment) error
+
+	// Rates. ListRates orders them by scope, target and effective date.
+	CreateRate(r *Rate) error
+	ListRates() ([]*Rate, error)
+	DeleteRate(id int64) error
+
+	// Rounding rules, ordered by scope and target.
+	CreateRoundingRule(r *RoundingRule) error
+	UpdateRoundingRule(r *RoundingRule) error
+	ListRoundingRules() ([]*RoundingRule, error)
+	DeleteRoundingRule(id int64) error
+
+	// Currencies. SetCurrency replaces the setting for the same scope and
+	// target (matched case-insensitively); ListExchangeRates orders rates by
+	// date.
+	SetCurrency(c *CurrencySetting) error
+	ListCurrencySettings() ([]*CurrencySetting, error)
+	CreateExchangeRate(r *ExchangeRate) error
+	ListExchangeRates() ([]*ExchangeRate, error)
+
+	// Assistant history
+	SaveInteraction(i *Interaction) error
+	GetInteraction(id int64) (*Interaction, error)
+	ListInteractions(limit int) ([]*Interaction, error)
+}
@@ -0,0 +1,29 @@
+package chronos_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/regiellis/chronos-go/chronos"
+	"github.com/regiellis/chronos-go/db"
+)
+
+// eachRepository runs fn against every Repository implementation so the
+// in-memory and SQLite stores stay behaviourally identical.
+func eachRepository(t *testing.T, fn func(t *testing.T, repo chronos.Repository)) {
+	t.Helper()
+	t.Run("memory", func(t *testing.T) {
+		fn(t, chronos.NewMemoryRepository())
+	})
+	t.Run("sqlite", func(t *testing.T) {
+		store, err := db.NewStore(filepath.Join(t.TempDir(), "chronos.db"))
+		if err != nil {
+			t.Fatalf("Failed to create store: %v", err)
+		}
+		t.Cleanup(func() { store.DB.Close() })
+		if err := store.InitSchema(); err != nil {
+			t.Fatalf("Failed to init schema: %v", err)
+		}
+		fn(t, store)
+	})
+}
@@ -0,0 +1,219 @@
+package chronos
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Rounding directions.
+const (
+	RoundUp      = "up"
+	RoundDown    = "down"
+	RoundNearest = "nearest"
+)
+
+// What a rounding rule rounds: each entry, each day's total or the total of
+// everything billed together.
+const (
+	RoundPerEntry   = "entry"
+	RoundPerDay     = "day"
+	RoundPerInvoice = "invoice"
+)
+
+// RoundingRule is how a client or project bills tracked time: rounded to
+// Increment minutes in Direction, per entry, day or invoice, and never less
+// than Minimum minutes for each of those. A project's rule beats its
+// client's.
+type RoundingRule struct {
+	ID        int64     `json:"id"`
+	Scope     string    `json:"scope"`     // RateProject or RateClient
+	Target    string    `json:"target"`    // Project or client name
+	Increment int64     `json:"increment"` // Minutes; 0 leaves the time as tracked
+	Direction string    `json:"direction"` // RoundUp, RoundDown or RoundNearest
+	Per       string    `json:"per"`       // RoundPerEntry, RoundPerDay or RoundPerInvoice
+	Minimum   int64     `json:"minimum"`   // Minutes
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Round returns the billed minutes for minutes of tracked time. Nothing
+// tracked bills nothing, whatever the minimum.
+func (r *RoundingRule) Round(minutes float64) float64 {
+	if minutes <= 0 {
+		return 0
+	}
+	billed := minutes
+	if r.Increment > 0 {
+		// Tolerate float noise from timestamps so 30.0000001 stays 30.
+		steps := minutes / float64(r.Increment)
+		switch r.Direction {
+		case RoundDown:
+			steps = math.Floor(steps + 1e-6)
+		case RoundNearest:
+			steps = math.Round(steps)
+		default:
+			steps = math.Ceil(steps - 1e-6)
+		}
+		billed = steps * float64(r.Increment)
+	}
+	return math.Max(billed, float64(r.Minimum))
+}
+
+// String describes the rule, e.g. "15m up per day, minimum 60m".
+func (r *RoundingRule) String() string {
+	s := "as tracked"
+	if r.Increment > 0 {
+		s = fmt.Sprintf("%dm %s", r.Increment, r.Direction)
+	}
+	s += " per " + r.Per
+	if r.Minimum > 0 {
+		s += fmt.Sprintf(", minimum %dm", r.Minimum)
+	}
+	return s
+}
+
+// SaveRoundingRule validates a rule and stores it, replacing the rule the
+// project or client already had.
+func SaveRoundingRule(repo Repository, r *RoundingRule) error {
+	if r.Scope != RateProject && r.Scope != RateClient {
+		return fmt.Errorf("rounding rules apply to a project or client, not %q", r.Scope)
+	}
+	r.Target = strings.TrimSpace(r.Target)
+	if r.Target == "" {
+		return fmt.Errorf("a %s rounding rule needs a %s", r.Scope, r.Scope)
+	}
+	if r.Direction == "" {
+		r.Direction = RoundUp
+	}
+	if r.Per == "" {
+		r.Per = RoundPerEntry
+	}
+	switch {
+	case r.Direction != RoundUp && r.Direction != RoundDown && r.Direction != RoundNearest:
+		return fmt.Errorf("unknown rounding direction %q (want up, down or nearest)", r.Direction)
+	case r.Per != RoundPerEntry && r.Per != RoundPerDay && r.Per != RoundPerInvoice:
+		return fmt.Errorf("unknown rounding period %q (want entry, day or invoice)", r.Per)
+	case r.Increment < 0 || r.Minimum < 0:
+		return fmt.Errorf("increment and minimum must not be negative")
+	}
+
+	rules, err := repo.ListRoundingRules()
+	if err != nil {
+		return err
+	}
+	r.UpdatedAt = time.Now()
+	for _, existing := range rules {
+		if existing.Scope == r.Scope && strings.EqualFold(existing.Target, r.Target) {
+			r.ID, r.CreatedAt = existing.ID, existing.CreatedAt
+			return repo.UpdateRoundingRule(r)
+		}
+	}
+	r.CreatedAt = r.UpdatedAt
+	return repo.CreateRoundingRule(r)
+}
+
+// ListRoundingRules returns every rounding rule, ordered by scope and target.
+func ListRoundingRules(repo Repository) ([]*RoundingRule, error) {
+	return repo.ListRoundingRules()
+}
+
+// DeleteRoundingRule removes a rounding rule by its ID.
+func DeleteRoundingRule(repo Repository, id int64) error {
+	return repo.DeleteRoundingRule(id)
+}
+
+// Rounder applies the rounding rules to entries being billed.
+type Rounder struct {
+	rules []*RoundingRule
+}
+
+// NewRounder loads the rounding rules from repo.
+func NewRounder(repo Repository) (*Rounder, error) {
+	rules, err := repo.ListRoundingRules()
+	if err != nil {
+		return nil, err
+	}
+	return &Rounder{rules: rules}, nil
+}
+
+// Rule returns the rule for e's project, else its client's, or nil.
+func (r *Rounder) Rule(e *Entry) *RoundingRule {
+	var clientRule *RoundingRule
+	for _, rule := range r.rules {
+		switch {
+		case rule.Scope == RateProject && e.Project != "" && strings.EqualFold(rule.Target, e.Project):
+			return rule
+		case rule.Scope == RateClient && e.Client != "" && strings.EqualFold(rule.Target, e.Client):
+			clientRule = rule
+		}
+	}
+	return clientRule
+}
+
+// Apply sets Billed on every billable entry with a rule. Per-day and
+// per-invoice rules round the group's total, and spread it over the group's
+// entries in proportion to their tracked time; entries are one invoice's
+// worth. Tracked time is left as it is and nothing is saved.
+func (r *Rounder) Apply(entries []*Entry) {
+	r.apply(entries, false)
+}
+
+// ApplyHistory is Apply for entries from any number of invoices, as in
+// reports. Per-invoice rules only round the entries not invoiced yet, together
+// as the next invoice would; invoiced entries are left to ApplyInvoiced, which
+// reports what their invoices billed.
+func (r *Rounder) ApplyHistory(entries []*Entry) {
+	r.apply(entries, true)
+}
+
+func (r *Rounder) apply(entries []*Entry, history bool) {
+	type group struct {
+		rule    *RoundingRule
+		entries []*Entry
+		minutes float64
+	}
+	groups := map[string]*group{}
+	var order []string
+	for _, e := range entries {
+		if e == nil || !e.Billable {
+			continue
+		}
+		rule := r.Rule(e)
+		if rule == nil {
+			continue
+		}
+		if history && rule.Per == RoundPerInvoice && e.Invoiced {
+			continue
+		}
+		key := fmt.Sprintf("%d", rule.ID)
+		switch rule.Per {
+		case RoundPerEntry:
+			billed := rule.Round(e.Minutes())
+			e.Billed = &billed
+			continue
+		case RoundPerDay:
+			key += e.StartTime.Local().Format("/2006-01-02")
+		}
+		g, ok := groups[key]
+		if !ok {
+			g = &group{rule: rule}
+			groups[key] = g
+			order = append(order, key)
+		}
+		g.entries = append(g.entries, e)
+		g.minutes += e.Minutes()
+	}
+	for _, key := range order {
+		g := groups[key]
+		total := g.rule.Round(g.minutes)
+		for _, e := range g.entries {
+			billed := 0.0
+			if g.minutes > 0 {
+				billed = total * e.Minutes() / g.minutes
+			}
+			e.Billed = &billed
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package chronos_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/regiellis/chronos-go/chronos"
+)
+
+func TestRoundingRuleRound(t *testing.T) {
+	tests := []struct {
+		rule    chronos.RoundingRule
+		minutes float64
+		want    float64
+	}{
+		{chronos.RoundingRule{Increment: 15, Direction: chronos.RoundUp}, 31, 45},
+		{chronos.RoundingRule{Increment: 15, Direction: chronos.RoundUp}, 30.0000001, 30},
+		{chronos.RoundingRule{Increment: 6, Direction: chronos.RoundNearest}, 32, 30},
+		{chronos.RoundingRule{Increment: 15, Direction: chronos.RoundDown}, 44, 30},
+		{chronos.RoundingRule{Increment: 15, Direction: chronos.RoundUp, Minimum: 60}, 20, 60},
+		{chronos.RoundingRule{Minimum: 60}, 0, 0},
+		{chronos.RoundingRule{}, 37, 37},
+	}
+	for _, tt := range tests {
+		if got := tt.rule.Round(tt.minutes); got != tt.want {
+			t.Errorf("%s: Round(%v) = %v, want %v", tt.rule.String(), tt.minutes, got, tt.want)
+		}
+	}
+}
+
+func TestRounder(t *testing.T) {
+	eachRepository(t, func(t *testing.T, repo chronos.Repository) {
+		for _, r := range []*chronos.RoundingRule{
+			{Scope: chronos.RateClient, Target: "Acme", Increment: 30},
+			{Scope: chronos.RateClient, Target: "acme", Increment: 15, Per: chronos.RoundPerDay, Minimum: 60},
+			{Scope: chronos.RateProject, Target: "Apollo", Increment: 6},
+			{Scope: chronos.RateClient, Target: "Globex", Increment: 15, Per: chronos.RoundPerInvoice},
+		} {
+			if err := chronos.SaveRoundingRule(repo, r); err != nil {
+				t.Fatalf("SaveRoundingRule failed: %v", err)
+			}
+		}
+		if err := chronos.SaveRoundingRule(repo, &chronos.RoundingRule{Scope: chronos.RateClient, Target: "Acme", Per: "week"}); err == nil {
+			t.Error("an unknown rounding period should be refused")
+		}
+		rules, err := chronos.ListRoundingRules(repo)
+		if err != nil || len(rules) != 3 {
+			t.Fatalf("ListRoundingRules = %v, %v; want the Acme rule replaced", rules, err)
+		}
+
+		day1 := time.Date(2026, 3, 2, 9, 0, 0, 0, time.Local)
+		day2 := day1.AddDate(0, 0, 1)
+		entries := []*chronos.Entry{
+			{Client: "Acme", Project: "Hermes", StartTime: day1, Duration: 20, Billable: true},
+			{Client: "Acme", Project: "Hermes", StartTime: day1.Add(time.Hour), Duration: 10, Billable: true},
+			{Client: "Acme", Project: "Hermes", StartTime: day2, Duration: 50, Billable: true},
+			{Client: "Acme", Project: "Apollo", StartTime: day2, Duration: 7, Billable: true, Rate: 100},
+			{Client: "Acme", Project: "Hermes", StartTime: day2, Duration: 20},
+			{Client: "Globex", StartTime: day1, Duration: 10, Billable: true},
+			{Client: "Globex", StartTime: day2, Duration: 10, Billable: true},
+			{Client: "Initech", StartTime: day1, Duration: 25, Billable: true},
+		}
+		rounder, err := chronos.NewRounder(repo)
+		if err != nil {
+			t.Fatalf("NewRounder failed: %v", err)
+		}
+		rounder.Apply(entries)
+		for i, want := range []float64{40, 20, 60, 12, 20, 15, 15, 25} {
+			if got := entries[i].BilledMinutes(); got != want {
+				t.Errorf("entry %d: billed %v minutes, want %v", i, got, want)
+			}
+		}
+		if entries[4].Billed != nil || entries[7].Billed != nil {
+			t.Error("non-billable entries and entries without a rule should keep their tracked time")
+		}
+		if entries[3].Minutes() != 7 || entries[3].Amount() != 20 {
+			t.Errorf("Apollo entry: tracked %v, amount %v; want 7 and 20", entries[3].Minutes(), entries[3].Amount())
+		}
+
+		lines := chronos.GroupInvoiceLines(entries[:3])
+		if len(lines) != 1 || lines[0].Minutes != 120 || lines[0].Tracked != 80 {
+			t.Errorf("invoice line should bill 120 of 80 tracked minutes: %+v", lines[0])
+		}
+	})
+}
+
+func TestRounderApplyHistory(t *testing.T) {
+	eachRepository(t, func(t *testing.T, repo chronos.Repository) {
+		if err := chronos.SaveRoundingRule(repo, &chronos.RoundingRule{Scope: chronos.RateClient, Target: "Globex", Increment: 15, Per: chronos.RoundPerInvoice, Minimum: 60}); err != nil {
+			t.Fatalf("SaveRoundingRule failed: %v", err)
+		}
+		rounder, err := chronos.NewRounder(repo)
+		if err != nil {
+			t.Fatalf("NewRounder failed: %v", err)
+		}
+		day := time.Date(2026, 3, 2, 9, 0, 0, 0, time.Local)
+		entries := []*chronos.Entry{
+			{Client: "Globex", StartTime: day, Duration: 10, Billable: true, Invoiced: true},
+			{Client: "Globex", StartTime: day, Duration: 20, Billable: true},
+			{Client: "Globex", StartTime: day, Duration: 10, Billable: true},
+		}
+		rounder.ApplyHistory(entries)
+		// The unbilled 30 minutes meet the minimum on their own.
+		if entries[0].Billed != nil || entries[1].BilledMinutes() != 40 || entries[2].BilledMinutes() != 20 {
+			t.Errorf("billed %v, %v, %v; want the invoiced entry left and 40, 20", entries[0].Billed, entries[1].BilledMinutes(), entries[2].BilledMinutes())
+		}
+	})
+}
@@ -0,0 +1,97 @@
+package chronos
+
+import (
+	"sort"
+	"time"
+)
+
+// Break is a pause inside an entry. Time spent on a break is not worked and
+// is excluded from the entry's duration. EndTime is zero while the break is running.
+type Break struct {
+	ID        int64     `json:"id"`
+	EntryID   int64     `json:"entry_id"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+}
+
+// Segment is one continuous stretch of work within an entry.
+type Segment struct {
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+// Duration returns the length of the segment.
+func (s Segment) Duration() time.Duration {
+	return s.EndTime.Sub(s.StartTime)
+}
+
+// end returns when the entry stopped, or now for an open entry.
+func (e *Entry) end(now time.Time) time.Time {
+	switch {
+	case !e.EndTime.IsZero():
+		return e.EndTime
+	case e.Duration > 0:
+		return e.StartTime.Add(time.Duration(e.Duration) * time.Minute)
+	}
+	return now
+}
+
+// IsPaused reports whether the entry is an open timer with a running break.
+func (e *Entry) IsPaused() bool {
+	if !e.IsOpen() || len(e.Breaks) == 0 {
+		return false
+	}
+	return e.Breaks[len(e.Breaks)-1].EndTime.IsZero()
+}
+
+// Segments splits the entry into worked stretches around its breaks.
+// Open entries and running breaks are measured up to now.
+func (e *Entry) Segments(now time.Time) []Segment {
+	if e.StartTime.IsZero() {
+		return nil
+	}
+	end := e.end(now)
+	breaks := make([]Break, len(e.Breaks))
+	copy(breaks, e.Breaks)
+	sort.Slice(breaks, func(i, j int) bool { return breaks[i].StartTime.Before(breaks[j].StartTime) })
+
+	var segments []Segment
+	cursor := e.StartTime
+	for _, b := range breaks {
+		bStart, bEnd := b.StartTime, b.EndTime
+		if bEnd.IsZero() || bEnd.After(end) {
+			bEnd = end
+		}
+		if bStart.Before(cursor) {
+			bStart = cursor
+		}
+		if !bStart.Before(bEnd) {
+			continue
+		}
+		if bStart.After(cursor) {
+			segments = append(segments, Segment{StartTime: cursor, EndTime: bStart})
+		}
+		cursor = bEnd
+	}
+	if end.After(cursor) {
+		segments = append(segments, Segment{StartTime: cursor, EndTime: end})
+	}
+	return segments
+}
+
+// Worked returns the total time worked in the entry, excluding breaks.
+func (e *Entry) Worked(now time.Time) time.Duration {
+	var total time.Duration
+	for _, s := range e.Segments(now) {
+		total += s.Duration()
+	}
+	return total
+}
+
+// BreakTime returns the total time spent on breaks, measured up to now for open entries.
+func (e *Entry) BreakTime(now time.Time) time.Duration {
+	if e.StartTime.IsZero() {
+		return 0
+	}
+	return e.end(now).Sub(e.StartTime) - e.Worked(now)
+}
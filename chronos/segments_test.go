@@ -0,0 +1,68 @@
+package chronos_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/regiellis/chronos-go/chronos"
+)
+
+func TestEntrySegments(t *testing.T) {
+	at := func(hour, min int) time.Time { return time.Date(2024, 3, 4, hour, min, 0, 0, time.UTC) }
+
+	entry := &chronos.Entry{
+		StartTime: at(9, 0),
+		EndTime:   at(17, 0),
+		Breaks: []chronos.Break{
+			{StartTime: at(15, 0), EndTime: at(15, 15)},
+			{StartTime: at(12, 0), EndTime: at(13, 0)},
+			{StartTime: at(16, 45), EndTime: at(18, 0)}, // Runs past the end; clipped
+		},
+	}
+
+	segments := entry.Segments(at(20, 0))
+	want := []chronos.Segment{
+		{StartTime: at(9, 0), EndTime: at(12, 0)},
+		{StartTime: at(13, 0), EndTime: at(15, 0)},
+		{StartTime: at(15, 15), EndTime: at(16, 45)},
+	}
+	if len(segments) != len(want) {
+		t.Fatalf("expected %d segments, got %d: %+v", len(want), len(segments), segments)
+	}
+	for i := range want {
+		if !segments[i].StartTime.Equal(want[i].StartTime) || !segments[i].EndTime.Equal(want[i].EndTime) {
+			t.Errorf("segment %d: expected %+v, got %+v", i, want[i], segments[i])
+		}
+	}
+	if worked := entry.Worked(at(20, 0)); worked != 6*time.Hour+30*time.Minute {
+		t.Errorf("Worked = %v, want 6h30m", worked)
+	}
+	if bt := entry.BreakTime(at(20, 0)); bt != 90*time.Minute {
+		t.Errorf("BreakTime = %v, want 1h30m", bt)
+	}
+	// With no stored duration, Minutes falls back to worked time, not the span.
+	if m := entry.Minutes(); m != 390 {
+		t.Errorf("Minutes = %v, want 390", m)
+	}
+}
+
+func TestEntrySegments_OpenAndPaused(t *testing.T) {
+	at := func(hour, min int) time.Time { return time.Date(2024, 3, 4, hour, min, 0, 0, time.UTC) }
+
+	entry := &chronos.Entry{
+		StartTime: at(9, 0),
+		Breaks:    []chronos.Break{{StartTime: at(10, 0)}}, // Still on a break
+	}
+	if !entry.IsOpen() || !entry.IsPaused() {
+		t.Fatalf("expected an open, paused entry")
+	}
+	if worked := entry.Worked(at(11, 0)); worked != time.Hour {
+		t.Errorf("Worked = %v, want 1h", worked)
+	}
+	if elapsed := entry.Elapsed(at(11, 0)); elapsed != time.Hour {
+		t.Errorf("Elapsed = %v, want 1h", elapsed)
+	}
+	if m := entry.Minutes(); m != 0 {
+		t.Errorf("open entry Minutes = %v, want 0", m)
+	}
+}
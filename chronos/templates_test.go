@@ -1,131 +1,69 @@
 package chronos_test
 
 import (
-	"strings"
+	"errors"
 	"testing"
 
 	"github.com/regiellis/chronos-go/chronos"
-	// "github.com/regiellis/chronos-go/db" // Would import if db was accessible for testing
-	// _ "github.com/mattn/go-sqlite3" // SQLite driver for in-memory DB
 )
 
-// Helper function to setup a test store (currently commented out)
-/*
-func setupTestDBForTemplates(t *testing.T) *db.Store {
-	t.Helper()
-	// store, err := db.NewStore(":memory:") // Use in-memory SQLite
-	// if err != nil {
-	// 	t.Fatalf("Failed to create in-memory store for templates test: %v", err)
-	// }
-	// if err := chronos.EnsureTemplatesTable(store); err != nil {
-	// 	t.Fatalf("Failed to ensure templates table: %v", err)
-	// }
-	// return store
-	return nil // Placeholder
-}
-*/
-
-func TestEnsureTemplatesTable(t *testing.T) {
-	t.Log("NOTE: TestEnsureTemplatesTable is a placeholder due to DB initialization issues.")
-	// store := setupTestDBForTemplates(t)
-	// if store == nil {
-	// 	t.Skip("Skipping DB-dependent test: store is nil")
-	// }
-
-	// The main test is that EnsureTemplatesTable runs without error.
-	// Further verification could involve trying to insert/select from the table.
-	// err := chronos.EnsureTemplatesTable(store)
-	// if err != nil {
-	// 	t.Errorf("EnsureTemplatesTable failed: %v", err)
-	// }
-
-	// Example: Try to save a template to see if table was created (optional)
-	// errSave := chronos.SaveTemplate(store, "test_ensure", "data")
-	// if errSave != nil {
-	// 	t.Errorf("Failed to save template after EnsureTemplatesTable: %v", errSave)
-	// }
-}
-
 func TestSaveTemplate(t *testing.T) {
-	t.Log("NOTE: TestSaveTemplate is a placeholder due to DB initialization issues.")
-	// store := setupTestDBForTemplates(t)
-	// if store == nil {
-	//  t.Skip("Skipping DB-dependent test: store is nil")
-	// }
-
-	templateName := "test_template"
-	templateText := "This is a test template entry."
-
-	// err := chronos.SaveTemplate(store, templateName, templateText)
-	// if err != nil {
-	// 	t.Errorf("SaveTemplate failed: %v", err)
-	// }
-
-	// Verification: try to get the template
-	// savedText, getErr := chronos.GetTemplate(store, templateName)
-	// if getErr != nil {
-	// 	t.Errorf("GetTemplate after SaveTemplate failed: %v", getErr)
-	// }
-	// if savedText != templateText {
-	// 	t.Errorf("Saved template text mismatch: expected '%s', got '%s'", templateText, savedText)
-	// }
-
-	// Test overwrite
-	// newTemplateText := "This is the updated template."
-	// errOverwrite := chronos.SaveTemplate(store, templateName, newTemplateText)
-	// if errOverwrite != nil {
-	//  t.Errorf("SaveTemplate (overwrite) failed: %v", errOverwrite)
-	// }
-	// overwrittenText, getOverwriteErr := chronos.GetTemplate(store, templateName)
-	// if getOverwriteErr != nil {
-	//  t.Errorf("GetTemplate after overwrite failed: %v", getOverwriteErr)
-	// }
-	// if overwrittenText != newTemplateText {
-	//  t.Errorf("Overwritten template text mismatch: expected '%s', got '%s'", newTemplateText, overwrittenText)
-	// }
+	eachRepository(t, func(t *testing.T, repo chronos.Repository) {
+		templateName := "test_template"
+		templateText := "This is a test template entry."
+
+		if err := chronos.SaveTemplate(repo, templateName, templateText); err != nil {
+			t.Fatalf("SaveTemplate failed: %v", err)
+		}
+		savedText, err := chronos.GetTemplate(repo, templateName)
+		if err != nil {
+			t.Fatalf("GetTemplate after SaveTemplate failed: %v", err)
+		}
+		if savedText != templateText {
+			t.Errorf("Saved template text mismatch: expected '%s', got '%s'", templateText, savedText)
+		}
+
+		// Test overwrite
+		newTemplateText := "This is the updated template."
+		if err := chronos.SaveTemplate(repo, templateName, newTemplateText); err != nil {
+			t.Fatalf("SaveTemplate (overwrite) failed: %v", err)
+		}
+		overwrittenText, err := chronos.GetTemplate(repo, templateName)
+		if err != nil {
+			t.Fatalf("GetTemplate after overwrite failed: %v", err)
+		}
+		if overwrittenText != newTemplateText {
+			t.Errorf("Overwritten template text mismatch: expected '%s', got '%s'", newTemplateText, overwrittenText)
+		}
+	})
 }
 
 func TestGetTemplate(t *testing.T) {
-	t.Log("NOTE: TestGetTemplate is a placeholder due to DB initialization issues.")
-	// store := setupTestDBForTemplates(t)
-	// if store == nil {
-	//  t.Skip("Skipping DB-dependent test: store is nil")
-	// }
-
-	templateName := "get_test_template"
-	templateText := "Content for get test."
-
-	// First, save a template to retrieve
-	// _ = chronos.SaveTemplate(store, templateName, templateText) // Ignore error for setup
-
-	// retrievedText, err := chronos.GetTemplate(store, templateName)
-	// if err != nil {
-	// 	t.Errorf("GetTemplate failed for existing template: %v", err)
-	// }
-	// if retrievedText != templateText {
-	// 	t.Errorf("Retrieved template text mismatch: expected '%s', got '%s'", templateText, retrievedText)
-	// }
+	eachRepository(t, func(t *testing.T, repo chronos.Repository) {
+		templateName := "get_test_template"
+		templateText := "Content for get test."
+		if err := chronos.SaveTemplate(repo, templateName, templateText); err != nil {
+			t.Fatalf("SaveTemplate failed: %v", err)
+		}
+
+		retrievedText, err := chronos.GetTemplate(repo, templateName)
+		if err != nil {
+			t.Errorf("GetTemplate failed for existing template: %v", err)
+		}
+		if retrievedText != templateText {
+			t.Errorf("Retrieved template text mismatch: expected '%s', got '%s'", templateText, retrievedText)
+		}
+	})
 }
 
 func TestGetTemplate_NotFound(t *testing.T) {
-	t.Log("NOTE: TestGetTemplate_NotFound is a placeholder due to DB initialization issues.")
-	// store := setupTestDBForTemplates(t)
-	// if store == nil {
-	//  t.Skip("Skipping DB-dependent test: store is nil")
-	// }
-
-	// _, err := chronos.GetTemplate(store, "non_existent_template")
-	// if err == nil {
-	// 	t.Errorf("Expected an error when getting a non-existent template, but got nil")
-	// }
-	// // Check if the error indicates "not found"
-	// if err != nil && !strings.Contains(strings.ToLower(err.Error()), "not found") {
-	//  t.Errorf("Expected 'not found' error, got: %v", err)
-	// }
-	// A more robust check might involve custom error types or specific error variables.
-	// For example, if chronos.ErrTemplateNotFound is defined:
-	// if !errors.Is(err, chronos.ErrTemplateNotFound) {
-	//  t.Errorf("Expected chronos.ErrTemplateNotFound, got %v", err)
-	// }
-	_ = strings.ToLower("") // dummy usage
+	eachRepository(t, func(t *testing.T, repo chronos.Repository) {
+		_, err := chronos.GetTemplate(repo, "non_existent_template")
+		if err == nil {
+			t.Fatalf("Expected an error when getting a non-existent template, but got nil")
+		}
+		if !errors.Is(err, chronos.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got: %v", err)
+		}
+	})
 }
@@ -0,0 +1,180 @@
+package chronos
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+var (
+	// ErrTimerRunning is returned when starting a timer while another one is open.
+	ErrTimerRunning = errors.New("a timer is already running")
+	// ErrNoTimer is returned when stopping with no open timer.
+	ErrNoTimer = errors.New("no timer is running")
+	// ErrNothingToResume is returned by ResumeTimer when there is no previous entry.
+	ErrNothingToResume = errors.New("no previous entry to resume")
+	// ErrTimerPaused is returned when pausing a timer that is already on a break.
+	ErrTimerPaused = errors.New("the timer is already paused")
+)
+
+// IsOpen reports whether the entry is a running timer: started, with no end yet.
+func (e *Entry) IsOpen() bool {
+	return !e.StartTime.IsZero() && e.EndTime.IsZero() && e.Duration == 0
+}
+
+// Elapsed returns how long the entry has been worked, excluding breaks.
+// Open entries are measured up to now.
+func (e *Entry) Elapsed(now time.Time) time.Duration {
+	if e.IsOpen() {
+		return e.Worked(now)
+	}
+	return time.Duration(e.Minutes() * float64(time.Minute))
+}
+
+// RunningTimer returns the open timer entry, or nil if no timer is running.
+// The timer is an ordinary entry with no end time, so it survives restarts.
+func RunningTimer(repo Repository) (*Entry, error) {
+	entries, err := repo.ListEntries(map[string]interface{}{"open": true})
+	if err != nil {
+		return nil, fmt.Errorf("RunningTimer: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	return entries[0], nil
+}
+
+// StartTimer opens a new timer entry from the given template at the given time.
+// Only one timer may run at a time.
+func StartTimer(repo Repository, entry *Entry, at time.Time) error {
+	running, err := RunningTimer(repo)
+	if err != nil {
+		return err
+	}
+	if running != nil {
+		return fmt.Errorf("StartTimer: %w (entry %d)", ErrTimerRunning, running.ID)
+	}
+	entry.ID = 0
+	entry.StartTime = at
+	entry.EndTime = time.Time{}
+	entry.Duration = 0
+	entry.Invoiced = false
+	entry.Breaks = nil
+	return CreateEntry(repo, entry)
+}
+
+// PauseTimer starts a break on the running timer.
+func PauseTimer(repo Repository, at time.Time) (*Entry, error) {
+	entry, err := RunningTimer(repo)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("PauseTimer: %w", ErrNoTimer)
+	}
+	if entry.IsPaused() {
+		return nil, fmt.Errorf("PauseTimer: %w", ErrTimerPaused)
+	}
+	if at.Before(entry.StartTime) {
+		at = entry.StartTime
+	}
+	b := Break{EntryID: entry.ID, StartTime: at}
+	if err := repo.CreateBreak(&b); err != nil {
+		return nil, fmt.Errorf("PauseTimer: %w", err)
+	}
+	entry.Breaks = append(entry.Breaks, b)
+	return entry, nil
+}
+
+// endBreak closes the running break of a paused entry.
+func endBreak(repo Repository, entry *Entry, at time.Time) error {
+	if !entry.IsPaused() {
+		return nil
+	}
+	b := &entry.Breaks[len(entry.Breaks)-1]
+	if at.Before(b.StartTime) {
+		at = b.StartTime
+	}
+	b.EndTime = at
+	return repo.UpdateBreak(b)
+}
+
+// StopTimer closes the running timer at the given time and attaches it to the
+// active block, filling project and client from the block when they are empty.
+// A running break ends with the timer; the stored duration excludes all breaks.
+func StopTimer(repo Repository, at time.Time) (*Entry, error) {
+	entry, err := RunningTimer(repo)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("StopTimer: %w", ErrNoTimer)
+	}
+	if at.Before(entry.StartTime) {
+		at = entry.StartTime
+	}
+	if err := endBreak(repo, entry, at); err != nil {
+		return nil, fmt.Errorf("StopTimer: failed to end break: %w", err)
+	}
+	entry.EndTime = at
+	entry.Duration = int64(math.Round(entry.Worked(at).Minutes()))
+
+	block, err := GetActiveBlock(repo)
+	if err != nil {
+		return nil, fmt.Errorf("StopTimer: failed to get active block: %w", err)
+	}
+	if block != nil {
+		entry.BlockID = block.ID
+		if entry.Project == "" {
+			entry.Project = block.Project
+		}
+		if entry.Client == "" {
+			entry.Client = block.Client
+		}
+	}
+	if err := UpdateEntry(repo, entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// ResumeTimer ends the break of a paused timer. With no timer running it
+// starts a new one for the project and task of the most recent entry.
+func ResumeTimer(repo Repository, at time.Time) (*Entry, error) {
+	running, err := RunningTimer(repo)
+	if err != nil {
+		return nil, err
+	}
+	if running != nil {
+		if !running.IsPaused() {
+			return nil, fmt.Errorf("ResumeTimer: %w (entry %d)", ErrTimerRunning, running.ID)
+		}
+		if err := endBreak(repo, running, at); err != nil {
+			return nil, fmt.Errorf("ResumeTimer: failed to end break: %w", err)
+		}
+		return running, nil
+	}
+
+	entries, err := repo.ListEntries(map[string]interface{}{"open": false})
+	if err != nil {
+		return nil, fmt.Errorf("ResumeTimer: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("ResumeTimer: %w", ErrNothingToResume)
+	}
+	last := entries[0]
+	entry := &Entry{
+		ProjectID: last.ProjectID,
+		Project:   last.Project,
+		Client:    last.Client,
+		Task:      last.Task,
+		Summary:   last.Summary,
+		Billable:  last.Billable,
+		Rate:      last.Rate,
+	}
+	if err := StartTimer(repo, entry, at); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
@@ -0,0 +1,155 @@
+package chronos_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/regiellis/chronos-go/chronos"
+)
+
+func TestTimerLifecycle(t *testing.T) {
+	eachRepository(t, func(t *testing.T, repo chronos.Repository) {
+		block := &chronos.Block{Name: "Sprint", Client: "Acme", Project: "Apollo", StartTime: time.Now().Add(-time.Hour)}
+		if err := chronos.CreateBlock(repo, block); err != nil {
+			t.Fatalf("CreateBlock failed: %v", err)
+		}
+		if err := chronos.SetActiveBlock(repo, block.ID); err != nil {
+			t.Fatalf("SetActiveBlock failed: %v", err)
+		}
+
+		start := time.Now().Add(-45 * time.Minute).Truncate(time.Second)
+		timer := &chronos.Entry{Project: "UI Design", Task: "Forms", Billable: true}
+		if err := chronos.StartTimer(repo, timer, start); err != nil {
+			t.Fatalf("StartTimer failed: %v", err)
+		}
+		if !timer.IsOpen() {
+			t.Fatalf("Expected started timer to be open: %+v", timer)
+		}
+
+		running, err := chronos.RunningTimer(repo)
+		if err != nil || running == nil || running.ID != timer.ID {
+			t.Fatalf("RunningTimer = %+v, %v; want entry %d", running, err, timer.ID)
+		}
+		if elapsed := running.Elapsed(start.Add(10 * time.Minute)); elapsed != 10*time.Minute {
+			t.Errorf("Elapsed = %v, want 10m", elapsed)
+		}
+
+		if err := chronos.StartTimer(repo, &chronos.Entry{Project: "Other"}, time.Now()); !errors.Is(err, chronos.ErrTimerRunning) {
+			t.Errorf("Expected ErrTimerRunning, got %v", err)
+		}
+
+		// An open timer is never offered for invoicing.
+		unbilled, _ := chronos.FindUnbilledEntries(repo)
+		if len(unbilled) != 0 {
+			t.Errorf("Expected running timer to be excluded from unbilled entries, got %d", len(unbilled))
+		}
+
+		stopped, err := chronos.StopTimer(repo, start.Add(45*time.Minute))
+		if err != nil {
+			t.Fatalf("StopTimer failed: %v", err)
+		}
+		if stopped.Duration != 45 || stopped.BlockID != block.ID || stopped.Client != "Acme" || stopped.Project != "UI Design" {
+			t.Errorf("Unexpected stopped entry: %+v", stopped)
+		}
+		if running, _ := chronos.RunningTimer(repo); running != nil {
+			t.Errorf("Expected no running timer after stop, got %+v", running)
+		}
+		if _, err := chronos.StopTimer(repo, time.Now()); !errors.Is(err, chronos.ErrNoTimer) {
+			t.Errorf("Expected ErrNoTimer, got %v", err)
+		}
+
+		resumed, err := chronos.ResumeTimer(repo, time.Now())
+		if err != nil {
+			t.Fatalf("ResumeTimer failed: %v", err)
+		}
+		if resumed.ID == stopped.ID || resumed.Project != "UI Design" || resumed.Task != "Forms" || !resumed.IsOpen() {
+			t.Errorf("Unexpected resumed timer: %+v", resumed)
+		}
+	})
+}
+
+func TestResumeTimer_NothingToResume(t *testing.T) {
+	eachRepository(t, func(t *testing.T, repo chronos.Repository) {
+		if _, err := chronos.ResumeTimer(repo, time.Now()); !errors.Is(err, chronos.ErrNothingToResume) {
+			t.Errorf("Expected ErrNothingToResume, got %v", err)
+		}
+	})
+}
+
+func TestTimerPauseResume(t *testing.T) {
+	eachRepository(t, func(t *testing.T, repo chronos.Repository) {
+		start := time.Now().Add(-3 * time.Hour).Truncate(time.Second)
+		if _, err := chronos.PauseTimer(repo, start); !errors.Is(err, chronos.ErrNoTimer) {
+			t.Errorf("Expected ErrNoTimer, got %v", err)
+		}
+
+		timer := &chronos.Entry{Project: "Apollo", Billable: true, Rate: 60}
+		if err := chronos.StartTimer(repo, timer, start); err != nil {
+			t.Fatalf("StartTimer failed: %v", err)
+		}
+
+		// Lunch from +1h to +1h45m.
+		paused, err := chronos.PauseTimer(repo, start.Add(time.Hour))
+		if err != nil {
+			t.Fatalf("PauseTimer failed: %v", err)
+		}
+		if !paused.IsPaused() {
+			t.Errorf("Expected entry to be paused")
+		}
+		if _, err := chronos.PauseTimer(repo, start.Add(70*time.Minute)); !errors.Is(err, chronos.ErrTimerPaused) {
+			t.Errorf("Expected ErrTimerPaused, got %v", err)
+		}
+		running, _ := chronos.RunningTimer(repo)
+		if running == nil || !running.IsPaused() {
+			t.Fatalf("Expected the pause to be persisted, got %+v", running)
+		}
+
+		resumed, err := chronos.ResumeTimer(repo, start.Add(105*time.Minute))
+		if err != nil {
+			t.Fatalf("ResumeTimer failed: %v", err)
+		}
+		if resumed.ID != timer.ID || resumed.IsPaused() {
+			t.Errorf("Expected the same entry to be resumed, got %+v", resumed)
+		}
+		if _, err := chronos.ResumeTimer(repo, start.Add(110*time.Minute)); !errors.Is(err, chronos.ErrTimerRunning) {
+			t.Errorf("Expected ErrTimerRunning when not paused, got %v", err)
+		}
+
+		// A second break still running when the timer stops ends with it.
+		if _, err := chronos.PauseTimer(repo, start.Add(150*time.Minute)); err != nil {
+			t.Fatalf("second PauseTimer failed: %v", err)
+		}
+		stopped, err := chronos.StopTimer(repo, start.Add(3*time.Hour))
+		if err != nil {
+			t.Fatalf("StopTimer failed: %v", err)
+		}
+		// 3h span - 45m lunch - 30m trailing break = 105m worked.
+		if stopped.Duration != 105 || stopped.Amount() != 105 {
+			t.Errorf("Expected 105 billable minutes ($105), got %d min ($%.2f)", stopped.Duration, stopped.Amount())
+		}
+
+		saved, err := chronos.GetEntryByID(repo, timer.ID)
+		if err != nil {
+			t.Fatalf("GetEntryByID failed: %v", err)
+		}
+		if len(saved.Breaks) != 2 || saved.Breaks[1].EndTime.IsZero() {
+			t.Errorf("Expected two closed breaks, got %+v", saved.Breaks)
+		}
+		// Only the lunch break sits between worked segments; the trailing one ends the entry.
+		if gaps := chronos.DetectIdleGaps([]*chronos.Entry{saved}, 30*time.Minute); len(gaps) != 1 || !gaps[0].Break {
+			t.Errorf("Expected the lunch break as the only idle gap, got %+v", gaps)
+		}
+
+		if err := chronos.DeleteEntry(repo, timer.ID); err != nil {
+			t.Fatalf("DeleteEntry failed: %v", err)
+		}
+		if err := chronos.StartTimer(repo, &chronos.Entry{Project: "Fresh"}, time.Now()); err != nil {
+			t.Fatalf("StartTimer after delete failed: %v", err)
+		}
+		fresh, _ := chronos.RunningTimer(repo)
+		if fresh == nil || len(fresh.Breaks) != 0 {
+			t.Errorf("Expected a new timer without breaks, got %+v", fresh)
+		}
+	})
+}
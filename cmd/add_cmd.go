@@ -1,15 +1,16 @@
 package cmd
 
 import (
-	"database/sql"
+	"context"
 	"fmt"
-	"strconv"
+	"os"
 	"strings"
 	"time"
 
+	"github.com/mattn/go-isatty"
 	"github.com/regiellis/chronos-go/chronos" // Imported chronos
-	"github.com/regiellis/chronos-go/db"
 	"github.com/regiellis/chronos-go/llm"
+	"github.com/regiellis/chronos-go/ui"
 	"github.com/regiellis/chronos-go/utils"
 	"github.com/spf13/cobra"
 )
@@ -20,6 +21,8 @@ var (
 	addScaleLeft  int
 	addSuggest    bool
 	addLLM        bool
+	addDryRun     bool
+	addYes        bool
 )
 
 // addCmd represents the add command
@@ -28,16 +31,19 @@ var addCmd = &cobra.Command{
 	Short: "Add a time entry (optionally with --scale and --llm)",
 	Args:  cobra.MinimumNArgs(0),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		dbPath := "chronos.db"
-		dbStore, err := db.NewStore(dbPath)
+		dbStore, err := openStore()
 		if err != nil {
-			return fmt.Errorf("failed to create db store: %w", err)
-		}
-		if err := dbStore.InitSchema(); err != nil {
-			return fmt.Errorf("failed to initialize schema: %w", err)
+			return err
 		}
 
-		llmClient := llm.NewOllamaClient() // Default model, consider making configurable
+		// The LLM is optional here; only build a client when a flag asks for it.
+		useLLM, _ := cmd.Flags().GetBool("llm")
+		var llmClient *llm.Client
+		if useLLM {
+			if llmClient, err = newLLMClient(dbStore); err != nil {
+				return err
+			}
+		}
 
 		if addSuggest || len(args) == 0 {
 			entries, listErr := chronos.ListEntries(dbStore, nil)
@@ -50,10 +56,12 @@ var addCmd = &cobra.Command{
 			}
 
 			if addSuggest && entries != nil && blocks != nil { // Ensure we have data for suggestions
-				suggestion, llmErr := llmClient.SuggestNextEntry(entries, blocks)
-				if llmErr == nil && suggestion != "" {
-					fmt.Println(utils.LLMStyle.Render(suggestion))
-				} else if llmErr != nil {
+				// Saved to the history like 'chronos suggest'.
+				record := chronos.Interaction{Kind: chronos.KindSuggest, Question: "What should I work on next?", ContextHash: chronos.ContextHash(entries, blocks)}
+				llmErr := askLLM(cmd, dbStore, record, "Thinking...", func(ctx context.Context, client *llm.Client) (string, error) {
+					return client.SuggestNextEntry(ctx, entries, blocks)
+				})
+				if llmErr != nil {
 					fmt.Println("LLM Suggestion Error:", llmErr)
 				}
 			}
@@ -63,98 +71,73 @@ var addCmd = &cobra.Command{
 		}
 
 		input := strings.Join(args, " ") // Join all args to form the input string
-		var newEntry chronos.Entry
 
-		useLLM, _ := cmd.Flags().GetBool("llm")
+		// The entry grammar handles the documented syntax. With --llm the model
+		// parses first; if it never returns a valid entry the grammar gets a go.
+		known := knownProjects(dbStore)
+		var parser llm.Parser = llm.NewGrammarParser()
 		if useLLM {
-			// Assuming llmClient.ParseEntry returns a temporary struct that needs mapping
-			// This part is highly dependent on the actual structure returned by llm.ParseEntry
-			parsedLLMEntry, llmErr := llmClient.ParseEntry(input)
-			if llmErr != nil {
-				return fmt.Errorf("LLM parsing failed: %w", llmErr)
-			}
-
-			newEntry.Summary = utils.SanitizeDescription(parsedLLMEntry.Summary) // Or Description
-			newEntry.StartTime = parsedLLMEntry.StartTime
-			if newEntry.StartTime.IsZero() { // Default to now if LLM doesn't provide it
-				newEntry.StartTime = time.Now()
-			}
-			// Duration might be provided directly or as EndTime by LLM
-			if parsedLLMEntry.EndTime.IsZero() && parsedLLMEntry.Duration > 0 {
-				newEntry.EndTime = newEntry.StartTime.Add(time.Duration(parsedLLMEntry.Duration) * time.Minute)
-			} else if !parsedLLMEntry.EndTime.IsZero() {
-				newEntry.EndTime = parsedLLMEntry.EndTime
-			} else {
-				// Default duration if LLM provides neither EndTime nor Duration (e.g., 30 mins)
-				newEntry.EndTime = newEntry.StartTime.Add(30 * time.Minute)
-			}
-			
-			// TODO: Implement ProjectID lookup based on parsedLLMEntry.ProjectName
-			// For now, placeholder. This would ideally involve chronos.GetProjectByName(dbStore, parsedLLMEntry.ProjectName)
-			newEntry.ProjectID = 0 // Placeholder
-			// Client handling from LLM is also TBD.
-		} else {
-			// Manual fallback: parse input as "duration project task description"
-			parts := make([]string, 0)
-			// Use strings.Fields to handle multiple spaces better than strings.SplitN
-			splitArgs := strings.Fields(input) 
-			if len(splitArgs) < 4 {
-				return fmt.Errorf("could not parse entry. Expected format: <duration> <project> <task> <description>. Got: '%s'", input)
-			}
-			
-			var durationMinutes int64
-			durStr := strings.ToLower(splitArgs[0])
-			parsedIntDur, errConv := strconv.ParseInt(durStr, 10, 64)
-			if errConv != nil {
-				parsedGoDur, errDur := time.ParseDuration(durStr)
-				if errDur != nil {
-					return fmt.Errorf("invalid duration '%s': %v (tried int minutes and Go duration string)", durStr, errDur)
-				}
-				durationMinutes = int64(parsedGoDur.Minutes())
+			llmClient.KnownProjects = known
+			parser = llm.FallbackParser{Primary: llmClient, Fallback: parser}
+		}
+		parsedEntry, err := parser.ParseEntry(input)
+		if err != nil {
+			return fmt.Errorf("could not parse entry %q: %w (expected e.g. '2h today on \"Project – Task\" -- description')", input, err)
+		}
+		newEntry := *parsedEntry
+
+		// Handle --scale for duration override
+		if addScale != "" { // Simpler logic: if --scale is set, it overrides parsed duration
+			parsedScaleDur, errScale := time.ParseDuration(addScale)
+			if errScale == nil {
+				newEntry.Duration = int64(parsedScaleDur.Minutes())
+				newEntry.EndTime = time.Time{}
 			} else {
-				durationMinutes = parsedIntDur
+				fmt.Printf("Warning: could not parse --scale duration '%s', using parsed duration: %v\n", addScale, errScale)
 			}
+		}
+		// Note: --scale-next logic is removed for simplicity in this refactoring pass,
+		// as it adds statefulness that complicates direct CreateEntry calls.
+		// It could be reintroduced by managing addScaleLeft at a higher level or within the command loop.
 
-			projectArg := utils.SanitizeString(splitArgs[1])
-			taskArg := utils.SanitizeString(splitArgs[2])
-			descriptionArg := utils.SanitizeDescription(strings.Join(splitArgs[3:], " ")) // Remainder is description
-
-			newEntry.Summary = fmt.Sprintf("%s: %s", taskArg, descriptionArg)
-			newEntry.StartTime = time.Now()
-
-			// Handle --scale for duration override
-			if addScale != "" { // Simpler logic: if --scale is set, it overrides parsed duration
-				parsedScaleDur, errScale := time.ParseDuration(addScale)
-				if errScale == nil {
-					durationMinutes = int64(parsedScaleDur.Minutes())
-				} else {
-					fmt.Printf("Warning: could not parse --scale duration '%s', using parsed duration: %v\n", addScale, errScale)
-				}
-			}
-			// Note: --scale-next logic is removed for simplicity in this refactoring pass,
-			// as it adds statefulness that complicates direct CreateEntry calls.
-			// It could be reintroduced by managing addScaleLeft at a higher level or within the command loop.
+		// An entry without an end or a duration would read as a running timer.
+		if newEntry.Duration <= 0 && newEntry.EndTime.IsZero() {
+			return fmt.Errorf("entry needs a positive duration; use 'chronos start' for a live timer")
+		}
 
-			newEntry.EndTime = newEntry.StartTime.Add(time.Duration(durationMinutes) * time.Minute)
-			
-			// TODO: Implement ProjectID lookup based on projectArg
-			// For now, placeholder: chronos.GetProjectByName(dbStore, projectArg)
-			newEntry.ProjectID = 0 // Placeholder
+		// Entries are logged as just finished unless the parser placed them in time.
+		if newEntry.StartTime.IsZero() {
+			newEntry.StartTime = time.Now().Add(-time.Duration(newEntry.Duration) * time.Minute)
 		}
 
-		newEntry.CreatedAt = time.Now()
-		newEntry.UpdatedAt = time.Now()
 		newEntry.Invoiced = false // Default for new entries
 
 		activeBlock, errBlock := chronos.GetActiveBlock(dbStore)
-		if errBlock != nil && errBlock != sql.ErrNoRows { // sql.ErrNoRows is okay, means no active block
+		if errBlock != nil { // No active block is reported as nil, nil
 			return fmt.Errorf("failed to get active block: %w", errBlock)
 		}
 		if activeBlock != nil {
-			newEntry.BlockID = activeBlock.ID
-			// If ProjectID is still 0, and block has a project, try to use it (needs name to ID lookup)
-			if newEntry.ProjectID == 0 && activeBlock.Project != "" {
-				// TODO: newEntry.ProjectID = chronos.GetProjectByName(dbStore, activeBlock.Project).ID
+			applyBlock(&newEntry, activeBlock, known)
+		}
+
+		if newEntry.Project != "" && len(known) > 0 && !containsFold(known, newEntry.Project) {
+			fmt.Println(utils.WarningStyle.Render(fmt.Sprintf("%q is a new project.", newEntry.Project)))
+		}
+		if addDryRun {
+			fmt.Println(ui.EntryPreview(&newEntry, activeBlock))
+			fmt.Println(utils.InfoStyle.Render("Dry run: entry not saved."))
+			return nil
+		}
+		// Parsed entries, LLM ones especially, are shown for review before saving
+		// unless --yes is given or there is no terminal to ask on.
+		if !addYes && isatty.IsTerminal(os.Stdin.Fd()) && isatty.IsTerminal(os.Stdout.Fd()) {
+			ok, err := ui.ConfirmEntry(&newEntry, activeBlock)
+			if err != nil {
+				return fmt.Errorf("confirm entry: %w", err)
+			}
+			if !ok {
+				fmt.Println(utils.WarningStyle.Render("Entry discarded."))
+				return nil
 			}
 		}
 
@@ -163,10 +146,10 @@ var addCmd = &cobra.Command{
 		}
 
 		fmt.Println(utils.SuccessStyle.Render("Entry added!"))
-		durationOutput := newEntry.EndTime.Sub(newEntry.StartTime).Minutes()
 		fmt.Println(utils.EntryStyle.Render(fmt.Sprintf(
-			"Summary: %s\nProjectID: %d\nBlockID: %d\nDuration: %.0f min\nTime: %s",
-			newEntry.Summary, newEntry.ProjectID, newEntry.BlockID, durationOutput, newEntry.StartTime.Format("2006-01-02 15:04"))))
+			"ID: %d\nProject: %s\nClient: %s\nTask: %s\nSummary: %s\nBlockID: %d\nDuration: %.0f min\nTime: %s",
+			newEntry.ID, newEntry.Project, newEntry.Client, newEntry.Task, newEntry.Summary, newEntry.BlockID,
+			newEntry.Minutes(), newEntry.StartTime.Format("2006-01-02 15:04"))))
 
 		if useLLM { // Check flag again, as it might only be for post-processing
 			// Ensure llmClient is the same instance or re-initialize if needed
@@ -174,7 +157,7 @@ var addCmd = &cobra.Command{
 			if listErr != nil {
 				fmt.Println("Warning: Could not list entries for LLM feedback:", listErr)
 			} else {
-				feedback, llmErr := llmClient.FeedbackAfterEntry(&newEntry, entries)
+				feedback, llmErr := llmClient.FeedbackAfterEntry(cmd.Context(), &newEntry, entries)
 				if llmErr == nil && feedback != "" {
 					fmt.Println(utils.LLMStyle.Render(feedback))
 				} else if llmErr != nil {
@@ -186,11 +169,61 @@ var addCmd = &cobra.Command{
 	},
 }
 
+// knownProjects lists project names from the projects table and past entries.
+func knownProjects(repo chronos.Repository) []string {
+	var names []string
+	add := func(name string) {
+		if name != "" && !containsFold(names, name) {
+			names = append(names, name)
+		}
+	}
+	if projects, err := chronos.ListProjects(repo, nil); err == nil {
+		for _, p := range projects {
+			add(p.Name)
+		}
+	}
+	if entries, err := chronos.ListEntries(repo, nil); err == nil {
+		for _, e := range entries {
+			add(e.Project)
+		}
+	}
+	return names
+}
+
+func containsFold(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyBlock files an entry under the active block. Inside a project-scoped
+// block a bare "on X" names the task, so "30m on Review" logs Review against
+// the block's project rather than creating a project called Review. Known
+// projects are left alone.
+func applyBlock(entry *chronos.Entry, block *chronos.Block, known []string) {
+	entry.BlockID = block.ID
+	if block.Project != "" && entry.Task == "" && entry.Project != "" && !strings.EqualFold(entry.Project, block.Project) && !containsFold(known, entry.Project) {
+		entry.Task = entry.Project
+		entry.Project = ""
+	}
+	if entry.Project == "" {
+		entry.Project = block.Project
+	}
+	if entry.Client == "" {
+		entry.Client = block.Client
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(addCmd)
 	addCmd.Flags().StringVar(&addScale, "scale", "", "Override duration for this entry (e.g. 1h, 30m, 15m)")
 	// --scale-next related flags are kept for now but their logic is simplified/partially removed in RunE
-	addCmd.Flags().IntVar(&addScaleCount, "scale-next", 0, "Apply scale to the next N entries (functionality limited in refactor)") 
+	addCmd.Flags().IntVar(&addScaleCount, "scale-next", 0, "Apply scale to the next N entries (functionality limited in refactor)")
 	addCmd.Flags().BoolVar(&addSuggest, "suggest", false, "Show LLM-powered suggestions before entry")
-	addCmd.Flags().BoolVar(&addLLM, "llm", false, "Use LLM for parsing entry and/or feedback after entry")
+	addCmd.Flags().BoolVar(&addDryRun, "dry-run", false, "Show the parsed entry without saving it")
+	addCmd.Flags().BoolVarP(&addYes, "yes", "y", false, "Save without the confirmation prompt")
+	addCmd.Flags().BoolVar(&addLLM, "llm", false, "Parse the entry with the LLM (falling back to the entry grammar) and show feedback after entry")
 }
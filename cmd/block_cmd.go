@@ -5,7 +5,6 @@ import (
 	"time"
 
 	"github.com/regiellis/chronos-go/chronos" // Imported chronos
-	"github.com/regiellis/chronos-go/db"
 	"github.com/regiellis/chronos-go/utils"
 	"github.com/spf13/cobra"
 )
@@ -20,13 +19,9 @@ var blockStartCmd = &cobra.Command{
 	Short: "Start a new block and mark it active",
 	Args:  cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		dbPath := "chronos.db"
-		dbStore, err := db.NewStore(dbPath)
+		dbStore, err := openStore()
 		if err != nil {
-			return fmt.Errorf("failed to create db store: %w", err)
-		}
-		if err := dbStore.InitSchema(); err != nil {
-			return fmt.Errorf("failed to initialize schema: %w", err)
+			return err
 		}
 
 		durStr, _ := cmd.Flags().GetString("duration")
@@ -46,7 +41,7 @@ var blockStartCmd = &cobra.Command{
 
 		newBlock := &chronos.Block{
 			Name:      utils.SanitizeString(args[0]),
-			Client:    utils.SanitizeString(client), // Consider mapping to ClientID
+			Client:    utils.SanitizeString(client),  // Consider mapping to ClientID
 			Project:   utils.SanitizeString(project), // Consider mapping to ProjectID
 			StartTime: startTime,
 			EndTime:   endTime,
@@ -68,11 +63,10 @@ var blockStartCmd = &cobra.Command{
 			// For now, return the error.
 			return fmt.Errorf("block created (ID: %d) but failed to set as active: %w", newBlock.ID, err)
 		}
-		
+
 		// Update newBlock.Active to true as SetActiveBlock was successful
 		newBlock.Active = true
 
-
 		fmt.Println(utils.SuccessStyle.Render("Started new block and set as active!"))
 		fmt.Println(utils.EntryStyle.Render(
 			fmt.Sprintf("ID: %d\nName: %s\nClient: %s\nProject: %s\nStart: %s\nEnd: %s\nActive: %t",
@@ -90,13 +84,9 @@ var blockStopCmd = &cobra.Command{
 	Use:   "stop",
 	Short: "Stop the currently active block",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		dbPath := "chronos.db"
-		dbStore, err := db.NewStore(dbPath)
+		dbStore, err := openStore()
 		if err != nil {
-			return fmt.Errorf("failed to create db store: %w", err)
-		}
-		if err := dbStore.InitSchema(); err != nil {
-			return fmt.Errorf("failed to initialize schema: %w", err)
+			return err
 		}
 
 		activeBlock, err := chronos.GetActiveBlock(dbStore)
@@ -119,7 +109,6 @@ var blockStopCmd = &cobra.Command{
 	},
 }
 
-
 func init() {
 	blockStartCmd.Flags().String("duration", "2w", "Block duration (e.g. 2w, 10d, 1m)")
 	blockStartCmd.Flags().String("client", "", "Client name (optional)")
@@ -1,4 +1,4 @@
-package main
+package cmd
 
 import (
 	"fmt"
@@ -6,7 +6,7 @@ import (
 	"time"
 
 	"github.com/regiellis/chronos-go/chronos"
-	"github.com/regiellis/chronos-go/db"
+	"github.com/spf13/cobra"
 )
 
 var projects = []string{"Chronos", "Apollo", "Hermes", "Zeus"}
@@ -23,36 +23,41 @@ var descriptions = []string{
 
 func randomEntry() *chronos.Entry {
 	return &chronos.Entry{
-		Project:     projects[rand.Intn(len(projects))],
-		Client:      clients[rand.Intn(len(clients))],
-		Task:        tasks[rand.Intn(len(tasks))],
-		Description: descriptions[rand.Intn(len(descriptions))],
-		Duration:    int64(rand.Intn(120) + 15),                                   // 15-135 min
-		EntryTime:   time.Now().Add(-time.Duration(rand.Intn(30*24)) * time.Hour), // within last 30 days
-		CreatedAt:   time.Now(),
-		Billable:    rand.Intn(2) == 0,
-		Rate:        float64(rand.Intn(100) + 50),
-		Invoiced:    rand.Intn(2) == 0,
+		Project:   projects[rand.Intn(len(projects))],
+		Client:    clients[rand.Intn(len(clients))],
+		Task:      tasks[rand.Intn(len(tasks))],
+		Summary:   descriptions[rand.Intn(len(descriptions))],
+		Duration:  int64(rand.Intn(120) + 15),                                   // 15-135 min
+		StartTime: time.Now().Add(-time.Duration(rand.Intn(30*24)) * time.Hour), // within last 30 days
+		Billable:  rand.Intn(2) == 0,
+		Rate:      float64(rand.Intn(100) + 50),
+		Invoiced:  rand.Intn(2) == 0,
 	}
 }
 
-func main() {
-	rand.Seed(time.Now().UnixNano())
-	dbPath := "chronos.db"
-	dbStore, err := db.NewStore(dbPath)
-	if err != nil {
-		panic(err)
-	}
-	if err := dbStore.InitSchema(); err != nil {
-		panic(err)
-	}
-	count := 50 // Number of entries to insert
-	for i := 0; i < count; i++ {
-		entry := randomEntry()
-		err := dbStore.AddEntry(entry)
+// seedCmd fills the database with random entries for development and demos.
+var seedCmd = &cobra.Command{
+	Use:    "seed",
+	Short:  "Insert random entries for development",
+	Hidden: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dbStore, err := openStore()
 		if err != nil {
-			fmt.Printf("Failed to insert entry %d: %v\n", i, err)
+			return err
 		}
-	}
-	fmt.Printf("Inserted %d random entries.\n", count)
+		count, _ := cmd.Flags().GetInt("count")
+		for i := 0; i < count; i++ {
+			entry := randomEntry()
+			if err := chronos.CreateEntry(dbStore, entry); err != nil {
+				fmt.Printf("Failed to insert entry %d: %v\n", i, err)
+			}
+		}
+		fmt.Printf("Inserted %d random entries.\n", count)
+		return nil
+	},
+}
+
+func init() {
+	seedCmd.Flags().Int("count", 50, "Number of entries to insert")
+	rootCmd.AddCommand(seedCmd)
 }
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/regiellis/chronos-go/config"
+	"github.com/regiellis/chronos-go/llm"
+	"github.com/regiellis/chronos-go/utils"
+	"github.com/spf13/cobra"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the cache of LLM summaries and reviews",
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Delete every cached LLM reply",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		n, err := (&llm.Cache{Dir: config.LLMCacheDir()}).Clear()
+		if err != nil {
+			return fmt.Errorf("clear cache: %w", err)
+		}
+		fmt.Println(utils.SuccessStyle.Render(fmt.Sprintf("Removed %d cached answer(s).", n)))
+		return nil
+	},
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheClearCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
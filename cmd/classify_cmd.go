@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+
+	"github.com/mattn/go-isatty"
+	"github.com/regiellis/chronos-go/chronos"
+	"github.com/regiellis/chronos-go/ui"
+	"github.com/regiellis/chronos-go/utils"
+	"github.com/spf13/cobra"
+)
+
+// classifyCmd proposes a project, client and task for entries missing a
+// project or task. Past entries train a keyword model first; with --llm the
+// model places whatever that leaves over. Proposals are reviewed, then
+// applied in bulk.
+var classifyCmd = &cobra.Command{
+	Use:   "classify",
+	Short: "Propose projects and tasks for entries that have none",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dbStore, err := openStore()
+		if err != nil {
+			return err
+		}
+		entries, err := chronos.ListEntries(dbStore, nil)
+		if err != nil {
+			return fmt.Errorf("failed to list entries: %w", err)
+		}
+		byID := map[int64]*chronos.Entry{}
+		var unclassified []*chronos.Entry
+		for _, e := range entries {
+			if chronos.IsUnclassified(e) {
+				unclassified = append(unclassified, e)
+				byID[e.ID] = e
+			}
+		}
+		if len(unclassified) == 0 {
+			fmt.Println(utils.SuccessStyle.Render("Every entry has a project and task."))
+			return nil
+		}
+
+		minConfidence, _ := cmd.Flags().GetFloat64("min-confidence")
+		classifier := chronos.NewClassifier(entries)
+		var proposals []chronos.Classification
+		var remaining []*chronos.Entry
+		for _, e := range unclassified {
+			if p, ok := classifier.Classify(e); ok && p.Confidence >= minConfidence {
+				proposals = append(proposals, p)
+			} else {
+				remaining = append(remaining, e)
+			}
+		}
+
+		if useLLM, _ := cmd.Flags().GetBool("llm"); useLLM && len(remaining) > 0 {
+			llmClient, err := newLLMClient(dbStore)
+			if err != nil {
+				return err
+			}
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer stop()
+			fmt.Println(utils.InactiveStyle.Render(fmt.Sprintf("Asking the LLM about %d entries...", len(remaining))))
+			classified, err := llmClient.ClassifyEntries(ctx, remaining, classifier.Categories())
+			if errors.Is(err, context.Canceled) {
+				fmt.Println(utils.WarningStyle.Render("Cancelled."))
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			proposals = append(proposals, classified...)
+		}
+
+		if len(proposals) == 0 {
+			fmt.Println(utils.InactiveStyle.Render(fmt.Sprintf("No proposals for %d unclassified entries. Try --llm or a lower --min-confidence.", len(unclassified))))
+			return nil
+		}
+		sort.SliceStable(proposals, func(i, j int) bool { return proposals[i].EntryID < proposals[j].EntryID })
+
+		if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+			fmt.Println(ui.ClassificationsPreview(proposals, byID))
+			fmt.Println(utils.InfoStyle.Render("Dry run: no entries changed."))
+			return nil
+		}
+		accepted := proposals
+		if yes, _ := cmd.Flags().GetBool("yes"); !yes && isatty.IsTerminal(os.Stdin.Fd()) && isatty.IsTerminal(os.Stdout.Fd()) {
+			if accepted, err = ui.ReviewClassifications(proposals, byID); err != nil {
+				return fmt.Errorf("review classifications: %w", err)
+			}
+		} else {
+			fmt.Println(ui.ClassificationsPreview(proposals, byID))
+		}
+		if len(accepted) == 0 {
+			fmt.Println(utils.WarningStyle.Render("No entries changed."))
+			return nil
+		}
+
+		n, err := chronos.ApplyClassifications(dbStore, accepted)
+		if err != nil {
+			return fmt.Errorf("classified %d entries before failing: %w", n, err)
+		}
+		fmt.Println(utils.SuccessStyle.Render(fmt.Sprintf("Classified %d of %d unclassified entries.", n, len(unclassified))))
+		return nil
+	},
+}
+
+func init() {
+	classifyCmd.Flags().Bool("llm", false, "Ask the LLM about entries the history model cannot place")
+	classifyCmd.Flags().Float64("min-confidence", 0.3, "Drop history proposals below this confidence (0-1)")
+	classifyCmd.Flags().Bool("dry-run", false, "Show the proposals without changing any entry")
+	classifyCmd.Flags().BoolP("yes", "y", false, "Apply every proposal without reviewing them")
+	rootCmd.AddCommand(classifyCmd)
+}
@@ -1,7 +1,11 @@
 package cmd_test
 
 import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -12,18 +16,44 @@ func runChronos(args ...string) (string, error) {
 	return string(out), err
 }
 
-func TestBlockStartAndView(t *testing.T) {
+// useTempDB points every invocation in the test at a database of its own,
+// never the user's real data.
+func useTempDB(t *testing.T) {
+	t.Helper()
+	t.Setenv("CHRONOS_DB", filepath.Join(t.TempDir(), "chronos.db"))
+}
+
+// startTestBlock gives the test its own database with an active block for
+// TestClient's TestProject, so "on X" files entries under task X.
+func startTestBlock(t *testing.T) {
+	t.Helper()
+	useTempDB(t)
 	out, err := runChronos("block", "start", "Test Block", "--duration", "1h", "--client", "TestClient", "--project", "TestProject")
 	if err != nil || !strings.Contains(out, "Started new block") {
 		t.Fatalf("block start failed: %v\n%s", err, out)
 	}
-	out, err = runChronos("view", "block")
+}
+
+// addEntries adds each text with 'chronos add --yes'.
+func addEntries(t *testing.T, texts ...string) {
+	t.Helper()
+	for _, text := range texts {
+		if out, err := runChronos("add", "--yes", text); err != nil {
+			t.Fatalf("add %q failed: %v\n%s", text, err, out)
+		}
+	}
+}
+
+func TestBlockStartAndView(t *testing.T) {
+	startTestBlock(t)
+	out, err := runChronos("view", "block")
 	if err != nil || !strings.Contains(out, "Active block") {
 		t.Fatalf("view block failed: %v\n%s", err, out)
 	}
 }
 
 func TestAddEntryAndList(t *testing.T) {
+	startTestBlock(t)
 	out, err := runChronos("add", "30m today on Test Task -- test entry")
 	if err != nil {
 		t.Fatalf("add entry failed: %v\n%s", err, out)
@@ -34,14 +64,184 @@ func TestAddEntryAndList(t *testing.T) {
 	}
 }
 
+func TestAddDryRun(t *testing.T) {
+	startTestBlock(t)
+	out, err := runChronos("add", "45m today on Dry Run Task -- not saved", "--dry-run")
+	if err != nil || !strings.Contains(out, "Dry Run Task") || !strings.Contains(out, "not saved") {
+		t.Fatalf("add --dry-run failed: %v\n%s", err, out)
+	}
+	out, err = runChronos("view", "list", "--task", "Dry Run Task")
+	if err != nil || strings.Contains(out, "Dry Run Task") {
+		t.Fatalf("dry run entry was saved: %v\n%s", err, out)
+	}
+}
+
 func TestInvoiceExport(t *testing.T) {
+	startTestBlock(t)
+	addEntries(t, "1h today on Exports $50 -- Invoice data")
 	out, err := runChronos("export", "invoice", "--format", "json")
 	if err != nil || !strings.Contains(out, "total_amount") {
 		t.Fatalf("export invoice failed: %v\n%s", err, out)
 	}
 }
 
+func TestPolishedInvoiceExport(t *testing.T) {
+	startTestBlock(t)
+	llmServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"response":"\"Delivered the reporting dashboard.\"","done":true}`)
+	}))
+	defer llmServer.Close()
+	t.Setenv("LLM_PROVIDER", "ollama")
+	t.Setenv("LLM_HOST", llmServer.URL)
+	// Keep go run's build cache where it is while the LLM cache moves.
+	if gocache, err := exec.Command("go", "env", "GOCACHE").Output(); err == nil {
+		t.Setenv("GOCACHE", strings.TrimSpace(string(gocache)))
+	}
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	addEntries(t, "45m today on Dashboards $80 -- Fixed chart bug")
+	out, err := runChronos("export", "invoice", "--polish", "--format", "markdown")
+	if err != nil || !strings.Contains(out, "| TestProject | Dashboards | Delivered the reporting dashboard. | 0.75 | 0.75 | 60.00 |") {
+		t.Fatalf("export invoice --polish failed: %v\n%s", err, out)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	startTestBlock(t)
+	addEntries(t, "30m today on Charts -- Axis labels", "15m today -- axis labels tweak")
+	out, err := runChronos("classify", "--yes")
+	if err != nil || !strings.Contains(out, "Classified") {
+		t.Fatalf("classify failed: %v\n%s", err, out)
+	}
+	out, err = runChronos("view", "list", "--task", "Charts")
+	if err != nil || !strings.Contains(out, "axis labels tweak") {
+		t.Fatalf("entry not filed under Charts: %v\n%s", err, out)
+	}
+}
+
+func TestInvoiceLifecycle(t *testing.T) {
+	startTestBlock(t)
+	addEntries(t, "2h today on Portal @Initech $90 -- Login page", "30m today on Portal $90 -- Style guide")
+	// The entries are for two clients, so one invoice for everything is refused.
+	out, err := runChronos("invoice", "create")
+	if err == nil || !strings.Contains(out, "invoice one client at a time with --client") {
+		t.Fatalf("invoice create should refuse entries for several clients: %v\n%s", err, out)
+	}
+	out, err = runChronos("invoice", "create", "--client", "Initech")
+	if err != nil || !strings.Contains(out, "Draft invoice #") {
+		t.Fatalf("invoice create failed: %v\n%s", err, out)
+	}
+	out, err = runChronos("invoice", "list", "--client", "Initech", "--status", "draft")
+	fields := strings.Fields(out)
+	if err != nil || len(fields) == 0 {
+		t.Fatalf("invoice list failed: %v\n%s", err, out)
+	}
+	id := fields[0]
+	out, err = runChronos("invoice", "issue", id)
+	if err != nil || !strings.Contains(out, "Issued invoice INV-") {
+		t.Fatalf("invoice issue failed: %v\n%s", err, out)
+	}
+	out, err = runChronos("invoice", "show", id, "--format", "markdown")
+	if err != nil || !strings.Contains(out, "| TestProject | Portal | Login page | 2.00 | 180.00 |") {
+		t.Fatalf("invoice show failed: %v\n%s", err, out)
+	}
+	out, err = runChronos("invoice", "void", id)
+	if err != nil || !strings.Contains(out, "1 entries are unbilled again") {
+		t.Fatalf("invoice void failed: %v\n%s", err, out)
+	}
+}
+
+func TestClientRate(t *testing.T) {
+	startTestBlock(t)
+	out, err := runChronos("rate", "set", "75", "--client", "Globex", "--from", "2020-01-01")
+	if err != nil || !strings.Contains(out, "Rate 75.00/h set for client Globex") {
+		t.Fatalf("rate set failed: %v\n%s", err, out)
+	}
+	addEntries(t, "1h today on Reports @Globex -- Quarterly numbers")
+	out, err = runChronos("view", "invoice-md", "--client", "Globex")
+	if err != nil || !strings.Contains(out, "| Quarterly numbers | 1.00 | 1.00 | 75.00 | 75.00 |") {
+		t.Fatalf("client rate not applied: %v\n%s", err, out)
+	}
+	out, err = runChronos("rate", "list")
+	if err != nil || !strings.Contains(out, "client Globex") {
+		t.Fatalf("rate list failed: %v\n%s", err, out)
+	}
+}
+
+func TestClientRounding(t *testing.T) {
+	startTestBlock(t)
+	out, err := runChronos("rounding", "set", "--client", "Hooli", "--increment", "15", "--minimum", "30")
+	if err != nil || !strings.Contains(out, "client Hooli now bills 15m up per entry, minimum 30m") {
+		t.Fatalf("rounding set failed: %v\n%s", err, out)
+	}
+	addEntries(t, "50m today on Support @Hooli $60 -- Ticket triage", "10m today on Support @Hooli $60 -- Password reset")
+	out, err = runChronos("view", "invoice-md", "--client", "Hooli")
+	if err != nil || !strings.Contains(out, "| Ticket triage | 0.83 | 1.00 | 60.00 | 60.00 |") ||
+		!strings.Contains(out, "| Password reset | 0.17 | 0.50 | 60.00 | 30.00 |") || !strings.Contains(out, "**Tracked Hours:** 1.00") {
+		t.Fatalf("rounding not applied: %v\n%s", err, out)
+	}
+}
+
+func TestInvoiceTermsAndItems(t *testing.T) {
+	startTestBlock(t)
+	out, err := runChronos("invoice", "terms", "Umbrella", "--tax", "VAT=20", "--discount", "10%")
+	if err != nil || !strings.Contains(out, "New invoices for Umbrella get Discount 10%, VAT 20%") {
+		t.Fatalf("invoice terms failed: %v\n%s", err, out)
+	}
+	addEntries(t, "1h today on Vaccines @Umbrella $100 -- Lab work")
+	out, err = runChronos("export", "invoice", "--client", "Umbrella", "--format", "markdown")
+	for _, want := range []string{"**Subtotal:** $100.00", "**Discount (10%):** -$10.00", "**VAT (20%):** $18.00", "**Total Amount:** $108.00"} {
+		if err != nil || !strings.Contains(out, want) {
+			t.Fatalf("export invoice missing %q: %v\n%s", want, err, out)
+		}
+	}
+	if out, err := runChronos("invoice", "create", "--client", "Umbrella"); err != nil {
+		t.Fatalf("invoice create failed: %v\n%s", err, out)
+	}
+	out, err = runChronos("invoice", "list", "--client", "Umbrella")
+	fields := strings.Fields(out)
+	if err != nil || len(fields) == 0 {
+		t.Fatalf("invoice list failed: %v\n%s", err, out)
+	}
+	out, err = runChronos("invoice", "add-item", fields[0], "Lab hosting", "30", "--expense")
+	if err != nil || !strings.Contains(out, "total now $144.00") {
+		t.Fatalf("invoice add-item failed: %v\n%s", err, out)
+	}
+	out, err = runChronos("invoice", "show", fields[0], "--format", "markdown")
+	if err != nil || !strings.Contains(out, "| Expense |  | Lab hosting |  | 30.00 |") || !strings.Contains(out, "**Total Amount:** $144.00") {
+		t.Fatalf("invoice show failed: %v\n%s", err, out)
+	}
+}
+
+func TestClientCurrency(t *testing.T) {
+	startTestBlock(t)
+	out, err := runChronos("currency", "set", "eur", "--client", "Stark")
+	if err != nil || !strings.Contains(out, "client Stark now bills in EUR") {
+		t.Fatalf("currency set failed: %v\n%s", err, out)
+	}
+	if out, err := runChronos("currency", "rate", "EUR", "USD", "1.5", "--date", "2020-01-01"); err != nil {
+		t.Fatalf("currency rate failed: %v\n%s", err, out)
+	}
+	addEntries(t, "2h today on Armor @Stark $600 -- Suit fitting")
+	out, err = runChronos("export", "invoice", "--client", "Stark", "--format", "markdown")
+	if err != nil || !strings.Contains(out, "**Total Amount:** €1,200.00") {
+		t.Fatalf("export invoice not in EUR: %v\n%s", err, out)
+	}
+	out, err = runChronos("analytics")
+	if err != nil || !strings.Contains(out, "EUR: €1,200.00") || !strings.Contains(out, "Total in USD:") {
+		t.Fatalf("analytics revenue failed: %v\n%s", err, out)
+	}
+	if out, err := runChronos("invoice", "create", "--client", "Stark"); err != nil {
+		t.Fatalf("invoice create failed: %v\n%s", err, out)
+	}
+	out, err = runChronos("invoice", "list", "--client", "Stark")
+	if err != nil || !strings.Contains(out, "€1,200.00") {
+		t.Fatalf("invoice list failed: %v\n%s", err, out)
+	}
+}
+
 func TestTemplateSaveAndUse(t *testing.T) {
+	useTempDB(t)
 	out, err := runChronos("template", "standup", "15m today on Standup -- Daily standup")
 	if err != nil || !strings.Contains(out, "Template saved") {
 		t.Fatalf("template save failed: %v\n%s", err, out)
@@ -52,9 +252,43 @@ func TestTemplateSaveAndUse(t *testing.T) {
 	}
 }
 
+func TestTimerStartStatusStop(t *testing.T) {
+	useTempDB(t)
+	out, err := runChronos("start", "UI Design", "--task", "Forms")
+	if err != nil || !strings.Contains(out, "Timer started") {
+		t.Fatalf("start failed: %v\n%s", err, out)
+	}
+	out, err = runChronos("status")
+	if err != nil || !strings.Contains(out, "Timer running") || !strings.Contains(out, "Forms") {
+		t.Fatalf("status failed: %v\n%s", err, out)
+	}
+	out, err = runChronos("pause")
+	if err != nil || !strings.Contains(out, "Timer paused") {
+		t.Fatalf("pause failed: %v\n%s", err, out)
+	}
+	out, err = runChronos("status")
+	if err != nil || !strings.Contains(out, "Timer paused since") {
+		t.Fatalf("status while paused failed: %v\n%s", err, out)
+	}
+	out, err = runChronos("resume")
+	if err != nil || !strings.Contains(out, "Timer resumed") || !strings.Contains(out, "Break:") {
+		t.Fatalf("resume failed: %v\n%s", err, out)
+	}
+	out, err = runChronos("stop")
+	if err != nil || !strings.Contains(out, "Timer stopped") {
+		t.Fatalf("stop failed: %v\n%s", err, out)
+	}
+	out, err = runChronos("status")
+	if err != nil || !strings.Contains(out, "No timer is running") {
+		t.Fatalf("status after stop failed: %v\n%s", err, out)
+	}
+}
+
 func TestSmartInvoice(t *testing.T) {
+	startTestBlock(t)
+	addEntries(t, "1h today on Backups @Initech $90 -- Nightly job", "30m today on Backups $90 -- Restore drill")
 	out, err := runChronos("invoice-smart")
-	if err != nil || !strings.Contains(out, "Marked") {
+	if err != nil || !strings.Contains(out, "Marked") || !strings.Contains(out, "Draft invoice #") {
 		t.Fatalf("invoice-smart failed: %v\n%s", err, out)
 	}
 }
@@ -0,0 +1,239 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/regiellis/chronos-go/chronos"
+	"github.com/regiellis/chronos-go/config"
+	"github.com/regiellis/chronos-go/utils"
+	"github.com/spf13/cobra"
+)
+
+// currencyCmd manages what currency clients and projects bill in and the
+// exchange rates reports use to convert totals into the home currency set in
+// chronos.json.
+var currencyCmd = &cobra.Command{
+	Use:   "currency",
+	Short: "Set billing currencies and exchange rates",
+}
+
+// homeCurrency returns the home currency from chronos.json.
+func homeCurrency() (string, error) {
+	cfgPath, err := userConfigPath()
+	if err != nil {
+		return "", err
+	}
+	cfg, _ := config.LoadConfig(cfgPath)
+	return chronos.NormalizeCurrency(cfg.Currency), nil
+}
+
+// newCurrencies loads the stored currencies and exchange rates with the home
+// currency from chronos.json.
+func newCurrencies(repo chronos.Repository) (*chronos.Currencies, error) {
+	home, err := homeCurrency()
+	if err != nil {
+		return nil, err
+	}
+	return chronos.NewCurrencies(repo, home)
+}
+
+// invoiceCurrency returns the currency entries, which must have Currency set,
+// are billed in, or the home currency when there are none. Amounts in
+// different currencies cannot share an invoice.
+func invoiceCurrency(entries []*chronos.Entry) (string, error) {
+	codes := map[string]bool{}
+	for _, e := range entries {
+		codes[e.Currency] = true
+	}
+	if len(codes) > 1 {
+		list := make([]string, 0, len(codes))
+		for code := range codes {
+			list = append(list, code)
+		}
+		sort.Strings(list)
+		return "", fmt.Errorf("entries bill in %s; invoice one currency at a time with --client or --block", strings.Join(list, " and "))
+	}
+	for code := range codes {
+		return code, nil
+	}
+	return homeCurrency()
+}
+
+var currencySetCmd = &cobra.Command{
+	Use:   "set [code]",
+	Short: "Set the currency of a project or client, or the home currency",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		code := strings.ToUpper(strings.TrimSpace(args[0]))
+		project, _ := cmd.Flags().GetString("project")
+		client, _ := cmd.Flags().GetString("client")
+		var scope, target string
+		switch {
+		case project != "" && client != "":
+			return fmt.Errorf("use only one of --project and --client")
+		case project != "":
+			scope, target = chronos.RateProject, utils.SanitizeString(project)
+		case client != "":
+			scope, target = chronos.RateClient, utils.SanitizeString(client)
+		}
+
+		if scope == "" {
+			if !chronos.ValidCurrency(code) {
+				return fmt.Errorf("invalid currency code %q (want three letters, e.g. EUR)", code)
+			}
+			cfgPath, err := userConfigPath()
+			if err != nil {
+				return err
+			}
+			cfg, _ := config.LoadConfig(cfgPath)
+			cfg.Currency = code
+			if err := config.SaveConfig(cfgPath, cfg); err != nil {
+				return err
+			}
+			fmt.Println(utils.SuccessStyle.Render(fmt.Sprintf("Home currency set to %s.", code)))
+			return nil
+		}
+
+		dbStore, err := openStore()
+		if err != nil {
+			return err
+		}
+		if err := chronos.SetCurrency(dbStore, scope, target, code); err != nil {
+			return err
+		}
+		fmt.Println(utils.SuccessStyle.Render(fmt.Sprintf("%s %s now bills in %s.", scope, target, code)))
+		return nil
+	},
+}
+
+var currencyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the home currency and project and client currencies",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dbStore, err := openStore()
+		if err != nil {
+			return err
+		}
+		currencies, err := newCurrencies(dbStore)
+		if err != nil {
+			return err
+		}
+		fmt.Println(utils.LabelStyle.Render("Home (chronos.json):"), utils.ValueStyle.Render(currencies.Home))
+		settings, err := chronos.ListCurrencySettings(dbStore)
+		if err != nil {
+			return err
+		}
+		if len(settings) == 0 {
+			fmt.Println(utils.InactiveStyle.Render("Everything bills in the home currency. Change a client's with 'chronos currency set EUR --client <name>'."))
+			return nil
+		}
+		for _, s := range settings {
+			fmt.Printf("%s %s\n",
+				utils.LabelStyle.Render(fmt.Sprintf("%-28s", truncate(s.Scope+" "+s.Target, 28))),
+				utils.ValueStyle.Render(s.Code))
+		}
+		return nil
+	},
+}
+
+var currencyRateCmd = &cobra.Command{
+	Use:   "rate [from] [to] [rate]",
+	Short: "Record what one unit of a currency is worth in another from a date on",
+	Args:  cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rate, err := strconv.ParseFloat(args[2], 64)
+		if err != nil {
+			return fmt.Errorf("invalid exchange rate %q: %w", args[2], err)
+		}
+		r := &chronos.ExchangeRate{From: args[0], To: args[1], Rate: rate}
+		date, _ := cmd.Flags().GetString("date")
+		if date == "" {
+			now := time.Now()
+			r.Date = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+		} else if r.Date, err = time.ParseInLocation("2006-01-02", date, time.Local); err != nil {
+			return fmt.Errorf("invalid --date %q (want YYYY-MM-DD): %w", date, err)
+		}
+
+		dbStore, err := openStore()
+		if err != nil {
+			return err
+		}
+		if err := chronos.CreateExchangeRate(dbStore, r); err != nil {
+			return err
+		}
+		fmt.Println(utils.SuccessStyle.Render(fmt.Sprintf("1 %s = %s %s from %s.", r.From, strconv.FormatFloat(r.Rate, 'f', -1, 64), r.To, r.Date.Format("2006-01-02"))))
+		return nil
+	},
+}
+
+var currencyImportCmd = &cobra.Command{
+	Use:   "import [file.csv]",
+	Short: "Import exchange rates from a CSV of date,from,to,rate rows",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		rates, err := chronos.ParseExchangeRatesCSV(f)
+		if err != nil {
+			return fmt.Errorf("%s: %w", args[0], err)
+		}
+		dbStore, err := openStore()
+		if err != nil {
+			return err
+		}
+		for _, r := range rates {
+			if err := chronos.CreateExchangeRate(dbStore, r); err != nil {
+				return fmt.Errorf("%s: %w", r.Date.Format("2006-01-02"), err)
+			}
+		}
+		fmt.Println(utils.SuccessStyle.Render(fmt.Sprintf("Imported %d exchange rates.", len(rates))))
+		return nil
+	},
+}
+
+var currencyRatesCmd = &cobra.Command{
+	Use:   "rates",
+	Short: "List exchange rates, oldest first",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dbStore, err := openStore()
+		if err != nil {
+			return err
+		}
+		rates, err := chronos.ListExchangeRates(dbStore)
+		if err != nil {
+			return err
+		}
+		if len(rates) == 0 {
+			fmt.Println(utils.InactiveStyle.Render("No exchange rates. Add one with 'chronos currency rate' or 'chronos currency import'."))
+			return nil
+		}
+		for _, r := range rates {
+			fmt.Printf("%s %s %s\n",
+				utils.LabelStyle.Render(fmt.Sprintf("%4d  %s/%s", r.ID, r.From, r.To)),
+				utils.ValueStyle.Render(fmt.Sprintf("%12s", strconv.FormatFloat(r.Rate, 'f', -1, 64))),
+				utils.InactiveStyle.Render("from "+r.Date.Format("2006-01-02")))
+		}
+		return nil
+	},
+}
+
+func init() {
+	currencySetCmd.Flags().String("project", "", "Project that bills in the currency")
+	currencySetCmd.Flags().String("client", "", "Client that bills in the currency")
+	currencyRateCmd.Flags().String("date", "", "First day the rate applies (YYYY-MM-DD, default today)")
+
+	currencyCmd.AddCommand(currencySetCmd)
+	currencyCmd.AddCommand(currencyListCmd)
+	currencyCmd.AddCommand(currencyRateCmd)
+	currencyCmd.AddCommand(currencyImportCmd)
+	currencyCmd.AddCommand(currencyRatesCmd)
+	rootCmd.AddCommand(currencyCmd)
+}
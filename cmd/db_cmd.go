@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/regiellis/chronos-go/db"
+	"github.com/regiellis/chronos-go/utils"
+	"github.com/spf13/cobra"
+)
+
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Inspect and upgrade the Chronos database schema",
+}
+
+var dbMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Apply pending schema migrations",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dbPath, err := resolveDBPath()
+		if err != nil {
+			return err
+		}
+		dbStore, err := db.NewStore(dbPath)
+		if err != nil {
+			return fmt.Errorf("db store: %w", err)
+		}
+		applied, err := dbStore.Migrate()
+		for _, m := range applied {
+			fmt.Println(utils.SuccessStyle.Render(fmt.Sprintf("Applied %04d_%s", m.Version, m.Name)))
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Println(utils.InfoStyle.Render("Database is already up to date."))
+		}
+		return nil
+	},
+}
+
+var dbStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the schema version and pending migrations",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dbPath, err := resolveDBPath()
+		if err != nil {
+			return err
+		}
+		dbStore, err := db.NewStore(dbPath)
+		if err != nil {
+			return fmt.Errorf("db store: %w", err)
+		}
+		states, err := dbStore.MigrationStatus()
+		if err != nil {
+			return err
+		}
+		version, err := dbStore.SchemaVersion()
+		if err != nil {
+			return err
+		}
+
+		pending := 0
+		fmt.Println(utils.TitleStyle.Render(fmt.Sprintf("Schema version %d", version)))
+		for _, st := range states {
+			if st.Applied {
+				fmt.Println(utils.ActiveStyle.Render(fmt.Sprintf("  [x] %04d_%s (applied %s)", st.Version, st.Name, st.AppliedAt.Format("2006-01-02 15:04"))))
+			} else {
+				pending++
+				fmt.Println(utils.InactiveStyle.Render(fmt.Sprintf("  [ ] %04d_%s", st.Version, st.Name)))
+			}
+		}
+		if pending > 0 {
+			fmt.Println(utils.WarningStyle.Render(fmt.Sprintf("%d pending migration(s). Run 'chronos db migrate' to apply.", pending)))
+		}
+		return nil
+	},
+}
+
+func init() {
+	dbCmd.AddCommand(dbMigrateCmd)
+	dbCmd.AddCommand(dbStatusCmd)
+	rootCmd.AddCommand(dbCmd)
+}
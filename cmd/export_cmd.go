@@ -1,11 +1,18 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"os/signal"
+	"strings"
 
+	log "github.com/charmbracelet/log"
+	"github.com/mattn/go-isatty"
 	"github.com/regiellis/chronos-go/chronos"
-	"github.com/regiellis/chronos-go/db"
+	"github.com/regiellis/chronos-go/ui"
 	"github.com/regiellis/chronos-go/utils"
 	"github.com/spf13/cobra"
 )
@@ -19,16 +26,15 @@ var exportSummaryCmd = &cobra.Command{
 	Use:   "summary",
 	Short: "Export summary as JSON",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		dbPath := "chronos.db"
-		dbStore, err := db.NewStore(dbPath)
+		dbStore, err := openStore()
 		if err != nil {
 			return err
 		}
-		if err := dbStore.InitSchema(); err != nil {
+		entries, err := chronos.ListEntries(dbStore, nil)
+		if err != nil {
 			return err
 		}
-		entries, err := dbStore.ListEntries(nil)
-		if err != nil {
+		if _, err := billHistory(dbStore, entries); err != nil {
 			return err
 		}
 		data, err := json.MarshalIndent(entries, "", "  ")
@@ -43,9 +49,47 @@ var exportSummaryCmd = &cobra.Command{
 
 var exportSuggestCmd = &cobra.Command{
 	Use:   "suggestion",
-	Short: "Export the latest LLM suggestion or answer as JSON or Markdown",
+	Short: "Export a saved LLM suggestion or answer as JSON or Markdown",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		fmt.Println(utils.SuccessStyle.Render("Exporting last suggestion/answer as JSON/Markdown (feature stub)"))
+		dbStore, err := openStore()
+		if err != nil {
+			return err
+		}
+		id, _ := cmd.Flags().GetInt64("id")
+		format, _ := cmd.Flags().GetString("format")
+		var interaction *chronos.Interaction
+		if id > 0 {
+			if interaction, err = chronos.GetInteraction(dbStore, id); err != nil {
+				return err
+			}
+		} else {
+			latest, err := chronos.ListInteractions(dbStore, 1)
+			if err != nil {
+				return err
+			}
+			if len(latest) == 0 {
+				return fmt.Errorf("no saved answers yet; try 'chronos ask'")
+			}
+			interaction = latest[0]
+		}
+		answer := strings.TrimSpace(interaction.Answer)
+		if answer == "" {
+			answer = "_No answer saved._"
+		}
+		switch format {
+		case "markdown":
+			fmt.Printf("# %s\n\n%s\n\n---\n\n- Kind: %s\n- Model: %s\n- Asked: %s\n- Context: `%s`\n",
+				interaction.Question, answer, interaction.Kind, interaction.Model,
+				interaction.CreatedAt.Format("2006-01-02 15:04"), interaction.ContextHash)
+		case "json":
+			data, err := json.MarshalIndent(interaction, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(data))
+		default:
+			return fmt.Errorf("unknown format %q (want json or markdown)", format)
+		}
 		return nil
 	},
 }
@@ -54,49 +98,67 @@ var exportInvoiceCmd = &cobra.Command{
 	Use:   "invoice",
 	Short: "Export invoice view as JSON or Markdown",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		dbPath := "chronos.db"
-		dbStore, err := db.NewStore(dbPath)
+		dbStore, err := openStore()
 		if err != nil {
 			return err
 		}
-		blockID, _ := cmd.Flags().GetInt64("block")
-		client, _ := cmd.Flags().GetString("client")
 		format, _ := cmd.Flags().GetString("format")
-		filter := map[string]interface{}{"billable": true}
-		if blockID > 0 {
-			filter["block_id"] = blockID
-		}
-		if client != "" {
-			filter["client"] = client
-		}
-		entries, err := dbStore.ListEntries(filter)
+		entries, err := invoiceEntries(cmd, dbStore)
 		if err != nil {
 			return err
 		}
-		var totalMinutes int64
-		var totalAmount float64
+		var lines []*chronos.InvoiceLine
+		if polish, _ := cmd.Flags().GetBool("polish"); polish {
+			var ok bool
+			if lines, ok, err = polishInvoiceLines(cmd, dbStore, entries); err != nil || !ok {
+				return err
+			}
+		}
+		var trackedMinutes, billedMinutes float64
 		for _, e := range entries {
-			totalMinutes += e.Duration
-			totalAmount += (float64(e.Duration) / 60.0) * e.Rate
+			trackedMinutes += e.Minutes()
+			billedMinutes += e.BilledMinutes()
+		}
+		totalLines := lines
+		if totalLines == nil {
+			totalLines = chronos.GroupInvoiceLines(entries)
+		}
+		totals, currency, err := previewTotals(cmd, dbStore, entries, totalLines)
+		if err != nil {
+			return err
 		}
 		invoice := struct {
-			Entries     []*chronos.Entry `json:"entries"`
-			TotalHours  float64          `json:"total_hours"`
-			TotalAmount float64          `json:"total_amount"`
+			Entries      []*chronos.Entry       `json:"entries"`
+			Lines        []*chronos.InvoiceLine `json:"lines,omitempty"`
+			TrackedHours float64                `json:"tracked_hours"`
+			TotalHours   float64                `json:"total_hours"`
+			Currency     string                 `json:"currency"`
+			Totals       chronos.InvoiceTotals  `json:"totals"`
+			TotalAmount  float64                `json:"total_amount"` // After discounts and taxes
 		}{
-			Entries:     entries,
-			TotalHours:  float64(totalMinutes) / 60.0,
-			TotalAmount: totalAmount,
+			Entries:      entries,
+			Lines:        lines,
+			TrackedHours: trackedMinutes / 60.0,
+			TotalHours:   billedMinutes / 60.0,
+			Currency:     currency,
+			Totals:       totals,
+			TotalAmount:  totals.Total,
 		}
 		if format == "markdown" {
-			fmt.Println(utils.TitleStyle.Render("Invoice (Markdown Export)"))
-			fmt.Println("# Invoice\n\n| Project | Task | Description | Hours | Rate | Amount |\n|---|---|---|---|---|---|")
-			for _, e := range entries {
-				hours := float64(e.Duration) / 60.0
-				amt := hours * e.Rate
-				fmt.Println(fmt.Sprintf("| %s | %s | %s | %.2f | %.2f | %.2f |", e.Project, e.Task, e.Description, hours, e.Rate, amt))
+			out := cmd.OutOrStdout()
+			fmt.Fprintln(out, utils.TitleStyle.Render("Invoice (Markdown Export)"))
+			if lines != nil {
+				fmt.Fprintln(out, "# Invoice\n\n| Project | Task | Description | Tracked | Hours | Amount |\n|---|---|---|---|---|---|")
+				for _, l := range lines {
+					fmt.Fprintf(out, "| %s | %s | %s | %.2f | %.2f | %.2f |\n", l.Project, l.Task, l.Description, l.TrackedHours(), l.Hours(), l.Amount)
+				}
+			} else {
+				fmt.Fprintln(out, "# Invoice\n\n| Project | Task | Description | Tracked | Hours | Rate | Amount |\n|---|---|---|---|---|---|---|")
+				for _, e := range entries {
+					fmt.Fprintf(out, "| %s | %s | %s | %.2f | %.2f | %.2f | %.2f |\n", e.Project, e.Task, e.Summary, e.Hours(), e.BilledHours(), e.Rate, e.Amount())
+				}
 			}
-			fmt.Println(fmt.Sprintf("\n**Total Hours:** %.2f\n**Total Amount:** $%.2f\n", invoice.TotalHours, invoice.TotalAmount))
+			fmt.Fprintf(out, "\n**Tracked Hours:** %.2f\n**Total Hours:** %.2f\n%s\n", invoice.TrackedHours, invoice.TotalHours, totalsMarkdown(totals, currency))
 			return nil
 		}
 		data, err := json.MarshalIndent(invoice, "", "  ")
@@ -109,12 +171,69 @@ var exportInvoiceCmd = &cobra.Command{
 	},
 }
 
+// polishInvoiceLines groups entries into invoice lines, has the LLM write a
+// client-facing description for each and lets the user review them. Lines
+// the model fails on keep their raw summaries. ok is false when the user
+// cancels.
+func polishInvoiceLines(cmd *cobra.Command, repo chronos.Repository, entries []*chronos.Entry) (lines []*chronos.InvoiceLine, ok bool, err error) {
+	var block *chronos.Block
+	if blockID, _ := cmd.Flags().GetInt64("block"); blockID > 0 {
+		if block, err = chronos.GetBlockByID(repo, blockID); err != nil {
+			return nil, false, err
+		}
+	}
+	clientName, _ := cmd.Flags().GetString("client")
+	if clientName == "" && block != nil {
+		clientName = block.Client
+	}
+	lines = chronos.GroupInvoiceLines(entries)
+	if len(lines) == 0 {
+		return lines, true, nil
+	}
+	llmClient, err := newLLMClient(repo)
+	if err != nil {
+		return nil, false, err
+	}
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+	defer stop()
+	for i, line := range lines {
+		fmt.Println(utils.InactiveStyle.Render(fmt.Sprintf("Describing line %d of %d: %s...", i+1, len(lines), line.Label())))
+		description, err := llmClient.DescribeInvoiceLine(ctx, clientName, line, block)
+		if errors.Is(err, context.Canceled) {
+			fmt.Println(utils.WarningStyle.Render("Cancelled."))
+			return nil, false, nil
+		}
+		if err != nil {
+			log.Warn("Keeping the raw description", "project", line.Project, "task", line.Task, "error", err)
+			continue
+		}
+		if description != "" {
+			line.Description = description
+		}
+	}
+
+	if yes, _ := cmd.Flags().GetBool("yes"); yes || !isatty.IsTerminal(os.Stdin.Fd()) || !isatty.IsTerminal(os.Stdout.Fd()) {
+		return lines, true, nil
+	}
+	if ok, err = ui.ReviewInvoiceLines(lines); err != nil || !ok {
+		if err == nil {
+			fmt.Println(utils.WarningStyle.Render("Invoice not exported."))
+		}
+		return nil, false, err
+	}
+	return lines, true, nil
+}
+
 func init() {
 	exportCmd.AddCommand(exportSummaryCmd)
+	exportSuggestCmd.Flags().Int64("id", 0, "History ID to export (default: the latest answer)")
+	exportSuggestCmd.Flags().String("format", "json", "Export format: json or markdown")
 	exportCmd.AddCommand(exportSuggestCmd)
 	exportInvoiceCmd.Flags().Int64("block", 0, "Block ID to invoice")
 	exportInvoiceCmd.Flags().String("client", "", "Client to invoice")
 	exportInvoiceCmd.Flags().String("format", "json", "Export format: json or markdown")
+	exportInvoiceCmd.Flags().Bool("polish", false, "Group entries per project/task and have the LLM write client-facing line descriptions")
+	exportInvoiceCmd.Flags().BoolP("yes", "y", false, "With --polish, skip reviewing the descriptions")
 	exportCmd.AddCommand(exportInvoiceCmd)
 	rootCmd.AddCommand(exportCmd)
 }
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/regiellis/chronos-go/chronos"
+	"github.com/regiellis/chronos-go/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// historyCmd lists saved assistant answers, most recent first. Indexes count
+// from 1 for the latest and are what `history show` and `history rerun` take.
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show your recent LLM questions and answers",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dbStore, err := openStore()
+		if err != nil {
+			return err
+		}
+		limit, _ := cmd.Flags().GetInt("limit")
+		list, err := chronos.ListInteractions(dbStore, limit)
+		if err != nil {
+			return err
+		}
+		if len(list) == 0 {
+			fmt.Println(utils.InactiveStyle.Render("No history yet. Try 'chronos ask'."))
+			return nil
+		}
+		for n, i := range list {
+			when := "unknown date"
+			if !i.CreatedAt.IsZero() {
+				when = i.CreatedAt.Format("2006-01-02 15:04")
+			}
+			fmt.Printf("%s %s %s %s\n",
+				utils.LabelStyle.Render(fmt.Sprintf("%3d.", n+1)),
+				utils.InactiveStyle.Render(fmt.Sprintf("[id %d] %s %-9s", i.ID, when, i.Kind)),
+				utils.ValueStyle.Render(truncate(i.Question, 60)),
+				utils.InactiveStyle.Render(i.Model))
+		}
+		fmt.Println(utils.InactiveStyle.Render("Use 'chronos history show N' to read an answer or 'chronos history rerun N' to ask again."))
+		return nil
+	},
+}
+
+var historyShowCmd = &cobra.Command{
+	Use:   "show [index]",
+	Short: "Show a saved answer in full",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dbStore, err := openStore()
+		if err != nil {
+			return err
+		}
+		i, err := interactionAt(dbStore, args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Println(utils.TitleStyle.Render(i.Question))
+		fmt.Println(utils.InactiveStyle.Render(fmt.Sprintf("%s · %s · %s", i.Kind, i.Model, i.CreatedAt.Format("2006-01-02 15:04"))))
+		if i.Answer == "" {
+			fmt.Println(utils.InactiveStyle.Render("(no answer saved)"))
+			return nil
+		}
+		fmt.Println(i.Answer)
+		return nil
+	},
+}
+
+var historyRerunCmd = &cobra.Command{
+	Use:   "rerun [index]",
+	Short: "Ask a saved question again against your current data",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dbStore, err := openStore()
+		if err != nil {
+			return err
+		}
+		i, err := interactionAt(dbStore, args[0])
+		if err != nil {
+			return err
+		}
+		var rerun *cobra.Command
+		var rerunArgs []string
+		switch i.Kind {
+		case chronos.KindAsk:
+			rerun, rerunArgs = askCmd, []string{i.Question}
+		case chronos.KindSuggest:
+			rerun = suggestCmd
+		case chronos.KindRemind:
+			rerun = remindCmd
+		case chronos.KindSummarize:
+			rerun = summarizeCmd
+			if i.BlockID > 0 {
+				if err := rerun.Flags().Set("block", strconv.FormatInt(i.BlockID, 10)); err != nil {
+					return err
+				}
+			}
+		case chronos.KindReview:
+			rerun, rerunArgs = reviewCmd, []string{strings.TrimSuffix(i.Question, " review")}
+		default:
+			return fmt.Errorf("cannot re-run a %q interaction", i.Kind)
+		}
+		// Hand on the flags given to rerun that the target command also has.
+		var flagErr error
+		cmd.Flags().Visit(func(f *pflag.Flag) {
+			if rerun.Flags().Lookup(f.Name) != nil && flagErr == nil {
+				flagErr = rerun.Flags().Set(f.Name, f.Value.String())
+			}
+		})
+		if flagErr != nil {
+			return flagErr
+		}
+		fmt.Println(utils.InactiveStyle.Render("Re-running: " + i.Question))
+		rerun.SetContext(cmd.Context())
+		if err := rerun.RunE(rerun, rerunArgs); err != nil {
+			return err
+		}
+		if latest, err := chronos.ListInteractions(dbStore, 1); err == nil && len(latest) == 1 && latest[0].ID != i.ID && i.ContextHash != "" {
+			if latest[0].ContextHash == i.ContextHash {
+				fmt.Println(utils.InactiveStyle.Render("Your data has not changed since the original answer."))
+			} else {
+				fmt.Println(utils.InactiveStyle.Render("Your data has changed since the original answer."))
+			}
+		}
+		return nil
+	},
+}
+
+// interactionAt resolves a 1-based history index (1 = most recent).
+func interactionAt(repo chronos.Repository, arg string) (*chronos.Interaction, error) {
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 1 {
+		return nil, fmt.Errorf("invalid history index %q", arg)
+	}
+	list, err := chronos.ListInteractions(repo, n)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) < n {
+		return nil, fmt.Errorf("history has only %d item(s)", len(list))
+	}
+	return list[n-1], nil
+}
+
+func truncate(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-1]) + "…"
+	}
+	return s
+}
+
+func init() {
+	historyCmd.Flags().Int("limit", 20, "Number of items to show")
+	historyRerunCmd.Flags().Bool("show-trace", false, "Show which tools the model called (ask only)")
+	historyRerunCmd.Flags().Bool("no-cache", false, "Ask the model again instead of reusing a cached summary or review")
+	historyCmd.AddCommand(historyShowCmd)
+	historyCmd.AddCommand(historyRerunCmd)
+	rootCmd.AddCommand(historyCmd)
+}
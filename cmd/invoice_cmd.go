@@ -0,0 +1,534 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/regiellis/chronos-go/chronos"
+	"github.com/regiellis/chronos-go/config"
+	"github.com/regiellis/chronos-go/ui"
+	"github.com/regiellis/chronos-go/utils"
+	"github.com/spf13/cobra"
+)
+
+// invoiceCmd manages invoices: a draft is created from unbilled entries,
+// issued (which numbers it), paid or voided. Invoices are addressed by ID or
+// by number.
+var invoiceCmd = &cobra.Command{
+	Use:   "invoice",
+	Short: "Create, issue and track invoices",
+}
+
+var invoiceCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a draft invoice from unbilled entries",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dbStore, err := openStore()
+		if err != nil {
+			return err
+		}
+		entries, err := invoiceEntries(cmd, dbStore)
+		if err != nil {
+			return fmt.Errorf("list entries: %w", err)
+		}
+		if len(entries) == 0 {
+			fmt.Println(utils.InfoStyle.Render("No unbilled entries to invoice."))
+			return nil
+		}
+		lines := chronos.GroupInvoiceLines(entries)
+		if polish, _ := cmd.Flags().GetBool("polish"); polish {
+			var ok bool
+			if lines, ok, err = polishInvoiceLines(cmd, dbStore, entries); err != nil || !ok {
+				return err
+			}
+		}
+		currency, err := invoiceCurrency(entries)
+		if err != nil {
+			return err
+		}
+		client, err := invoiceClient(cmd, dbStore, entries)
+		if err != nil {
+			return err
+		}
+		blockID, _ := cmd.Flags().GetInt64("block")
+		inv := &chronos.Invoice{Client: client, Currency: currency, BlockID: blockID, Lines: lines}
+		if inv.Client != "" {
+			if inv.Adjustments, err = chronos.GetClientTerms(dbStore, inv.Client); err != nil {
+				return err
+			}
+		}
+
+		if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+			fmt.Println(ui.InvoicePreview(inv))
+			fmt.Println(utils.InfoStyle.Render("Dry run: invoice not created."))
+			return nil
+		}
+		if err := chronos.CreateInvoice(dbStore, inv); err != nil {
+			return fmt.Errorf("create invoice: %w", err)
+		}
+		fmt.Println(ui.InvoicePreview(inv))
+		fmt.Println(utils.SuccessStyle.Render(fmt.Sprintf("Draft invoice #%d created for %d entries.", inv.ID, len(entries))))
+		fmt.Println(utils.InactiveStyle.Render(fmt.Sprintf("Issue it with 'chronos invoice issue %d'.", inv.ID)))
+		return nil
+	},
+}
+
+// invoiceClient names the client an invoice is for: --client, else the
+// block's client, else the client shared by every entry. Entries for
+// several clients cannot share an invoice.
+func invoiceClient(cmd *cobra.Command, repo chronos.Repository, entries []*chronos.Entry) (string, error) {
+	if client, _ := cmd.Flags().GetString("client"); client != "" {
+		return utils.SanitizeString(client), nil
+	}
+	if blockID, _ := cmd.Flags().GetInt64("block"); blockID > 0 {
+		if block, err := chronos.GetBlockByID(repo, blockID); err == nil && block.Client != "" {
+			return block.Client, nil
+		}
+	}
+	clients := map[string]bool{}
+	for _, e := range entries {
+		clients[e.Client] = true
+	}
+	if len(clients) > 1 {
+		list := make([]string, 0, len(clients))
+		for client := range clients {
+			if client == "" {
+				client = "no client"
+			}
+			list = append(list, client)
+		}
+		sort.Strings(list)
+		return "", fmt.Errorf("entries belong to %s; invoice one client at a time with --client", strings.Join(list, ", "))
+	}
+	return entries[0].Client, nil
+}
+
+var invoiceListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List invoices, newest first",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dbStore, err := openStore()
+		if err != nil {
+			return err
+		}
+		filters := map[string]interface{}{}
+		if status, _ := cmd.Flags().GetString("status"); status != "" {
+			filters["status"] = strings.ToLower(status)
+		}
+		if client, _ := cmd.Flags().GetString("client"); client != "" {
+			filters["client"] = utils.SanitizeString(client)
+		}
+		invoices, err := chronos.ListInvoices(dbStore, filters)
+		if err != nil {
+			return err
+		}
+		if len(invoices) == 0 {
+			fmt.Println(utils.InactiveStyle.Render("No invoices yet. Try 'chronos invoice create'."))
+			return nil
+		}
+		now := time.Now()
+		for _, inv := range invoices {
+			status := inv.Status
+			if inv.Status == chronos.InvoiceIssued && now.After(inv.DueDate) {
+				status = "overdue"
+			}
+			issued := "-"
+			if !inv.IssueDate.IsZero() {
+				issued = inv.IssueDate.Format("2006-01-02")
+			}
+			fmt.Printf("%s %s %s %s\n",
+				utils.LabelStyle.Render(fmt.Sprintf("%4d  %-16s", inv.ID, inv.Label())),
+				utils.InactiveStyle.Render(fmt.Sprintf("%-8s %s", status, issued)),
+				utils.ValueStyle.Render(fmt.Sprintf("%-20s %12s", truncate(inv.Client, 20), money(inv.Total(), inv.Currency))),
+				utils.InactiveStyle.Render(fmt.Sprintf("%.2fh", inv.Hours())))
+		}
+		return nil
+	},
+}
+
+var invoiceShowCmd = &cobra.Command{
+	Use:   "show [id or number]",
+	Short: "Show an invoice with its lines",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dbStore, err := openStore()
+		if err != nil {
+			return err
+		}
+		inv, err := invoiceByArg(dbStore, args[0])
+		if err != nil {
+			return err
+		}
+		switch format, _ := cmd.Flags().GetString("format"); format {
+		case "json":
+			out, err := json.MarshalIndent(struct {
+				*chronos.Invoice
+				Totals chronos.InvoiceTotals `json:"totals"`
+			}{inv, inv.Totals()}, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(out))
+		case "markdown":
+			fmt.Println(invoiceMarkdown(inv))
+		default:
+			fmt.Println(ui.InvoicePreview(inv))
+		}
+		return nil
+	},
+}
+
+// invoiceMarkdown renders an invoice as a Markdown document.
+func invoiceMarkdown(inv *chronos.Invoice) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Invoice %s\n\n", inv.Label())
+	if inv.Client != "" {
+		fmt.Fprintf(&b, "**Client:** %s\n", inv.Client)
+	}
+	fmt.Fprintf(&b, "**Currency:** %s\n", chronos.NormalizeCurrency(inv.Currency))
+	if !inv.IssueDate.IsZero() {
+		fmt.Fprintf(&b, "**Issued:** %s\n**Due:** %s\n", inv.IssueDate.Format("2006-01-02"), inv.DueDate.Format("2006-01-02"))
+	}
+	b.WriteString("\n| Project | Task | Description | Hours | Amount |\n|---|---|---|---|---|\n")
+	for _, l := range inv.Lines {
+		if l.Kind != "" {
+			fmt.Fprintf(&b, "| %s |  | %s |  | %.2f |\n", l.Label(), l.Description, l.Amount)
+			continue
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %.2f | %.2f |\n", l.Project, l.Task, l.Description, l.Hours(), l.Amount)
+	}
+	fmt.Fprintf(&b, "\n**Total Hours:** %.2f\n%s", inv.Hours(), totalsMarkdown(inv.Totals(), inv.Currency))
+	return b.String()
+}
+
+// totalsMarkdown renders the money lines under an invoice table: the
+// subtotal, discounts and taxes when there are any, then the total, all in
+// currency.
+func totalsMarkdown(t chronos.InvoiceTotals, currency string) string {
+	var b strings.Builder
+	if len(t.Discounts) > 0 || len(t.Taxes) > 0 {
+		fmt.Fprintf(&b, "**Subtotal:** %s\n", money(t.Subtotal, currency))
+		for _, a := range append(t.Discounts, t.Taxes...) {
+			fmt.Fprintf(&b, "**%s:** %s\n", adjustmentLabel(a), money(a.Amount, currency))
+		}
+	}
+	fmt.Fprintf(&b, "**Total Amount:** %s\n", money(t.Total, currency))
+	return b.String()
+}
+
+// adjustmentLabel names an applied adjustment, e.g. "VAT (20%)".
+func adjustmentLabel(a chronos.AdjustmentAmount) string {
+	if a.Percent == 0 {
+		return a.Name
+	}
+	return fmt.Sprintf("%s (%s%%)", a.Name, strconv.FormatFloat(a.Percent, 'f', -1, 64))
+}
+
+// money formats an amount in currency, e.g. $1,234.50 or -€100.00.
+func money(f float64, currency string) string {
+	return chronos.FormatMoney(f, currency)
+}
+
+// previewTotals works out the totals of lines that are not on an invoice
+// yet, with the terms of the client they would be invoiced to, and the
+// currency they would be in. Entries come from invoiceEntries.
+func previewTotals(cmd *cobra.Command, repo chronos.Repository, entries []*chronos.Entry, lines []*chronos.InvoiceLine) (chronos.InvoiceTotals, string, error) {
+	currency, err := invoiceCurrency(entries)
+	if err != nil {
+		return chronos.InvoiceTotals{}, "", err
+	}
+	var terms []*chronos.Adjustment
+	// Entries for several clients are previewed without terms; creating the
+	// invoice asks for --client.
+	if len(entries) > 0 {
+		if client, err := invoiceClient(cmd, repo, entries); err == nil && client != "" {
+			if terms, err = chronos.GetClientTerms(repo, client); err != nil {
+				return chronos.InvoiceTotals{}, "", err
+			}
+		}
+	}
+	return chronos.CalculateTotals(lines, terms), currency, nil
+}
+
+var invoiceIssueCmd = &cobra.Command{
+	Use:   "issue [id or number]",
+	Short: "Issue a draft invoice, giving it the next number",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dbStore, err := openStore()
+		if err != nil {
+			return err
+		}
+		inv, err := invoiceByArg(dbStore, args[0])
+		if err != nil {
+			return err
+		}
+		issued, err := invoiceDate(cmd)
+		if err != nil {
+			return err
+		}
+		cfgPath, err := userConfigPath()
+		if err != nil {
+			return err
+		}
+		cfg, _ := config.LoadConfig(cfgPath)
+		dueDays := cfg.InvoiceDueDays
+		if cmd.Flags().Changed("due-days") || dueDays <= 0 {
+			dueDays, _ = cmd.Flags().GetInt("due-days")
+		}
+		numbering := chronos.InvoiceNumbering{Format: cfg.InvoiceNumberFormat, Start: cfg.InvoiceStartNumber}
+		if inv, err = chronos.IssueInvoice(dbStore, inv.ID, numbering, issued, dueDays); err != nil {
+			return err
+		}
+		fmt.Println(utils.SuccessStyle.Render(fmt.Sprintf("Issued invoice %s for %s, due %s.", inv.Number, money(inv.Total(), inv.Currency), inv.DueDate.Format("2006-01-02"))))
+		return nil
+	},
+}
+
+var invoicePayCmd = &cobra.Command{
+	Use:   "pay [id or number]",
+	Short: "Mark an issued invoice as paid",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dbStore, err := openStore()
+		if err != nil {
+			return err
+		}
+		inv, err := invoiceByArg(dbStore, args[0])
+		if err != nil {
+			return err
+		}
+		paid, err := invoiceDate(cmd)
+		if err != nil {
+			return err
+		}
+		if inv, err = chronos.MarkInvoicePaid(dbStore, inv.ID, paid); err != nil {
+			return err
+		}
+		fmt.Println(utils.SuccessStyle.Render(fmt.Sprintf("Invoice %s marked paid.", inv.Label())))
+		return nil
+	},
+}
+
+var invoiceVoidCmd = &cobra.Command{
+	Use:   "void [id or number]",
+	Short: "Void an invoice and release its entries back to unbilled",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dbStore, err := openStore()
+		if err != nil {
+			return err
+		}
+		inv, err := invoiceByArg(dbStore, args[0])
+		if err != nil {
+			return err
+		}
+		if inv, err = chronos.VoidInvoice(dbStore, inv.ID); err != nil {
+			return err
+		}
+		fmt.Println(utils.WarningStyle.Render(fmt.Sprintf("Invoice %s voided; %d entries are unbilled again.", inv.Label(), len(inv.EntryIDs()))))
+		return nil
+	},
+}
+
+var invoiceTermsCmd = &cobra.Command{
+	Use:   "terms [client]",
+	Short: "Show or set the taxes and discounts a client's invoices start with",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dbStore, err := openStore()
+		if err != nil {
+			return err
+		}
+		client := utils.SanitizeString(args[0])
+		clearTerms, _ := cmd.Flags().GetBool("clear")
+		terms, err := parseAdjustments(cmd)
+		if err != nil {
+			return err
+		}
+		if !clearTerms && len(terms) == 0 {
+			if terms, err = chronos.GetClientTerms(dbStore, client); err != nil {
+				return err
+			}
+			if len(terms) == 0 {
+				fmt.Println(utils.InactiveStyle.Render(fmt.Sprintf("%s has no invoice terms. Add some with --tax, --withholding or --discount.", client)))
+				return nil
+			}
+			fmt.Println(utils.LabelStyle.Render(client+":"), utils.ValueStyle.Render(adjustmentsSummary(terms)))
+			return nil
+		}
+		if err := chronos.SetClientTerms(dbStore, client, terms); err != nil {
+			return err
+		}
+		if len(terms) == 0 {
+			fmt.Println(utils.SuccessStyle.Render(fmt.Sprintf("Invoice terms for %s cleared.", client)))
+			return nil
+		}
+		fmt.Println(utils.SuccessStyle.Render(fmt.Sprintf("New invoices for %s get %s.", client, adjustmentsSummary(terms))))
+		return nil
+	},
+}
+
+var invoiceAdjustCmd = &cobra.Command{
+	Use:   "adjust [id or number]",
+	Short: "Replace the taxes and discounts of a draft invoice",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dbStore, err := openStore()
+		if err != nil {
+			return err
+		}
+		inv, err := invoiceByArg(dbStore, args[0])
+		if err != nil {
+			return err
+		}
+		adjustments, err := parseAdjustments(cmd)
+		if err != nil {
+			return err
+		}
+		if clearAll, _ := cmd.Flags().GetBool("clear"); !clearAll && len(adjustments) == 0 {
+			return fmt.Errorf("give --tax, --withholding or --discount, or --clear to remove them all")
+		}
+		if inv, err = chronos.SetInvoiceAdjustments(dbStore, inv.ID, adjustments); err != nil {
+			return err
+		}
+		fmt.Println(ui.InvoicePreview(inv))
+		return nil
+	},
+}
+
+var invoiceAddItemCmd = &cobra.Command{
+	Use:   "add-item [id or number] [description] [amount]",
+	Short: "Add a fixed-price item or an expense to a draft invoice",
+	Args:  cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		amount, err := strconv.ParseFloat(args[2], 64)
+		if err != nil {
+			return fmt.Errorf("invalid amount %q: %w", args[2], err)
+		}
+		dbStore, err := openStore()
+		if err != nil {
+			return err
+		}
+		inv, err := invoiceByArg(dbStore, args[0])
+		if err != nil {
+			return err
+		}
+		line := &chronos.InvoiceLine{Kind: chronos.LineFixed, Description: utils.SanitizeString(args[1]), Amount: amount}
+		if expense, _ := cmd.Flags().GetBool("expense"); expense {
+			line.Kind = chronos.LineExpense
+		}
+		if inv, err = chronos.AddInvoiceItem(dbStore, inv.ID, line); err != nil {
+			return err
+		}
+		fmt.Println(utils.SuccessStyle.Render(fmt.Sprintf("Added %s %q (%s) to invoice %s; total now %s.", strings.ToLower(line.Label()), line.Description, money(line.Amount, inv.Currency), inv.Label(), money(inv.Total(), inv.Currency))))
+		return nil
+	},
+}
+
+// parseAdjustments reads the --tax, --withholding and --discount flags. Each
+// is NAME=VALUE or just VALUE; taxes are percentages, and a discount is a
+// percentage when it ends in % and a fixed amount otherwise.
+func parseAdjustments(cmd *cobra.Command) ([]*chronos.Adjustment, error) {
+	var adjustments []*chronos.Adjustment
+	for _, kind := range []string{chronos.AdjustDiscount, chronos.AdjustTax, chronos.AdjustWithholding} {
+		values, _ := cmd.Flags().GetStringArray(kind)
+		for _, v := range values {
+			a := &chronos.Adjustment{Kind: kind}
+			if name, value, ok := strings.Cut(v, "="); ok {
+				a.Name, v = utils.SanitizeString(name), value
+			}
+			v = strings.TrimSpace(v)
+			percent := kind != chronos.AdjustDiscount || strings.HasSuffix(v, "%")
+			f, err := strconv.ParseFloat(strings.TrimSuffix(v, "%"), 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid --%s %q: %w", kind, v, err)
+			}
+			if percent {
+				a.Percent = f
+			} else {
+				a.Amount = f
+			}
+			if err := a.Validate(); err != nil {
+				return nil, err
+			}
+			adjustments = append(adjustments, a)
+		}
+	}
+	return adjustments, nil
+}
+
+// adjustmentsSummary lists adjustments as "VAT 20%, Discount 50.00".
+func adjustmentsSummary(adjustments []*chronos.Adjustment) string {
+	names := make([]string, len(adjustments))
+	for i, a := range adjustments {
+		names[i] = a.String()
+	}
+	return strings.Join(names, ", ")
+}
+
+// invoiceByArg finds an invoice by ID or, failing that, by number.
+func invoiceByArg(repo chronos.Repository, arg string) (*chronos.Invoice, error) {
+	if id, err := strconv.ParseInt(arg, 10, 64); err == nil {
+		return chronos.GetInvoiceByID(repo, id)
+	}
+	invoices, err := chronos.ListInvoices(repo, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, inv := range invoices {
+		if strings.EqualFold(inv.Number, arg) {
+			return inv, nil
+		}
+	}
+	return nil, fmt.Errorf("invoice %q: %w", arg, chronos.ErrNotFound)
+}
+
+// invoiceDate returns the --date flag, defaulting to today.
+func invoiceDate(cmd *cobra.Command) (time.Time, error) {
+	date, _ := cmd.Flags().GetString("date")
+	if date == "" {
+		return time.Now(), nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", date, time.Local)
+	if err != nil {
+		return t, fmt.Errorf("invalid --date %q (want YYYY-MM-DD): %w", date, err)
+	}
+	return t, nil
+}
+
+func init() {
+	invoiceCreateCmd.Flags().Int64("block", 0, "Block ID to invoice")
+	invoiceCreateCmd.Flags().String("client", "", "Client to invoice")
+	invoiceCreateCmd.Flags().Bool("polish", false, "Have the LLM write client-facing line descriptions")
+	invoiceCreateCmd.Flags().BoolP("yes", "y", false, "With --polish, skip reviewing the descriptions")
+	invoiceCreateCmd.Flags().Bool("dry-run", false, "Show the invoice without creating it")
+	invoiceListCmd.Flags().String("status", "", "Only list invoices with this status (draft, issued, paid, void)")
+	invoiceListCmd.Flags().String("client", "", "Only list invoices for this client")
+	invoiceShowCmd.Flags().String("format", "text", "Output format: text, json or markdown")
+	invoiceIssueCmd.Flags().String("date", "", "Issue date (YYYY-MM-DD, default today)")
+	invoiceIssueCmd.Flags().Int("due-days", chronos.DefaultInvoiceDueDays, "Days until the invoice is due (overrides invoice_due_days in chronos.json)")
+	invoicePayCmd.Flags().String("date", "", "Payment date (YYYY-MM-DD, default today)")
+	for _, c := range []*cobra.Command{invoiceTermsCmd, invoiceAdjustCmd} {
+		c.Flags().StringArray("tax", nil, "Tax as NAME=PERCENT, e.g. VAT=20 (repeatable)")
+		c.Flags().StringArray("withholding", nil, "Withholding tax as NAME=PERCENT, taken off the total (repeatable)")
+		c.Flags().StringArray("discount", nil, "Discount as [NAME=]10% or a fixed [NAME=]50 (repeatable)")
+		c.Flags().Bool("clear", false, "Remove all taxes and discounts")
+	}
+	invoiceAddItemCmd.Flags().Bool("expense", false, "Add an expense, which is never discounted, instead of a fixed-price item")
+
+	invoiceCmd.AddCommand(invoiceCreateCmd)
+	invoiceCmd.AddCommand(invoiceListCmd)
+	invoiceCmd.AddCommand(invoiceShowCmd)
+	invoiceCmd.AddCommand(invoiceIssueCmd)
+	invoiceCmd.AddCommand(invoicePayCmd)
+	invoiceCmd.AddCommand(invoiceVoidCmd)
+	invoiceCmd.AddCommand(invoiceTermsCmd)
+	invoiceCmd.AddCommand(invoiceAdjustCmd)
+	invoiceCmd.AddCommand(invoiceAddItemCmd)
+	rootCmd.AddCommand(invoiceCmd)
+}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/regiellis/chronos-go/config"
+	"github.com/regiellis/chronos-go/llm"
+	"github.com/regiellis/chronos-go/utils"
+	"github.com/spf13/cobra"
+)
+
+var promptsCmd = &cobra.Command{
+	Use:   "prompts",
+	Short: "List, show, edit or reset the LLM prompt templates",
+	Long: "Every LLM feature renders a Go text/template. Editing one writes an override to " +
+		config.PromptsDir() + "; reset deletes it and restores the built-in default.",
+}
+
+var promptsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the prompt templates and whether they are customized",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		prompts := llm.Prompts{Dir: config.PromptsDir()}
+		for _, name := range llm.PromptNames() {
+			_, custom, err := prompts.Source(name)
+			if err != nil {
+				return err
+			}
+			status := utils.InactiveStyle.Render("default")
+			if custom {
+				status = utils.WarningStyle.Render("custom  " + prompts.Path(name))
+			}
+			fmt.Printf("%-20s %s\n", utils.ValueStyle.Render(name), status)
+		}
+		return nil
+	},
+}
+
+var promptsShowCmd = &cobra.Command{
+	Use:   "show [name]",
+	Short: "Print the template in effect for a prompt",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		text, _, err := llm.Prompts{Dir: config.PromptsDir()}.Source(args[0])
+		if showDefault, _ := cmd.Flags().GetBool("default"); showDefault {
+			text, err = llm.DefaultPrompt(args[0])
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Print(text)
+		return nil
+	},
+}
+
+var promptsEditCmd = &cobra.Command{
+	Use:   "edit [name]",
+	Short: "Open a prompt template in $EDITOR, starting from the default",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		prompts := llm.Prompts{Dir: config.PromptsDir()}
+		name := args[0]
+		text, custom, err := prompts.Source(name)
+		if err != nil {
+			return err
+		}
+		path := prompts.Path(name)
+		if !custom {
+			if err := os.MkdirAll(prompts.Dir, 0700); err != nil {
+				return fmt.Errorf("create prompts directory: %w", err)
+			}
+			if err := os.WriteFile(path, []byte(text), 0600); err != nil {
+				return fmt.Errorf("write %s: %w", path, err)
+			}
+		}
+		editor := os.Getenv("VISUAL")
+		if editor == "" {
+			editor = os.Getenv("EDITOR")
+		}
+		if editor == "" {
+			editor = "vi"
+		}
+		// EDITOR may carry flags, e.g. "code --wait".
+		argv := append(strings.Fields(editor), path)
+		edit := exec.Command(argv[0], argv[1:]...)
+		edit.Stdin, edit.Stdout, edit.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err := edit.Run(); err != nil {
+			return fmt.Errorf("run %s: %w (the template is at %s)", editor, err, path)
+		}
+
+		if err := prompts.Check(name); err != nil {
+			fmt.Println(utils.ErrorStyle.Render("The template does not parse: " + err.Error()))
+			fmt.Println(utils.InactiveStyle.Render("Fix it with 'chronos prompts edit " + name + "' or restore the default with 'chronos prompts reset " + name + "'."))
+			return nil
+		}
+		fmt.Println(utils.SuccessStyle.Render("Saved " + path))
+		return nil
+	},
+}
+
+var promptsResetCmd = &cobra.Command{
+	Use:   "reset [name]",
+	Short: "Delete a customized prompt and go back to the default",
+	Args: func(cmd *cobra.Command, args []string) error {
+		if all, _ := cmd.Flags().GetBool("all"); all {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		prompts := llm.Prompts{Dir: config.PromptsDir()}
+		names := args
+		if all, _ := cmd.Flags().GetBool("all"); all {
+			names = llm.PromptNames()
+		}
+		for _, name := range names {
+			removed, err := prompts.Reset(name)
+			if err != nil {
+				return err
+			}
+			if removed {
+				fmt.Println(utils.SuccessStyle.Render("Restored the default " + name + " prompt."))
+			} else if len(args) > 0 {
+				fmt.Println(utils.InactiveStyle.Render("The " + name + " prompt is not customized."))
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	promptsShowCmd.Flags().Bool("default", false, "Show the built-in default even if customized")
+	promptsResetCmd.Flags().Bool("all", false, "Reset every prompt")
+	promptsCmd.AddCommand(promptsListCmd)
+	promptsCmd.AddCommand(promptsShowCmd)
+	promptsCmd.AddCommand(promptsEditCmd)
+	promptsCmd.AddCommand(promptsResetCmd)
+	rootCmd.AddCommand(promptsCmd)
+}
@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/regiellis/chronos-go/chronos"
+	"github.com/regiellis/chronos-go/config"
+	"github.com/regiellis/chronos-go/utils"
+	"github.com/spf13/cobra"
+)
+
+// rateCmd sets the default hourly rate in chronos.json. Its subcommands keep
+// dated rates for projects, clients and blocks in the database; invoices use
+// the most specific one in effect when each entry was worked.
+var rateCmd = &cobra.Command{
+	Use:   "rate [amount]",
+	Short: "Quickly set your default hourly rate (config file)",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfgPath, err := userConfigPath()
+		if err != nil {
+			return err
+		}
+		cfg, err := config.LoadConfig(cfgPath)
+		if err != nil {
+			return err
+		}
+		rate, err := strconv.ParseFloat(args[0], 64)
+		if err != nil {
+			return err
+		}
+		cfg.DefaultRate = rate
+		return config.SaveConfig(cfgPath, cfg)
+	},
+}
+
+// newRateResolver resolves entry rates against the stored rates and the
+// default rate in chronos.json.
+func newRateResolver(repo chronos.Repository) (*chronos.RateResolver, error) {
+	cfgPath, err := userConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	cfg, _ := config.LoadConfig(cfgPath)
+	return chronos.NewRateResolver(repo, cfg.DefaultRate)
+}
+
+var rateSetCmd = &cobra.Command{
+	Use:   "set [amount]",
+	Short: "Set an hourly rate for a project, client, block or everything from a date on",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		amount, err := strconv.ParseFloat(args[0], 64)
+		if err != nil {
+			return fmt.Errorf("invalid rate %q: %w", args[0], err)
+		}
+		r := &chronos.Rate{Scope: chronos.RateDefault, Amount: amount}
+		project, _ := cmd.Flags().GetString("project")
+		client, _ := cmd.Flags().GetString("client")
+		blockID, _ := cmd.Flags().GetInt64("block")
+		scopes := 0
+		if project != "" {
+			r.Scope, r.Target = chronos.RateProject, utils.SanitizeString(project)
+			scopes++
+		}
+		if client != "" {
+			r.Scope, r.Target = chronos.RateClient, utils.SanitizeString(client)
+			scopes++
+		}
+		if blockID > 0 {
+			r.Scope, r.Target = chronos.RateBlock, strconv.FormatInt(blockID, 10)
+			scopes++
+		}
+		if scopes > 1 {
+			return fmt.Errorf("use only one of --project, --client and --block")
+		}
+		from, _ := cmd.Flags().GetString("from")
+		if from == "" {
+			now := time.Now()
+			r.EffectiveFrom = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+		} else if r.EffectiveFrom, err = time.ParseInLocation("2006-01-02", from, time.Local); err != nil {
+			return fmt.Errorf("invalid --from %q (want YYYY-MM-DD): %w", from, err)
+		}
+
+		dbStore, err := openStore()
+		if err != nil {
+			return err
+		}
+		if err := chronos.CreateRate(dbStore, r); err != nil {
+			return err
+		}
+		fmt.Println(utils.SuccessStyle.Render(fmt.Sprintf("Rate %.2f/h set for %s from %s.", r.Amount, rateLabel(r), r.EffectiveFrom.Format("2006-01-02"))))
+		return nil
+	},
+}
+
+var rateListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List dated rates and the configured default",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dbStore, err := openStore()
+		if err != nil {
+			return err
+		}
+		rates, err := chronos.ListRates(dbStore)
+		if err != nil {
+			return err
+		}
+		cfgPath, err := userConfigPath()
+		if err != nil {
+			return err
+		}
+		cfg, _ := config.LoadConfig(cfgPath)
+		fmt.Println(utils.LabelStyle.Render("Default (chronos.json):"), utils.ValueStyle.Render(fmt.Sprintf("%.2f/h", cfg.DefaultRate)))
+		if len(rates) == 0 {
+			fmt.Println(utils.InactiveStyle.Render("No dated rates. Add one with 'chronos rate set'."))
+			return nil
+		}
+		for _, r := range rates {
+			fmt.Printf("%s %s %s\n",
+				utils.LabelStyle.Render(fmt.Sprintf("%4d  %-28s", r.ID, truncate(rateLabel(r), 28))),
+				utils.ValueStyle.Render(fmt.Sprintf("%10.2f/h", r.Amount)),
+				utils.InactiveStyle.Render("from "+r.EffectiveFrom.Format("2006-01-02")))
+		}
+		return nil
+	},
+}
+
+var rateRemoveCmd = &cobra.Command{
+	Use:   "remove [id]",
+	Short: "Remove a dated rate",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid rate ID %q: %w", args[0], err)
+		}
+		dbStore, err := openStore()
+		if err != nil {
+			return err
+		}
+		if err := chronos.DeleteRate(dbStore, id); err != nil {
+			return err
+		}
+		fmt.Println(utils.SuccessStyle.Render(fmt.Sprintf("Rate %d removed.", id)))
+		return nil
+	},
+}
+
+var rateExplainCmd = &cobra.Command{
+	Use:   "explain [entry id]",
+	Short: "Show which rate applies to an entry and where it comes from",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid entry ID %q: %w", args[0], err)
+		}
+		dbStore, err := openStore()
+		if err != nil {
+			return err
+		}
+		entry, err := chronos.GetEntryByID(dbStore, id)
+		if err != nil {
+			return err
+		}
+		rates, err := newRateResolver(dbStore)
+		if err != nil {
+			return err
+		}
+		rate, source := rates.Resolve(entry)
+		if source == "" {
+			fmt.Println(utils.WarningStyle.Render(fmt.Sprintf("No rate applies to entry %d. Set one with 'chronos rate set'.", id)))
+			return nil
+		}
+		fmt.Println(utils.InfoStyle.Render(fmt.Sprintf("Entry %d bills at %.2f/h (%s rate).", id, rate, source)))
+		return nil
+	},
+}
+
+// rateLabel describes what a rate applies to.
+func rateLabel(r *chronos.Rate) string {
+	switch r.Scope {
+	case chronos.RateDefault:
+		return "everything"
+	case chronos.RateBlock:
+		return "block " + r.Target
+	default:
+		return r.Scope + " " + r.Target
+	}
+}
+
+func init() {
+	rateSetCmd.Flags().String("project", "", "Project the rate applies to")
+	rateSetCmd.Flags().String("client", "", "Client the rate applies to")
+	rateSetCmd.Flags().Int64("block", 0, "Block ID the rate applies to")
+	rateSetCmd.Flags().String("from", "", "First day the rate applies (YYYY-MM-DD, default today)")
+
+	rateCmd.AddCommand(rateSetCmd)
+	rateCmd.AddCommand(rateListCmd)
+	rateCmd.AddCommand(rateRemoveCmd)
+	rateCmd.AddCommand(rateExplainCmd)
+	rootCmd.AddCommand(rateCmd)
+}
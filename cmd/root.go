@@ -4,10 +4,14 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
-	"database/sql"
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -18,7 +22,6 @@ import (
 	log "github.com/charmbracelet/log"
 	"github.com/regiellis/chronos-go/chronos" // Imported chronos
 	"github.com/regiellis/chronos-go/config"
-	"github.com/regiellis/chronos-go/db"
 	"github.com/regiellis/chronos-go/llm"
 	"github.com/regiellis/chronos-go/ui"
 	"github.com/regiellis/chronos-go/utils"
@@ -48,55 +51,83 @@ var rootCmd = &cobra.Command{
 	),
 }
 
-// askCmd represents the ask command
+// askShowTrace prints the tools the model called after the answer.
+var askShowTrace bool
+
+// askCmd represents the ask command. The model answers by calling read-only
+// tools that query the store, so the numbers it reports are exact.
 var askCmd = &cobra.Command{
 	Use:   "ask [question]",
 	Short: "Ask the LLM about your tracked time, blocks, or progress",
 	Args:  cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		dbPath := "chronos.db"
-		dbStore, err := db.NewStore(dbPath)
+		dbStore, err := openStore()
 		if err != nil {
-			return fmt.Errorf("db store: %w", err)
-		}
-		if err := dbStore.InitSchema(); err != nil {
-			return fmt.Errorf("schema init: %w", err)
+			return err
 		}
-		llmClient := llm.NewOllamaClient()
 		question := strings.Join(args, " ")
-		entries, _ := chronos.ListEntries(dbStore, nil) // Refactored
-		blocks, _ := chronos.ListBlocks(dbStore, nil)   // Refactored
-		answer, err := llmClient.AnswerUserQuery(question, entries, blocks)
+		entries, _ := chronos.ListEntries(dbStore, nil)
+		blocks, _ := chronos.ListBlocks(dbStore, nil)
+		record := chronos.Interaction{Kind: chronos.KindAsk, Question: question, ContextHash: chronos.ContextHash(entries, blocks)}
+		rates, err := newRateResolver(dbStore)
 		if err != nil {
 			return err
 		}
-		log.Info(answer)
-		return nil
+		rounder, err := chronos.NewRounder(dbStore)
+		if err != nil {
+			return err
+		}
+		currencies, err := newCurrencies(dbStore)
+		if err != nil {
+			return err
+		}
+		tools := &llm.StoreTools{Repo: dbStore, Rates: rates, Rounding: rounder, Currencies: currencies, Now: time.Now}
+		var trace []llm.ToolTrace
+		err = askLLM(cmd, dbStore, record, "Thinking...", func(ctx context.Context, llmClient *llm.Client) (string, error) {
+			var answer string
+			var err error
+			answer, trace, err = llmClient.AnswerWithTools(ctx, question, tools)
+			return answer, err
+		})
+		if askShowTrace {
+			printToolTrace(trace)
+		}
+		return err
 	},
 }
 
+// printToolTrace lists each tool call with its arguments and (shortened) result.
+func printToolTrace(trace []llm.ToolTrace) {
+	if len(trace) == 0 {
+		fmt.Println(utils.InactiveStyle.Render("No tools were called."))
+		return
+	}
+	fmt.Println(utils.LabelStyle.Render("Tool calls:"))
+	for i, t := range trace {
+		result := t.Result
+		if len(result) > 200 {
+			result = result[:200] + "..."
+		}
+		fmt.Printf("  %d. %s %s\n", i+1, utils.ValueStyle.Render(t.Name), string(t.Arguments))
+		fmt.Println(utils.InactiveStyle.Render("     -> " + result))
+	}
+}
+
 // suggestCmd represents the suggest command
 var suggestCmd = &cobra.Command{
 	Use:   "suggest",
 	Short: "Get a suggestion for your next entry or task",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		dbPath := "chronos.db"
-		dbStore, err := db.NewStore(dbPath)
+		dbStore, err := openStore()
 		if err != nil {
-			return fmt.Errorf("db store: %w", err)
-		}
-		if err := dbStore.InitSchema(); err != nil {
-			return fmt.Errorf("schema init: %w", err)
+			return err
 		}
-		llmClient := llm.NewOllamaClient()
 		entries, _ := chronos.ListEntries(dbStore, nil) // Refactored
 		blocks, _ := chronos.ListBlocks(dbStore, nil)   // Refactored
-		suggestion, err := llmClient.SuggestNextEntry(entries, blocks)
-		if err != nil {
-			return err
-		}
-		log.Info(suggestion)
-		return nil
+		record := chronos.Interaction{Kind: chronos.KindSuggest, Question: "What should I work on next?", ContextHash: chronos.ContextHash(entries, blocks)}
+		return askLLM(cmd, dbStore, record, "Thinking...", func(ctx context.Context, llmClient *llm.Client) (string, error) {
+			return llmClient.SuggestNextEntry(ctx, entries, blocks)
+		})
 	},
 }
 
@@ -115,45 +146,16 @@ var remindCmd = &cobra.Command{
 	Use:   "remind",
 	Short: "Show smart reminders or nudges based on your activity",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		dbPath := "chronos.db"
-		dbStore, err := db.NewStore(dbPath)
+		dbStore, err := openStore()
 		if err != nil {
-			return fmt.Errorf("db store: %w", err)
-		}
-		if err := dbStore.InitSchema(); err != nil {
-			return fmt.Errorf("schema init: %w", err)
+			return err
 		}
-		llmClient := llm.NewOllamaClient()
 		entries, _ := chronos.ListEntries(dbStore, nil) // Refactored
 		blocks, _ := chronos.ListBlocks(dbStore, nil)   // Refactored
-		reminder, err := llmClient.SmartReminder(entries, blocks)
-		if err != nil {
-			return err
-		}
-		log.Info(reminder)
-		return nil
-	},
-}
-
-// historyCmd represents the history command (manages LLM query history, not chronos entries/blocks)
-var historyCmd = &cobra.Command{
-	Use:   "history",
-	Short: "Show your recent LLM queries for quick re-use",
-	RunE: func(cmd *cobra.Command, args []string) error {
-		dbPath := "chronos.db"
-		dbStore, err := db.NewStore(dbPath) // This is a direct db.Store usage for its specific QueryHistory
-		if err != nil {
-			return err
-		}
-		// QueryHistory is specific to db.Store and not part of chronos package's concerns for now
-		queries, err := dbStore.QueryHistory(10)
-		if err != nil {
-			return err
-		}
-		for i, q := range queries {
-			log.Info("%d: %s", i+1, q)
-		}
-		return nil
+		record := chronos.Interaction{Kind: chronos.KindRemind, Question: "Any reminders?", ContextHash: chronos.ContextHash(entries, blocks)}
+		return askLLM(cmd, dbStore, record, "Thinking...", func(ctx context.Context, llmClient *llm.Client) (string, error) {
+			return llmClient.SmartReminder(ctx, entries, blocks)
+		})
 	},
 }
 
@@ -163,24 +165,17 @@ var completeCmd = &cobra.Command{
 	Short: "Get LLM-powered auto-completions for project, client, or task fields",
 	Args:  cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		dbPath := "chronos.db"
-		dbStore, err := db.NewStore(dbPath)
+		dbStore, err := openStore()
 		if err != nil {
-			return fmt.Errorf("db store: %w", err)
-		}
-		if err := dbStore.InitSchema(); err != nil { // Ensure schema for List functions
-			return fmt.Errorf("schema init: %w", err)
+			return err
 		}
-		llmClient := llm.NewOllamaClient()
 		entries, _ := chronos.ListEntries(dbStore, nil) // Refactored
 		blocks, _ := chronos.ListBlocks(dbStore, nil)   // Refactored
 		partial := args[0]
-		suggestion, err := llmClient.AutoCompleteFields(partial, entries, blocks)
-		if err != nil {
-			return err
-		}
-		log.Info(suggestion)
-		return nil
+		_, err = streamLLM(cmd, dbStore, "Thinking...", func(ctx context.Context, llmClient *llm.Client) (string, error) {
+			return llmClient.AutoCompleteFields(ctx, partial, entries, blocks)
+		})
+		return err
 	},
 }
 
@@ -189,13 +184,9 @@ var editCmd = &cobra.Command{
 	Short: "Edit a time entry by ID (toggles invoiced status for now)",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		dbPath := "chronos.db"
-		dbStore, err := db.NewStore(dbPath)
+		dbStore, err := openStore()
 		if err != nil {
-			return fmt.Errorf("db store: %w", err)
-		}
-		if err := dbStore.InitSchema(); err != nil {
-			return fmt.Errorf("schema init: %w", err)
+			return err
 		}
 		id, err := strconv.ParseInt(args[0], 10, 64)
 		if err != nil {
@@ -204,7 +195,7 @@ var editCmd = &cobra.Command{
 
 		entry, err := chronos.GetEntryByID(dbStore, id) // Refactored
 		if err != nil {
-			if err == sql.ErrNoRows || strings.Contains(err.Error(), "no entry found") { // Check for custom error from GetEntryByID
+			if errors.Is(err, chronos.ErrNotFound) {
 				log.Error("Entry not found", "ID", id)
 			} else {
 				log.Error("Failed to get entry", "ID", id, "error", err)
@@ -212,9 +203,18 @@ var editCmd = &cobra.Command{
 			return fmt.Errorf("could not retrieve entry %d: %w", id, err)
 		}
 
+		// Entries on an invoice are released by voiding it, not here, so
+		// they cannot be billed twice.
+		inv, err := chronos.EntryInvoice(dbStore, id)
+		if err != nil {
+			return fmt.Errorf("could not check invoices for entry %d: %w", id, err)
+		}
+		if inv != nil {
+			return fmt.Errorf("entry %d is on invoice %s; void the invoice to release it", id, inv.Label())
+		}
+
 		// For demo: just toggle invoiced status
 		entry.Invoiced = !entry.Invoiced
-		entry.UpdatedAt = time.Now() // Update timestamp
 
 		if err := chronos.UpdateEntry(dbStore, entry); err != nil { // Refactored
 			log.Error("Failed to update entry", "ID", id, "error", err)
@@ -230,13 +230,9 @@ var deleteCmd = &cobra.Command{
 	Short: "Delete a time entry by ID",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		dbPath := "chronos.db"
-		dbStore, err := db.NewStore(dbPath)
+		dbStore, err := openStore()
 		if err != nil {
-			return fmt.Errorf("db store: %w", err)
-		}
-		if err := dbStore.InitSchema(); err != nil { // Ensure schema for DeleteEntry
-			return fmt.Errorf("schema init: %w", err)
+			return err
 		}
 		id, err := strconv.ParseInt(args[0], 10, 64)
 		if err != nil {
@@ -263,11 +259,12 @@ var pomodoroCmd = &cobra.Command{
 				dur = d
 			}
 		}
-		// Pomodoro UI and logic for creating entry after completion is in ui.PomodoroModel.
-		// This part doesn't directly use chronos CRUD yet, but ui.PomodoroModel might internally.
-		// If ui.PomodoroModel needs db.Store, it should be passed.
-		// For now, assuming it handles its own DB interaction or this will be refactored later.
-		model := ui.NewPomodoroModel(dur) // This might need dbStore if it creates an entry.
+		dbStore, err := openStore()
+		if err != nil {
+			return err
+		}
+		// The completed session is logged as an entry by ui.PomodoroModel.
+		model := ui.NewPomodoroModel(dbStore, dur)
 		p := tea.NewProgram(model)
 		return p.Start()
 	},
@@ -277,21 +274,17 @@ var idleCmd = &cobra.Command{
 	Use:   "idle-detect",
 	Short: "Detect idle gaps and suggest logging missed time",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		dbPath := "chronos.db"
-		dbStore, err := db.NewStore(dbPath)
+		dbStore, err := openStore()
 		if err != nil {
-			return fmt.Errorf("db store: %w", err)
-		}
-		if err := dbStore.InitSchema(); err != nil {
-			return fmt.Errorf("schema init: %w", err)
+			return err
 		}
 		// Fetch all entries. DetectIdleGaps will sort them.
-		entries, err := chronos.ListEntries(dbStore, nil) 
+		entries, err := chronos.ListEntries(dbStore, nil)
 		if err != nil {
 			return fmt.Errorf("failed to list entries for idle detection: %w", err)
 		}
 
-		if len(entries) < 2 {
+		if len(entries) == 0 {
 			log.Warn("Not enough entries to detect idle gaps.")
 			return nil
 		}
@@ -306,102 +299,122 @@ var idleCmd = &cobra.Command{
 
 		log.Info("Detected Idle Gaps (longer than %v):", minGapDuration)
 		for _, gap := range idleGaps {
-			log.Warn(fmt.Sprintf("Gap from %s to %s (Duration: %v)",
+			msg := fmt.Sprintf("Gap from %s to %s (Duration: %v)",
 				gap.StartTime.Format("2006-01-02 15:04"),
 				gap.EndTime.Format("2006-01-02 15:04"),
 				gap.Duration.Round(time.Minute), // Rounded for cleaner output
-			))
+			)
+			if gap.Break {
+				log.Info(msg + " [recorded break]")
+				continue
+			}
+			log.Warn(msg)
 		}
 		return nil
 	},
 }
 
-var rateCmd = &cobra.Command{
-	Use:   "rate [amount]",
-	Short: "Quickly set your default hourly rate (config file)",
-	Args:  cobra.ExactArgs(1),
+var analyticsCmd = &cobra.Command{
+	Use:   "analytics",
+	Short: "Show client/project/task analytics",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		cfg, err := config.LoadConfig("chronos.json")
+		dbStore, err := openStore()
 		if err != nil {
 			return err
 		}
-		rate, err := strconv.ParseFloat(args[0], 64)
+		entries, err := chronos.ListEntries(dbStore, nil)
 		if err != nil {
-			return err
+			return fmt.Errorf("failed to list entries: %w", err)
 		}
-		cfg.DefaultRate = rate
-		return config.SaveConfig("chronos.json", cfg)
-	},
-}
 
-var analyticsCmd = &cobra.Command{
-	Use:   "analytics",
-	Short: "Show client/project analytics (limited functionality post-refactor)",
-	RunE: func(cmd *cobra.Command, args []string) error {
-		dbPath := "chronos.db"
-		dbStore, err := db.NewStore(dbPath)
-		if err != nil {
-			return fmt.Errorf("db store: %w", err)
-		}
-		if err := dbStore.InitSchema(); err != nil {
-			return fmt.Errorf("schema init: %w", err)
+		groupings := []struct {
+			title string
+			key   func(*chronos.Entry) string
+		}{
+			{"Project", func(e *chronos.Entry) string { return e.Project }},
+			{"Client", func(e *chronos.Entry) string { return e.Client }},
+			{"Task", func(e *chronos.Entry) string { return e.Task }},
 		}
-		entries, err := chronos.ListEntries(dbStore, nil)
+		currencies, err := billHistory(dbStore, entries)
 		if err != nil {
-			return fmt.Errorf("failed to list entries: %w", err)
+			return err
+		}
+		for _, g := range groupings {
+			log.Info(fmt.Sprintf("%s Totals (Hours):", g.title))
+			billed := chronos.CalculateBilledTotalsBy(entries, g.key)
+			for name, totalMinutes := range chronos.CalculateTotalsBy(entries, g.key) {
+				if billed[name] != totalMinutes {
+					log.Info(fmt.Sprintf("- %s: %.2f hours (billed %.2f)", name, totalMinutes/60.0, billed[name]/60.0))
+					continue
+				}
+				log.Info(fmt.Sprintf("- %s: %.2f hours", name, totalMinutes/60.0))
+			}
 		}
 
-		log.Info("Project Totals (Hours per ProjectID):")
-		projectTotalsMinutes := chronos.CalculateProjectTotalsByProjectID(entries)
-		for projectID, totalMinutes := range projectTotalsMinutes {
-			// TODO: Enhance output by fetching project name using chronos.GetProjectByID(dbStore, projectID)
-			log.Info(fmt.Sprintf("- ProjectID %d: %.2f hours", projectID, totalMinutes/60.0))
+		// Revenue stays in the currency it was billed in; the home total
+		// converts each entry at the exchange rate of its day.
+		revenue := currencies.Revenue(entries)
+		if len(revenue.ByCurrency) == 0 {
+			return nil
+		}
+		log.Info("Revenue:")
+		for _, t := range revenue.ByCurrency {
+			log.Info(fmt.Sprintf("- %s: %s", t.Currency, chronos.FormatMoney(t.Amount, t.Currency)))
+		}
+		log.Info(fmt.Sprintf("Total in %s: %s", revenue.HomeCode, chronos.FormatMoney(revenue.Home, revenue.HomeCode)))
+		if len(revenue.Unconverted) > 0 {
+			log.Warn(fmt.Sprintf("No exchange rate for %s; those amounts are left out of the %s total. Add one with 'chronos currency rate'.",
+				strings.Join(revenue.Unconverted, ", "), revenue.HomeCode))
 		}
-		
-		log.Warn("Client and Task specific analytics are not available with the current chronos.Entry structure.")
-		log.Warn("To re-enable, chronos.Entry would need direct Client/Task fields or advanced parsing/lookups.")
 		return nil
 	},
 }
 
 var reviewCmd = &cobra.Command{
 	Use:   "review [period]",
-	Short: "Generate a weekly or monthly review (limited functionality post-refactor)",
+	Short: "Generate a weekly or monthly review of your work",
 	Args:  cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		period := "week"
 		if len(args) > 0 {
 			period = args[0]
 		}
-		dbPath := "chronos.db"
-		dbStore, err := db.NewStore(dbPath)
+		dbStore, err := openStore()
 		if err != nil {
-			return fmt.Errorf("db store: %w", err)
-		}
-		if err := dbStore.InitSchema(); err != nil {
-			return fmt.Errorf("schema init: %w", err)
+			return err
 		}
 
-		var sinceFilter time.Time
+		var since llm.Period
 		if period == "month" {
-			sinceFilter = time.Now().AddDate(0, -1, 0)
+			since = llm.LastDays(time.Now(), 30)
 		} else { // Default to week
-			sinceFilter = time.Now().AddDate(0, 0, -7)
+			period = "week"
+			since = llm.LastDays(time.Now(), 7)
 		}
-		
-		// chronos.ListEntries filter for date range: map[string]interface{}{"start_date": sinceFilter}
-		// This gets entries that started *on or after* sinceFilter.
-		// We might want to filter entries client-side as well if ListEntries doesn't support precise range.
+
 		allEntries, err := chronos.ListEntries(dbStore, nil) // Fetch all, then filter by date client-side for review period
 		if err != nil {
 			return fmt.Errorf("failed to list entries for review: %w", err)
 		}
+		blocks, err := chronos.ListBlocks(dbStore, nil)
+		if err != nil {
+			return fmt.Errorf("failed to list blocks for review: %w", err)
+		}
 
-		totalMinutesInPeriod := chronos.CalculateReviewPeriodTotals(allEntries, sinceFilter)
-		
+		totalMinutesInPeriod := chronos.CalculateReviewPeriodTotals(allEntries, since.From)
 		log.Info(fmt.Sprintf("%s review: %.2f hours", strings.Title(period), totalMinutesInPeriod/60.0))
-		log.Warn("LLM summary and detailed earnings/task breakdown need rework due to struct changes.")
-		// TODO: Add LLM summary and export as Markdown
+
+		record := chronos.Interaction{Kind: chronos.KindReview, Question: period + " review", ContextHash: chronos.ContextHash(allEntries, blocks)}
+		err = askLLM(cmd, dbStore, record, "Reviewing the "+since.Label+"...", func(ctx context.Context, llmClient *llm.Client) (string, error) {
+			if llmNoCache {
+				llmClient.Cache = nil
+			}
+			return llmClient.ReviewPeriod(ctx, since, allEntries, blocks)
+		})
+		if err != nil {
+			// The totals above are still useful without a model.
+			log.Warn("No LLM review", "error", err)
+		}
 		return nil
 	},
 }
@@ -412,16 +425,9 @@ var templateCmd = &cobra.Command{
 	Short: "Save or use an entry template/snippet",
 	Args:  cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		dbPath := "chronos.db"
-		dbStore, err := db.NewStore(dbPath)
+		dbStore, err := openStore()
 		if err != nil {
-			return fmt.Errorf("db store: %w", err)
-		}
-		// Note: InitSchema() might also call EnsureTemplatesTable if we integrate it there,
-		// but explicit call from chronos.SaveTemplate/GetTemplate is safer for direct usage.
-		// For this command, InitSchema is generally good practice if other parts of dbStore are used.
-		if err := dbStore.InitSchema(); err != nil {
-			return fmt.Errorf("schema init: %w", err)
+			return err
 		}
 
 		if len(args) == 1 { // Get template
@@ -454,51 +460,60 @@ var templateCmd = &cobra.Command{
 
 var invoiceSmartCmd = &cobra.Command{
 	Use:   "invoice-smart",
-	Short: "Detect unbilled entries and mark as invoiced",
+	Short: "Put all unbilled billable entries on draft invoices, one per client",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		dbPath := "chronos.db"
-		dbStore, err := db.NewStore(dbPath)
+		dbStore, err := openStore()
 		if err != nil {
-			return fmt.Errorf("db store: %w", err)
-		}
-		if err := dbStore.InitSchema(); err != nil {
-			return fmt.Errorf("schema init: %w", err)
+			return err
 		}
 
-		// Find unbilled entries
-		unbilledEntries, err := chronos.ListEntries(dbStore, map[string]interface{}{"invoiced": false})
+		// invoice-smart has no --client or --block, so this is every unbilled
+		// billable entry, with its rate, rounding and currency.
+		unbilledEntries, err := invoiceEntries(cmd, dbStore)
 		if err != nil {
 			return fmt.Errorf("failed to find unbilled entries: %w", err)
 		}
-
 		if len(unbilledEntries) == 0 {
 			log.Info("No unbilled entries found.")
 			return nil
 		}
 
-		var successfullyMarkedCount int
-		var errorMessages []string
-
-		for _, entry := range unbilledEntries {
-			entry.Invoiced = true
-			entry.UpdatedAt = time.Now()
-			if errUpdate := chronos.UpdateEntry(dbStore, entry); errUpdate != nil {
-				errMsg := fmt.Sprintf("Failed to mark entry ID %d as invoiced: %v", entry.ID, errUpdate)
-				log.Error(errMsg)
-				errorMessages = append(errorMessages, errMsg)
-			} else {
-				successfullyMarkedCount++
+		// One draft per client, and per currency when a client's projects
+		// bill in different ones.
+		type draftKey struct{ client, currency string }
+		byDraft := map[draftKey][]*chronos.Entry{}
+		var keys []draftKey
+		for _, e := range unbilledEntries {
+			key := draftKey{e.Client, e.Currency}
+			if _, ok := byDraft[key]; !ok {
+				keys = append(keys, key)
 			}
+			byDraft[key] = append(byDraft[key], e)
 		}
-
-		log.Info(fmt.Sprintf("Marked %d entries as invoiced.", successfullyMarkedCount))
-		if len(errorMessages) > 0 {
-			log.Error("Some entries could not be marked as invoiced:")
-			for _, msg := range errorMessages {
-				log.Error(fmt.Sprintf("- %s", msg))
+		sort.Slice(keys, func(i, j int) bool {
+			if keys[i].client != keys[j].client {
+				return keys[i].client < keys[j].client
+			}
+			return keys[i].currency < keys[j].currency
+		})
+
+		var markedCount, draftCount int
+		for _, key := range keys {
+			entries := byDraft[key]
+			inv := &chronos.Invoice{Client: key.client, Currency: key.currency, Lines: chronos.GroupInvoiceLines(entries)}
+			if err := chronos.CreateInvoice(dbStore, inv); err != nil {
+				return fmt.Errorf("create invoice for %q: %w", key.client, err)
 			}
-			return fmt.Errorf("%d entries failed to update", len(errorMessages))
+			markedCount += len(entries)
+			draftCount++
+			client := key.client
+			if client == "" {
+				client = "no client"
+			}
+			log.Info(fmt.Sprintf("Draft invoice #%d for %s: %d entries, %s.", inv.ID, client, len(entries), money(inv.Total(), inv.Currency)))
 		}
+
+		log.Info(fmt.Sprintf("Marked %d entries as invoiced on %d draft invoices.", markedCount, draftCount))
 		return nil
 	},
 }
@@ -508,19 +523,29 @@ var doctorCmd = &cobra.Command{
 	Short: "Check Chronos dependencies and environment",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		log.Info("Chronos Doctor: Checking system dependencies...")
-		// Check Ollama
-		ollamaPath, err := exec.LookPath("ollama")
+		// Check the LLM server over HTTP; it may be remote or in a container.
+		envCfg, _ := config.LoadEnvConfig()
+		provider, err := llm.NewProvider(envCfg)
 		if err != nil {
-			log.Error("Ollama not found in PATH.")
-			log.Info("Install Ollama: https://ollama.com/download or use your package manager.")
-		} else {
-			log.Info("Ollama found: %s", ollamaPath)
-			// Try running ollama list
-			_, err := exec.Command("ollama", "list").CombinedOutput()
-			if err != nil {
-				log.Error("Ollama is installed but not responding. Try restarting the Ollama service.")
-			} else {
-				log.Info("Ollama is responding.")
+			log.Error(err.Error())
+		} else if lister, ok := provider.(llm.ModelLister); ok {
+			model := envCfg.LLMModel
+			if model == "" && envCfg.LLMProvider == llm.ProviderOllama {
+				model = envCfg.OllamaModel
+			}
+			models, err := lister.Models(cmd.Context())
+			switch {
+			case errors.Is(err, llm.ErrServerDown):
+				log.Error(fmt.Sprintf("The %s server is not reachable: %v", envCfg.LLMProvider, err))
+				log.Info("Start it (e.g. 'ollama serve'), install Ollama from https://ollama.com/download, or set LLM_PROVIDER/LLM_HOST in .env.")
+			case err != nil:
+				log.Error(fmt.Sprintf("The %s server returned an error: %v", envCfg.LLMProvider, err))
+			default:
+				log.Info(fmt.Sprintf("The %s server is responding (%d model(s) available).", envCfg.LLMProvider, len(models)))
+				// Ollama lists untagged models as name:latest.
+				if model != "" && !slices.Contains(models, model) && !slices.Contains(models, model+":latest") {
+					log.Warn(fmt.Sprintf("Model %s is not available on the server.", model))
+				}
 			}
 		}
 		// Check SQLite3
@@ -532,10 +557,21 @@ var doctorCmd = &cobra.Command{
 			log.Info("sqlite3 CLI found.")
 		}
 		// Check DB file
-		if _, errDbFile := os.Stat("chronos.db"); os.IsNotExist(errDbFile) {
-			log.Warn("chronos.db not found. It will be created on first use.")
+		dbPath, err := resolveDBPath()
+		if err != nil {
+			return err
+		}
+		if _, errDbFile := os.Stat(dbPath); os.IsNotExist(errDbFile) {
+			log.Warn(fmt.Sprintf("Database not found at %s. It will be created on first use.", dbPath))
 		} else {
-			log.Info("chronos.db found.")
+			log.Info(fmt.Sprintf("Database found at %s.", dbPath))
+		}
+		// Older builds kept chronos.db in the working directory.
+		legacy, _ := filepath.Abs(config.DBFileName)
+		if current, _ := filepath.Abs(dbPath); legacy != current {
+			if _, errLegacy := os.Stat(config.DBFileName); errLegacy == nil {
+				log.Warn(fmt.Sprintf("Found %s in the current directory. Use --db %s or move it to %s.", config.DBFileName, config.DBFileName, dbPath))
+			}
 		}
 		// Check .env
 		envPath := config.FindEnvPath()
@@ -563,17 +599,19 @@ func Execute() {
 
 func init() {
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle") // Example global flag
+	rootCmd.PersistentFlags().StringVar(&dbFlag, "db", "", "Path to the database file (default $XDG_DATA_HOME/chronos/chronos.db, or $CHRONOS_DB)")
+	rootCmd.PersistentFlags().StringVar(&profileFlag, "profile", "", "Named profile with its own database and config (or $CHRONOS_PROFILE)")
+	askCmd.Flags().BoolVar(&askShowTrace, "show-trace", false, "Show which tools the model called")
+	queryCmd.Flags().BoolVar(&askShowTrace, "show-trace", false, "Show which tools the model called")
 	rootCmd.AddCommand(askCmd)
 	rootCmd.AddCommand(suggestCmd)
 	rootCmd.AddCommand(queryCmd)
 	rootCmd.AddCommand(remindCmd)
-	rootCmd.AddCommand(historyCmd)
 	rootCmd.AddCommand(completeCmd)
 	rootCmd.AddCommand(editCmd)
 	rootCmd.AddCommand(deleteCmd)
 	rootCmd.AddCommand(pomodoroCmd)
 	rootCmd.AddCommand(idleCmd)
-	rootCmd.AddCommand(rateCmd)
 	rootCmd.AddCommand(analyticsCmd)
 	rootCmd.AddCommand(reviewCmd)
 	rootCmd.AddCommand(templateCmd)
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/regiellis/chronos-go/chronos"
+	"github.com/regiellis/chronos-go/utils"
+	"github.com/spf13/cobra"
+)
+
+// roundingCmd manages how clients and projects round billed time. Invoices,
+// invoice views and analytics bill the rounded time; tracked time is never
+// changed.
+var roundingCmd = &cobra.Command{
+	Use:   "rounding",
+	Short: "Set how a client or project rounds billed time",
+}
+
+var roundingSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Set the rounding rule of a project or client",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		project, _ := cmd.Flags().GetString("project")
+		client, _ := cmd.Flags().GetString("client")
+		rule := &chronos.RoundingRule{}
+		switch {
+		case project != "" && client != "":
+			return fmt.Errorf("use only one of --project and --client")
+		case project != "":
+			rule.Scope, rule.Target = chronos.RateProject, utils.SanitizeString(project)
+		case client != "":
+			rule.Scope, rule.Target = chronos.RateClient, utils.SanitizeString(client)
+		default:
+			return fmt.Errorf("--project or --client is required")
+		}
+		rule.Increment, _ = cmd.Flags().GetInt64("increment")
+		rule.Direction, _ = cmd.Flags().GetString("direction")
+		rule.Per, _ = cmd.Flags().GetString("per")
+		rule.Minimum, _ = cmd.Flags().GetInt64("minimum")
+
+		dbStore, err := openStore()
+		if err != nil {
+			return err
+		}
+		if err := chronos.SaveRoundingRule(dbStore, rule); err != nil {
+			return err
+		}
+		fmt.Println(utils.SuccessStyle.Render(fmt.Sprintf("%s %s now bills %s.", rule.Scope, rule.Target, rule)))
+		return nil
+	},
+}
+
+var roundingListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List rounding rules",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dbStore, err := openStore()
+		if err != nil {
+			return err
+		}
+		rules, err := chronos.ListRoundingRules(dbStore)
+		if err != nil {
+			return err
+		}
+		if len(rules) == 0 {
+			fmt.Println(utils.InactiveStyle.Render("No rounding rules; time is billed as tracked. Add one with 'chronos rounding set'."))
+			return nil
+		}
+		for _, r := range rules {
+			fmt.Printf("%s %s\n",
+				utils.LabelStyle.Render(fmt.Sprintf("%4d  %-28s", r.ID, truncate(r.Scope+" "+r.Target, 28))),
+				utils.ValueStyle.Render(r.String()))
+		}
+		return nil
+	},
+}
+
+var roundingRemoveCmd = &cobra.Command{
+	Use:   "remove [id]",
+	Short: "Remove a rounding rule",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid rule ID %q: %w", args[0], err)
+		}
+		dbStore, err := openStore()
+		if err != nil {
+			return err
+		}
+		if err := chronos.DeleteRoundingRule(dbStore, id); err != nil {
+			return err
+		}
+		fmt.Println(utils.SuccessStyle.Render(fmt.Sprintf("Rounding rule %d removed.", id)))
+		return nil
+	},
+}
+
+func init() {
+	roundingSetCmd.Flags().String("project", "", "Project the rule applies to")
+	roundingSetCmd.Flags().String("client", "", "Client the rule applies to")
+	roundingSetCmd.Flags().Int64("increment", 0, "Round to this many minutes (0 bills the time as tracked)")
+	roundingSetCmd.Flags().String("direction", chronos.RoundUp, "Round up, down or nearest")
+	roundingSetCmd.Flags().String("per", chronos.RoundPerEntry, "Round each entry, each day's total or the invoice total (entry, day, invoice)")
+	roundingSetCmd.Flags().Int64("minimum", 0, "Minimum minutes billed per entry, day or invoice")
+
+	roundingCmd.AddCommand(roundingSetCmd)
+	roundingCmd.AddCommand(roundingListCmd)
+	roundingCmd.AddCommand(roundingRemoveCmd)
+	rootCmd.AddCommand(roundingCmd)
+}
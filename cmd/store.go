@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/regiellis/chronos-go/config"
+	"github.com/regiellis/chronos-go/db"
+)
+
+var (
+	dbFlag      string
+	profileFlag string
+)
+
+// activeProfile returns the profile selected by --profile or CHRONOS_PROFILE.
+func activeProfile() (string, error) {
+	profile := profileFlag
+	if profile == "" {
+		profile = os.Getenv("CHRONOS_PROFILE")
+	}
+	if err := config.ValidateProfile(profile); err != nil {
+		return "", err
+	}
+	return profile, nil
+}
+
+// resolveDBPath returns the database path for this invocation and makes sure
+// its directory exists.
+func resolveDBPath() (string, error) {
+	profile, err := activeProfile()
+	if err != nil {
+		return "", err
+	}
+	path := config.DBPath(dbFlag, profile)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", fmt.Errorf("create data directory: %w", err)
+	}
+	return path, nil
+}
+
+// userConfigPath returns the chronos.json path for the active profile.
+func userConfigPath() (string, error) {
+	profile, err := activeProfile()
+	if err != nil {
+		return "", err
+	}
+	return config.UserConfigPath(profile), nil
+}
+
+// openStore opens the database for this invocation and applies pending migrations.
+func openStore() (*db.Store, error) {
+	path, err := resolveDBPath()
+	if err != nil {
+		return nil, err
+	}
+	dbStore, err := db.NewStore(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create db store: %w", err)
+	}
+	if err := dbStore.InitSchema(); err != nil {
+		return nil, fmt.Errorf("failed to initialize schema: %w", err)
+	}
+	return dbStore, nil
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	log "github.com/charmbracelet/log"
+	"github.com/mattn/go-isatty"
+	"github.com/regiellis/chronos-go/chronos"
+	"github.com/regiellis/chronos-go/llm"
+	"github.com/regiellis/chronos-go/ui"
+	"github.com/regiellis/chronos-go/utils"
+	"github.com/spf13/cobra"
+)
+
+// newLLMClient builds the configured LLM client and teaches its redactor the
+// client and project names in repo.
+func newLLMClient(repo chronos.Repository) (*llm.Client, error) {
+	client, err := llm.NewClient()
+	if err != nil {
+		return nil, err
+	}
+	if err := client.Redactor.Learn(repo); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// streamLLM runs an LLM call and shows its output as it is generated: in the
+// streaming markdown view on a terminal, as plain text when piped. Ctrl+C
+// cancels the request and is not reported as an error; the answer is then "".
+func streamLLM(cmd *cobra.Command, repo chronos.Repository, title string, call func(ctx context.Context, client *llm.Client) (string, error)) (string, error) {
+	client, err := newLLMClient(repo)
+	if err != nil {
+		return "", err
+	}
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+	defer stop()
+
+	var answer string
+	if isatty.IsTerminal(os.Stdout.Fd()) {
+		answer, err = ui.RunStream(ctx, title, func(ctx context.Context, onToken func(string)) (string, error) {
+			client.OnToken = onToken
+			return call(ctx, client)
+		})
+		fmt.Println()
+	} else {
+		client.OnToken = func(tok string) { fmt.Print(tok) }
+		answer, err = call(ctx, client)
+		fmt.Println()
+	}
+	if errors.Is(err, context.Canceled) {
+		fmt.Println(utils.WarningStyle.Render("Cancelled."))
+		return "", nil
+	}
+	return answer, err
+}
+
+// askLLM streams an assistant answer like streamLLM and saves it to the
+// history along with record's kind, question and context hash.
+func askLLM(cmd *cobra.Command, repo chronos.Repository, record chronos.Interaction, title string, call func(ctx context.Context, client *llm.Client) (string, error)) error {
+	var model string
+	answer, err := streamLLM(cmd, repo, title, func(ctx context.Context, client *llm.Client) (string, error) {
+		model = llm.ModelName(client.Provider)
+		return call(ctx, client)
+	})
+	if err != nil || strings.TrimSpace(answer) == "" {
+		return err
+	}
+	record.Answer, record.Model = answer, model
+	if err := chronos.SaveInteraction(repo, &record); err != nil {
+		log.Warn("Could not save the answer to history", "error", err)
+	}
+	return nil
+}
@@ -1,9 +1,10 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 
-	"github.com/regiellis/chronos-go/db"
+	"github.com/regiellis/chronos-go/chronos"
 	"github.com/regiellis/chronos-go/llm"
 	"github.com/regiellis/chronos-go/utils"
 	"github.com/spf13/cobra"
@@ -13,15 +14,16 @@ var summarizeCmd = &cobra.Command{
 	Use:   "summarize",
 	Short: "Get an LLM-generated summary for a block",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		dbPath := "chronos.db"
-		dbStore, err := db.NewStore(dbPath)
+		dbStore, err := openStore()
 		if err != nil {
 			return err
 		}
-		if err := dbStore.InitSchema(); err != nil {
-			return err
+		var block *chronos.Block
+		if blockID, _ := cmd.Flags().GetInt64("block"); blockID > 0 {
+			block, err = chronos.GetBlockByID(dbStore, blockID)
+		} else {
+			block, err = chronos.GetActiveBlock(dbStore)
 		}
-		block, err := dbStore.GetActiveBlock()
 		if err != nil {
 			return err
 		}
@@ -29,21 +31,27 @@ var summarizeCmd = &cobra.Command{
 			fmt.Println(utils.ErrorStyle.Render("No active block."))
 			return nil
 		}
-		entries, err := dbStore.ListEntries(map[string]interface{}{"block_id": block.ID})
-		if err != nil {
-			return err
-		}
-		llmClient := &llm.OllamaClient{Model: "llama2:7b"}
-		summary, err := llmClient.SummarizeBlock(block, entries)
+		entries, err := chronos.ListEntries(dbStore, map[string]interface{}{"block_id": block.ID})
 		if err != nil {
 			return err
 		}
 		fmt.Println(utils.TitleStyle.Render("Block Summary"))
-		fmt.Println(utils.LLMStyle.Render(summary))
-		return nil
+		record := chronos.Interaction{Kind: chronos.KindSummarize, Question: "Summarize block " + block.Name, ContextHash: chronos.ContextHash(entries, []*chronos.Block{block}), BlockID: block.ID}
+		return askLLM(cmd, dbStore, record, "Summarizing "+block.Name+"...", func(ctx context.Context, llmClient *llm.Client) (string, error) {
+			if llmNoCache {
+				llmClient.Cache = nil
+			}
+			return llmClient.SummarizeBlock(ctx, block, entries)
+		})
 	},
 }
 
+// llmNoCache bypasses the reply cache for summarize and review.
+var llmNoCache bool
+
 func init() {
+	summarizeCmd.Flags().BoolVar(&llmNoCache, "no-cache", false, "Ask the model again instead of reusing a cached summary")
+	summarizeCmd.Flags().Int64("block", 0, "Block ID to summarize (default: the active block)")
+	reviewCmd.Flags().BoolVar(&llmNoCache, "no-cache", false, "Ask the model again instead of reusing a cached review")
 	rootCmd.AddCommand(summarizeCmd)
 }
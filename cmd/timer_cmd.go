@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/regiellis/chronos-go/chronos"
+	"github.com/regiellis/chronos-go/utils"
+	"github.com/spf13/cobra"
+)
+
+var startCmd = &cobra.Command{
+	Use:   "start [project]",
+	Short: "Start a live timer for a project",
+	Long:  "Start a live timer. The timer is stored as an open entry, so it keeps running across restarts until 'chronos stop'.",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dbStore, err := openStore()
+		if err != nil {
+			return err
+		}
+		task, _ := cmd.Flags().GetString("task")
+		client, _ := cmd.Flags().GetString("client")
+		summary, _ := cmd.Flags().GetString("summary")
+		billable, _ := cmd.Flags().GetBool("billable")
+
+		entry := &chronos.Entry{
+			Client:   client,
+			Task:     task,
+			Summary:  summary,
+			Billable: billable,
+		}
+		if len(args) > 0 {
+			entry.Project = args[0]
+		}
+		// Without a project the timer belongs to the active block's project.
+		if entry.Project == "" {
+			if block, _ := chronos.GetActiveBlock(dbStore); block != nil {
+				entry.Project = block.Project
+			}
+		}
+
+		if err := chronos.StartTimer(dbStore, entry, time.Now()); err != nil {
+			if errors.Is(err, chronos.ErrTimerRunning) {
+				fmt.Println(utils.WarningStyle.Render("A timer is already running. Use 'chronos stop' first."))
+				return printTimer(dbStore)
+			}
+			return fmt.Errorf("failed to start timer: %w", err)
+		}
+		fmt.Println(utils.SuccessStyle.Render("Timer started!"))
+		fmt.Println(utils.EntryStyle.Render(timerLabel(entry) + "\nStarted: " + entry.StartTime.Format("15:04")))
+		return nil
+	},
+}
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the running timer and elapsed time",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dbStore, err := openStore()
+		if err != nil {
+			return err
+		}
+		return printTimer(dbStore)
+	},
+}
+
+var stopCmd = &cobra.Command{
+	Use:   "stop [summary]",
+	Short: "Stop the running timer and attach it to the active block",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dbStore, err := openStore()
+		if err != nil {
+			return err
+		}
+		if len(args) > 0 {
+			running, err := chronos.RunningTimer(dbStore)
+			if err != nil {
+				return err
+			}
+			if running != nil {
+				running.Summary = strings.Join(args, " ")
+				if err := chronos.UpdateEntry(dbStore, running); err != nil {
+					return fmt.Errorf("failed to update timer summary: %w", err)
+				}
+			}
+		}
+
+		entry, err := chronos.StopTimer(dbStore, time.Now())
+		if err != nil {
+			if errors.Is(err, chronos.ErrNoTimer) {
+				fmt.Println(utils.InfoStyle.Render("No timer is running."))
+				return nil
+			}
+			return fmt.Errorf("failed to stop timer: %w", err)
+		}
+		fmt.Println(utils.SuccessStyle.Render("Timer stopped!"))
+		details := fmt.Sprintf("ID: %d\n%s\nBlockID: %d\nDuration: %s",
+			entry.ID, timerLabel(entry), entry.BlockID, formatElapsed(entry.Elapsed(time.Now())))
+		if len(entry.Breaks) > 0 {
+			details += fmt.Sprintf("\nBreaks: %s (excluded)", formatElapsed(entry.BreakTime(entry.EndTime)))
+		}
+		fmt.Println(utils.EntryStyle.Render(details))
+		return nil
+	},
+}
+
+var pauseCmd = &cobra.Command{
+	Use:   "pause",
+	Short: "Pause the running timer and start a break",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dbStore, err := openStore()
+		if err != nil {
+			return err
+		}
+		entry, err := chronos.PauseTimer(dbStore, time.Now())
+		if err != nil {
+			switch {
+			case errors.Is(err, chronos.ErrNoTimer):
+				fmt.Println(utils.InfoStyle.Render("No timer is running."))
+				return nil
+			case errors.Is(err, chronos.ErrTimerPaused):
+				fmt.Println(utils.InfoStyle.Render("The timer is already paused. Use 'chronos resume' to continue."))
+				return nil
+			}
+			return fmt.Errorf("failed to pause timer: %w", err)
+		}
+		fmt.Println(utils.SuccessStyle.Render("Timer paused."))
+		fmt.Println(utils.EntryStyle.Render(fmt.Sprintf("%s\nWorked so far: %s",
+			timerLabel(entry), formatElapsed(entry.Elapsed(time.Now())))))
+		return nil
+	},
+}
+
+var resumeCmd = &cobra.Command{
+	Use:   "resume",
+	Short: "End a break, or start a new timer for the last entry's project and task",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dbStore, err := openStore()
+		if err != nil {
+			return err
+		}
+		entry, err := chronos.ResumeTimer(dbStore, time.Now())
+		if err != nil {
+			switch {
+			case errors.Is(err, chronos.ErrTimerRunning):
+				fmt.Println(utils.WarningStyle.Render("A timer is already running."))
+				return printTimer(dbStore)
+			case errors.Is(err, chronos.ErrNothingToResume):
+				fmt.Println(utils.InfoStyle.Render("Nothing to resume yet. Use 'chronos start' to begin a timer."))
+				return nil
+			}
+			return fmt.Errorf("failed to resume timer: %w", err)
+		}
+		fmt.Println(utils.SuccessStyle.Render("Timer resumed!"))
+		if len(entry.Breaks) > 0 {
+			last := entry.Breaks[len(entry.Breaks)-1]
+			fmt.Println(utils.EntryStyle.Render(fmt.Sprintf("%s\nBreak: %s\nWorked so far: %s",
+				timerLabel(entry), formatElapsed(last.EndTime.Sub(last.StartTime)), formatElapsed(entry.Elapsed(time.Now())))))
+			return nil
+		}
+		fmt.Println(utils.EntryStyle.Render(timerLabel(entry) + "\nStarted: " + entry.StartTime.Format("15:04")))
+		return nil
+	},
+}
+
+// printTimer renders the running timer, or a note that none is running.
+func printTimer(repo chronos.Repository) error {
+	entry, err := chronos.RunningTimer(repo)
+	if err != nil {
+		return fmt.Errorf("failed to get running timer: %w", err)
+	}
+	if entry == nil {
+		fmt.Println(utils.InfoStyle.Render("No timer is running."))
+		return nil
+	}
+	now := time.Now()
+	if entry.IsPaused() {
+		last := entry.Breaks[len(entry.Breaks)-1]
+		fmt.Println(utils.WarningStyle.Render("Timer paused since " + last.StartTime.Format("15:04")))
+	} else {
+		fmt.Println(utils.ActiveStyle.Render("Timer running"))
+	}
+	details := fmt.Sprintf("%s\nStarted: %s\nElapsed: %s",
+		timerLabel(entry), entry.StartTime.Format("2006-01-02 15:04"), formatElapsed(entry.Elapsed(now)))
+	if len(entry.Breaks) > 0 {
+		details += fmt.Sprintf("\nBreaks: %d (%s)", len(entry.Breaks), formatElapsed(entry.BreakTime(now)))
+	}
+	fmt.Println(utils.EntryStyle.Render(details))
+	return nil
+}
+
+func timerLabel(e *chronos.Entry) string {
+	label := "Project: " + e.Project
+	if e.Task != "" {
+		label += "\nTask: " + e.Task
+	}
+	if e.Client != "" {
+		label += "\nClient: " + e.Client
+	}
+	if e.Summary != "" {
+		label += "\nSummary: " + e.Summary
+	}
+	return label
+}
+
+// formatElapsed renders a duration as 1h05m or 12m30s.
+func formatElapsed(d time.Duration) string {
+	d = d.Round(time.Second)
+	if d >= time.Hour {
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+}
+
+func init() {
+	startCmd.Flags().String("task", "", "Task name (optional)")
+	startCmd.Flags().String("client", "", "Client name (optional)")
+	startCmd.Flags().String("summary", "", "Short description (optional)")
+	startCmd.Flags().Bool("billable", true, "Mark the entry as billable")
+	rootCmd.AddCommand(startCmd)
+	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(stopCmd)
+	rootCmd.AddCommand(pauseCmd)
+	rootCmd.AddCommand(resumeCmd)
+}
@@ -1,24 +1,26 @@
-package main
+package cmd
 
 import (
-	"os"
-
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/regiellis/chronos-go/db"
 	"github.com/regiellis/chronos-go/ui"
+	"github.com/spf13/cobra"
 )
 
-func main() {
-	dbStore, err := db.NewStore("chronos.db")
-	if err != nil {
-		panic(err)
-	}
-	if err := dbStore.InitSchema(); err != nil {
-		panic(err)
-	}
-	model := ui.NewMainMenuModel(dbStore)
-	p := tea.NewProgram(model)
-	if _, err := p.Run(); err != nil {
-		os.Exit(1)
-	}
+var tuiCmd = &cobra.Command{
+	Use:   "tui",
+	Short: "Open the interactive Chronos TUI",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dbStore, err := openStore()
+		if err != nil {
+			return err
+		}
+		model := ui.NewMainMenuModel(dbStore)
+		p := tea.NewProgram(model)
+		_, err = p.Run()
+		return err
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(tuiCmd)
 }
@@ -1,13 +1,14 @@
 package cmd
 
 import (
-	"database/sql"
 	"fmt"
+	"os"
+	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-isatty"
 	"github.com/regiellis/chronos-go/chronos" // Imported chronos
-	"github.com/regiellis/chronos-go/db"
 	"github.com/regiellis/chronos-go/ui"
 	"github.com/regiellis/chronos-go/utils"
 	"github.com/spf13/cobra"
@@ -22,42 +23,42 @@ var viewBlockCmd = &cobra.Command{
 	Use:   "block",
 	Short: "Show the active block and its progress",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		dbPath := "chronos.db"
-		dbStore, err := db.NewStore(dbPath)
+		dbStore, err := openStore()
 		if err != nil {
-			return fmt.Errorf("failed to create db store: %w", err)
-		}
-		if err := dbStore.InitSchema(); err != nil {
-			return fmt.Errorf("failed to initialize schema: %w", err)
+			return err
 		}
 
 		// Use new chronos function
 		activeBlock, err := chronos.GetActiveBlock(dbStore)
 		if err != nil {
-			// sql.ErrNoRows is a valid case where no block is active, not necessarily a fatal error.
-			if err == sql.ErrNoRows {
-				fmt.Println(utils.InfoStyle.Render("No active block."))
-				return nil
-			}
 			return fmt.Errorf("failed to get active block: %w", err)
 		}
-		if activeBlock == nil { // Should be covered by sql.ErrNoRows, but good for safety
+		if activeBlock == nil {
 			fmt.Println(utils.InfoStyle.Render("No active block."))
 			return nil
 		}
 
-		fmt.Println(utils.TitleStyle.Render("Active Block"))
-		// Assuming chronos.Block struct fields are compatible with this display.
-		// chronos.Block has Name, Client (string), Project (string), StartTime, EndTime.
+		entries, err := chronos.ListEntries(dbStore, map[string]interface{}{"block_id": activeBlock.ID})
+		if err != nil {
+			return fmt.Errorf("failed to list block entries: %w", err)
+		}
+		var loggedMinutes float64
+		for _, e := range entries {
+			loggedMinutes += e.Minutes()
+		}
+
+		fmt.Println(utils.TitleStyle.Render("Active block"))
 		fmt.Println(utils.EntryStyle.Render(
-			fmt.Sprintf("ID: %d\nName: %s\nClient: %s\nProject: %s\nStart: %s\nEnd: %s\nActive: %t",
-				activeBlock.ID, // Added ID
+			fmt.Sprintf("ID: %d\nName: %s\nClient: %s\nProject: %s\nStart: %s\nEnd: %s\nActive: %t\nEntries: %d\nLogged: %.2f hours",
+				activeBlock.ID,
 				utils.SanitizeString(activeBlock.Name),
-				utils.SanitizeString(activeBlock.Client),   // Assumes Client is a string name
-				utils.SanitizeString(activeBlock.Project), // Assumes Project is a string name
-				activeBlock.StartTime.Format("2006-01-02 15:04"), // More precise time
-				activeBlock.EndTime.Format("2006-01-02 15:04"),   // More precise time
-				activeBlock.Active, // Added Active status
+				utils.SanitizeString(activeBlock.Client),
+				utils.SanitizeString(activeBlock.Project),
+				activeBlock.StartTime.Format("2006-01-02 15:04"),
+				activeBlock.EndTime.Format("2006-01-02 15:04"),
+				activeBlock.Active,
+				len(entries),
+				loggedMinutes/60.0,
 			),
 		))
 		return nil
@@ -66,37 +67,46 @@ var viewBlockCmd = &cobra.Command{
 
 var (
 	filterBlockID  int64
-	filterProject  string // Project name
-	filterClient   string // Client name
-	// filterTask     string // Task filtering is not directly supported by new ListEntries; would require summary LIKE '%task%'
+	filterProject  string
+	filterClient   string
+	filterTask     string
+	filterTag      string
 	filterFrom     string
 	filterTo       string
-	// filterMinDur   int64 // Duration filters not directly supported; require calculation from Start/End
-	// filterMaxDur   int64
-	filterBillable bool // Maps to "invoiced"
-	// filterMinRate  float64 // Rate is not on chronos.Entry; requires joining with Project or other logic
-	// filterMaxRate  float64
+	filterMinDur   int64
+	filterMaxDur   int64
+	filterBillable bool
+	filterMinRate  float64
+	filterMaxRate  float64
 )
 
 var viewListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "Show a list of time entries (filterable)",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		dbPath := "chronos.db"
-		dbStore, err := db.NewStore(dbPath)
+		dbStore, err := openStore()
 		if err != nil {
-			return fmt.Errorf("failed to create db store: %w", err)
-		}
-		if err := dbStore.InitSchema(); err != nil {
-			return fmt.Errorf("failed to initialize schema: %w", err)
+			return err
 		}
 
 		chronosFilters := map[string]interface{}{}
 		if filterBlockID > 0 {
 			chronosFilters["block_id"] = filterBlockID
 		}
-		if filterBillable { // Assuming "billable" flag maps to "invoiced" status
-			chronosFilters["invoiced"] = true // Or false, depending on desired logic if flag could mean "only unbilled"
+		if filterProject != "" {
+			chronosFilters["project"] = utils.SanitizeString(filterProject)
+		}
+		if filterClient != "" {
+			chronosFilters["client"] = utils.SanitizeString(filterClient)
+		}
+		if filterTask != "" {
+			chronosFilters["task"] = utils.SanitizeString(filterTask)
+		}
+		if filterTag != "" {
+			chronosFilters["tag"] = utils.SanitizeString(strings.TrimPrefix(filterTag, "#"))
+		}
+		if filterBillable {
+			chronosFilters["billable"] = true
 		}
 		if filterFrom != "" {
 			if t, errDate := time.Parse("2006-01-02", filterFrom); errDate == nil {
@@ -112,151 +122,191 @@ var viewListCmd = &cobra.Command{
 				fmt.Printf("Warning: could not parse 'to' date '%s': %v\n", filterTo, errDate)
 			}
 		}
-		
-		// TODO: Filtering by Project Name (filterProject) requires a lookup:
-		// if filterProject != "" {
-		//   project, err := chronos.GetProjectByName(dbStore, filterProject) // Hypothetical function
-		//   if err == nil && project != nil {
-		//     chronosFilters["project_id"] = project.ID
-		//   } else {
-		//     fmt.Printf("Warning: Project '%s' not found, filter not applied.\n", filterProject)
-		//   }
-		// }
-		// Client, Task, Duration, and Rate filters are not directly supported by the new chronos.ListEntries.
-		// These would require more complex logic (e.g., joining tables, post-filtering, or enhancing ListEntries).
+		if filterMinDur > 0 {
+			chronosFilters["min_duration"] = filterMinDur
+		}
+		if filterMaxDur > 0 {
+			chronosFilters["max_duration"] = filterMaxDur
+		}
+		if filterMinRate > 0 {
+			chronosFilters["min_rate"] = filterMinRate
+		}
+		if filterMaxRate > 0 {
+			chronosFilters["max_rate"] = filterMaxRate
+		}
 
 		entries, err := chronos.ListEntries(dbStore, chronosFilters)
 		if err != nil {
 			return fmt.Errorf("failed to list entries: %w", err)
 		}
 
-		// Assuming ui.NewListViewModel is compatible with []*chronos.Entry
 		model := ui.NewListViewModel(entries)
+		if !isatty.IsTerminal(os.Stdout.Fd()) {
+			// Piped or scripted output: render once instead of starting the interactive list.
+			fmt.Println(model.View())
+			return nil
+		}
 		p := tea.NewProgram(model)
-		return p.Start()
+		_, err = p.Run()
+		return err
 	},
 }
 
-// Note: The invoice commands below will be significantly impacted by chronos.Entry struct changes.
-// chronos.Entry (ID, BlockID, ProjectID, Summary, StartTime, EndTime, CreatedAt, UpdatedAt, Invoiced)
-// does not have direct fields for Rate, Duration (must be calculated), Project (name), Task (name).
-// These commands will need substantial rework to fetch related data (e.g., Project details for rate)
-// or the chronos.Entry struct/ListEntries function needs to be enhanced.
+// invoiceEntries returns the unbilled, billable entries for the --block/--client flags of an invoice view,
+// with their effective rates and rounded, billed time filled in.
+func invoiceEntries(cmd *cobra.Command, repo chronos.Repository) ([]*chronos.Entry, error) {
+	blockID, _ := cmd.Flags().GetInt64("block")
+	clientName, _ := cmd.Flags().GetString("client")
+
+	chronosFilters := map[string]interface{}{"billable": true, "invoiced": false, "open": false}
+	if blockID > 0 {
+		chronosFilters["block_id"] = blockID
+	}
+	if clientName != "" {
+		chronosFilters["client"] = utils.SanitizeString(clientName)
+	}
+	entries, err := chronos.ListEntries(repo, chronosFilters)
+	if err != nil {
+		return nil, err
+	}
+	rates, err := newRateResolver(repo)
+	if err != nil {
+		return nil, err
+	}
+	rates.Apply(entries)
+	rounder, err := chronos.NewRounder(repo)
+	if err != nil {
+		return nil, err
+	}
+	rounder.Apply(entries)
+	currencies, err := newCurrencies(repo)
+	if err != nil {
+		return nil, err
+	}
+	currencies.Apply(entries)
+	return entries, nil
+}
+
+// billHistory fills in the rate, billed time and currency of entries from
+// any period, as reports show them: entries on an invoice as that invoice
+// billed them, the rest by today's rates and rules. It returns the
+// currencies it used.
+func billHistory(repo chronos.Repository, entries []*chronos.Entry) (*chronos.Currencies, error) {
+	rates, err := newRateResolver(repo)
+	if err != nil {
+		return nil, err
+	}
+	rates.Apply(entries)
+	rounder, err := chronos.NewRounder(repo)
+	if err != nil {
+		return nil, err
+	}
+	rounder.ApplyHistory(entries)
+	currencies, err := newCurrencies(repo)
+	if err != nil {
+		return nil, err
+	}
+	currencies.Apply(entries)
+	invoices, err := chronos.ListInvoices(repo, nil)
+	if err != nil {
+		return nil, err
+	}
+	chronos.ApplyInvoiced(entries, invoices)
+	return currencies, nil
+}
 
 var viewInvoiceCmd = &cobra.Command{
 	Use:   "invoice",
-	Short: "Show invoice-ready summary (functionality limited post-refactor)",
+	Short: "Show invoice-ready summary of unbilled entries",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		dbPath := "chronos.db"
-		dbStore, err := db.NewStore(dbPath)
-		if err != nil { return fmt.Errorf("db store: %w", err) }
-		if err := dbStore.InitSchema(); err != nil { return fmt.Errorf("schema init: %w", err) }
-
-		blockID, _ := cmd.Flags().GetInt64("block")
-		// clientName, _ := cmd.Flags().GetString("client") // Client name filtering is complex now
-
-		chronosFilters := map[string]interface{}{"invoiced": false} // Typically invoice un-invoiced entries
-		if blockID > 0 {
-			chronosFilters["block_id"] = blockID
+		dbStore, err := openStore()
+		if err != nil {
+			return err
 		}
-		// TODO: Add project filtering if needed, via project ID lookup.
-
-		entries, err := chronos.ListEntries(dbStore, chronosFilters)
-		if err != nil { return fmt.Errorf("list entries: %w", err) }
 
-		var totalMinutes float64
-		var totalAmount float64
-		fmt.Println(utils.WarningStyle.Render("Warning: Invoice calculation may be inaccurate due to missing Rate and direct Duration fields in refactored Entry struct."))
+		entries, err := invoiceEntries(cmd, dbStore)
+		if err != nil {
+			return fmt.Errorf("list entries: %w", err)
+		}
 
+		var trackedMinutes, billedMinutes float64
 		for _, e := range entries {
-			duration := e.EndTime.Sub(e.StartTime).Minutes()
-			totalMinutes += duration
-			// TODO: Need to fetch Project Rate for accurate billing. Using placeholder rate 0.
-			// project, _ := chronos.GetProjectByID(dbStore, e.ProjectID)
-			// rate := project.Rate (if project is not nil)
-			rate := 0.0 // Placeholder
-			totalAmount += (duration / 60.0) * rate
+			trackedMinutes += e.Minutes()
+			billedMinutes += e.BilledMinutes()
+		}
+		totals, currency, err := previewTotals(cmd, dbStore, entries, chronos.GroupInvoiceLines(entries))
+		if err != nil {
+			return err
 		}
 
-		fmt.Println(utils.TitleStyle.Render("Invoice Summary (Limited)"))
-		fmt.Println(utils.EntryStyle.Render(
-			fmt.Sprintf("Billable entries: %d\nTotal hours: %.2f\nTotal amount: $%.2f (Rate calculation needs rework)",
-				len(entries), totalMinutes/60.0, totalAmount,
-			),
-		))
+		summary := fmt.Sprintf("Billable entries: %d\nTracked hours: %.2f\nBilled hours: %.2f\n", len(entries), trackedMinutes/60.0, billedMinutes/60.0)
+		if len(totals.Discounts) > 0 || len(totals.Taxes) > 0 {
+			summary += fmt.Sprintf("Subtotal: %s\n", money(totals.Subtotal, currency))
+			for _, a := range append(totals.Discounts, totals.Taxes...) {
+				summary += fmt.Sprintf("%s: %s\n", adjustmentLabel(a), money(a.Amount, currency))
+			}
+		}
+		summary += fmt.Sprintf("Total amount: %s", money(totals.Total, currency))
+		fmt.Println(utils.TitleStyle.Render("Invoice Summary"))
+		fmt.Println(utils.EntryStyle.Render(summary))
 		return nil
 	},
 }
 
 var invoiceMDViewCmd = &cobra.Command{
 	Use:   "invoice-md",
-	Short: "Render invoice as Markdown (functionality limited post-refactor)",
+	Short: "Render invoice as Markdown",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		dbPath := "chronos.db"
-		dbStore, err := db.NewStore(dbPath)
-		if err != nil { return fmt.Errorf("db store: %w", err) }
-		if err := dbStore.InitSchema(); err != nil { return fmt.Errorf("schema init: %w", err) }
-
-		blockID, _ := cmd.Flags().GetInt64("block")
-		// clientName, _ := cmd.Flags().GetString("client")
-
-		chronosFilters := map[string]interface{}{"invoiced": false}
-		if blockID > 0 {
-			chronosFilters["block_id"] = blockID
+		dbStore, err := openStore()
+		if err != nil {
+			return err
 		}
 
-		entries, err := chronos.ListEntries(dbStore, chronosFilters)
-		if err != nil { return fmt.Errorf("list entries: %w", err) }
-		
-		fmt.Println(utils.WarningStyle.Render("Warning: Invoice Markdown generation may be inaccurate or incomplete."))
+		entries, err := invoiceEntries(cmd, dbStore)
+		if err != nil {
+			return fmt.Errorf("list entries: %w", err)
+		}
 
-		var totalMinutes float64
-		var totalAmount float64
-		md := "# Invoice (Limited)\n\n| Project ID | Summary | Hours | Rate | Amount |\n|---|---|---|---|---|\n"
+		var trackedMinutes, billedMinutes float64
+		md := "# Invoice\n\n| Project | Task | Description | Tracked | Hours | Rate | Amount |\n|---|---|---|---|---|---|---|\n"
 		for _, e := range entries {
-			hours := e.EndTime.Sub(e.StartTime).Minutes() / 60.0
-			totalMinutes += hours * 60
-			// TODO: Fetch Project & Rate. Using placeholders.
-			// project, _ := chronos.GetProjectByID(dbStore, e.ProjectID)
-			// projectName := "N/A" (if project is nil else project.Name)
-			// rate := 0.0 (if project is nil else project.Rate)
-			projectName := fmt.Sprintf("ProjID %d", e.ProjectID)
-			rate := 0.0 // Placeholder
-			amt := hours * rate
-			totalAmount += amt
-			// Summary is the main text. Task/Description separation is gone from chronos.Entry.
-			md += fmt.Sprintf("| %s | %s | %.2f | %.2f | %.2f |\n", projectName, e.Summary, hours, rate, amt)
+			trackedMinutes += e.Minutes()
+			billedMinutes += e.BilledMinutes()
+			md += fmt.Sprintf("| %s | %s | %s | %.2f | %.2f | %.2f | %.2f |\n", e.Project, e.Task, e.Summary, e.Hours(), e.BilledHours(), e.Rate, e.Amount())
 		}
-		md += fmt.Sprintf("\n**Total Hours:** %.2f\n**Total Amount:** $%.2f (Rate calculation needs rework)\n", totalMinutes/60.0, totalAmount)
-		
-		fmt.Println(utils.TitleStyle.Render("Invoice (Markdown Preview - Limited)"))
+		totals, currency, err := previewTotals(cmd, dbStore, entries, chronos.GroupInvoiceLines(entries))
+		if err != nil {
+			return err
+		}
+		md += fmt.Sprintf("\n**Tracked Hours:** %.2f\n**Total Hours:** %.2f\n%s", trackedMinutes/60.0, billedMinutes/60.0, totalsMarkdown(totals, currency))
+
+		fmt.Println(utils.TitleStyle.Render("Invoice (Markdown Preview)"))
 		fmt.Println(md) // In a real scenario, this would go through Glamour or similar.
 		return nil
 	},
 }
 
-
 func init() {
 	// Flags for viewListCmd
 	viewListCmd.Flags().Int64Var(&filterBlockID, "block", 0, "Filter by block ID")
-	viewListCmd.Flags().StringVar(&filterProject, "project", "", "Filter by project name (requires lookup)")
-	viewListCmd.Flags().StringVar(&filterClient, "client", "", "Filter by client name (currently not supported)")
-	// viewListCmd.Flags().StringVar(&filterTask, "task", "", "Filter by task (currently not supported)")
+	viewListCmd.Flags().StringVar(&filterProject, "project", "", "Filter by project name")
+	viewListCmd.Flags().StringVar(&filterClient, "client", "", "Filter by client name")
+	viewListCmd.Flags().StringVar(&filterTask, "task", "", "Filter by task")
+	viewListCmd.Flags().StringVar(&filterTag, "tag", "", "Filter by tag")
 	viewListCmd.Flags().StringVar(&filterFrom, "from", "", "Filter from date (YYYY-MM-DD)")
 	viewListCmd.Flags().StringVar(&filterTo, "to", "", "Filter to date (YYYY-MM-DD)")
-	// viewListCmd.Flags().Int64Var(&filterMinDur, "min-duration", 0, "Filter by minimum duration (minutes, currently not supported)")
-	// viewListCmd.Flags().Int64Var(&filterMaxDur, "max-duration", 0, "Filter by maximum duration (minutes, currently not supported)")
-	viewListCmd.Flags().BoolVar(&filterBillable, "billable", false, "Show only 'billable' (invoiced=true or invoiced=false based on interpretation) entries")
-	// viewListCmd.Flags().Float64Var(&filterMinRate, "min-rate", 0, "Filter by minimum rate (currently not supported)")
-	// viewListCmd.Flags().Float64Var(&filterMaxRate, "max-rate", 0, "Filter by maximum rate (currently not supported)")
-
+	viewListCmd.Flags().Int64Var(&filterMinDur, "min-duration", 0, "Filter by minimum duration (minutes)")
+	viewListCmd.Flags().Int64Var(&filterMaxDur, "max-duration", 0, "Filter by maximum duration (minutes)")
+	viewListCmd.Flags().BoolVar(&filterBillable, "billable", false, "Show only billable entries")
+	viewListCmd.Flags().Float64Var(&filterMinRate, "min-rate", 0, "Filter by minimum rate")
+	viewListCmd.Flags().Float64Var(&filterMaxRate, "max-rate", 0, "Filter by maximum rate")
 
 	viewInvoiceCmd.Flags().Int64("block", 0, "Block ID to invoice")
-	viewInvoiceCmd.Flags().String("client", "", "Client to invoice (currently not directly supported for filtering)")
+	viewInvoiceCmd.Flags().String("client", "", "Client to invoice")
 	viewCmd.AddCommand(viewInvoiceCmd)
 
 	invoiceMDViewCmd.Flags().Int64("block", 0, "Block ID to invoice")
-	invoiceMDViewCmd.Flags().String("client", "", "Client to invoice (currently not directly supported for filtering)")
+	invoiceMDViewCmd.Flags().String("client", "", "Client to invoice")
 	viewCmd.AddCommand(invoiceMDViewCmd)
 
 	viewCmd.AddCommand(viewBlockCmd)
@@ -6,18 +6,64 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 )
 
+// UserConfig holds the per-profile settings in chronos.json. The invoice
+// settings number issued invoices (InvoiceNumberFormat, e.g.
+// "INV-{YYYY}-{NNNN}", counting from InvoiceStartNumber) and set how many days
+// they are due after issue; zero values mean the defaults. Currency is the
+// home currency, which amounts without a project or client currency are in
+// and reports convert totals into (USD when empty).
 type UserConfig struct {
 	DefaultRate     float64 `json:"default_rate"`
 	DefaultBillable bool    `json:"default_billable"`
 	Theme           string  `json:"theme"`
+	Currency        string  `json:"currency,omitempty"`
+
+	InvoiceNumberFormat string `json:"invoice_number_format,omitempty"`
+	InvoiceStartNumber  int64  `json:"invoice_start_number,omitempty"`
+	InvoiceDueDays      int    `json:"invoice_due_days,omitempty"`
 }
 
-// EnvConfig holds LLM/Ollama config
+// EnvConfig holds LLM config. LLMProvider picks the backend ("ollama",
+// "llamacpp" or "openai"); LLMHost and LLMModel override the Ollama values
+// for whichever backend is selected. LLMContextBudget caps, in tokens, the
+// time tracking data sent with assistant prompts (0 means the default).
+// LLMRedact lists what to hide from the model (see RedactFor) and
+// LLMRedactPattern is an extra regular expression to strip. LLMCacheTTL is how
+// long cached summaries are reused (0 means the default).
 type EnvConfig struct {
 	OllamaHost  string
 	OllamaModel string
+	LLMProvider string
+	LLMHost     string
+	LLMModel    string
+	LLMAPIKey   string
+
+	LLMContextBudget int
+
+	LLMRedact         string
+	LLMRedactPattern  string
+	LLMRedactProvider map[string]string
+
+	LLMCacheTTL time.Duration
+}
+
+// envKeys are the variables LoadEnvConfig reads.
+var envKeys = []string{"OLLAMA_HOST", "OLLAMA_MODEL", "LLM_PROVIDER", "LLM_HOST", "LLM_MODEL", "LLM_API_KEY", "LLM_CONTEXT_BUDGET",
+	"LLM_REDACT", "LLM_REDACT_PATTERN", "LLM_REDACT_OLLAMA", "LLM_REDACT_LLAMACPP", "LLM_REDACT_OPENAI", "LLM_CACHE_TTL"}
+
+// RedactFor returns the redaction setting for provider: LLM_REDACT_<PROVIDER>
+// when set (so a hosted API can be redacted while a local model is not),
+// otherwise LLM_REDACT.
+func (c *EnvConfig) RedactFor(provider string) string {
+	if v, ok := c.LLMRedactProvider[strings.ToLower(provider)]; ok {
+		return v
+	}
+	return c.LLMRedact
 }
 
 func LoadConfig(path string) (*UserConfig, error) {
@@ -34,6 +80,9 @@ func LoadConfig(path string) (*UserConfig, error) {
 }
 
 func SaveConfig(path string, cfg *UserConfig) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
 	f, err := os.Create(path)
 	if err != nil {
 		return err
@@ -58,37 +107,69 @@ func FindEnvPath() string {
 	return cfgPaths[0] // default location
 }
 
-// LoadEnvConfig loads Ollama config from .env
+// LoadEnvConfig loads LLM config from .env; variables set in the process
+// environment take precedence over the file.
 func LoadEnvConfig() (*EnvConfig, error) {
-	path := FindEnvPath()
-	f, err := os.Open(path)
-	if err != nil {
-		return &EnvConfig{OllamaHost: "http://localhost:11434", OllamaModel: "llama2:7b"}, nil
-	}
-	defer f.Close()
-	cfg := &EnvConfig{}
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if len(line) == 0 || line[0] == '#' {
-			continue
+	cfg := &EnvConfig{LLMRedactProvider: map[string]string{}}
+	set := func(k, v string) {
+		switch k {
+		case "OLLAMA_HOST":
+			cfg.OllamaHost = v
+		case "OLLAMA_MODEL":
+			cfg.OllamaModel = v
+		case "LLM_PROVIDER":
+			cfg.LLMProvider = strings.ToLower(v)
+		case "LLM_HOST":
+			cfg.LLMHost = v
+		case "LLM_MODEL":
+			cfg.LLMModel = v
+		case "LLM_API_KEY":
+			cfg.LLMAPIKey = v
+		case "LLM_CONTEXT_BUDGET":
+			if n, err := strconv.Atoi(v); err == nil && n > 0 {
+				cfg.LLMContextBudget = n
+			}
+		case "LLM_REDACT":
+			cfg.LLMRedact = v
+		case "LLM_REDACT_PATTERN":
+			cfg.LLMRedactPattern = v
+		case "LLM_REDACT_OLLAMA", "LLM_REDACT_LLAMACPP", "LLM_REDACT_OPENAI":
+			cfg.LLMRedactProvider[strings.ToLower(strings.TrimPrefix(k, "LLM_REDACT_"))] = v
+		case "LLM_CACHE_TTL":
+			if d, err := time.ParseDuration(v); err == nil && d > 0 {
+				cfg.LLMCacheTTL = d
+			}
 		}
-		var k, v string
-		if n, _ := fmt.Sscanf(line, "%[^=]=%s", &k, &v); n == 2 {
-			switch k {
-			case "OLLAMA_HOST":
-				cfg.OllamaHost = v
-			case "OLLAMA_MODEL":
-				cfg.OllamaModel = v
+	}
+
+	if f, err := os.Open(FindEnvPath()); err == nil {
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if len(line) == 0 || line[0] == '#' {
+				continue
+			}
+			if k, v, ok := strings.Cut(line, "="); ok {
+				set(strings.TrimSpace(k), strings.Trim(strings.TrimSpace(v), `"'`))
 			}
 		}
+		f.Close()
+	}
+	for _, k := range envKeys {
+		if v := os.Getenv(k); v != "" {
+			set(k, v)
+		}
 	}
+
 	if cfg.OllamaHost == "" {
 		cfg.OllamaHost = "http://localhost:11434"
 	}
 	if cfg.OllamaModel == "" {
 		cfg.OllamaModel = "llama2:7b"
 	}
+	if cfg.LLMProvider == "" {
+		cfg.LLMProvider = "ollama"
+	}
 	return cfg, nil
 }
 
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadEnvConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	for _, k := range envKeys {
+		t.Setenv(k, "")
+	}
+	dir := filepath.Join(home, ".config", "chronos")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	env := "# local server\nLLM_PROVIDER=OpenAI\nLLM_HOST=http://localhost:1234\nLLM_MODEL=\"mistral-7b\"\nLLM_CONTEXT_BUDGET=3000\nLLM_REDACT=emails\nLLM_REDACT_OPENAI=all\n"
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte(env), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadEnvConfig()
+	if err != nil {
+		t.Fatalf("LoadEnvConfig: %v", err)
+	}
+	if cfg.LLMProvider != "openai" || cfg.LLMHost != "http://localhost:1234" || cfg.LLMModel != "mistral-7b" || cfg.LLMContextBudget != 3000 {
+		t.Errorf("file values not loaded: %+v", cfg)
+	}
+	if cfg.OllamaHost != "http://localhost:11434" || cfg.OllamaModel != "llama2:7b" {
+		t.Errorf("Ollama defaults not applied: %+v", cfg)
+	}
+	if cfg.RedactFor("openai") != "all" || cfg.RedactFor("ollama") != "emails" {
+		t.Errorf("per-provider redaction not applied: %+v", cfg)
+	}
+
+	t.Setenv("LLM_MODEL", "qwen2")
+	if cfg, _ = LoadEnvConfig(); cfg.LLMModel != "qwen2" {
+		t.Errorf("environment should win over .env, got %q", cfg.LLMModel)
+	}
+}
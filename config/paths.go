@@ -0,0 +1,89 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DBFileName is the database file created inside a profile's data directory.
+const DBFileName = "chronos.db"
+
+// UserConfigFileName is the user config file inside a profile's config directory.
+const UserConfigFileName = "chronos.json"
+
+// ValidateProfile rejects profile names that cannot be used as a directory name.
+// The empty string selects the default profile.
+func ValidateProfile(profile string) error {
+	if profile == "" {
+		return nil
+	}
+	if profile == "." || profile == ".." || strings.ContainsAny(profile, `/\`) {
+		return fmt.Errorf("invalid profile name %q", profile)
+	}
+	return nil
+}
+
+// xdgDir returns $<envVar>/chronos, falling back to ~/<fallback>/chronos.
+func xdgDir(envVar string, fallback ...string) string {
+	base := os.Getenv(envVar)
+	if base == "" || !filepath.IsAbs(base) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			home = "."
+		}
+		base = filepath.Join(append([]string{home}, fallback...)...)
+	}
+	return filepath.Join(base, "chronos")
+}
+
+func withProfile(dir, profile string) string {
+	if profile == "" {
+		return dir
+	}
+	return filepath.Join(dir, "profiles", profile)
+}
+
+// DataDir returns where a profile keeps its database:
+// $XDG_DATA_HOME/chronos (default ~/.local/share/chronos), with named
+// profiles under profiles/<name>.
+func DataDir(profile string) string {
+	return withProfile(xdgDir("XDG_DATA_HOME", ".local", "share"), profile)
+}
+
+// ConfigDir returns where a profile keeps its config:
+// $XDG_CONFIG_HOME/chronos (default ~/.config/chronos), with named
+// profiles under profiles/<name>.
+func ConfigDir(profile string) string {
+	return withProfile(xdgDir("XDG_CONFIG_HOME", ".config"), profile)
+}
+
+// PromptsDir returns where prompt template overrides live: the prompts
+// directory of the default profile's config, shared by all profiles.
+func PromptsDir() string {
+	return filepath.Join(ConfigDir(""), "prompts")
+}
+
+// LLMCacheDir returns where cached LLM replies live:
+// $XDG_CACHE_HOME/chronos/llm (default ~/.cache/chronos/llm).
+func LLMCacheDir() string {
+	return filepath.Join(xdgDir("XDG_CACHE_HOME", ".cache"), "llm")
+}
+
+// DBPath resolves the database path. An explicit path (the --db flag) wins,
+// then the CHRONOS_DB environment variable, then the profile's data directory.
+func DBPath(explicit, profile string) string {
+	if explicit != "" {
+		return explicit
+	}
+	if env := os.Getenv("CHRONOS_DB"); env != "" {
+		return env
+	}
+	return filepath.Join(DataDir(profile), DBFileName)
+}
+
+// UserConfigPath returns the path of a profile's chronos.json.
+func UserConfigPath(profile string) string {
+	return filepath.Join(ConfigDir(profile), UserConfigFileName)
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDBPathPrecedence(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", "/data")
+	t.Setenv("CHRONOS_DB", "")
+
+	if got := DBPath("", ""); got != filepath.Join("/data", "chronos", "chronos.db") {
+		t.Errorf("default: got %s", got)
+	}
+	if got := DBPath("", "work"); got != filepath.Join("/data", "chronos", "profiles", "work", "chronos.db") {
+		t.Errorf("profile: got %s", got)
+	}
+
+	t.Setenv("CHRONOS_DB", "/env/chronos.db")
+	if got := DBPath("", "work"); got != "/env/chronos.db" {
+		t.Errorf("env: got %s", got)
+	}
+	if got := DBPath("./flag.db", "work"); got != "./flag.db" {
+		t.Errorf("flag: got %s", got)
+	}
+}
+
+func TestXDGFallbackToHome(t *testing.T) {
+	t.Setenv("HOME", "/home/tester")
+	t.Setenv("XDG_DATA_HOME", "")
+	t.Setenv("XDG_CONFIG_HOME", "relative/ignored")
+
+	if got := DataDir(""); got != filepath.Join("/home/tester", ".local", "share", "chronos") {
+		t.Errorf("DataDir: got %s", got)
+	}
+	if got := UserConfigPath("personal"); got != filepath.Join("/home/tester", ".config", "chronos", "profiles", "personal", "chronos.json") {
+		t.Errorf("UserConfigPath: got %s", got)
+	}
+}
+
+func TestValidateProfile(t *testing.T) {
+	for _, ok := range []string{"", "work", "client-a"} {
+		if err := ValidateProfile(ok); err != nil {
+			t.Errorf("ValidateProfile(%q) = %v", ok, err)
+		}
+	}
+	for _, bad := range []string{"..", "a/b", `a\b`} {
+		if err := ValidateProfile(bad); err == nil {
+			t.Errorf("ValidateProfile(%q) should fail", bad)
+		}
+	}
+}
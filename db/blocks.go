@@ -0,0 +1,166 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/regiellis/chronos-go/chronos"
+)
+
+const blockColumns = `id, name, client, project, start_time, end_time, active, created_at, updated_at`
+
+func scanBlock(row rowScanner) (*chronos.Block, error) {
+	block := &chronos.Block{}
+	// Handle potential NULL EndTime / UpdatedAt from DB
+	var endTime, updatedAt sql.NullTime
+	err := row.Scan(
+		&block.ID, &block.Name, &block.Client, &block.Project,
+		&block.StartTime, &endTime, &block.Active, &block.CreatedAt, &updatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if endTime.Valid {
+		block.EndTime = endTime.Time
+	}
+	if updatedAt.Valid {
+		block.UpdatedAt = updatedAt.Time
+	}
+	return block, nil
+}
+
+// CreateBlock inserts a new block and sets its ID.
+func (s *Store) CreateBlock(block *chronos.Block) error {
+	query := `
+		INSERT INTO blocks (name, client, project, start_time, end_time, active, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	res, err := s.DB.Exec(query, block.Name, block.Client, block.Project, block.StartTime, nullTime(block.EndTime), block.Active, block.CreatedAt, block.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("CreateBlock: failed to execute insert: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("CreateBlock: failed to get last insert ID: %w", err)
+	}
+	block.ID = id
+	return nil
+}
+
+// GetBlock retrieves a block by its ID.
+func (s *Store) GetBlock(id int64) (*chronos.Block, error) {
+	query := `SELECT ` + blockColumns + ` FROM blocks WHERE id = ?`
+	block, err := scanBlock(s.DB.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("GetBlock: no block found with ID %d: %w", id, chronos.ErrNotFound)
+		}
+		return nil, fmt.Errorf("GetBlock: failed to scan row: %w", err)
+	}
+	return block, nil
+}
+
+// UpdateBlock updates an existing block.
+func (s *Store) UpdateBlock(block *chronos.Block) error {
+	query := `
+		UPDATE blocks
+		SET name = ?, client = ?, project = ?, start_time = ?, end_time = ?, active = ?, updated_at = ?
+		WHERE id = ?`
+	_, err := s.DB.Exec(query, block.Name, block.Client, block.Project, block.StartTime, nullTime(block.EndTime), block.Active, block.UpdatedAt, block.ID)
+	if err != nil {
+		return fmt.Errorf("UpdateBlock: failed to execute update: %w", err)
+	}
+	return nil
+}
+
+// DeleteBlock removes a block by its ID.
+func (s *Store) DeleteBlock(id int64) error {
+	_, err := s.DB.Exec("DELETE FROM blocks WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("DeleteBlock: failed to execute delete: %w", err)
+	}
+	return nil
+}
+
+// ListBlocks retrieves blocks, newest first, optionally filtered.
+// See chronos.ListBlocks for the supported filter keys.
+func (s *Store) ListBlocks(filters map[string]interface{}) ([]*chronos.Block, error) {
+	baseQuery := "SELECT " + blockColumns + " FROM blocks"
+	var conditions []string
+	var args []interface{}
+
+	for key, value := range filters {
+		switch key {
+		case "active", "client", "project":
+			conditions = append(conditions, key+" = ?")
+			args = append(args, value)
+		case "start_date": // Blocks started on or after this date
+			conditions = append(conditions, "date(start_time) >= date(?)")
+			args = append(args, value)
+		case "end_date": // Blocks started on or before this date
+			conditions = append(conditions, "date(start_time) <= date(?)")
+			args = append(args, value)
+		}
+	}
+
+	query := baseQuery
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY start_time DESC, id DESC"
+
+	rows, err := s.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ListBlocks: failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	blocks := []*chronos.Block{}
+	for rows.Next() {
+		block, err := scanBlock(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ListBlocks: failed to scan row: %w", err)
+		}
+		blocks = append(blocks, block)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ListBlocks: error during rows iteration: %w", err)
+	}
+	return blocks, nil
+}
+
+// GetActiveBlock retrieves the currently active block, or nil if there is none.
+func (s *Store) GetActiveBlock() (*chronos.Block, error) {
+	query := `SELECT ` + blockColumns + ` FROM blocks WHERE active = TRUE LIMIT 1`
+	block, err := scanBlock(s.DB.QueryRow(query))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No active block is not an error in this context
+		}
+		return nil, fmt.Errorf("GetActiveBlock: failed to scan row: %w", err)
+	}
+	return block, nil
+}
+
+// SetActiveBlock activates a block and deactivates all others in one transaction.
+func (s *Store) SetActiveBlock(id int64) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("SetActiveBlock: failed to begin transaction: %w", err)
+	}
+
+	_, err = tx.Exec("UPDATE blocks SET active = FALSE, updated_at = ? WHERE active = TRUE", time.Now())
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("SetActiveBlock: failed to deactivate other blocks: %w", err)
+	}
+
+	_, err = tx.Exec("UPDATE blocks SET active = TRUE, updated_at = ? WHERE id = ?", time.Now(), id)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("SetActiveBlock: failed to activate block ID %d: %w", id, err)
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,70 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/regiellis/chronos-go/chronos"
+)
+
+// CreateBreak inserts a break for an entry and sets its ID.
+func (s *Store) CreateBreak(b *chronos.Break) error {
+	res, err := s.DB.Exec(`INSERT INTO entry_breaks (entry_id, start_time, end_time) VALUES (?, ?, ?)`,
+		b.EntryID, b.StartTime, nullTime(b.EndTime))
+	if err != nil {
+		return fmt.Errorf("CreateBreak: failed to execute insert: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("CreateBreak: failed to get last insert ID: %w", err)
+	}
+	b.ID = id
+	return nil
+}
+
+// UpdateBreak updates the times of an existing break.
+func (s *Store) UpdateBreak(b *chronos.Break) error {
+	_, err := s.DB.Exec(`UPDATE entry_breaks SET start_time = ?, end_time = ? WHERE id = ?`,
+		b.StartTime, nullTime(b.EndTime), b.ID)
+	if err != nil {
+		return fmt.Errorf("UpdateBreak: failed to execute update: %w", err)
+	}
+	return nil
+}
+
+// attachBreaks loads the breaks of the given entries into Entry.Breaks in one query.
+func (s *Store) attachBreaks(entries []*chronos.Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	byID := make(map[int64]*chronos.Entry, len(entries))
+	placeholders := make([]string, 0, len(entries))
+	args := make([]interface{}, 0, len(entries))
+	for _, e := range entries {
+		byID[e.ID] = e
+		placeholders = append(placeholders, "?")
+		args = append(args, e.ID)
+	}
+	query := `SELECT id, entry_id, start_time, end_time FROM entry_breaks
+		WHERE entry_id IN (` + strings.Join(placeholders, ", ") + `) ORDER BY start_time, id`
+	rows, err := s.DB.Query(query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query breaks: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var b chronos.Break
+		var endTime sql.NullTime
+		if err := rows.Scan(&b.ID, &b.EntryID, &b.StartTime, &endTime); err != nil {
+			return fmt.Errorf("failed to scan break: %w", err)
+		}
+		if endTime.Valid {
+			b.EndTime = endTime.Time
+		}
+		if e := byID[b.EntryID]; e != nil {
+			e.Breaks = append(e.Breaks, b)
+		}
+	}
+	return rows.Err()
+}
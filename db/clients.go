@@ -0,0 +1,80 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/regiellis/chronos-go/chronos"
+)
+
+// CreateClient inserts a new client and sets its ID.
+func (s *Store) CreateClient(client *chronos.Client) error {
+	query := `
+		INSERT INTO clients (name, contact_info, created_at, updated_at)
+		VALUES (?, ?, ?, ?)`
+	res, err := s.DB.Exec(query, client.Name, client.ContactInfo, client.CreatedAt, client.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	client.ID = id
+	return nil
+}
+
+// GetClient retrieves a client by its ID.
+func (s *Store) GetClient(id int64) (*chronos.Client, error) {
+	client := &chronos.Client{}
+	query := `
+		SELECT id, name, contact_info, created_at, updated_at
+		FROM clients WHERE id = ?`
+	err := s.DB.QueryRow(query, id).Scan(&client.ID, &client.Name, &client.ContactInfo, &client.CreatedAt, &client.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("GetClient: no client found with ID %d: %w", id, chronos.ErrNotFound)
+		}
+		return nil, err
+	}
+	return client, nil
+}
+
+// UpdateClient updates an existing client.
+func (s *Store) UpdateClient(client *chronos.Client) error {
+	query := `
+		UPDATE clients
+		SET name = ?, contact_info = ?, updated_at = ?
+		WHERE id = ?`
+	_, err := s.DB.Exec(query, client.Name, client.ContactInfo, client.UpdatedAt, client.ID)
+	return err
+}
+
+// DeleteClient removes a client by its ID.
+func (s *Store) DeleteClient(id int64) error {
+	_, err := s.DB.Exec("DELETE FROM clients WHERE id = ?", id)
+	return err
+}
+
+// ListClients retrieves all clients.
+func (s *Store) ListClients() ([]*chronos.Client, error) {
+	query := `
+		SELECT id, name, contact_info, created_at, updated_at
+		FROM clients ORDER BY id`
+	rows, err := s.DB.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	clients := []*chronos.Client{}
+	for rows.Next() {
+		client := &chronos.Client{}
+		err := rows.Scan(&client.ID, &client.Name, &client.ContactInfo, &client.CreatedAt, &client.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		clients = append(clients, client)
+	}
+	return clients, rows.Err()
+}
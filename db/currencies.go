@@ -0,0 +1,88 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/regiellis/chronos-go/chronos"
+)
+
+// SetCurrency stores a project or client currency, replacing the one for the
+// same scope and target, and sets its ID.
+func (s *Store) SetCurrency(c *chronos.CurrencySetting) error {
+	_, err := s.DB.Exec(`
+		INSERT INTO currencies (scope, target, code) VALUES (?, ?, ?)
+		ON CONFLICT (scope, target) DO UPDATE SET target = excluded.target, code = excluded.code`,
+		c.Scope, c.Target, c.Code)
+	if err != nil {
+		return fmt.Errorf("SetCurrency: failed to execute upsert: %w", err)
+	}
+	// LastInsertId is stale after an update, so look the row up instead.
+	err = s.DB.QueryRow(`SELECT id FROM currencies WHERE scope = ? AND target = ?`, c.Scope, c.Target).Scan(&c.ID)
+	if err != nil {
+		return fmt.Errorf("SetCurrency: failed to read ID: %w", err)
+	}
+	return nil
+}
+
+// ListCurrencySettings retrieves all project and client currencies by scope
+// and target.
+func (s *Store) ListCurrencySettings() ([]*chronos.CurrencySetting, error) {
+	rows, err := s.DB.Query(`SELECT id, scope, target, code FROM currencies ORDER BY scope, target`)
+	if err != nil {
+		return nil, fmt.Errorf("ListCurrencySettings: failed to execute query: %w", err)
+	}
+	defer rows.Close()
+	settings := []*chronos.CurrencySetting{}
+	for rows.Next() {
+		c := &chronos.CurrencySetting{}
+		if err := rows.Scan(&c.ID, &c.Scope, &c.Target, &c.Code); err != nil {
+			return nil, fmt.Errorf("ListCurrencySettings: failed to scan row: %w", err)
+		}
+		settings = append(settings, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ListCurrencySettings: error during rows iteration: %w", err)
+	}
+	return settings, nil
+}
+
+// CreateExchangeRate inserts an exchange rate and sets its ID.
+func (s *Store) CreateExchangeRate(r *chronos.ExchangeRate) error {
+	res, err := s.DB.Exec(`
+		INSERT INTO exchange_rates (from_currency, to_currency, rate, date, created_at)
+		VALUES (?, ?, ?, ?, ?)`,
+		r.From, r.To, r.Rate, r.Date, r.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("CreateExchangeRate: failed to execute insert: %w", err)
+	}
+	if r.ID, err = res.LastInsertId(); err != nil {
+		return fmt.Errorf("CreateExchangeRate: failed to get last insert ID: %w", err)
+	}
+	return nil
+}
+
+// ListExchangeRates retrieves all exchange rates by date.
+func (s *Store) ListExchangeRates() ([]*chronos.ExchangeRate, error) {
+	rows, err := s.DB.Query(`
+		SELECT id, from_currency, to_currency, rate, date, created_at
+		FROM exchange_rates ORDER BY date, id`)
+	if err != nil {
+		return nil, fmt.Errorf("ListExchangeRates: failed to execute query: %w", err)
+	}
+	defer rows.Close()
+	rates := []*chronos.ExchangeRate{}
+	for rows.Next() {
+		r := &chronos.ExchangeRate{}
+		var createdAt sql.NullTime
+		if err := rows.Scan(&r.ID, &r.From, &r.To, &r.Rate, &r.Date, &createdAt); err != nil {
+			return nil, fmt.Errorf("ListExchangeRates: failed to scan row: %w", err)
+		}
+		r.CreatedAt = createdAt.Time
+		rates = append(rates, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ListExchangeRates: error during rows iteration: %w", err)
+	}
+	return rates, nil
+}
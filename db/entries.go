@@ -0,0 +1,208 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/regiellis/chronos-go/chronos"
+)
+
+const entryColumns = `id, block_id, project_id, project, client, task, summary, start_time, end_time, duration, billable, rate, invoiced, tags, created_at, updated_at`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanEntry(row rowScanner) (*chronos.Entry, error) {
+	entry := &chronos.Entry{}
+	var endTime sql.NullTime
+	var tags sql.NullString
+	err := row.Scan(
+		&entry.ID, &entry.BlockID, &entry.ProjectID, &entry.Project, &entry.Client, &entry.Task, &entry.Summary,
+		&entry.StartTime, &endTime, &entry.Duration, &entry.Billable, &entry.Rate, &entry.Invoiced, &tags,
+		&entry.CreatedAt, &entry.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if endTime.Valid {
+		entry.EndTime = endTime.Time
+	}
+	if tags.String != "" {
+		entry.Tags = strings.Split(tags.String, ",")
+	}
+	return entry, nil
+}
+
+// joinTags stores tags as a comma-separated list; SanitizeString keeps commas out of tag names.
+func joinTags(tags []string) string {
+	return strings.Join(tags, ",")
+}
+
+// nullTime maps a zero time to NULL so open entries and blocks keep an empty end_time.
+func nullTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t
+}
+
+// CreateEntry inserts a new entry and sets its ID.
+func (s *Store) CreateEntry(entry *chronos.Entry) error {
+	query := `
+		INSERT INTO entries (block_id, project_id, project, client, task, summary, start_time, end_time, duration, billable, rate, invoiced, tags, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	res, err := s.DB.Exec(query,
+		entry.BlockID, entry.ProjectID, entry.Project, entry.Client, entry.Task, entry.Summary,
+		entry.StartTime, nullTime(entry.EndTime), entry.Duration, entry.Billable, entry.Rate, entry.Invoiced, joinTags(entry.Tags),
+		entry.CreatedAt, entry.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("CreateEntry: failed to execute insert: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("CreateEntry: failed to get last insert ID: %w", err)
+	}
+	entry.ID = id
+	return nil
+}
+
+// GetEntry retrieves an entry by its ID.
+func (s *Store) GetEntry(id int64) (*chronos.Entry, error) {
+	query := `SELECT ` + entryColumns + ` FROM entries WHERE id = ?`
+	entry, err := scanEntry(s.DB.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("GetEntry: no entry found with ID %d: %w", id, chronos.ErrNotFound)
+		}
+		return nil, fmt.Errorf("GetEntry: failed to scan row: %w", err)
+	}
+	if err := s.attachBreaks([]*chronos.Entry{entry}); err != nil {
+		return nil, fmt.Errorf("GetEntry: %w", err)
+	}
+	return entry, nil
+}
+
+// UpdateEntry updates an existing entry.
+func (s *Store) UpdateEntry(entry *chronos.Entry) error {
+	query := `
+		UPDATE entries
+		SET block_id = ?, project_id = ?, project = ?, client = ?, task = ?, summary = ?, start_time = ?, end_time = ?,
+			duration = ?, billable = ?, rate = ?, invoiced = ?, tags = ?, updated_at = ?
+		WHERE id = ?`
+	_, err := s.DB.Exec(query,
+		entry.BlockID, entry.ProjectID, entry.Project, entry.Client, entry.Task, entry.Summary,
+		entry.StartTime, nullTime(entry.EndTime), entry.Duration, entry.Billable, entry.Rate, entry.Invoiced, joinTags(entry.Tags),
+		entry.UpdatedAt, entry.ID)
+	if err != nil {
+		return fmt.Errorf("UpdateEntry: failed to execute update: %w", err)
+	}
+	return nil
+}
+
+// DeleteEntry removes an entry and its breaks by ID.
+func (s *Store) DeleteEntry(id int64) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("DeleteEntry: failed to begin transaction: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM entry_breaks WHERE entry_id = ?", id); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("DeleteEntry: failed to delete breaks: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM entries WHERE id = ?", id); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("DeleteEntry: failed to execute delete: %w", err)
+	}
+	return tx.Commit()
+}
+
+// ListEntries retrieves entries, newest first, optionally filtered.
+// See chronos.ListEntries for the supported filter keys.
+func (s *Store) ListEntries(filters map[string]interface{}) ([]*chronos.Entry, error) {
+	baseQuery := "SELECT " + entryColumns + " FROM entries"
+	var conditions []string
+	var args []interface{}
+
+	for key, value := range filters {
+		switch key {
+		case "block_id", "project_id", "project", "client", "task", "billable", "invoiced":
+			conditions = append(conditions, key+" = ?")
+			args = append(args, value)
+		case "start_date": // Assumes value is time.Time or string parsable to time
+			conditions = append(conditions, "date(start_time) >= date(?)")
+			args = append(args, value)
+		case "end_date": // Assumes value is time.Time or string parsable to time
+			conditions = append(conditions, "date(start_time) <= date(?)")
+			args = append(args, value)
+		case "open": // Running timers have no end time yet
+			if open, _ := value.(bool); open {
+				conditions = append(conditions, "end_time IS NULL AND duration = 0")
+			} else {
+				conditions = append(conditions, "NOT (end_time IS NULL AND duration = 0)")
+			}
+		case "tag":
+			conditions = append(conditions, "(',' || tags || ',') LIKE ('%,' || ? || ',%')")
+			args = append(args, value)
+		case "min_duration":
+			conditions = append(conditions, "duration >= ?")
+			args = append(args, value)
+		case "max_duration":
+			conditions = append(conditions, "duration <= ?")
+			args = append(args, value)
+		case "min_rate":
+			conditions = append(conditions, "rate >= ?")
+			args = append(args, value)
+		case "max_rate":
+			conditions = append(conditions, "rate <= ?")
+			args = append(args, value)
+		}
+	}
+
+	query := baseQuery
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY start_time DESC, id DESC"
+
+	rows, err := s.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ListEntries: failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []*chronos.Entry{}
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ListEntries: failed to scan row: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ListEntries: error during rows iteration: %w", err)
+	}
+	rows.Close()
+	if err := s.attachBreaks(entries); err != nil {
+		return nil, fmt.Errorf("ListEntries: %w", err)
+	}
+	return entries, nil
+}
+
+// MarkEntriesInvoiced flags the given entries as invoiced in a single transaction.
+func (s *Store) MarkEntriesInvoiced(ids []int64) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("MarkEntriesInvoiced: failed to begin transaction: %w", err)
+	}
+	for _, id := range ids {
+		if _, err := tx.Exec("UPDATE entries SET invoiced = TRUE, updated_at = ? WHERE id = ?", time.Now(), id); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("MarkEntriesInvoiced: failed to mark entry %d: %w", id, err)
+		}
+	}
+	return tx.Commit()
+}
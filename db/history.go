@@ -0,0 +1,68 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/regiellis/chronos-go/chronos"
+)
+
+// Rows from before answers were saved have only a query and may lack a date.
+const historyColumns = `id, COALESCE(kind, 'ask'), COALESCE(query, ''), COALESCE(answer, ''), COALESCE(model, ''), COALESCE(context_hash, ''), COALESCE(block_id, 0), created_at`
+
+// SaveInteraction inserts an assistant exchange and sets its ID.
+func (s *Store) SaveInteraction(i *chronos.Interaction) error {
+	query := `
+		INSERT INTO query_history (kind, query, answer, model, context_hash, block_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`
+	res, err := s.DB.Exec(query, i.Kind, i.Question, i.Answer, i.Model, i.ContextHash, i.BlockID, i.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("SaveInteraction: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	i.ID = id
+	return nil
+}
+
+// GetInteraction retrieves a saved exchange by ID.
+func (s *Store) GetInteraction(id int64) (*chronos.Interaction, error) {
+	i, err := scanInteraction(s.DB.QueryRow(`SELECT `+historyColumns+` FROM query_history WHERE id = ?`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("GetInteraction: no interaction found with ID %d: %w", id, chronos.ErrNotFound)
+		}
+		return nil, err
+	}
+	return i, nil
+}
+
+// ListInteractions returns the last limit exchanges, most recent first.
+func (s *Store) ListInteractions(limit int) ([]*chronos.Interaction, error) {
+	rows, err := s.DB.Query(`SELECT `+historyColumns+` FROM query_history ORDER BY id DESC LIMIT ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ListInteractions: %w", err)
+	}
+	defer rows.Close()
+	list := []*chronos.Interaction{}
+	for rows.Next() {
+		i, err := scanInteraction(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, i)
+	}
+	return list, rows.Err()
+}
+
+func scanInteraction(row rowScanner) (*chronos.Interaction, error) {
+	i := &chronos.Interaction{}
+	var createdAt sql.NullTime
+	if err := row.Scan(&i.ID, &i.Kind, &i.Question, &i.Answer, &i.Model, &i.ContextHash, &i.BlockID, &createdAt); err != nil {
+		return nil, err
+	}
+	i.CreatedAt = createdAt.Time
+	return i, nil
+}
@@ -0,0 +1,352 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/regiellis/chronos-go/chronos"
+)
+
+const invoiceColumns = `id, number, sequence, client, currency, block_id, status, issue_date, due_date, paid_at, created_at, updated_at`
+
+func scanInvoice(row rowScanner) (*chronos.Invoice, error) {
+	inv := &chronos.Invoice{}
+	var issueDate, dueDate, paidAt, createdAt, updatedAt sql.NullTime
+	err := row.Scan(&inv.ID, &inv.Number, &inv.Sequence, &inv.Client, &inv.Currency, &inv.BlockID, &inv.Status,
+		&issueDate, &dueDate, &paidAt, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+	inv.IssueDate, inv.DueDate, inv.PaidAt = issueDate.Time, dueDate.Time, paidAt.Time
+	inv.CreatedAt, inv.UpdatedAt = createdAt.Time, updatedAt.Time
+	return inv, nil
+}
+
+// CreateInvoice inserts an invoice with its lines, their entry links and its
+// adjustments and marks the entries invoiced in a single transaction, and sets
+// the IDs. An entry that is missing or already invoiced rolls it all back.
+func (s *Store) CreateInvoice(inv *chronos.Invoice) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("CreateInvoice: failed to begin transaction: %w", err)
+	}
+	res, err := tx.Exec(`
+		INSERT INTO invoices (number, sequence, client, currency, block_id, status, issue_date, due_date, paid_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		inv.Number, inv.Sequence, inv.Client, inv.Currency, inv.BlockID, inv.Status,
+		nullTime(inv.IssueDate), nullTime(inv.DueDate), nullTime(inv.PaidAt), inv.CreatedAt, inv.UpdatedAt)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("CreateInvoice: failed to execute insert: %w", err)
+	}
+	invoiceID, err := res.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("CreateInvoice: failed to get last insert ID: %w", err)
+	}
+	lineIDs := make([]int64, len(inv.Lines))
+	for i, l := range inv.Lines {
+		if lineIDs[i], err = insertInvoiceLine(tx, invoiceID, i, l); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("CreateInvoice: line %d: %w", i+1, err)
+		}
+	}
+	adjustmentIDs, err := insertInvoiceAdjustments(tx, invoiceID, inv.Adjustments)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("CreateInvoice: %w", err)
+	}
+	for _, entryID := range inv.EntryIDs() {
+		if err := markEntryInvoiced(tx, entryID); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("CreateInvoice: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("CreateInvoice: %w", err)
+	}
+	inv.ID = invoiceID
+	for i, l := range inv.Lines {
+		l.ID = lineIDs[i]
+	}
+	for i, a := range inv.Adjustments {
+		a.ID = adjustmentIDs[i]
+	}
+	return nil
+}
+
+// markEntryInvoiced flags an entry as invoiced unless it already is.
+func markEntryInvoiced(tx *sql.Tx, entryID int64) error {
+	res, err := tx.Exec(`UPDATE entries SET invoiced = TRUE, updated_at = ? WHERE id = ? AND NOT invoiced`, time.Now(), entryID)
+	if err != nil {
+		return fmt.Errorf("failed to mark entry %d: %w", entryID, err)
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 1 {
+		return err
+	}
+	var exists bool
+	if err := tx.QueryRow(`SELECT EXISTS(SELECT 1 FROM entries WHERE id = ?)`, entryID).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check entry %d: %w", entryID, err)
+	}
+	if !exists {
+		return fmt.Errorf("no entry found with ID %d: %w", entryID, chronos.ErrNotFound)
+	}
+	return fmt.Errorf("entry %d is %w", entryID, chronos.ErrAlreadyInvoiced)
+}
+
+// insertInvoiceLine inserts a line at position with its entry links.
+func insertInvoiceLine(tx *sql.Tx, invoiceID int64, position int, l *chronos.InvoiceLine) (int64, error) {
+	res, err := tx.Exec(`
+		INSERT INTO invoice_lines (invoice_id, position, kind, project, task, description, minutes, tracked_minutes, amount)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		invoiceID, position, l.Kind, l.Project, l.Task, l.Description, l.Minutes, l.Tracked, l.Amount)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert line: %w", err)
+	}
+	lineID, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get line ID: %w", err)
+	}
+	for _, entryID := range l.EntryIDs {
+		if _, err := tx.Exec(`INSERT INTO invoice_line_entries (line_id, entry_id) VALUES (?, ?)`, lineID, entryID); err != nil {
+			return 0, fmt.Errorf("failed to link entry %d: %w", entryID, err)
+		}
+	}
+	return lineID, nil
+}
+
+// insertInvoiceAdjustments inserts an invoice's adjustments in order and
+// returns their IDs.
+func insertInvoiceAdjustments(tx *sql.Tx, invoiceID int64, adjustments []*chronos.Adjustment) ([]int64, error) {
+	ids := make([]int64, len(adjustments))
+	for i, a := range adjustments {
+		res, err := tx.Exec(`
+			INSERT INTO invoice_adjustments (invoice_id, position, kind, name, percent, amount)
+			VALUES (?, ?, ?, ?, ?, ?)`,
+			invoiceID, i, a.Kind, a.Name, a.Percent, a.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to insert adjustment %q: %w", a.Name, err)
+		}
+		if ids[i], err = res.LastInsertId(); err != nil {
+			return nil, fmt.Errorf("failed to get adjustment ID: %w", err)
+		}
+	}
+	return ids, nil
+}
+
+// AddInvoiceLine appends a line to an invoice and sets its ID.
+func (s *Store) AddInvoiceLine(invoiceID int64, line *chronos.InvoiceLine) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("AddInvoiceLine: failed to begin transaction: %w", err)
+	}
+	var position int
+	if err := tx.QueryRow(`SELECT COALESCE(MAX(position), -1) + 1 FROM invoice_lines WHERE invoice_id = ?`, invoiceID).Scan(&position); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("AddInvoiceLine: failed to find position: %w", err)
+	}
+	lineID, err := insertInvoiceLine(tx, invoiceID, position, line)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("AddInvoiceLine: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("AddInvoiceLine: %w", err)
+	}
+	line.ID = lineID
+	return nil
+}
+
+// GetInvoice retrieves an invoice, its lines and its adjustments by ID.
+func (s *Store) GetInvoice(id int64) (*chronos.Invoice, error) {
+	inv, err := scanInvoice(s.DB.QueryRow(`SELECT `+invoiceColumns+` FROM invoices WHERE id = ?`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("GetInvoice: no invoice found with ID %d: %w", id, chronos.ErrNotFound)
+		}
+		return nil, fmt.Errorf("GetInvoice: failed to scan row: %w", err)
+	}
+	if err := s.attachInvoiceLines([]*chronos.Invoice{inv}); err != nil {
+		return nil, fmt.Errorf("GetInvoice: %w", err)
+	}
+	return inv, nil
+}
+
+// UpdateInvoice updates an invoice's number, status and dates and replaces
+// its adjustments. Its lines are left as they are.
+func (s *Store) UpdateInvoice(inv *chronos.Invoice) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("UpdateInvoice: failed to begin transaction: %w", err)
+	}
+	_, err = tx.Exec(`
+		UPDATE invoices
+		SET number = ?, sequence = ?, client = ?, block_id = ?, status = ?, issue_date = ?, due_date = ?, paid_at = ?, updated_at = ?
+		WHERE id = ?`,
+		inv.Number, inv.Sequence, inv.Client, inv.BlockID, inv.Status,
+		nullTime(inv.IssueDate), nullTime(inv.DueDate), nullTime(inv.PaidAt), inv.UpdatedAt, inv.ID)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("UpdateInvoice: failed to execute update: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM invoice_adjustments WHERE invoice_id = ?`, inv.ID); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("UpdateInvoice: failed to clear adjustments: %w", err)
+	}
+	ids, err := insertInvoiceAdjustments(tx, inv.ID, inv.Adjustments)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("UpdateInvoice: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("UpdateInvoice: %w", err)
+	}
+	for i, a := range inv.Adjustments {
+		a.ID = ids[i]
+	}
+	return nil
+}
+
+// VoidInvoice sets a draft or issued invoice void and releases its entries in
+// a single transaction.
+func (s *Store) VoidInvoice(inv *chronos.Invoice) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("VoidInvoice: failed to begin transaction: %w", err)
+	}
+	_, err = tx.Exec(`
+		UPDATE entries SET invoiced = FALSE, updated_at = ?
+		WHERE id IN (
+			SELECT le.entry_id FROM invoice_line_entries le
+			JOIN invoice_lines l ON l.id = le.line_id
+			WHERE l.invoice_id = ?)`, inv.UpdatedAt, inv.ID)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("VoidInvoice: failed to release entries: %w", err)
+	}
+	res, err := tx.Exec(`UPDATE invoices SET status = ?, updated_at = ? WHERE id = ? AND status IN (?, ?)`,
+		chronos.InvoiceVoid, inv.UpdatedAt, inv.ID, chronos.InvoiceDraft, chronos.InvoiceIssued)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("VoidInvoice: failed to execute update: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil || n != 1 {
+		tx.Rollback()
+		if err != nil {
+			return fmt.Errorf("VoidInvoice: %w", err)
+		}
+		return fmt.Errorf("VoidInvoice: invoice %d is not a draft or issued: %w", inv.ID, chronos.ErrInvoiceStatus)
+	}
+	return tx.Commit()
+}
+
+// ListInvoices retrieves invoices with their lines and adjustments, newest
+// first.
+// See chronos.ListInvoices for the supported filter keys.
+func (s *Store) ListInvoices(filters map[string]interface{}) ([]*chronos.Invoice, error) {
+	var conditions []string
+	var args []interface{}
+	for key, value := range filters {
+		switch key {
+		case "status", "client":
+			conditions = append(conditions, key+" = ?")
+			args = append(args, value)
+		}
+	}
+	query := `SELECT ` + invoiceColumns + ` FROM invoices`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY id DESC"
+
+	rows, err := s.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ListInvoices: failed to execute query: %w", err)
+	}
+	defer rows.Close()
+	invoices := []*chronos.Invoice{}
+	for rows.Next() {
+		inv, err := scanInvoice(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ListInvoices: failed to scan row: %w", err)
+		}
+		invoices = append(invoices, inv)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ListInvoices: error during rows iteration: %w", err)
+	}
+	rows.Close()
+	if err := s.attachInvoiceLines(invoices); err != nil {
+		return nil, fmt.Errorf("ListInvoices: %w", err)
+	}
+	return invoices, nil
+}
+
+// attachInvoiceLines loads the lines of the given invoices, in order, with
+// their entry IDs, and then the invoices' adjustments.
+func (s *Store) attachInvoiceLines(invoices []*chronos.Invoice) error {
+	if len(invoices) == 0 {
+		return nil
+	}
+	byID := make(map[int64]*chronos.Invoice, len(invoices))
+	placeholders := make([]string, 0, len(invoices))
+	args := make([]interface{}, 0, len(invoices))
+	for _, inv := range invoices {
+		byID[inv.ID] = inv
+		inv.Lines = []*chronos.InvoiceLine{}
+		placeholders = append(placeholders, "?")
+		args = append(args, inv.ID)
+	}
+	query := `SELECT l.id, l.invoice_id, l.kind, l.project, l.task, l.description, l.minutes, l.tracked_minutes, l.amount, e.entry_id
+		FROM invoice_lines l LEFT JOIN invoice_line_entries e ON e.line_id = l.id
+		WHERE l.invoice_id IN (` + strings.Join(placeholders, ", ") + `)
+		ORDER BY l.invoice_id, l.position, e.rowid`
+	rows, err := s.DB.Query(query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query invoice lines: %w", err)
+	}
+	defer rows.Close()
+	var line *chronos.InvoiceLine
+	for rows.Next() {
+		var l chronos.InvoiceLine
+		var invoiceID int64
+		var entryID sql.NullInt64
+		if err := rows.Scan(&l.ID, &invoiceID, &l.Kind, &l.Project, &l.Task, &l.Description, &l.Minutes, &l.Tracked, &l.Amount, &entryID); err != nil {
+			return fmt.Errorf("failed to scan invoice line: %w", err)
+		}
+		if line == nil || line.ID != l.ID {
+			line = &l
+			if inv := byID[invoiceID]; inv != nil {
+				inv.Lines = append(inv.Lines, line)
+			}
+		}
+		if entryID.Valid {
+			line.EntryIDs = append(line.EntryIDs, entryID.Int64)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	rows, err = s.DB.Query(`SELECT id, invoice_id, kind, name, percent, amount FROM invoice_adjustments
+		WHERE invoice_id IN (`+strings.Join(placeholders, ", ")+`)
+		ORDER BY invoice_id, position`, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query invoice adjustments: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		a := &chronos.Adjustment{}
+		var invoiceID int64
+		if err := rows.Scan(&a.ID, &invoiceID, &a.Kind, &a.Name, &a.Percent, &a.Amount); err != nil {
+			return fmt.Errorf("failed to scan invoice adjustment: %w", err)
+		}
+		if inv := byID[invoiceID]; inv != nil {
+			inv.Adjustments = append(inv.Adjustments, a)
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,55 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/regiellis/chronos-go/chronos"
+)
+
+func TestVoidInvoiceRollsBack(t *testing.T) {
+	store := openTestStore(t)
+	if err := store.InitSchema(); err != nil {
+		t.Fatalf("InitSchema failed: %v", err)
+	}
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	var entries []*chronos.Entry
+	for _, task := range []string{"API", "Design"} {
+		e := &chronos.Entry{Project: "Apollo", Client: "Acme", Task: task, StartTime: start, Duration: 60, Billable: true, Rate: 100}
+		if err := chronos.CreateEntry(store, e); err != nil {
+			t.Fatalf("CreateEntry failed: %v", err)
+		}
+		entries = append(entries, e)
+	}
+	inv := &chronos.Invoice{Client: "Acme", Lines: chronos.GroupInvoiceLines(entries)}
+	if err := chronos.CreateInvoice(store, inv); err != nil {
+		t.Fatalf("CreateInvoice failed: %v", err)
+	}
+
+	// Fail the status update after the entries have been released.
+	if _, err := store.DB.Exec(`
+		CREATE TRIGGER fail_void BEFORE UPDATE OF status ON invoices
+		WHEN NEW.status = 'void'
+		BEGIN SELECT RAISE(ABORT, 'void refused'); END`); err != nil {
+		t.Fatalf("CREATE TRIGGER failed: %v", err)
+	}
+	if _, err := chronos.VoidInvoice(store, inv.ID); err == nil {
+		t.Fatal("VoidInvoice should fail when the status cannot be set")
+	}
+	if got, err := store.GetInvoice(inv.ID); err != nil || got.Status != chronos.InvoiceDraft {
+		t.Errorf("invoice should still be a draft: %+v, %v", got, err)
+	}
+	if unbilled, _ := chronos.FindUnbilledEntries(store); len(unbilled) != 0 {
+		t.Errorf("a failed void should release nothing, %d entries unbilled", len(unbilled))
+	}
+
+	if _, err := store.DB.Exec(`DROP TRIGGER fail_void`); err != nil {
+		t.Fatalf("DROP TRIGGER failed: %v", err)
+	}
+	if _, err := chronos.VoidInvoice(store, inv.ID); err != nil {
+		t.Fatalf("VoidInvoice failed: %v", err)
+	}
+	if unbilled, _ := chronos.FindUnbilledEntries(store); len(unbilled) != 2 {
+		t.Errorf("voiding should release both entries, %d unbilled", len(unbilled))
+	}
+}
@@ -0,0 +1,308 @@
+package db
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/charmbracelet/log"
+)
+
+//go:embed migrations/*.sql
+var migrationFS embed.FS
+
+// Migration is a single ordered, forward-only schema change.
+// SQL migrations live in db/migrations as NNNN_name.sql; changes that need
+// to inspect the existing schema are written in Go and listed in goMigrations.
+type Migration struct {
+	Version int
+	Name    string
+	up      func(tx *sql.Tx) error
+}
+
+// MigrationState reports whether a migration has been applied to a database.
+type MigrationState struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+var goMigrations = []Migration{
+	{Version: 2, Name: "reconcile_legacy_tables", up: reconcileLegacyTables},
+}
+
+// Migrations returns every known migration in version order.
+func Migrations() ([]Migration, error) {
+	migrations := append([]Migration{}, goMigrations...)
+	files, err := fs.Glob(migrationFS, "migrations/*.sql")
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		base := strings.TrimSuffix(strings.TrimPrefix(file, "migrations/"), ".sql")
+		versionStr, name, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("migration %s: expected NNNN_name.sql", file)
+		}
+		version, err := strconv.Atoi(versionStr)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: invalid version: %w", file, err)
+		}
+		body, err := migrationFS.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		stmt := string(body)
+		migrations = append(migrations, Migration{
+			Version: version,
+			Name:    name,
+			up: func(tx *sql.Tx) error {
+				_, err := tx.Exec(stmt)
+				return err
+			},
+		})
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].Version == migrations[i-1].Version {
+			return nil, fmt.Errorf("duplicate migration version %d", migrations[i].Version)
+		}
+	}
+	return migrations, nil
+}
+
+func (s *Store) ensureVersionTable() error {
+	_, err := s.DB.Exec(`CREATE TABLE IF NOT EXISTS schema_version (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at DATETIME NOT NULL
+	)`)
+	return err
+}
+
+func (s *Store) appliedVersions() (map[int]time.Time, error) {
+	rows, err := s.DB.Query(`SELECT version, applied_at FROM schema_version`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	applied := map[int]time.Time{}
+	for rows.Next() {
+		var version int
+		var at time.Time
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, err
+		}
+		applied[version] = at
+	}
+	return applied, rows.Err()
+}
+
+// MigrationStatus lists every known migration and whether it has been applied.
+func (s *Store) MigrationStatus() ([]MigrationState, error) {
+	if err := s.ensureVersionTable(); err != nil {
+		return nil, err
+	}
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+	applied, err := s.appliedVersions()
+	if err != nil {
+		return nil, err
+	}
+	states := make([]MigrationState, 0, len(migrations))
+	for _, m := range migrations {
+		at, ok := applied[m.Version]
+		states = append(states, MigrationState{Version: m.Version, Name: m.Name, Applied: ok, AppliedAt: at})
+	}
+	return states, nil
+}
+
+// SchemaVersion returns the highest applied migration version, or 0 for an unversioned database.
+func (s *Store) SchemaVersion() (int, error) {
+	if err := s.ensureVersionTable(); err != nil {
+		return 0, err
+	}
+	var version sql.NullInt64
+	if err := s.DB.QueryRow(`SELECT MAX(version) FROM schema_version`).Scan(&version); err != nil {
+		return 0, err
+	}
+	return int(version.Int64), nil
+}
+
+// Migrate applies all pending migrations in order, each in its own transaction,
+// and returns the migrations that were applied.
+func (s *Store) Migrate() ([]Migration, error) {
+	if err := s.ensureVersionTable(); err != nil {
+		return nil, fmt.Errorf("Migrate: failed to create schema_version table: %w", err)
+	}
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, fmt.Errorf("Migrate: failed to load migrations: %w", err)
+	}
+	applied, err := s.appliedVersions()
+	if err != nil {
+		return nil, fmt.Errorf("Migrate: failed to read schema_version: %w", err)
+	}
+
+	var ran []Migration
+	for _, m := range migrations {
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+		tx, err := s.DB.Begin()
+		if err != nil {
+			return ran, fmt.Errorf("Migrate: failed to begin transaction: %w", err)
+		}
+		if err := m.up(tx); err != nil {
+			tx.Rollback()
+			log.Error("[DB] Migration failed", "version", m.Version, "name", m.Name, "error", err)
+			return ran, fmt.Errorf("Migrate: migration %04d_%s failed: %w", m.Version, m.Name, err)
+		}
+		if _, err := tx.Exec(`INSERT INTO schema_version (version, name, applied_at) VALUES (?, ?, ?)`, m.Version, m.Name, time.Now()); err != nil {
+			tx.Rollback()
+			return ran, fmt.Errorf("Migrate: failed to record migration %d: %w", m.Version, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return ran, fmt.Errorf("Migrate: failed to commit migration %d: %w", m.Version, err)
+		}
+		ran = append(ran, m)
+	}
+	return ran, nil
+}
+
+// tableColumns returns the column names of a table; an empty set means the table does not exist.
+func tableColumns(tx *sql.Tx, table string) (map[string]bool, error) {
+	rows, err := tx.Query(`SELECT name FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	cols := map[string]bool{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		cols[name] = true
+	}
+	return cols, rows.Err()
+}
+
+// reconcileLegacyTables upgrades databases written by builds that predate
+// schema versioning. Two entries layouts existed: one keyed on
+// entry_time/description/duration and one on start_time/summary/project_id.
+// Both are rebuilt into the unified layout from migration 1.
+func reconcileLegacyTables(tx *sql.Tx) error {
+	cols, err := tableColumns(tx, "entries")
+	if err != nil {
+		return err
+	}
+	expected := []string{"id", "block_id", "project_id", "project", "client", "task", "summary", "start_time", "end_time", "duration", "billable", "rate", "invoiced", "created_at", "updated_at"}
+	missing := false
+	for _, c := range expected {
+		if !cols[c] {
+			missing = true
+			break
+		}
+	}
+	if missing {
+		if err := rebuildEntries(tx, cols); err != nil {
+			return fmt.Errorf("rebuild entries: %w", err)
+		}
+	}
+
+	blockCols, err := tableColumns(tx, "blocks")
+	if err != nil {
+		return err
+	}
+	if !blockCols["updated_at"] {
+		if _, err := tx.Exec(`ALTER TABLE blocks ADD COLUMN updated_at DATETIME`); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`UPDATE blocks SET updated_at = created_at`); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func rebuildEntries(tx *sql.Tx, cols map[string]bool) error {
+	pick := func(candidates []string, fallback string) string {
+		for _, c := range candidates {
+			if cols[c] {
+				return c
+			}
+		}
+		return fallback
+	}
+	start := pick([]string{"start_time", "entry_time"}, "created_at")
+	end := "NULL"
+	switch {
+	case cols["end_time"]:
+		end = "end_time"
+	case cols["duration"]:
+		end = fmt.Sprintf("datetime(%s, '+' || duration || ' minutes')", start)
+	}
+	duration := "0"
+	switch {
+	case cols["duration"]:
+		duration = "duration"
+	case cols["end_time"]:
+		duration = fmt.Sprintf("CAST(ROUND((julianday(end_time) - julianday(%s)) * 1440) AS INTEGER)", start)
+	}
+	selectExprs := []string{
+		"id",
+		pick([]string{"block_id"}, "0"),
+		pick([]string{"project_id"}, "0"),
+		pick([]string{"project"}, "''"),
+		pick([]string{"client"}, "''"),
+		pick([]string{"task"}, "''"),
+		pick([]string{"summary", "description"}, "''"),
+		start,
+		end,
+		duration,
+		pick([]string{"billable"}, "1"),
+		pick([]string{"rate"}, "0"),
+		pick([]string{"invoiced"}, "0"),
+		pick([]string{"created_at"}, start),
+		pick([]string{"updated_at", "created_at"}, start),
+	}
+
+	stmts := []string{
+		`ALTER TABLE entries RENAME TO entries_legacy`,
+		`CREATE TABLE entries (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			block_id INTEGER DEFAULT 0,
+			project_id INTEGER DEFAULT 0,
+			project TEXT DEFAULT '',
+			client TEXT DEFAULT '',
+			task TEXT DEFAULT '',
+			summary TEXT DEFAULT '',
+			start_time DATETIME,
+			end_time DATETIME,
+			duration INTEGER DEFAULT 0,
+			billable BOOLEAN DEFAULT 1,
+			rate REAL DEFAULT 0,
+			invoiced BOOLEAN DEFAULT 0,
+			created_at DATETIME,
+			updated_at DATETIME
+		)`,
+		`INSERT INTO entries (id, block_id, project_id, project, client, task, summary, start_time, end_time, duration, billable, rate, invoiced, created_at, updated_at)
+			SELECT ` + strings.Join(selectExprs, ", ") + ` FROM entries_legacy`,
+		`DROP TABLE entries_legacy`,
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package db
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+	store, err := NewStore(filepath.Join(t.TempDir(), "chronos.db"))
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	t.Cleanup(func() { store.DB.Close() })
+	return store
+}
+
+func latestVersion(t *testing.T) int {
+	t.Helper()
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatalf("Migrations failed: %v", err)
+	}
+	return migrations[len(migrations)-1].Version
+}
+
+func TestMigrationsAreOrdered(t *testing.T) {
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatalf("Migrations failed: %v", err)
+	}
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			t.Errorf("expected migration %d at position %d, got %04d_%s", i+1, i, m.Version, m.Name)
+		}
+	}
+}
+
+func TestMigrateFreshDatabase(t *testing.T) {
+	store := openTestStore(t)
+
+	applied, err := store.Migrate()
+	if err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if len(applied) != latestVersion(t) {
+		t.Errorf("expected %d migrations applied, got %d", latestVersion(t), len(applied))
+	}
+	for _, table := range []string{"entries", "entry_breaks", "blocks", "clients", "projects", "templates", "query_history", "invoices", "invoice_lines", "invoice_line_entries", "rates", "rounding_rules", "invoice_adjustments", "client_terms", "currencies", "exchange_rates"} {
+		var name string
+		if err := store.DB.QueryRow(`SELECT name FROM sqlite_master WHERE type='table' AND name=?`, table).Scan(&name); err != nil {
+			t.Errorf("table %s missing after migrate: %v", table, err)
+		}
+	}
+
+	// A second run is a no-op.
+	applied, err = store.Migrate()
+	if err != nil {
+		t.Fatalf("second Migrate failed: %v", err)
+	}
+	if len(applied) != 0 {
+		t.Errorf("expected no migrations on second run, got %d", len(applied))
+	}
+	version, err := store.SchemaVersion()
+	if err != nil {
+		t.Fatalf("SchemaVersion failed: %v", err)
+	}
+	if version != latestVersion(t) {
+		t.Errorf("expected schema version %d, got %d", latestVersion(t), version)
+	}
+}
+
+func TestMigrateLegacyStoreLayout(t *testing.T) {
+	store := openTestStore(t)
+
+	// Layout written by the original db.Store.InitSchema.
+	_, err := store.DB.Exec(`CREATE TABLE entries (
+		id INTEGER PRIMARY KEY AUTOINCREMENT, block_id INTEGER, project TEXT, client TEXT, task TEXT,
+		description TEXT, duration INTEGER, entry_time DATETIME, created_at DATETIME,
+		billable BOOLEAN DEFAULT 1, rate REAL DEFAULT 0, invoiced BOOLEAN DEFAULT 0);
+		CREATE TABLE blocks (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT, client TEXT, project TEXT,
+		start_time DATETIME, end_time DATETIME, active BOOLEAN, created_at DATETIME);`)
+	if err != nil {
+		t.Fatalf("creating legacy tables failed: %v", err)
+	}
+	entryTime := time.Date(2025, 5, 2, 9, 0, 0, 0, time.UTC)
+	_, err = store.DB.Exec(`INSERT INTO entries (block_id, project, client, task, description, duration, entry_time, created_at, billable, rate, invoiced)
+		VALUES (1, 'Apollo', 'Acme Corp', 'Coding', 'Fixed bug Y', 90, ?, ?, 1, 75, 0)`, entryTime, entryTime)
+	if err != nil {
+		t.Fatalf("inserting legacy entry failed: %v", err)
+	}
+	if _, err := store.DB.Exec(`INSERT INTO blocks (name, active, created_at) VALUES ('Sprint', 1, ?)`, entryTime); err != nil {
+		t.Fatalf("inserting legacy block failed: %v", err)
+	}
+
+	if err := store.InitSchema(); err != nil {
+		t.Fatalf("InitSchema on legacy database failed: %v", err)
+	}
+
+	var summary, project string
+	var duration int64
+	var rate float64
+	var start, end time.Time
+	err = store.DB.QueryRow(`SELECT summary, project, duration, rate, start_time, end_time FROM entries WHERE id = 1`).
+		Scan(&summary, &project, &duration, &rate, &start, &end)
+	if err != nil {
+		t.Fatalf("reading upgraded entry failed: %v", err)
+	}
+	if summary != "Fixed bug Y" || project != "Apollo" || duration != 90 || rate != 75 {
+		t.Errorf("legacy data not carried over: summary=%q project=%q duration=%d rate=%.2f", summary, project, duration, rate)
+	}
+	if !start.Equal(entryTime) || !end.Equal(entryTime.Add(90*time.Minute)) {
+		t.Errorf("unexpected times after upgrade: start=%v end=%v", start, end)
+	}
+
+	var updatedAt time.Time
+	if err := store.DB.QueryRow(`SELECT updated_at FROM blocks WHERE id = 1`).Scan(&updatedAt); err != nil {
+		t.Errorf("blocks.updated_at not added: %v", err)
+	}
+}
+
+func TestMigrateLegacySummaryLayout(t *testing.T) {
+	store := openTestStore(t)
+
+	// Layout the chronos package wrote against before the models were unified.
+	_, err := store.DB.Exec(`CREATE TABLE entries (
+		id INTEGER PRIMARY KEY AUTOINCREMENT, block_id INTEGER, project_id INTEGER, summary TEXT,
+		start_time DATETIME, end_time DATETIME, created_at DATETIME, updated_at DATETIME, invoiced BOOLEAN)`)
+	if err != nil {
+		t.Fatalf("creating legacy table failed: %v", err)
+	}
+	start := time.Date(2025, 5, 2, 9, 0, 0, 0, time.UTC)
+	_, err = store.DB.Exec(`INSERT INTO entries (block_id, project_id, summary, start_time, end_time, created_at, updated_at, invoiced)
+		VALUES (0, 3, 'Standup', ?, ?, ?, ?, 0)`, start, start.Add(15*time.Minute), start, start)
+	if err != nil {
+		t.Fatalf("inserting legacy entry failed: %v", err)
+	}
+
+	if err := store.InitSchema(); err != nil {
+		t.Fatalf("InitSchema on legacy database failed: %v", err)
+	}
+
+	var projectID, duration int64
+	if err := store.DB.QueryRow(`SELECT project_id, duration FROM entries WHERE id = 1`).Scan(&projectID, &duration); err != nil {
+		t.Fatalf("reading upgraded entry failed: %v", err)
+	}
+	if projectID != 3 || duration != 15 {
+		t.Errorf("expected project_id 3 and duration 15, got %d and %d", projectID, duration)
+	}
+}
@@ -0,0 +1,59 @@
+-- Core tables. IF NOT EXISTS keeps this safe on databases created before
+-- schema versioning; their layouts are reconciled by migration 2.
+CREATE TABLE IF NOT EXISTS entries (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	block_id INTEGER DEFAULT 0,
+	project_id INTEGER DEFAULT 0,
+	project TEXT DEFAULT '',
+	client TEXT DEFAULT '',
+	task TEXT DEFAULT '',
+	summary TEXT DEFAULT '',
+	start_time DATETIME,
+	end_time DATETIME,
+	duration INTEGER DEFAULT 0,
+	billable BOOLEAN DEFAULT 1,
+	rate REAL DEFAULT 0,
+	invoiced BOOLEAN DEFAULT 0,
+	created_at DATETIME,
+	updated_at DATETIME
+);
+
+CREATE TABLE IF NOT EXISTS blocks (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT,
+	client TEXT,
+	project TEXT,
+	start_time DATETIME,
+	end_time DATETIME,
+	active BOOLEAN,
+	created_at DATETIME,
+	updated_at DATETIME
+);
+
+CREATE TABLE IF NOT EXISTS clients (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	contact_info TEXT DEFAULT '',
+	created_at DATETIME,
+	updated_at DATETIME
+);
+
+CREATE TABLE IF NOT EXISTS projects (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	client_id INTEGER DEFAULT 0,
+	rate REAL DEFAULT 0,
+	created_at DATETIME,
+	updated_at DATETIME
+);
+
+CREATE TABLE IF NOT EXISTS templates (
+	name TEXT PRIMARY KEY,
+	entry TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS query_history (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	query TEXT,
+	created_at DATETIME
+);
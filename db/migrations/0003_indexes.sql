@@ -0,0 +1,5 @@
+CREATE INDEX IF NOT EXISTS idx_entries_start_time ON entries (start_time);
+CREATE INDEX IF NOT EXISTS idx_entries_block_id ON entries (block_id);
+CREATE INDEX IF NOT EXISTS idx_blocks_active ON blocks (active);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_clients_name ON clients (name);
+CREATE INDEX IF NOT EXISTS idx_projects_client_id ON projects (client_id);
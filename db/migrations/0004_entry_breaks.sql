@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS entry_breaks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    entry_id INTEGER NOT NULL,
+    start_time DATETIME NOT NULL,
+    end_time DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_entry_breaks_entry_id ON entry_breaks (entry_id);
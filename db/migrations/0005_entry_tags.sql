@@ -0,0 +1 @@
+ALTER TABLE entries ADD COLUMN tags TEXT DEFAULT '';
@@ -0,0 +1,4 @@
+ALTER TABLE query_history ADD COLUMN kind TEXT DEFAULT 'ask';
+ALTER TABLE query_history ADD COLUMN answer TEXT DEFAULT '';
+ALTER TABLE query_history ADD COLUMN model TEXT DEFAULT '';
+ALTER TABLE query_history ADD COLUMN context_hash TEXT DEFAULT '';
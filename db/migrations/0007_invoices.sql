@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS invoices (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    number TEXT DEFAULT '',
+    sequence INTEGER DEFAULT 0,
+    client TEXT DEFAULT '',
+    block_id INTEGER DEFAULT 0,
+    status TEXT NOT NULL DEFAULT 'draft',
+    issue_date DATETIME,
+    due_date DATETIME,
+    paid_at DATETIME,
+    created_at DATETIME,
+    updated_at DATETIME
+);
+-- Drafts have no number yet; issued numbers are never reused, even after a void.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_invoices_number ON invoices (number) WHERE number != '';
+CREATE TABLE IF NOT EXISTS invoice_lines (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    invoice_id INTEGER NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    project TEXT DEFAULT '',
+    task TEXT DEFAULT '',
+    description TEXT DEFAULT '',
+    minutes REAL DEFAULT 0,
+    amount REAL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS idx_invoice_lines_invoice_id ON invoice_lines (invoice_id);
+CREATE TABLE IF NOT EXISTS invoice_line_entries (
+    line_id INTEGER NOT NULL,
+    entry_id INTEGER NOT NULL,
+    PRIMARY KEY (line_id, entry_id)
+);
+CREATE INDEX IF NOT EXISTS idx_invoice_line_entries_entry_id ON invoice_line_entries (entry_id);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS rates (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    scope TEXT NOT NULL,
+    -- Project or client name, or block ID; empty for the default rate.
+    target TEXT DEFAULT '',
+    amount REAL NOT NULL DEFAULT 0,
+    effective_from DATETIME NOT NULL,
+    created_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_rates_scope_target ON rates (scope, target, effective_from);
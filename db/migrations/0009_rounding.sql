@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS rounding_rules (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    scope TEXT NOT NULL,
+    target TEXT NOT NULL,
+    increment INTEGER DEFAULT 0,
+    direction TEXT NOT NULL DEFAULT 'up',
+    per TEXT NOT NULL DEFAULT 'entry',
+    minimum INTEGER DEFAULT 0,
+    created_at DATETIME,
+    updated_at DATETIME
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_rounding_rules_target ON rounding_rules (scope, target COLLATE NOCASE);
+-- Invoice lines keep the tracked time next to the rounded, billed minutes.
+ALTER TABLE invoice_lines ADD COLUMN tracked_minutes REAL DEFAULT 0;
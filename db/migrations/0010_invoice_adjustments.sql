@@ -0,0 +1,23 @@
+-- Fixed-price items and expenses sit next to billed time on an invoice.
+ALTER TABLE invoice_lines ADD COLUMN kind TEXT DEFAULT '';
+CREATE TABLE IF NOT EXISTS invoice_adjustments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    invoice_id INTEGER NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    kind TEXT NOT NULL,
+    name TEXT DEFAULT '',
+    percent REAL DEFAULT 0,
+    amount REAL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS idx_invoice_adjustments_invoice_id ON invoice_adjustments (invoice_id);
+-- The adjustments a client's new invoices start with.
+CREATE TABLE IF NOT EXISTS client_terms (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    client TEXT NOT NULL COLLATE NOCASE,
+    position INTEGER NOT NULL DEFAULT 0,
+    kind TEXT NOT NULL,
+    name TEXT DEFAULT '',
+    percent REAL DEFAULT 0,
+    amount REAL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS idx_client_terms_client ON client_terms (client);
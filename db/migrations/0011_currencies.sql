@@ -0,0 +1,19 @@
+-- The currency a project or client bills in, and exchange rates between
+-- currencies entered by hand or imported from CSV.
+CREATE TABLE IF NOT EXISTS currencies (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    scope TEXT NOT NULL,
+    target TEXT NOT NULL COLLATE NOCASE,
+    code TEXT NOT NULL,
+    UNIQUE (scope, target)
+);
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    from_currency TEXT NOT NULL,
+    to_currency TEXT NOT NULL,
+    rate REAL NOT NULL,
+    date DATETIME NOT NULL,
+    created_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_exchange_rates_pair ON exchange_rates (from_currency, to_currency, date);
+ALTER TABLE invoices ADD COLUMN currency TEXT DEFAULT 'USD';
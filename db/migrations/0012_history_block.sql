@@ -0,0 +1,2 @@
+-- The block a summary was about, so re-running it summarizes the same block.
+ALTER TABLE query_history ADD COLUMN block_id INTEGER DEFAULT 0;
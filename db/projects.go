@@ -0,0 +1,85 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/regiellis/chronos-go/chronos"
+)
+
+// CreateProject inserts a new project and sets its ID.
+func (s *Store) CreateProject(project *chronos.Project) error {
+	query := `
+		INSERT INTO projects (name, client_id, rate, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?)`
+	res, err := s.DB.Exec(query, project.Name, project.ClientID, project.Rate, project.CreatedAt, project.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	project.ID = id
+	return nil
+}
+
+// GetProject retrieves a project by its ID.
+func (s *Store) GetProject(id int64) (*chronos.Project, error) {
+	project := &chronos.Project{}
+	query := `
+		SELECT id, name, client_id, rate, created_at, updated_at
+		FROM projects WHERE id = ?`
+	err := s.DB.QueryRow(query, id).Scan(&project.ID, &project.Name, &project.ClientID, &project.Rate, &project.CreatedAt, &project.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("GetProject: no project found with ID %d: %w", id, chronos.ErrNotFound)
+		}
+		return nil, err
+	}
+	return project, nil
+}
+
+// UpdateProject updates an existing project.
+func (s *Store) UpdateProject(project *chronos.Project) error {
+	query := `
+		UPDATE projects
+		SET name = ?, client_id = ?, rate = ?, updated_at = ?
+		WHERE id = ?`
+	_, err := s.DB.Exec(query, project.Name, project.ClientID, project.Rate, project.UpdatedAt, project.ID)
+	return err
+}
+
+// DeleteProject removes a project by its ID.
+func (s *Store) DeleteProject(id int64) error {
+	_, err := s.DB.Exec("DELETE FROM projects WHERE id = ?", id)
+	return err
+}
+
+// ListProjects retrieves projects, optionally filtered by clientID.
+func (s *Store) ListProjects(clientID *int64) ([]*chronos.Project, error) {
+	var rows *sql.Rows
+	var err error
+
+	query := `SELECT id, name, client_id, rate, created_at, updated_at FROM projects`
+	if clientID != nil {
+		rows, err = s.DB.Query(query+` WHERE client_id = ? ORDER BY id`, *clientID)
+	} else {
+		rows, err = s.DB.Query(query + ` ORDER BY id`)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	projects := []*chronos.Project{}
+	for rows.Next() {
+		project := &chronos.Project{}
+		err := rows.Scan(&project.ID, &project.Name, &project.ClientID, &project.Rate, &project.CreatedAt, &project.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, project)
+	}
+	return projects, rows.Err()
+}
@@ -0,0 +1,56 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/regiellis/chronos-go/chronos"
+)
+
+// CreateRate inserts a rate and sets its ID.
+func (s *Store) CreateRate(r *chronos.Rate) error {
+	res, err := s.DB.Exec(`
+		INSERT INTO rates (scope, target, amount, effective_from, created_at)
+		VALUES (?, ?, ?, ?, ?)`,
+		r.Scope, r.Target, r.Amount, r.EffectiveFrom, r.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("CreateRate: failed to execute insert: %w", err)
+	}
+	if r.ID, err = res.LastInsertId(); err != nil {
+		return fmt.Errorf("CreateRate: failed to get last insert ID: %w", err)
+	}
+	return nil
+}
+
+// ListRates retrieves all rates by scope, target and effective date.
+func (s *Store) ListRates() ([]*chronos.Rate, error) {
+	rows, err := s.DB.Query(`
+		SELECT id, scope, target, amount, effective_from, created_at
+		FROM rates ORDER BY scope, target, effective_from, id`)
+	if err != nil {
+		return nil, fmt.Errorf("ListRates: failed to execute query: %w", err)
+	}
+	defer rows.Close()
+	rates := []*chronos.Rate{}
+	for rows.Next() {
+		r := &chronos.Rate{}
+		var createdAt sql.NullTime
+		if err := rows.Scan(&r.ID, &r.Scope, &r.Target, &r.Amount, &r.EffectiveFrom, &createdAt); err != nil {
+			return nil, fmt.Errorf("ListRates: failed to scan row: %w", err)
+		}
+		r.CreatedAt = createdAt.Time
+		rates = append(rates, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ListRates: error during rows iteration: %w", err)
+	}
+	return rates, nil
+}
+
+// DeleteRate removes a rate by its ID.
+func (s *Store) DeleteRate(id int64) error {
+	if _, err := s.DB.Exec("DELETE FROM rates WHERE id = ?", id); err != nil {
+		return fmt.Errorf("DeleteRate: failed to execute delete: %w", err)
+	}
+	return nil
+}
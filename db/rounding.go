@@ -0,0 +1,66 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/regiellis/chronos-go/chronos"
+)
+
+// CreateRoundingRule inserts a rounding rule and sets its ID.
+func (s *Store) CreateRoundingRule(r *chronos.RoundingRule) error {
+	res, err := s.DB.Exec(`
+		INSERT INTO rounding_rules (scope, target, increment, direction, per, minimum, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		r.Scope, r.Target, r.Increment, r.Direction, r.Per, r.Minimum, r.CreatedAt, r.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("CreateRoundingRule: failed to execute insert: %w", err)
+	}
+	if r.ID, err = res.LastInsertId(); err != nil {
+		return fmt.Errorf("CreateRoundingRule: failed to get last insert ID: %w", err)
+	}
+	return nil
+}
+
+// UpdateRoundingRule updates a rounding rule.
+func (s *Store) UpdateRoundingRule(r *chronos.RoundingRule) error {
+	_, err := s.DB.Exec(`
+		UPDATE rounding_rules
+		SET scope = ?, target = ?, increment = ?, direction = ?, per = ?, minimum = ?, updated_at = ?
+		WHERE id = ?`,
+		r.Scope, r.Target, r.Increment, r.Direction, r.Per, r.Minimum, r.UpdatedAt, r.ID)
+	if err != nil {
+		return fmt.Errorf("UpdateRoundingRule: failed to execute update: %w", err)
+	}
+	return nil
+}
+
+// ListRoundingRules retrieves all rounding rules by scope and target.
+func (s *Store) ListRoundingRules() ([]*chronos.RoundingRule, error) {
+	rows, err := s.DB.Query(`
+		SELECT id, scope, target, increment, direction, per, minimum, created_at, updated_at
+		FROM rounding_rules ORDER BY scope, target`)
+	if err != nil {
+		return nil, fmt.Errorf("ListRoundingRules: failed to execute query: %w", err)
+	}
+	defer rows.Close()
+	rules := []*chronos.RoundingRule{}
+	for rows.Next() {
+		r := &chronos.RoundingRule{}
+		if err := rows.Scan(&r.ID, &r.Scope, &r.Target, &r.Increment, &r.Direction, &r.Per, &r.Minimum, &r.CreatedAt, &r.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("ListRoundingRules: failed to scan row: %w", err)
+		}
+		rules = append(rules, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ListRoundingRules: error during rows iteration: %w", err)
+	}
+	return rules, nil
+}
+
+// DeleteRoundingRule removes a rounding rule by its ID.
+func (s *Store) DeleteRoundingRule(id int64) error {
+	if _, err := s.DB.Exec("DELETE FROM rounding_rules WHERE id = ?", id); err != nil {
+		return fmt.Errorf("DeleteRoundingRule: failed to execute delete: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/regiellis/chronos-go/chronos"
+)
+
+// SaveTemplate saves or updates an entry template.
+func (s *Store) SaveTemplate(name string, entryText string) error {
+	query := `INSERT OR REPLACE INTO templates (name, entry) VALUES (?, ?)`
+	_, err := s.DB.Exec(query, name, entryText)
+	if err != nil {
+		return fmt.Errorf("failed to save template '%s': %w", name, err)
+	}
+	return nil
+}
+
+// GetTemplate retrieves an entry template by its name.
+func (s *Store) GetTemplate(name string) (string, error) {
+	var entryText string
+	query := `SELECT entry FROM templates WHERE name = ?`
+	err := s.DB.QueryRow(query, name).Scan(&entryText)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", fmt.Errorf("template '%s': %w", name, chronos.ErrNotFound)
+		}
+		return "", fmt.Errorf("failed to get template '%s': %w", name, err)
+	}
+	return entryText, nil
+}
@@ -0,0 +1,63 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/regiellis/chronos-go/chronos"
+)
+
+// GetClientTerms retrieves a client's invoice terms in order.
+func (s *Store) GetClientTerms(client string) ([]*chronos.Adjustment, error) {
+	rows, err := s.DB.Query(`SELECT id, kind, name, percent, amount FROM client_terms WHERE client = ? ORDER BY position`, client)
+	if err != nil {
+		return nil, fmt.Errorf("GetClientTerms: failed to execute query: %w", err)
+	}
+	defer rows.Close()
+	var terms []*chronos.Adjustment
+	for rows.Next() {
+		a := &chronos.Adjustment{}
+		if err := rows.Scan(&a.ID, &a.Kind, &a.Name, &a.Percent, &a.Amount); err != nil {
+			return nil, fmt.Errorf("GetClientTerms: failed to scan row: %w", err)
+		}
+		terms = append(terms, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("GetClientTerms: error during rows iteration: %w", err)
+	}
+	return terms, nil
+}
+
+// SetClientTerms replaces a client's invoice terms in a single transaction
+// and sets their IDs.
+func (s *Store) SetClientTerms(client string, terms []*chronos.Adjustment) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("SetClientTerms: failed to begin transaction: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM client_terms WHERE client = ?`, client); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("SetClientTerms: failed to clear terms: %w", err)
+	}
+	ids := make([]int64, len(terms))
+	for i, a := range terms {
+		res, err := tx.Exec(`
+			INSERT INTO client_terms (client, position, kind, name, percent, amount)
+			VALUES (?, ?, ?, ?, ?, ?)`,
+			client, i, a.Kind, a.Name, a.Percent, a.Amount)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("SetClientTerms: failed to insert %q: %w", a.Name, err)
+		}
+		if ids[i], err = res.LastInsertId(); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("SetClientTerms: failed to get last insert ID: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("SetClientTerms: %w", err)
+	}
+	for i, a := range terms {
+		a.ID = ids[i]
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package llm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultCacheTTL is how long cached replies are reused when LLM_CACHE_TTL is
+// not set.
+const DefaultCacheTTL = 30 * 24 * time.Hour
+
+// Cache stores replies to deterministic LLM calls on disk, one JSON file per
+// key, so repeating a call on unchanged data is instant and gives the same
+// answer. A nil *Cache caches nothing.
+type Cache struct {
+	Dir string
+	// TTL is how long a reply stays valid; DefaultCacheTTL when zero.
+	TTL time.Duration
+	// Now defaults to time.Now.
+	Now func() time.Time
+}
+
+type cachedReply struct {
+	Provider  string    `json:"provider"`
+	Model     string    `json:"model"`
+	Prompt    string    `json:"prompt"`
+	Answer    string    `json:"answer"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CacheKey identifies a call by provider, model, prompt template version,
+// redaction setting (Redactor.Setting) and a hash of the data the template was
+// rendered with.
+func CacheKey(provider, model, prompt, version, redaction string, data interface{}) (string, error) {
+	input, err := json.Marshal(data)
+	if err != nil {
+		return "", fmt.Errorf("cache key: %w", err)
+	}
+	h := sha256.New()
+	for _, part := range []string{provider, model, prompt, version, redaction} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	h.Write(input)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.Dir, key+".json")
+}
+
+func (c *Cache) now() time.Time {
+	if c.Now != nil {
+		return c.Now()
+	}
+	return time.Now()
+}
+
+// Get returns the reply stored under key unless it is missing or expired.
+// Expired replies are removed.
+func (c *Cache) Get(key string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	b, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return "", false
+	}
+	var r cachedReply
+	if err := json.Unmarshal(b, &r); err != nil {
+		return "", false
+	}
+	ttl := c.TTL
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+	if c.now().Sub(r.CreatedAt) > ttl {
+		os.Remove(c.path(key))
+		return "", false
+	}
+	return r.Answer, true
+}
+
+// Put stores answer under key.
+func (c *Cache) Put(key, provider, model, prompt, answer string) error {
+	if c == nil {
+		return nil
+	}
+	if err := os.MkdirAll(c.Dir, 0700); err != nil {
+		return fmt.Errorf("create cache directory: %w", err)
+	}
+	b, err := json.MarshalIndent(cachedReply{provider, model, prompt, answer, c.now()}, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Write then rename so a concurrent reader never sees half a file.
+	tmp := c.path(key) + ".tmp"
+	if err := os.WriteFile(tmp, b, 0600); err != nil {
+		return fmt.Errorf("write cache: %w", err)
+	}
+	return os.Rename(tmp, c.path(key))
+}
+
+// Clear removes every cached reply and reports how many there were.
+func (c *Cache) Clear() (int, error) {
+	if c == nil {
+		return 0, nil
+	}
+	files, err := filepath.Glob(filepath.Join(c.Dir, "*.json"))
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, f := range files {
+		if err := os.Remove(f); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
@@ -0,0 +1,73 @@
+package llm
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/regiellis/chronos-go/chronos"
+)
+
+func TestCachedSummaries(t *testing.T) {
+	now := parseNow
+	cache := &Cache{Dir: t.TempDir(), TTL: time.Hour, Now: func() time.Time { return now }}
+	prompts := Prompts{Dir: t.TempDir()}
+	p := &scriptedProvider{replies: []string{"First summary.", "Second summary.", "Third summary.", "Fourth summary."}}
+	c := &Client{Provider: p, Cache: cache, Prompts: prompts, Now: func() time.Time { return now }}
+	block := &chronos.Block{ID: 1, Name: "Sprint", Client: "Acme", Project: "Apollo"}
+	entries := []*chronos.Entry{{ID: 1, Project: "Apollo", Task: "API", Summary: "Auth", Duration: 60}}
+	ctx := context.Background()
+
+	summarize := func() string {
+		t.Helper()
+		var streamed string
+		c.OnToken = func(tok string) { streamed += tok }
+		out, err := c.SummarizeBlock(ctx, block, entries)
+		if err != nil || streamed != out {
+			t.Fatalf("SummarizeBlock: %q (streamed %q), %v", out, streamed, err)
+		}
+		return out
+	}
+	if summarize() != "First summary." || summarize() != "First summary." || len(p.prompts) != 1 {
+		t.Fatalf("an unchanged block should be answered from the cache, %d prompt(s) sent", len(p.prompts))
+	}
+
+	entries[0].Duration = 90
+	if summarize() != "Second summary." {
+		t.Error("changed data should miss the cache")
+	}
+
+	if err := os.WriteFile(prompts.Path(PromptSummarize), []byte("Summarize {{.Block.Name}} briefly."), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if summarize() != "Third summary." {
+		t.Error("an edited template should miss the cache")
+	}
+
+	now = now.Add(2 * time.Hour)
+	if summarize() != "Fourth summary." {
+		t.Error("expired replies should not be reused")
+	}
+
+	c.Redactor, _ = NewRedactor(RedactClients, "")
+	p.replies = []string{"Redacted summary."}
+	if summarize() != "Redacted summary." {
+		t.Error("a different redaction setting should miss the cache")
+	}
+	c.Redactor = nil
+
+	c.Cache = nil // --no-cache
+	p.replies = []string{"Fresh."}
+	if summarize() != "Fresh." {
+		t.Error("a nil cache should always ask the model")
+	}
+
+	if n, err := cache.Clear(); n != 4 || err != nil {
+		t.Errorf("Clear: %d, %v", n, err)
+	}
+	if _, ok := cache.Get(strings.Repeat("0", 64)); ok {
+		t.Error("unknown keys should miss")
+	}
+}
@@ -0,0 +1,135 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	log "github.com/charmbracelet/log"
+	"github.com/regiellis/chronos-go/chronos"
+)
+
+// classifyBatch is how many entries go into one classification prompt.
+const classifyBatch = 20
+
+// classifySchema is the JSON schema for classification replies.
+var classifySchema = json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "classifications": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "properties": {
+          "entry_id": {"type": "integer"},
+          "project": {"type": "string"},
+          "client": {"type": "string"},
+          "task": {"type": "string"},
+          "confidence": {"type": "number", "minimum": 0, "maximum": 1}
+        },
+        "required": ["entry_id", "project", "task"]
+      }
+    }
+  },
+  "required": ["classifications"]
+}`)
+
+type llmClassification struct {
+	EntryID    int64   `json:"entry_id"`
+	Project    string  `json:"project"`
+	Client     string  `json:"client"`
+	Task       string  `json:"task"`
+	Confidence float64 `json:"confidence"`
+}
+
+// ClassifyEntries asks the model to file unclassified entries under the
+// known categories. Proposals for unknown entries or projects are dropped,
+// so entries the model cannot place are simply left out. Replies that are
+// not valid JSON are re-prompted up to MaxRepairs times.
+func (c *Client) ClassifyEntries(ctx context.Context, entries []*chronos.Entry, categories []chronos.Category) ([]chronos.Classification, error) {
+	var out []chronos.Classification
+	for start := 0; start < len(entries); start += classifyBatch {
+		batch := entries[start:min(start+classifyBatch, len(entries))]
+		classified, err := c.classifyBatch(ctx, batch, categories)
+		if err != nil {
+			log.Error("[LLM] ClassifyEntries failed", "error", err)
+			return out, err
+		}
+		out = append(out, classified...)
+	}
+	return out, nil
+}
+
+func (c *Client) classifyBatch(ctx context.Context, entries []*chronos.Entry, categories []chronos.Category) ([]chronos.Classification, error) {
+	prompt, err := c.Prompts.Render(PromptClassify, struct {
+		Schema     string
+		Categories []chronos.Category
+		Entries    []*chronos.Entry
+	}{string(classifySchema), categories, entries})
+	if err != nil {
+		return nil, err
+	}
+	var lastErr error
+	for attempt := 0; attempt <= c.MaxRepairs; attempt++ {
+		output, err := c.generateJSON(ctx, prompt, classifySchema)
+		if err != nil {
+			return nil, fmt.Errorf("LLM classification request failed: %w", err)
+		}
+		classified, err := decodeClassifications(output, entries, categories)
+		if err == nil {
+			return classified, nil
+		}
+		log.Warn("[LLM] ClassifyEntries reply rejected", "attempt", attempt+1, "error", err)
+		lastErr = err
+		prompt = fmt.Sprintf("%s\n\nYour previous reply was:\n%s\nIt was rejected: %v\nReply with the corrected JSON object only.", prompt, output, err)
+	}
+	return nil, fmt.Errorf("LLM did not return valid classifications after %d attempt(s): %v", c.MaxRepairs+1, lastErr)
+}
+
+// decodeClassifications decodes a reply and keeps the proposals that name
+// one of entries and, when there are categories, one of their projects.
+func decodeClassifications(output string, entries []*chronos.Entry, categories []chronos.Category) ([]chronos.Classification, error) {
+	first, last := strings.IndexByte(output, '{'), strings.LastIndexByte(output, '}')
+	if first < 0 || last < first {
+		return nil, fmt.Errorf("reply contains no JSON object")
+	}
+	var resp struct {
+		Classifications []llmClassification `json:"classifications"`
+	}
+	if err := json.Unmarshal([]byte(output[first:last+1]), &resp); err != nil {
+		return nil, fmt.Errorf("reply does not match the schema: %v", err)
+	}
+
+	byID := map[int64]*chronos.Entry{}
+	for _, e := range entries {
+		byID[e.ID] = e
+	}
+	var out []chronos.Classification
+	for _, r := range resp.Classifications {
+		entry, ok := byID[r.EntryID]
+		if !ok || strings.TrimSpace(r.Project) == "" {
+			continue
+		}
+		project, known := strings.TrimSpace(r.Project), len(categories) == 0
+		var projectID int64
+		for _, cat := range categories {
+			if strings.EqualFold(cat.Project, project) {
+				project, projectID, known = cat.Project, cat.ProjectID, true
+				break
+			}
+		}
+		if !known {
+			log.Warn("[LLM] ClassifyEntries proposed an unknown project", "entry", r.EntryID, "project", project)
+			continue
+		}
+		confidence := max(0, min(r.Confidence, 1))
+		classified := chronos.NewClassification(entry, project, strings.TrimSpace(r.Client), strings.TrimSpace(r.Task), confidence, "llm")
+		if classified.ProjectID == 0 {
+			classified.ProjectID = projectID
+		}
+		out = append(out, classified)
+		delete(byID, r.EntryID) // One proposal per entry
+	}
+	return out, nil
+}
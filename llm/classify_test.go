@@ -0,0 +1,40 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/regiellis/chronos-go/chronos"
+)
+
+func TestClassifyEntries(t *testing.T) {
+	p := &constrainedProvider{scriptedProvider{replies: []string{
+		"Sure! Here you go.",
+		`{"classifications":[
+			{"entry_id":1,"project":"apollo","task":"API","confidence":1.4},
+			{"entry_id":1,"project":"Zeus","task":"Ops"},
+			{"entry_id":2,"project":"Hermes","task":"Ops"},
+			{"entry_id":99,"project":"Apollo","task":"API"}
+		]}`,
+	}}}
+	entries := []*chronos.Entry{
+		{ID: 1, Client: "Initech", Summary: "Token refresh"},
+		{ID: 2, Summary: "Something new"},
+	}
+	categories := []chronos.Category{{ProjectID: 7, Project: "Apollo", Client: "Acme", Task: "API", Count: 3}, {Project: "Zeus", Task: "Ops"}}
+	got, err := testParser(p).ClassifyEntries(context.Background(), entries, categories)
+	if err != nil {
+		t.Fatalf("ClassifyEntries failed: %v", err)
+	}
+	if len(p.schemas) != 2 || !json.Valid(p.schemas[0]) {
+		t.Fatalf("expected the schema on the first try and the repair, got %d", len(p.schemas))
+	}
+	if len(got) != 1 {
+		t.Fatalf("expected only the proposal for entry 1 to survive, got %+v", got)
+	}
+	want := chronos.Classification{EntryID: 1, Category: chronos.Category{ProjectID: 7, Project: "Apollo", Client: "Initech", Task: "API"}, Confidence: 1, Source: "llm"}
+	if got[0] != want {
+		t.Errorf("got %+v, want %+v", got[0], want)
+	}
+}
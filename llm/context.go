@@ -0,0 +1,341 @@
+package llm
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/regiellis/chronos-go/chronos"
+)
+
+// Defaults for ContextBuilder. A 7B model typically has a 4k context; this
+// leaves room for the instructions and the answer.
+const (
+	DefaultContextBudget = 1500 // tokens
+	DefaultRecentEntries = 15
+	DefaultLookbackDays  = 30
+)
+
+// charsPerToken is the usual rough estimate for English text.
+const charsPerToken = 4
+
+// Period is the span of time a prompt is about; To is exclusive.
+type Period struct {
+	From, To time.Time
+	Label    string
+}
+
+func (p Period) contains(t time.Time) bool {
+	return !t.Before(p.From) && t.Before(p.To)
+}
+
+// LastDays is the period covering today and the n-1 days before it.
+func LastDays(now time.Time, n int) Period {
+	today := startOfDay(now)
+	return Period{From: today.AddDate(0, 0, 1-n), To: today.AddDate(0, 0, 1), Label: fmt.Sprintf("last %d days", n)}
+}
+
+var (
+	lastNDaysRe = regexp.MustCompile(`\b(?:last|past) (\d+) days?\b`)
+	monthRe     = regexp.MustCompile(`\b(?:(in|for|during|of) )?(january|february|march|april|may|june|july|august|september|october|november|december)\b`)
+)
+
+// PeriodFor picks the period a question refers to: today, yesterday, this or
+// last week/month/year, "last N days" or a month name ("in May"). Questions
+// that name none get the last DefaultLookbackDays days.
+func PeriodFor(question string, now time.Time) Period {
+	q := strings.ToLower(question)
+	today := startOfDay(now)
+	week := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7)) // Monday
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	year := time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location())
+
+	switch {
+	case strings.Contains(q, "today"):
+		return Period{From: today, To: today.AddDate(0, 0, 1), Label: "today"}
+	case strings.Contains(q, "yesterday"):
+		return Period{From: today.AddDate(0, 0, -1), To: today, Label: "yesterday"}
+	case strings.Contains(q, "this week"):
+		return Period{From: week, To: week.AddDate(0, 0, 7), Label: "this week"}
+	case strings.Contains(q, "last week"):
+		return Period{From: week.AddDate(0, 0, -7), To: week, Label: "last week"}
+	case strings.Contains(q, "this month"):
+		return Period{From: month, To: month.AddDate(0, 1, 0), Label: "this month"}
+	case strings.Contains(q, "last month"):
+		return Period{From: month.AddDate(0, -1, 0), To: month, Label: "last month"}
+	case strings.Contains(q, "this year"):
+		return Period{From: year, To: year.AddDate(1, 0, 0), Label: "this year"}
+	case strings.Contains(q, "last year"):
+		return Period{From: year.AddDate(-1, 0, 0), To: year, Label: "last year"}
+	}
+	if m := lastNDaysRe.FindStringSubmatch(q); m != nil {
+		if n, err := strconv.Atoi(m[1]); err == nil && n > 0 {
+			return LastDays(now, n)
+		}
+	}
+	for _, m := range monthRe.FindAllStringSubmatch(q, -1) {
+		// "may" is also a verb; only count it after a preposition.
+		if m[2] == "may" && m[1] == "" {
+			continue
+		}
+		t, err := time.Parse("January", m[2])
+		if err != nil {
+			continue
+		}
+		from := time.Date(now.Year(), t.Month(), 1, 0, 0, 0, 0, now.Location())
+		if from.After(now) {
+			from = from.AddDate(-1, 0, 0)
+		}
+		return Period{From: from, To: from.AddDate(0, 1, 0), Label: from.Format("January 2006")}
+	}
+	return LastDays(now, DefaultLookbackDays)
+}
+
+// ContextBuilder condenses entries and blocks into a short plain-text brief
+// for prompts: totals for the period, active block progress, hours per
+// project, client and day, and the latest entries, cut to fit Budget.
+type ContextBuilder struct {
+	Budget int // tokens; DefaultContextBudget when zero
+	Recent int // latest entries to list; DefaultRecentEntries when zero
+	Now    func() time.Time
+}
+
+// budget tracks the characters left for the brief.
+type budget struct {
+	b    strings.Builder
+	left int
+}
+
+// line adds s if it fits and reports whether it did.
+func (w *budget) line(s string) bool {
+	if len(s)+1 > w.left {
+		return false
+	}
+	w.b.WriteString(s)
+	w.b.WriteByte('\n')
+	w.left -= len(s) + 1
+	return true
+}
+
+// list adds "title: a, b, c" with as many items as fit, noting how many were cut.
+func (w *budget) list(title string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	line := title + ":"
+	for i, item := range items {
+		more := fmt.Sprintf(" ... and %d more", len(items)-i)
+		next := line + " " + item
+		if i < len(items)-1 {
+			next += ","
+		}
+		if len(next)+len(more)+1 > w.left {
+			w.line(line + more)
+			return
+		}
+		line = next
+	}
+	w.line(line)
+}
+
+func (b ContextBuilder) start() (*budget, time.Time) {
+	tokens := b.Budget
+	if tokens <= 0 {
+		tokens = DefaultContextBudget
+	}
+	now := time.Now()
+	if b.Now != nil {
+		now = b.Now()
+	}
+	return &budget{left: tokens * charsPerToken}, now
+}
+
+// Build writes the brief for period.
+func (b ContextBuilder) Build(period Period, entries []*chronos.Entry, blocks []*chronos.Block) string {
+	w, now := b.start()
+
+	var inPeriod []*chronos.Entry
+	var total, billable, unbilled float64
+	for _, e := range entries {
+		if e == nil || !period.contains(e.StartTime) {
+			continue
+		}
+		inPeriod = append(inPeriod, e)
+		h := e.Hours()
+		if e.IsOpen() {
+			h = e.Worked(now).Hours()
+		}
+		total += h
+		if e.Billable {
+			billable += h
+			if !e.Invoiced {
+				unbilled += h
+			}
+		}
+	}
+	w.line(fmt.Sprintf("Now: %s. Period: %s (%s to %s): %d entries, %.2fh total, %.2fh billable, %.2fh not yet invoiced.",
+		now.Format("Mon 2006-01-02 15:04"), period.Label, period.From.Format("2006-01-02"), period.To.AddDate(0, 0, -1).Format("2006-01-02"),
+		len(inPeriod), total, billable, unbilled))
+
+	for _, block := range blocks {
+		if block != nil && block.Active {
+			w.line(blockProgress(block, entries, now))
+		}
+	}
+
+	w.list("Hours by project", rankedTotals(chronos.CalculateTotalsBy(inPeriod, func(e *chronos.Entry) string { return e.Project })))
+	w.list("Hours by client", rankedTotals(chronos.CalculateTotalsBy(inPeriod, func(e *chronos.Entry) string { return e.Client })))
+
+	days := chronos.CalculateTotalsBy(inPeriod, func(e *chronos.Entry) string { return e.StartTime.Format("Mon 2006-01-02") })
+	dayNames := make([]string, 0, len(days))
+	for d := range days {
+		dayNames = append(dayNames, d)
+	}
+	sort.Slice(dayNames, func(i, j int) bool { return dayNames[i][4:] > dayNames[j][4:] }) // newest first
+	dayItems := make([]string, len(dayNames))
+	for i, d := range dayNames {
+		dayItems[i] = fmt.Sprintf("%s %.2fh", d, days[d]/60)
+	}
+	w.list("Hours by day", dayItems)
+
+	recent := b.Recent
+	if recent <= 0 {
+		recent = DefaultRecentEntries
+	}
+	latest := append([]*chronos.Entry(nil), inPeriod...)
+	chronos.SortEntriesByStartTime(latest, false)
+	if len(latest) > recent {
+		latest = latest[:recent]
+	}
+	if len(latest) > 0 && w.line("Latest entries (newest first):") {
+		for i, e := range latest {
+			if !w.line(entryLine(e, now)) {
+				w.line(fmt.Sprintf("... and %d more", len(latest)-i))
+				break
+			}
+		}
+	}
+	return strings.TrimRight(w.b.String(), "\n")
+}
+
+// Names lists the distinct projects, clients and tasks, those containing
+// partial first, for autocompletion.
+func (b ContextBuilder) Names(partial string, entries []*chronos.Entry, blocks []*chronos.Block) string {
+	w, _ := b.start()
+	latest := append([]*chronos.Entry(nil), entries...)
+	chronos.SortEntriesByStartTime(latest, false)
+
+	collect := func(values func(add func(string))) []string {
+		var matches, others []string
+		seen := map[string]bool{}
+		values(func(v string) {
+			key := strings.ToLower(v)
+			if v == "" || seen[key] {
+				return
+			}
+			seen[key] = true
+			if partial != "" && strings.Contains(key, strings.ToLower(partial)) {
+				matches = append(matches, v)
+			} else {
+				others = append(others, v)
+			}
+		})
+		return append(matches, others...)
+	}
+	w.list("Projects", collect(func(add func(string)) {
+		for _, blk := range blocks {
+			add(blk.Project)
+		}
+		for _, e := range latest {
+			add(e.Project)
+		}
+	}))
+	w.list("Clients", collect(func(add func(string)) {
+		for _, blk := range blocks {
+			add(blk.Client)
+		}
+		for _, e := range latest {
+			add(e.Client)
+		}
+	}))
+	w.list("Tasks", collect(func(add func(string)) {
+		for _, e := range latest {
+			add(e.Task)
+		}
+	}))
+	return strings.TrimRight(w.b.String(), "\n")
+}
+
+func blockProgress(block *chronos.Block, entries []*chronos.Entry, now time.Time) string {
+	var logged float64
+	for _, e := range entries {
+		if e != nil && e.BlockID == block.ID {
+			logged += e.Hours()
+		}
+	}
+	s := fmt.Sprintf("Active block: %q (client %s, project %s) since %s, %.2fh logged", block.Name, orNone(block.Client), orNone(block.Project), block.StartTime.Format("2006-01-02"), logged)
+	if !block.EndTime.IsZero() {
+		days := int(block.EndTime.Sub(now).Hours() / 24)
+		s += fmt.Sprintf(", ends %s (%d days left)", block.EndTime.Format("2006-01-02"), days)
+	}
+	return s + "."
+}
+
+func entryLine(e *chronos.Entry, now time.Time) string {
+	name := orNone(e.Project)
+	if e.Task != "" {
+		name += " / " + e.Task
+	}
+	hours := e.Hours()
+	status := ""
+	switch {
+	case e.IsOpen():
+		hours = e.Worked(now).Hours()
+		status = " [running]"
+	case e.Billable && e.Invoiced:
+		status = " [invoiced]"
+	case e.Billable:
+		status = " [billable]"
+	}
+	line := fmt.Sprintf("- %s %.2fh %s", e.StartTime.Format("Mon 2006-01-02 15:04"), hours, name)
+	if e.Client != "" {
+		line += " @" + e.Client
+	}
+	if e.Summary != "" {
+		line += ": " + e.Summary
+	}
+	return line + status
+}
+
+// rankedTotals formats minute totals as "name 1.50h", largest first.
+func rankedTotals(totals map[string]float64) []string {
+	names := make([]string, 0, len(totals))
+	for name := range totals {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if totals[names[i]] != totals[names[j]] {
+			return totals[names[i]] > totals[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	items := make([]string, len(names))
+	for i, name := range names {
+		items[i] = fmt.Sprintf("%s %.2fh", name, totals[name]/60)
+	}
+	return items
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
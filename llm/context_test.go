@@ -0,0 +1,113 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/regiellis/chronos-go/chronos"
+)
+
+// Wednesday.
+var contextNow = time.Date(2025, 6, 11, 15, 0, 0, 0, time.UTC)
+
+func TestPeriodFor(t *testing.T) {
+	day := func(m time.Month, d int) time.Time { return time.Date(2025, m, d, 0, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		question string
+		from, to time.Time
+	}{
+		{"what did I do today?", day(6, 11), day(6, 12)},
+		{"hours yesterday", day(6, 10), day(6, 11)},
+		{"how much this week", day(6, 9), day(6, 16)},
+		{"billable last week?", day(6, 2), day(6, 9)},
+		{"last month by client", day(5, 1), day(6, 1)},
+		{"what did I work on in May", day(5, 1), day(6, 1)},
+		{"hours for september", time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)},
+		{"the past 3 days", day(6, 9), day(6, 12)},
+		{"may I see my top project?", day(5, 13), day(6, 12)},
+	}
+	for _, tt := range tests {
+		p := PeriodFor(tt.question, contextNow)
+		if !p.From.Equal(tt.from) || !p.To.Equal(tt.to) {
+			t.Errorf("%q: got %s to %s, want %s to %s", tt.question, p.From, p.To, tt.from, tt.to)
+		}
+	}
+}
+
+func contextEntries(n int) []*chronos.Entry {
+	var entries []*chronos.Entry
+	for i := 0; i < n; i++ {
+		start := contextNow.Add(-time.Duration(i) * 6 * time.Hour)
+		entries = append(entries, &chronos.Entry{
+			BlockID:   1,
+			Project:   fmt.Sprintf("Project%d", i%7),
+			Client:    "Acme",
+			Task:      "Task",
+			Summary:   fmt.Sprintf("work item %d", i),
+			StartTime: start,
+			EndTime:   start.Add(time.Hour),
+			Duration:  60,
+			Billable:  true,
+		})
+	}
+	return entries
+}
+
+func TestContextBuilder_Build(t *testing.T) {
+	blocks := []*chronos.Block{{ID: 1, Name: "June sprint", Client: "Acme", StartTime: contextNow.AddDate(0, 0, -10), Active: true}}
+	b := ContextBuilder{Now: func() time.Time { return contextNow }}
+	out := b.Build(PeriodFor("yesterday", contextNow), contextEntries(400), blocks)
+
+	for _, want := range []string{"Period: yesterday", "4 entries, 4.00h total", `Active block: "June sprint"`, "400.00h logged", "Hours by client: Acme 4.00h", "work item 4"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("brief lacks %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "work item 3\n") || strings.Contains(out, "work item 8") {
+		t.Errorf("brief includes entries outside the period:\n%s", out)
+	}
+}
+
+func TestContextBuilder_Budget(t *testing.T) {
+	entries := contextEntries(2000)
+	for _, budget := range []int{60, 200, 1500} {
+		b := ContextBuilder{Budget: budget, Now: func() time.Time { return contextNow }}
+		out := b.Build(LastDays(contextNow, 365), entries, nil)
+		if len(out) > budget*charsPerToken {
+			t.Errorf("budget %d: brief is %d chars", budget, len(out))
+		}
+		if !strings.HasPrefix(out, "Now: ") {
+			t.Errorf("budget %d: the period totals should always come first:\n%s", budget, out)
+		}
+	}
+	small := ContextBuilder{Budget: 100, Now: func() time.Time { return contextNow }}.Build(LastDays(contextNow, 365), entries, nil)
+	if !strings.Contains(small, "more") {
+		t.Errorf("truncated lists should say how much was left out:\n%s", small)
+	}
+}
+
+func TestContextBuilder_Names(t *testing.T) {
+	entries := []*chronos.Entry{
+		{Project: "Website", Client: "Acme", Task: "Deploy", StartTime: contextNow},
+		{Project: "apollo", Client: "acme", Task: "API", StartTime: contextNow.Add(-time.Hour)},
+		{Project: "Apollo", Client: "Zeta", Task: "API", StartTime: contextNow.Add(-2 * time.Hour)},
+	}
+	out := ContextBuilder{}.Names("apo", entries, nil)
+	if !strings.Contains(out, "Projects: apollo, Website\n") || !strings.Contains(out, "Clients: Acme, Zeta\n") || !strings.HasSuffix(out, "Tasks: Deploy, API") {
+		t.Errorf("unexpected names:\n%s", out)
+	}
+}
+
+func TestAnswerUserQueryUsesBrief(t *testing.T) {
+	p := &scriptedProvider{replies: []string{"4 hours."}}
+	c := &Client{Provider: p, Now: func() time.Time { return contextNow }, ContextBudget: 500}
+	if _, err := c.AnswerUserQuery(context.Background(), "how long did I work yesterday?", contextEntries(5000), nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.prompts[0]) > 500*charsPerToken+400 || strings.Contains(p.prompts[0], `"start_time"`) {
+		t.Errorf("prompt should carry the brief, not the raw entries (%d chars)", len(p.prompts[0]))
+	}
+}
@@ -0,0 +1,343 @@
+package llm
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/regiellis/chronos-go/chronos"
+)
+
+// ErrNoDuration is returned by GrammarParser when the input names neither a
+// duration nor a time range.
+var ErrNoDuration = errors.New("no duration or time range found")
+
+// GrammarParser parses entries written in Chronos' entry grammar without an LLM:
+//
+//	<duration | range> [day] [on <project>[ – <task>]] [@client] [#tag...] [$rate] [-- <description>]
+//
+// Durations: 2h, 1h30m, 90m, 1.5h. Days: today, yesterday, mon, friday,
+// last friday, 2024-03-04. Ranges: 9:00-11:30, 9-11:30, 1pm-3pm.
+// Parts may appear in any order; words outside the grammar become the summary.
+type GrammarParser struct {
+	// Now returns the reference time for relative days; defaults to time.Now.
+	Now func() time.Time
+}
+
+// NewGrammarParser returns a GrammarParser using the wall clock.
+func NewGrammarParser() *GrammarParser {
+	return &GrammarParser{Now: time.Now}
+}
+
+var (
+	durationRe = regexp.MustCompile(`^(?:(\d+(?:\.\d+)?)(?:h|hr|hrs|hour|hours))?(?:(\d+)(?:m|min|mins|minutes)?)?$`)
+	clockRe    = `(\d{1,2})(?::(\d{2}))?\s*(am|pm)?`
+	rangeRe    = regexp.MustCompile(`^` + clockRe + `\s*[-–—]\s*` + clockRe + `$`)
+	rateRe     = regexp.MustCompile(`^\$(\d+(?:\.\d+)?)(?:/h|/hr)?$`)
+	// Separators between project and task inside an "on" phrase.
+	taskSeparators = []string{" – ", " — ", " - ", ": "}
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tues": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+// parsed collects the pieces of an entry before they are turned into times.
+type parsed struct {
+	duration    time.Duration
+	day         time.Time
+	hasDay      bool
+	rangeStart  time.Duration // offset from midnight
+	rangeEnd    time.Duration
+	hasRange    bool
+	project     []string
+	client      string
+	tags        []string
+	rate        float64
+	summary     []string
+	description string
+}
+
+// ParseEntry implements Parser.
+func (p *GrammarParser) ParseEntry(input string) (*chronos.Entry, error) {
+	now := time.Now()
+	if p.Now != nil {
+		now = p.Now()
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	var r parsed
+	text := strings.TrimSpace(input)
+	// Everything after a standalone "--" is the description, verbatim.
+	// Padding makes the offset in the padded string the offset of "--" in text.
+	if i := strings.Index(" "+text+" ", " -- "); i >= 0 {
+		r.description = strings.TrimSpace(text[i+2:])
+		text = strings.TrimSpace(text[:i])
+	}
+
+	tokens := tokenize(text)
+	inProject := false
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		lower := strings.ToLower(tok)
+
+		switch {
+		case strings.HasPrefix(tok, "@") && len(tok) > 1:
+			r.client = tok[1:]
+			inProject = false
+			continue
+		case strings.HasPrefix(tok, "#") && len(tok) > 1:
+			r.tags = append(r.tags, tok[1:])
+			inProject = false
+			continue
+		case rateRe.MatchString(tok):
+			r.rate, _ = strconv.ParseFloat(rateRe.FindStringSubmatch(tok)[1], 64)
+			inProject = false
+			continue
+		case lower == "on" && !inProject && len(r.project) == 0:
+			inProject = true
+			continue
+		}
+
+		// Times and days end a project name, except that a day word can only
+		// end it once the name has started ("on Monday Planning" stays intact).
+		if d, ok := parseDuration(lower); ok && r.duration == 0 {
+			r.duration = d
+			inProject = false
+			continue
+		}
+		if start, end, ok := parseRange(lower); ok && !r.hasRange {
+			r.rangeStart, r.rangeEnd, r.hasRange = start, end, true
+			inProject = false
+			continue
+		}
+		if !r.hasDay && (!inProject || len(r.project) > 0) {
+			if lower == "last" && i+1 < len(tokens) {
+				if wd, ok := weekdays[strings.ToLower(tokens[i+1])]; ok {
+					r.day, r.hasDay = lastWeekday(today, wd, true), true
+					i++
+					inProject = false
+					continue
+				}
+			}
+			if day, ok := parseDay(lower, today); ok {
+				r.day, r.hasDay = day, true
+				inProject = false
+				continue
+			}
+		}
+
+		if inProject {
+			r.project = append(r.project, tok)
+		} else {
+			r.summary = append(r.summary, tok)
+		}
+	}
+
+	return r.entry(now, today)
+}
+
+// entry resolves the parsed pieces against the reference time.
+func (r *parsed) entry(now, today time.Time) (*chronos.Entry, error) {
+	if r.duration == 0 && !r.hasRange {
+		return nil, ErrNoDuration
+	}
+	day := today
+	if r.hasDay {
+		day = r.day
+	}
+
+	entry := &chronos.Entry{
+		Client:   r.client,
+		Tags:     r.tags,
+		Rate:     r.rate,
+		Billable: true,
+	}
+	entry.Project, entry.Task = splitProjectTask(strings.Join(r.project, " "))
+
+	summary := strings.Join(r.summary, " ")
+	switch {
+	case r.description != "" && summary != "":
+		entry.Summary = summary + " " + r.description
+	case r.description != "":
+		entry.Summary = r.description
+	default:
+		entry.Summary = summary
+	}
+
+	if r.hasRange {
+		entry.StartTime = day.Add(r.rangeStart)
+		entry.EndTime = day.Add(r.rangeEnd)
+		if !entry.EndTime.After(entry.StartTime) {
+			return nil, fmt.Errorf("time range ends before it starts")
+		}
+		span := entry.EndTime.Sub(entry.StartTime)
+		if r.duration != 0 && r.duration != span {
+			return nil, fmt.Errorf("duration %v does not match time range %v", r.duration, span)
+		}
+		entry.Duration = int64(span.Minutes())
+		return entry, nil
+	}
+
+	// Without a range the entry ends now, moved onto the requested day.
+	entry.Duration = int64(r.duration.Minutes())
+	end := day.Add(now.Sub(today))
+	entry.StartTime = end.Add(-r.duration)
+	entry.EndTime = end
+	return entry, nil
+}
+
+// tokenize splits on whitespace, keeping double-quoted phrases together.
+func tokenize(s string) []string {
+	var tokens []string
+	var cur strings.Builder
+	quoted := false
+	flush := func() {
+		if cur.Len() > 0 {
+			tokens = append(tokens, cur.String())
+			cur.Reset()
+		}
+	}
+	for _, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case (r == ' ' || r == '\t') && !quoted:
+			flush()
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	flush()
+	return tokens
+}
+
+// parseDuration accepts 2h, 1h30m, 1h30, 90m, 1.5h, 45min.
+func parseDuration(s string) (time.Duration, bool) {
+	m := durationRe.FindStringSubmatch(s)
+	if m == nil || (m[1] == "" && m[2] == "") {
+		return 0, false
+	}
+	// A bare number is not a duration, and "1h30" needs its hour part.
+	if m[1] == "" && !strings.ContainsAny(s, "m") {
+		return 0, false
+	}
+	var d time.Duration
+	if m[1] != "" {
+		hours, err := strconv.ParseFloat(m[1], 64)
+		if err != nil {
+			return 0, false
+		}
+		d += time.Duration(hours * float64(time.Hour))
+	}
+	if m[2] != "" {
+		mins, err := strconv.Atoi(m[2])
+		if err != nil {
+			return 0, false
+		}
+		d += time.Duration(mins) * time.Minute
+	}
+	return d.Round(time.Minute), d > 0
+}
+
+// parseRange accepts 9:00-11:30, 9-11:30 and 1pm-3pm, returning offsets from midnight.
+func parseRange(s string) (time.Duration, time.Duration, bool) {
+	m := rangeRe.FindStringSubmatch(s)
+	if m == nil {
+		return 0, 0, false
+	}
+	// "9-11" alone is too ambiguous; require a colon or am/pm somewhere.
+	if !strings.ContainsAny(s, ":apm") {
+		return 0, 0, false
+	}
+	startAmPM, endAmPM := m[3], m[6]
+	if startAmPM == "" {
+		startAmPM = endAmPM
+	}
+	start, ok1 := clockOffset(m[1], m[2], startAmPM)
+	end, ok2 := clockOffset(m[4], m[5], endAmPM)
+	// "11-1pm" means 11am-1pm.
+	if ok1 && ok2 && m[3] == "" && endAmPM == "pm" && start >= end && start-12*time.Hour < end {
+		start -= 12 * time.Hour
+	}
+	return start, end, ok1 && ok2
+}
+
+func clockOffset(hourStr, minStr, ampm string) (time.Duration, bool) {
+	hour, err := strconv.Atoi(hourStr)
+	if err != nil {
+		return 0, false
+	}
+	minute := 0
+	if minStr != "" {
+		if minute, err = strconv.Atoi(minStr); err != nil || minute > 59 {
+			return 0, false
+		}
+	}
+	switch ampm {
+	case "am":
+		if hour < 1 || hour > 12 {
+			return 0, false
+		}
+		if hour == 12 {
+			hour = 0
+		}
+	case "pm":
+		if hour < 1 || hour > 12 {
+			return 0, false
+		}
+		if hour != 12 {
+			hour += 12
+		}
+	}
+	if hour > 24 || (hour == 24 && minute > 0) {
+		return 0, false
+	}
+	return time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute, true
+}
+
+// parseDay accepts today, yesterday, weekday names and YYYY-MM-DD.
+func parseDay(s string, today time.Time) (time.Time, bool) {
+	switch s {
+	case "today":
+		return today, true
+	case "yesterday":
+		return today.AddDate(0, 0, -1), true
+	}
+	if wd, ok := weekdays[s]; ok {
+		return lastWeekday(today, wd, false), true
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, today.Location()); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+// lastWeekday returns the most recent wd on or before today. With strict set
+// ("last friday") today itself does not count.
+func lastWeekday(today time.Time, wd time.Weekday, strict bool) time.Time {
+	back := (int(today.Weekday()) - int(wd) + 7) % 7
+	if back == 0 && strict {
+		back = 7
+	}
+	return today.AddDate(0, 0, -back)
+}
+
+// splitProjectTask splits "UI Design – Form updates" into project and task.
+func splitProjectTask(s string) (string, string) {
+	for _, sep := range taskSeparators {
+		if project, task, ok := strings.Cut(s, sep); ok {
+			return strings.TrimSpace(project), strings.TrimSpace(task)
+		}
+	}
+	return strings.TrimSpace(s), ""
+}
@@ -0,0 +1,91 @@
+package llm
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGrammarParser(t *testing.T) {
+	// Wednesday afternoon.
+	now := time.Date(2025, 6, 11, 15, 0, 0, 0, time.Local)
+	p := &GrammarParser{Now: func() time.Time { return now }}
+	at := func(day, hour, min int) time.Time {
+		return time.Date(2025, 6, day, hour, min, 0, 0, time.Local)
+	}
+
+	tests := []struct {
+		input   string
+		start   time.Time
+		minutes int64
+		project string
+		task    string
+		client  string
+		summary string
+		tags    []string
+		rate    float64
+	}{
+		{input: "2h", start: at(11, 13, 0), minutes: 120},
+		{input: "1h30m on Apollo", start: at(11, 13, 30), minutes: 90, project: "Apollo"},
+		{input: "90m yesterday on Apollo", start: at(10, 13, 30), minutes: 90, project: "Apollo"},
+		{input: "1.5h mon", start: at(9, 13, 30), minutes: 90},
+		{input: "1h last wed", start: at(4, 14, 0), minutes: 60},
+		{input: "1h wed", start: at(11, 14, 0), minutes: 60},
+		{input: "45min 2025-06-01", start: at(1, 14, 15), minutes: 45},
+		{input: "9:00-11:30 on Apollo", start: at(11, 9, 0), minutes: 150, project: "Apollo"},
+		{input: "11-1pm yesterday", start: at(10, 11, 0), minutes: 120},
+		{input: "2h today on \"UI Design – Form updates\"", start: at(11, 13, 0), minutes: 120, project: "UI Design", task: "Form updates"},
+		{input: "30m today on Test Task -- test entry", start: at(11, 14, 30), minutes: 30, project: "Test Task", summary: "test entry"},
+		{input: "on Monday Planning 1h", start: at(11, 14, 0), minutes: 60, project: "Monday Planning"},
+		{input: "1h on Apollo @Acme #meeting #billing $85/h -- kickoff call", start: at(11, 14, 0), minutes: 60,
+			project: "Apollo", client: "Acme", tags: []string{"meeting", "billing"}, rate: 85, summary: "kickoff call"},
+		{input: "fixed login bug 1h on Apollo -- with --verbose flag", start: at(11, 14, 0), minutes: 60,
+			project: "Apollo", summary: "fixed login bug with --verbose flag"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			e, err := p.ParseEntry(tt.input)
+			if err != nil {
+				t.Fatalf("ParseEntry failed: %v", err)
+			}
+			if !e.StartTime.Equal(tt.start) || e.Duration != tt.minutes {
+				t.Errorf("time: got %s for %d min, want %s for %d min", e.StartTime, e.Duration, tt.start, tt.minutes)
+			}
+			if !e.EndTime.Equal(tt.start.Add(time.Duration(tt.minutes) * time.Minute)) {
+				t.Errorf("end: got %s", e.EndTime)
+			}
+			if e.Project != tt.project || e.Task != tt.task || e.Client != tt.client || e.Summary != tt.summary {
+				t.Errorf("fields: got project=%q task=%q client=%q summary=%q", e.Project, e.Task, e.Client, e.Summary)
+			}
+			if len(e.Tags) != len(tt.tags) {
+				t.Fatalf("tags: got %v, want %v", e.Tags, tt.tags)
+			}
+			for i := range tt.tags {
+				if e.Tags[i] != tt.tags[i] {
+					t.Errorf("tags: got %v, want %v", e.Tags, tt.tags)
+				}
+			}
+			if e.Rate != tt.rate || !e.Billable {
+				t.Errorf("billing: got rate=%.2f billable=%t", e.Rate, e.Billable)
+			}
+		})
+	}
+}
+
+func TestGrammarParser_Errors(t *testing.T) {
+	p := &GrammarParser{Now: func() time.Time { return time.Date(2025, 6, 11, 15, 0, 0, 0, time.Local) }}
+
+	if _, err := p.ParseEntry("today on Apollo -- no time"); !errors.Is(err, ErrNoDuration) {
+		t.Errorf("expected ErrNoDuration, got %v", err)
+	}
+	if _, err := p.ParseEntry("45 on Apollo"); !errors.Is(err, ErrNoDuration) {
+		t.Errorf("bare numbers should not be durations, got %v", err)
+	}
+	if _, err := p.ParseEntry("1h 9:00-11:00"); err == nil {
+		t.Errorf("expected an error when the duration contradicts the range")
+	}
+	if _, err := p.ParseEntry("11:00-9:00"); err == nil {
+		t.Errorf("expected an error for a backwards range")
+	}
+}
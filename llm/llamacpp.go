@@ -0,0 +1,86 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// DefaultLlamaCppHost is where llama.cpp's llama-server listens by default.
+const DefaultLlamaCppHost = "http://localhost:8080"
+
+// LlamaCppClient is the Provider for llama.cpp's server. It serves the model
+// it was started with, so there is no model to choose; chats are flattened
+// into a plain prompt for /completion.
+type LlamaCppClient struct {
+	Endpoint
+	NPredict int // maximum tokens to generate; the server default when zero
+}
+
+type completionRequest struct {
+	Prompt     string          `json:"prompt"`
+	NPredict   int             `json:"n_predict,omitempty"`
+	Stream     bool            `json:"stream"`
+	JSONSchema json.RawMessage `json:"json_schema,omitempty"`
+}
+
+type completionResponse struct {
+	Content string `json:"content"`
+	Stop    bool   `json:"stop"`
+}
+
+// Generate sends a prompt to /completion and returns the generated text.
+func (c *LlamaCppClient) Generate(ctx context.Context, prompt string) (string, error) {
+	var resp completionResponse
+	if err := c.do(ctx, http.MethodPost, "/completion", completionRequest{Prompt: prompt, NPredict: c.NPredict}, &resp); err != nil {
+		return "", fmt.Errorf("llama.cpp: %w", err)
+	}
+	return resp.Content, nil
+}
+
+// GenerateJSON is Generate with the output constrained to schema; the server
+// compiles the schema into a sampling grammar.
+func (c *LlamaCppClient) GenerateJSON(ctx context.Context, prompt string, schema json.RawMessage) (string, error) {
+	var resp completionResponse
+	if err := c.do(ctx, http.MethodPost, "/completion", completionRequest{Prompt: prompt, NPredict: c.NPredict, JSONSchema: schema}, &resp); err != nil {
+		return "", fmt.Errorf("llama.cpp: %w", err)
+	}
+	return resp.Content, nil
+}
+
+// GenerateStream streams /completion as server-sent events.
+func (c *LlamaCppClient) GenerateStream(ctx context.Context, prompt string, onToken func(string)) (string, error) {
+	var out strings.Builder
+	err := c.stream(ctx, "/completion", completionRequest{Prompt: prompt, NPredict: c.NPredict, Stream: true}, func(line []byte) error {
+		var chunk completionResponse
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return fmt.Errorf("decode stream: %w", err)
+		}
+		out.WriteString(chunk.Content)
+		onToken(chunk.Content)
+		if chunk.Stop {
+			return errStreamDone
+		}
+		return nil
+	})
+	if err != nil {
+		return out.String(), fmt.Errorf("llama.cpp: %w", err)
+	}
+	return out.String(), nil
+}
+
+// Chat flattens messages into a prompt and completes it.
+func (c *LlamaCppClient) Chat(ctx context.Context, messages []Message) (string, error) {
+	return c.Generate(ctx, flattenMessages(messages))
+}
+
+// Models lists the loaded model via the server's OpenAI-compatible /v1/models.
+func (c *LlamaCppClient) Models(ctx context.Context) ([]string, error) {
+	names, err := listOpenAIModels(ctx, c.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("llama.cpp: %w", err)
+	}
+	return names, nil
+}
@@ -0,0 +1,111 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// OllamaClient is the Provider for Ollama's HTTP API (/api/generate, /api/chat).
+type OllamaClient struct {
+	Endpoint
+	Model string // e.g., "llama2:7b"
+}
+
+type generateRequest struct {
+	Model  string          `json:"model"`
+	Prompt string          `json:"prompt"`
+	Stream bool            `json:"stream"`
+	Format json.RawMessage `json:"format,omitempty"` // "json" or a JSON schema
+}
+
+type generateResponse struct {
+	Response string `json:"response"`
+	Done     bool   `json:"done"`
+	Error    string `json:"error"`
+}
+
+type chatRequest struct {
+	Model    string    `json:"model"`
+	Messages []Message `json:"messages"`
+	Stream   bool      `json:"stream"`
+}
+
+type chatResponse struct {
+	Message Message `json:"message"`
+}
+
+// Generate sends a single prompt to /api/generate and returns the completion.
+func (c *OllamaClient) Generate(ctx context.Context, prompt string) (string, error) {
+	var resp generateResponse
+	if err := c.do(ctx, http.MethodPost, "/api/generate", generateRequest{Model: c.Model, Prompt: prompt}, &resp); err != nil {
+		return "", c.wrap(err)
+	}
+	return resp.Response, nil
+}
+
+// GenerateJSON is Generate with the output constrained to schema via Ollama's format field.
+func (c *OllamaClient) GenerateJSON(ctx context.Context, prompt string, schema json.RawMessage) (string, error) {
+	var resp generateResponse
+	if err := c.do(ctx, http.MethodPost, "/api/generate", generateRequest{Model: c.Model, Prompt: prompt, Format: schema}, &resp); err != nil {
+		return "", c.wrap(err)
+	}
+	return resp.Response, nil
+}
+
+// GenerateStream streams /api/generate, which replies with one JSON object per line.
+func (c *OllamaClient) GenerateStream(ctx context.Context, prompt string, onToken func(string)) (string, error) {
+	var out strings.Builder
+	err := c.stream(ctx, "/api/generate", generateRequest{Model: c.Model, Prompt: prompt, Stream: true}, func(line []byte) error {
+		var chunk generateResponse
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return fmt.Errorf("decode stream: %w", err)
+		}
+		if chunk.Error != "" {
+			return errors.New(chunk.Error)
+		}
+		out.WriteString(chunk.Response)
+		onToken(chunk.Response)
+		if chunk.Done {
+			return errStreamDone
+		}
+		return nil
+	})
+	if err != nil {
+		return out.String(), c.wrap(err)
+	}
+	return out.String(), nil
+}
+
+// Chat sends a conversation to /api/chat and returns the assistant's reply.
+func (c *OllamaClient) Chat(ctx context.Context, messages []Message) (string, error) {
+	var resp chatResponse
+	if err := c.do(ctx, http.MethodPost, "/api/chat", chatRequest{Model: c.Model, Messages: messages}, &resp); err != nil {
+		return "", c.wrap(err)
+	}
+	return resp.Message.Content, nil
+}
+
+// Models lists the models pulled on the server (GET /api/tags).
+func (c *OllamaClient) Models(ctx context.Context) ([]string, error) {
+	var resp struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/api/tags", nil, &resp); err != nil {
+		return nil, c.wrap(err)
+	}
+	names := make([]string, 0, len(resp.Models))
+	for _, m := range resp.Models {
+		names = append(names, m.Name)
+	}
+	return names, nil
+}
+
+func (c *OllamaClient) wrap(err error) error {
+	return fmt.Errorf("ollama (model %s): %w", c.Model, err)
+}
@@ -0,0 +1,151 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testClient(url string) *OllamaClient {
+	retryBackoff = time.Millisecond
+	return &OllamaClient{Model: "test-model", Endpoint: Endpoint{Host: url, Timeout: time.Second, Retries: 2}}
+}
+
+func TestOllamaGenerate(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/generate" || r.Method != http.MethodPost {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var req generateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		if req.Model != "test-model" || req.Prompt != "hello" || req.Stream {
+			t.Errorf("unexpected request body: %+v", req)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"response": "hi there", "done": true})
+	}))
+	defer srv.Close()
+
+	out, err := testClient(srv.URL).Generate(context.Background(), "hello")
+	if err != nil || out != "hi there" {
+		t.Fatalf("Generate: got %q, %v", out, err)
+	}
+}
+
+func TestOllamaChat(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req chatRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if r.URL.Path != "/api/chat" || len(req.Messages) != 2 || req.Messages[1].Content != "how long?" {
+			t.Errorf("unexpected chat request %s: %+v", r.URL.Path, req)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"message": Message{Role: "assistant", Content: "2 hours"}})
+	}))
+	defer srv.Close()
+
+	out, err := testClient(srv.URL).Chat(context.Background(), []Message{
+		{Role: "system", Content: "be brief"},
+		{Role: "user", Content: "how long?"},
+	})
+	if err != nil || out != "2 hours" {
+		t.Fatalf("Chat: got %q, %v", out, err)
+	}
+}
+
+func TestOllamaModelNotFound(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":"model 'test-model' not found, try pulling it first"}`))
+	}))
+	defer srv.Close()
+
+	_, err := testClient(srv.URL).Generate(context.Background(), "hello")
+	if !errors.Is(err, ErrModelNotFound) {
+		t.Fatalf("expected ErrModelNotFound, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("a missing model should not be retried, got %d calls", calls)
+	}
+}
+
+func TestOllamaServerDown(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+
+	_, err := testClient(url).Generate(context.Background(), "hello")
+	if !errors.Is(err, ErrServerDown) {
+		t.Fatalf("expected ErrServerDown, got %v", err)
+	}
+}
+
+func TestOllamaRetriesServerErrors(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			http.Error(w, `{"error":"loading model"}`, http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"response": "ok"})
+	}))
+	defer srv.Close()
+
+	out, err := testClient(srv.URL).Generate(context.Background(), "hello")
+	if err != nil || out != "ok" || calls != 3 {
+		t.Fatalf("expected success on the third attempt, got %q, %v after %d calls", out, err, calls)
+	}
+
+	atomic.StoreInt32(&calls, -10)
+	_, err = testClient(srv.URL).Generate(context.Background(), "hello")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable || apiErr.Message != "loading model" {
+		t.Fatalf("expected the last APIError once retries run out, got %v", err)
+	}
+}
+
+func TestOllamaTimeoutAndCancel(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	c := testClient(srv.URL)
+	c.Timeout = 20 * time.Millisecond
+	c.Retries = 0
+	if _, err := c.Generate(context.Background(), "hello"); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("expected ErrTimeout, got %v", err)
+	}
+
+	c.Timeout = time.Minute
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+	if _, err := c.Generate(ctx, "hello"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestNewOllamaClient(t *testing.T) {
+	t.Setenv("OLLAMA_HOST", "ollama.lan:11434/")
+	t.Setenv("OLLAMA_MODEL", "mistral")
+	t.Setenv("LLM_PROVIDER", ProviderOpenAI)
+	c, ok := NewOllamaClient().Provider.(*OllamaClient)
+	if !ok || c.Host != "http://ollama.lan:11434" || c.Model != "mistral" || c.Timeout != DefaultTimeout {
+		t.Errorf("NewOllamaClient provider = %+v", c)
+	}
+}
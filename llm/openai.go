@@ -0,0 +1,127 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// DefaultOpenAIHost is LM Studio's default address; vLLM and LocalAI need LLM_HOST.
+const DefaultOpenAIHost = "http://localhost:1234"
+
+// OpenAIClient is the Provider for any server exposing the OpenAI
+// /v1/chat/completions API (LM Studio, vLLM, LocalAI, ...). Host may include
+// or omit the /v1 suffix.
+type OpenAIClient struct {
+	Endpoint
+	Model string
+}
+
+type openAIChatRequest struct {
+	Model          string                `json:"model,omitempty"`
+	Messages       []Message             `json:"messages"`
+	Stream         bool                  `json:"stream"`
+	ResponseFormat *openAIResponseFormat `json:"response_format,omitempty"`
+}
+
+type openAIResponseFormat struct {
+	Type       string `json:"type"` // "json_schema"
+	JSONSchema struct {
+		Name   string          `json:"name"`
+		Schema json.RawMessage `json:"schema"`
+	} `json:"json_schema"`
+}
+
+type openAIChatResponse struct {
+	Choices []struct {
+		Message Message `json:"message"`
+		Delta   Message `json:"delta"` // streamed chunks
+	} `json:"choices"`
+}
+
+// Generate sends prompt as a single user message.
+func (c *OpenAIClient) Generate(ctx context.Context, prompt string) (string, error) {
+	return c.Chat(ctx, []Message{{Role: "user", Content: prompt}})
+}
+
+// Chat sends a conversation to /v1/chat/completions and returns the first choice.
+func (c *OpenAIClient) Chat(ctx context.Context, messages []Message) (string, error) {
+	return c.chat(ctx, openAIChatRequest{Model: c.Model, Messages: messages})
+}
+
+// GenerateStream streams the reply to prompt as server-sent events.
+func (c *OpenAIClient) GenerateStream(ctx context.Context, prompt string, onToken func(string)) (string, error) {
+	var out strings.Builder
+	req := openAIChatRequest{Model: c.Model, Messages: []Message{{Role: "user", Content: prompt}}, Stream: true}
+	err := c.v1().stream(ctx, "/v1/chat/completions", req, func(line []byte) error {
+		if string(line) == "[DONE]" {
+			return errStreamDone
+		}
+		var chunk openAIChatResponse
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return fmt.Errorf("decode stream: %w", err)
+		}
+		if len(chunk.Choices) > 0 {
+			out.WriteString(chunk.Choices[0].Delta.Content)
+			onToken(chunk.Choices[0].Delta.Content)
+		}
+		return nil
+	})
+	if err != nil {
+		return out.String(), fmt.Errorf("openai-compatible (model %s): %w", c.Model, err)
+	}
+	return out.String(), nil
+}
+
+// GenerateJSON sends prompt with a json_schema response format.
+func (c *OpenAIClient) GenerateJSON(ctx context.Context, prompt string, schema json.RawMessage) (string, error) {
+	format := &openAIResponseFormat{Type: "json_schema"}
+	format.JSONSchema.Name = "response"
+	format.JSONSchema.Schema = schema
+	return c.chat(ctx, openAIChatRequest{Model: c.Model, Messages: []Message{{Role: "user", Content: prompt}}, ResponseFormat: format})
+}
+
+func (c *OpenAIClient) chat(ctx context.Context, req openAIChatRequest) (string, error) {
+	var resp openAIChatResponse
+	if err := c.v1().do(ctx, http.MethodPost, "/v1/chat/completions", req, &resp); err != nil {
+		return "", fmt.Errorf("openai-compatible (model %s): %w", c.Model, err)
+	}
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("openai-compatible (model %s): response has no choices", c.Model)
+	}
+	return resp.Choices[0].Message.Content, nil
+}
+
+// Models lists the models the server offers.
+func (c *OpenAIClient) Models(ctx context.Context) ([]string, error) {
+	names, err := listOpenAIModels(ctx, c.v1())
+	if err != nil {
+		return nil, fmt.Errorf("openai-compatible: %w", err)
+	}
+	return names, nil
+}
+
+// v1 strips a trailing /v1 from Host so paths can always start with /v1.
+func (c *OpenAIClient) v1() Endpoint {
+	e := c.Endpoint
+	e.Host = strings.TrimSuffix(strings.TrimRight(e.Host, "/"), "/v1")
+	return e
+}
+
+func listOpenAIModels(ctx context.Context, e Endpoint) ([]string, error) {
+	var resp struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := e.do(ctx, http.MethodGet, "/v1/models", nil, &resp); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(resp.Data))
+	for _, m := range resp.Data {
+		names = append(names, m.ID)
+	}
+	return names, nil
+}
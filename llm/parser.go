@@ -1,8 +1,32 @@
 package llm
 
-import "github.com/regiellis/chronos-go/chronos"
+import (
+	"fmt"
+
+	"github.com/regiellis/chronos-go/chronos"
+)
 
 // Parser defines the interface for parsing natural language time entries.
 type Parser interface {
 	ParseEntry(input string) (*chronos.Entry, error)
 }
+
+// FallbackParser tries Primary first and hands the input to Fallback only when
+// Primary cannot parse it. A nil Fallback makes it behave like Primary alone.
+type FallbackParser struct {
+	Primary  Parser
+	Fallback Parser
+}
+
+// ParseEntry implements Parser.
+func (p FallbackParser) ParseEntry(input string) (*chronos.Entry, error) {
+	entry, err := p.Primary.ParseEntry(input)
+	if err == nil || p.Fallback == nil {
+		return entry, err
+	}
+	entry, fallbackErr := p.Fallback.ParseEntry(input)
+	if fallbackErr != nil {
+		return nil, fmt.Errorf("%v; fallback: %w", err, fallbackErr)
+	}
+	return entry, nil
+}
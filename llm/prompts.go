@@ -0,0 +1,149 @@
+package llm
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// Prompt template names, one per LLM feature.
+const (
+	PromptParse            = "parse"
+	PromptSummarize        = "summarize"
+	PromptFeedback         = "feedback"
+	PromptEnhancedFeedback = "enhanced_feedback"
+	PromptAsk              = "ask"
+	PromptAskTools         = "ask_tools"
+	PromptSuggest          = "suggest"
+	PromptRemind           = "remind"
+	PromptReview           = "review"
+	PromptInvoiceLine      = "invoice_line"
+	PromptClassify         = "classify"
+	PromptAutocomplete     = "autocomplete"
+)
+
+//go:embed prompts/*.tmpl
+var defaultPrompts embed.FS
+
+var promptFuncs = template.FuncMap{"join": strings.Join}
+
+// Prompts renders the prompt templates. A NAME.tmpl file in Dir overrides the
+// embedded default of the same name; with no Dir only the defaults are used.
+type Prompts struct {
+	Dir string
+}
+
+// PromptNames lists every template name.
+func PromptNames() []string {
+	files, _ := fs.Glob(defaultPrompts, "prompts/*.tmpl")
+	names := make([]string, len(files))
+	for i, f := range files {
+		names[i] = strings.TrimSuffix(filepath.Base(f), ".tmpl")
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DefaultPrompt returns the embedded template text for name.
+func DefaultPrompt(name string) (string, error) {
+	b, err := defaultPrompts.ReadFile("prompts/" + name + ".tmpl")
+	if err != nil {
+		return "", fmt.Errorf("unknown prompt %q (want one of %s)", name, strings.Join(PromptNames(), ", "))
+	}
+	return string(b), nil
+}
+
+// Path returns where the override for name lives.
+func (p Prompts) Path(name string) string {
+	return filepath.Join(p.Dir, name+".tmpl")
+}
+
+// Source returns the template text in effect for name and whether it comes
+// from an override file.
+func (p Prompts) Source(name string) (string, bool, error) {
+	def, err := DefaultPrompt(name)
+	if err != nil {
+		return "", false, err
+	}
+	if p.Dir == "" {
+		return def, false, nil
+	}
+	b, err := os.ReadFile(p.Path(name))
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return def, false, nil
+	case err != nil:
+		return "", false, fmt.Errorf("read prompt %s: %w", name, err)
+	}
+	return string(b), true, nil
+}
+
+// Version fingerprints the template in effect for name, so cached replies are
+// not reused once it is edited.
+func (p Prompts) Version(name string) (string, error) {
+	text, _, err := p.Source(name)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+// Reset removes the override for name, restoring the default. It reports
+// whether there was one.
+func (p Prompts) Reset(name string) (bool, error) {
+	if _, err := DefaultPrompt(name); err != nil {
+		return false, err
+	}
+	if p.Dir == "" {
+		return false, nil
+	}
+	err := os.Remove(p.Path(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Check reports whether the template in effect for name parses.
+func (p Prompts) Check(name string) error {
+	_, _, err := p.parse(name)
+	return err
+}
+
+func (p Prompts) parse(name string) (*template.Template, string, error) {
+	text, overridden, err := p.Source(name)
+	if err != nil {
+		return nil, "", err
+	}
+	where := "default"
+	if overridden {
+		where = p.Path(name)
+	}
+	tmpl, err := template.New(name).Funcs(promptFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, where, fmt.Errorf("prompt %s (%s): %w", name, where, err)
+	}
+	return tmpl, where, nil
+}
+
+// Render executes the template for name with data.
+func (p Prompts) Render(name string, data interface{}) (string, error) {
+	tmpl, where, err := p.parse(name)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("prompt %s (%s): %w", name, where, err)
+	}
+	return strings.TrimSpace(b.String()), nil
+}
@@ -0,0 +1,94 @@
+package llm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/regiellis/chronos-go/chronos"
+)
+
+// TestDefaultPrompts renders every default through the feature that uses it.
+func TestDefaultPrompts(t *testing.T) {
+	entry := &chronos.Entry{ID: 1, BlockID: 1, Project: "Apollo", Task: "API", Summary: "Auth endpoints", StartTime: parseNow.Add(-time.Hour), Duration: 45}
+	block := &chronos.Block{ID: 1, Name: "June sprint", Client: "Acme", Project: "Apollo", StartTime: parseNow.AddDate(0, 0, -7), EndTime: parseNow.AddDate(0, 0, 7), Active: true}
+	entries, blocks := []*chronos.Entry{entry}, []*chronos.Block{block}
+
+	p := &scriptedProvider{replies: append(make([]string, 9), `{"classifications":[]}`, `{"answer":"x"}`, "")}
+	c := testParser(p)
+	ctx := context.Background()
+	calls := []struct {
+		name string
+		call func() error
+		want string
+	}{
+		{PromptSummarize, func() error { _, err := c.SummarizeBlock(ctx, block, entries); return err }, "- Apollo / API: Auth endpoints (Duration: 45 min)"},
+		{PromptFeedback, func() error { _, err := c.FeedbackAfterEntry(ctx, entry, entries); return err }, "Total time logged in this context: 0.75 hours."},
+		{PromptEnhancedFeedback, func() error { _, err := c.EnhancedFeedback(ctx, entry, entries, block); return err }, "Progress: 0.2%. Block ends: 2025-06-18."},
+		{PromptAsk, func() error { _, err := c.AnswerUserQuery(ctx, "today?", entries, blocks); return err }, "The user asked: 'today?'"},
+		{PromptSuggest, func() error { _, err := c.SuggestNextEntry(ctx, entries, blocks); return err }, "Active block: \"June sprint\""},
+		{PromptRemind, func() error { _, err := c.SmartReminder(ctx, entries, blocks); return err }, "Latest entries"},
+		{PromptAutocomplete, func() error { _, err := c.AutoCompleteFields(ctx, "apo", entries, blocks); return err }, "Projects: Apollo"},
+		{PromptReview, func() error { _, err := c.ReviewPeriod(ctx, LastDays(parseNow, 7), entries, blocks); return err }, "over the last 7 days"},
+		{PromptInvoiceLine, func() error {
+			_, err := c.DescribeInvoiceLine(ctx, "Acme", chronos.GroupInvoiceLines(entries)[0], block)
+			return err
+		}, "- Auth endpoints"},
+		{PromptClassify, func() error {
+			_, err := c.ClassifyEntries(ctx, []*chronos.Entry{{ID: 2, Summary: "Token refresh"}}, []chronos.Category{{Project: "Apollo", Client: "Acme", Task: "API", Count: 1}})
+			return err
+		}, "- Apollo / Acme / API (1)"},
+		{PromptAskTools, func() error {
+			_, _, err := c.AnswerWithTools(ctx, "x", &StoreTools{Repo: chronos.NewMemoryRepository()})
+			return err
+		}, "Today is Wednesday 2025-06-11."},
+		{PromptParse, func() error { c.ParseEntry("2h on Apollo"); return nil }, "Known projects: none yet."},
+	}
+	if len(calls) != len(PromptNames()) {
+		t.Fatalf("expected a case per prompt, have %d for %v", len(calls), PromptNames())
+	}
+	for i, tt := range calls {
+		if err := tt.call(); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if prompt := p.prompts[i]; !strings.Contains(prompt, tt.want) || strings.Contains(prompt, "Fields:") {
+			t.Errorf("%s prompt should contain %q and no template comment:\n%s", tt.name, tt.want, prompt)
+		}
+	}
+}
+
+func TestPromptOverrides(t *testing.T) {
+	dir := t.TempDir()
+	prompts := Prompts{Dir: dir}
+	if err := os.WriteFile(prompts.Path(PromptSuggest), []byte("Antworte auf Deutsch.\n{{.Context}}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	p := &scriptedProvider{replies: []string{"ok"}}
+	c := &Client{Provider: p, Prompts: prompts, Now: func() time.Time { return parseNow }}
+	if _, err := c.SuggestNextEntry(context.Background(), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(p.prompts[0], "Antworte auf Deutsch.\nNow: ") {
+		t.Errorf("override not used:\n%s", p.prompts[0])
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "remind.tmpl"), []byte("{{.Nope}}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.SmartReminder(context.Background(), nil, nil); err == nil || !strings.Contains(err.Error(), "remind.tmpl") {
+		t.Errorf("a broken override should name its file, got %v", err)
+	}
+
+	if removed, err := prompts.Reset(PromptRemind); !removed || err != nil {
+		t.Fatalf("Reset: %v, %v", removed, err)
+	}
+	if _, custom, _ := prompts.Source(PromptRemind); custom {
+		t.Error("reset prompt should fall back to the default")
+	}
+	if _, err := prompts.Render("nope", nil); err == nil {
+		t.Error("unknown prompts should fail")
+	}
+}
@@ -0,0 +1,407 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/regiellis/chronos-go/config"
+)
+
+// Provider is a local LLM server. Client builds the Chronos prompts and sends
+// them through a Provider, so every feature works against any backend.
+type Provider interface {
+	// Generate completes a single prompt.
+	Generate(ctx context.Context, prompt string) (string, error)
+	// Chat returns the assistant's reply to a conversation.
+	Chat(ctx context.Context, messages []Message) (string, error)
+}
+
+// JSONGenerator is implemented by providers that can constrain a completion
+// to a JSON schema (Ollama's format, llama.cpp's json_schema, OpenAI's
+// response_format).
+type JSONGenerator interface {
+	GenerateJSON(ctx context.Context, prompt string, schema json.RawMessage) (string, error)
+}
+
+// Streamer is implemented by providers that can stream a completion. onToken
+// receives each piece as it arrives; the full text is returned at the end.
+type Streamer interface {
+	GenerateStream(ctx context.Context, prompt string, onToken func(string)) (string, error)
+}
+
+// ModelLister is implemented by providers that can report the models they serve.
+type ModelLister interface {
+	Models(ctx context.Context) ([]string, error)
+}
+
+// Provider names accepted in LLM_PROVIDER.
+const (
+	ProviderOllama   = "ollama"
+	ProviderLlamaCpp = "llamacpp"
+	ProviderOpenAI   = "openai"
+)
+
+// NewProvider builds the backend selected in cfg. LLM_HOST and LLM_MODEL
+// apply to any backend; the OLLAMA_* values only to Ollama.
+func NewProvider(cfg *config.EnvConfig) (Provider, error) {
+	endpoint := func(defaultHost string) Endpoint {
+		host := cfg.LLMHost
+		if host == "" {
+			host = defaultHost
+		}
+		return Endpoint{Host: normalizeHost(host), Timeout: DefaultTimeout, Retries: DefaultRetries, APIKey: cfg.LLMAPIKey}
+	}
+	model := cfg.LLMModel
+
+	switch cfg.LLMProvider {
+	case ProviderOllama, "":
+		if model == "" {
+			model = cfg.OllamaModel
+		}
+		return &OllamaClient{Endpoint: endpoint(cfg.OllamaHost), Model: model}, nil
+	case ProviderLlamaCpp, "llama.cpp":
+		return &LlamaCppClient{Endpoint: endpoint(DefaultLlamaCppHost)}, nil
+	case ProviderOpenAI:
+		return &OpenAIClient{Endpoint: endpoint(DefaultOpenAIHost), Model: model}, nil
+	default:
+		return nil, fmt.Errorf("NewProvider: unknown LLM_PROVIDER %q (want %s, %s or %s)", cfg.LLMProvider, ProviderOllama, ProviderLlamaCpp, ProviderOpenAI)
+	}
+}
+
+// ModelName names the model behind p, for display and the answer history.
+// llama.cpp serves whatever model it was started with.
+func ModelName(p Provider) string {
+	switch p := p.(type) {
+	case *OllamaClient:
+		return p.Model
+	case *OpenAIClient:
+		if p.Model != "" {
+			return p.Model
+		}
+		return ProviderOpenAI
+	case *LlamaCppClient:
+		return ProviderLlamaCpp
+	}
+	return ""
+}
+
+// providerName names the backend behind p, for cache keys.
+func providerName(p Provider) string {
+	switch p.(type) {
+	case *OllamaClient:
+		return ProviderOllama
+	case *OpenAIClient:
+		return ProviderOpenAI
+	case *LlamaCppClient:
+		return ProviderLlamaCpp
+	}
+	return fmt.Sprintf("%T", p)
+}
+
+// Errors returned (wrapped) by providers so callers can tell the user what to
+// fix instead of printing a raw transport error.
+var (
+	ErrServerDown    = errors.New("LLM server is not reachable")
+	ErrModelNotFound = errors.New("model not found on the LLM server")
+	ErrTimeout       = errors.New("LLM request timed out")
+)
+
+// APIError is a non-2xx response that does not map onto one of the errors above.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("LLM server returned %d: %s", e.StatusCode, e.Message)
+}
+
+// Defaults for Endpoint; local models can take a while to load on first use.
+const (
+	DefaultTimeout = 2 * time.Minute
+	DefaultRetries = 2
+)
+
+// retryBackoff is the wait before the first retry; it doubles each attempt.
+var retryBackoff = 500 * time.Millisecond
+
+// Message is one turn of a chat conversation.
+type Message struct {
+	Role    string `json:"role"` // "system", "user", "assistant" or "tool"
+	Content string `json:"content"`
+
+	// Tool calling, see ToolCaller; sent only by ChatTools.
+	ToolCalls  []ToolCall `json:"-"`
+	ToolCallID string     `json:"-"`
+	ToolName   string     `json:"-"`
+}
+
+// Endpoint holds the HTTP settings shared by all providers.
+type Endpoint struct {
+	Host       string        // base URL, e.g. "http://localhost:11434"
+	Timeout    time.Duration // per attempt; DefaultTimeout when zero
+	Retries    int           // extra attempts while the server is down or failing
+	APIKey     string        // sent as a bearer token when set
+	HTTPClient *http.Client  // http.DefaultClient when nil
+}
+
+// do sends body (if any) as JSON to path and decodes the reply into out,
+// retrying while the server is down or failing with a 5xx. Each attempt gets
+// its own timeout; ctx bounds the whole call.
+func (e Endpoint) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("%s: encode request: %w", path, err)
+		}
+	}
+
+	var err error
+	backoff := retryBackoff
+	for attempt := 0; ; attempt++ {
+		err = e.attempt(ctx, method, path, payload, out)
+		if err == nil || attempt >= e.Retries || !retryable(err) {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s: %w", path, ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+	if err != nil {
+		return fmt.Errorf("%s%s: %w", e.Host, path, err)
+	}
+	return nil
+}
+
+func (e Endpoint) attempt(ctx context.Context, method, path string, payload []byte, out interface{}) error {
+	timeout := e.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, method, strings.TrimRight(e.Host, "/")+path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.APIKey)
+	}
+
+	httpClient := e.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		switch {
+		case ctx.Err() != nil:
+			return ctx.Err() // cancelled by the caller, not our timeout
+		case errors.Is(err, context.DeadlineExceeded):
+			return fmt.Errorf("%w after %s", ErrTimeout, timeout)
+		default:
+			return fmt.Errorf("%w: %v", ErrServerDown, err)
+		}
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+			return fmt.Errorf("%w after %s", ErrTimeout, timeout)
+		}
+		return fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode/100 != 2 {
+		return classifyStatus(resp.StatusCode, data)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
+// stream POSTs body to path and hands each non-empty line of the reply to
+// onLine, with any SSE "data: " prefix removed. Timeout bounds the wait for
+// the response to start (model loading), not the generation itself, and
+// connection failures are retried only before anything has been received.
+func (e Endpoint) stream(ctx context.Context, path string, body interface{}, onLine func([]byte) error) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("%s: encode request: %w", path, err)
+	}
+	timeout := e.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	var resp *http.Response
+	backoff := retryBackoff
+	for attempt := 0; ; attempt++ {
+		resp, err = e.open(ctx, path, payload, timeout)
+		if err == nil || attempt >= e.Retries || !retryable(err) {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s: %w", path, ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+	if err != nil {
+		return fmt.Errorf("%s%s: %w", e.Host, path, err)
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 || line[0] == ':' || bytes.HasPrefix(line, []byte("event:")) {
+			continue
+		}
+		line = bytes.TrimSpace(bytes.TrimPrefix(line, []byte("data:")))
+		if err := onLine(line); err != nil {
+			if errors.Is(err, errStreamDone) {
+				return nil
+			}
+			return fmt.Errorf("%s%s: %w", e.Host, path, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("%s%s: %w", e.Host, path, ctx.Err())
+		}
+		return fmt.Errorf("%s%s: read stream: %w", e.Host, path, err)
+	}
+	return nil
+}
+
+// errStreamDone lets an onLine callback end a stream early without an error.
+var errStreamDone = errors.New("stream done")
+
+// open starts a streaming request and returns once the status is known. The
+// request stays tied to ctx for as long as the body is read.
+func (e Endpoint) open(ctx context.Context, path string, payload []byte, timeout time.Duration) (*http.Response, error) {
+	reqCtx, cancel := context.WithCancel(ctx)
+	timer := time.AfterFunc(timeout, cancel)
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodPost, strings.TrimRight(e.Host, "/")+path, bytes.NewReader(payload))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream, application/x-ndjson")
+	if e.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.APIKey)
+	}
+
+	httpClient := e.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	timedOut := !timer.Stop()
+	if err != nil {
+		cancel()
+		switch {
+		case ctx.Err() != nil:
+			return nil, ctx.Err()
+		case timedOut:
+			return nil, fmt.Errorf("%w after %s", ErrTimeout, timeout)
+		default:
+			return nil, fmt.Errorf("%w: %v", ErrServerDown, err)
+		}
+	}
+	if resp.StatusCode/100 != 2 {
+		defer cancel()
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		return nil, classifyStatus(resp.StatusCode, data)
+	}
+	resp.Body = cancelOnClose{resp.Body, cancel}
+	return resp, nil
+}
+
+// cancelOnClose releases the request context once the body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
+// classifyStatus turns an error reply into an error. Ollama and llama.cpp send
+// {"error": "..."}; OpenAI-compatible servers send {"error": {"message": "..."}}.
+func classifyStatus(status int, body []byte) error {
+	var reply struct {
+		Error json.RawMessage `json:"error"`
+	}
+	msg := strings.TrimSpace(string(body))
+	if json.Unmarshal(body, &reply) == nil && len(reply.Error) > 0 {
+		var text string
+		var obj struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(reply.Error, &text) == nil && text != "" {
+			msg = text
+		} else if json.Unmarshal(reply.Error, &obj) == nil && obj.Message != "" {
+			msg = obj.Message
+		}
+	}
+	if status == http.StatusNotFound && (strings.Contains(msg, "not found") || strings.Contains(msg, "does not exist")) {
+		return fmt.Errorf("%w: %s", ErrModelNotFound, msg)
+	}
+	return &APIError{StatusCode: status, Message: msg}
+}
+
+// retryable reports whether another attempt might succeed.
+func retryable(err error) bool {
+	if errors.Is(err, ErrServerDown) {
+		return true
+	}
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode >= 500
+}
+
+// normalizeHost accepts a bare host:port, like the ollama CLI does for OLLAMA_HOST.
+func normalizeHost(host string) string {
+	if !strings.Contains(host, "://") {
+		host = "http://" + host
+	}
+	return strings.TrimRight(host, "/")
+}
+
+// flattenMessages renders a conversation as a plain prompt for servers that
+// only offer text completion.
+func flattenMessages(messages []Message) string {
+	var b strings.Builder
+	for _, m := range messages {
+		role := m.Role
+		if role == "" {
+			role = "user"
+		}
+		b.WriteString(strings.ToUpper(role[:1]) + role[1:] + ": " + m.Content + "\n")
+	}
+	b.WriteString("Assistant:")
+	return b.String()
+}
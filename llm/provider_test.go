@@ -0,0 +1,128 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/regiellis/chronos-go/config"
+)
+
+func TestNewProvider(t *testing.T) {
+	tests := []struct {
+		cfg   config.EnvConfig
+		host  string
+		model string
+	}{
+		{cfg: config.EnvConfig{OllamaHost: "gpu-box:11434", OllamaModel: "llama3"}, host: "http://gpu-box:11434", model: "llama3"},
+		{cfg: config.EnvConfig{LLMProvider: "ollama", OllamaHost: "http://a:1", OllamaModel: "llama3", LLMHost: "http://b:2/", LLMModel: "qwen"}, host: "http://b:2", model: "qwen"},
+		{cfg: config.EnvConfig{LLMProvider: "llamacpp"}, host: DefaultLlamaCppHost},
+		{cfg: config.EnvConfig{LLMProvider: "openai", LLMHost: "http://vllm:8000/v1", LLMModel: "mistral"}, host: "http://vllm:8000/v1", model: "mistral"},
+	}
+	for _, tt := range tests {
+		p, err := NewProvider(&tt.cfg)
+		if err != nil {
+			t.Fatalf("NewProvider(%+v): %v", tt.cfg, err)
+		}
+		var host, model string
+		switch p := p.(type) {
+		case *OllamaClient:
+			host, model = p.Host, p.Model
+		case *LlamaCppClient:
+			host = p.Host
+		case *OpenAIClient:
+			host, model = p.Host, p.Model
+		}
+		if host != tt.host || model != tt.model {
+			t.Errorf("%q: got host=%s model=%s, want host=%s model=%s", tt.cfg.LLMProvider, host, model, tt.host, tt.model)
+		}
+	}
+
+	if _, err := NewProvider(&config.EnvConfig{LLMProvider: "bogus"}); err == nil {
+		t.Errorf("expected an error for an unknown provider")
+	}
+}
+
+func TestLlamaCppProvider(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req completionRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if r.URL.Path != "/completion" || req.Stream {
+			t.Errorf("unexpected request %s: %+v", r.URL.Path, req)
+		}
+		json.NewEncoder(w).Encode(map[string]string{"content": "echo: " + req.Prompt})
+	}))
+	defer srv.Close()
+
+	c := &LlamaCppClient{Endpoint: Endpoint{Host: srv.URL}}
+	out, err := c.Chat(context.Background(), []Message{{Role: "system", Content: "be brief"}, {Role: "user", Content: "hi"}})
+	if err != nil || out != "echo: System: be brief\nUser: hi\nAssistant:" {
+		t.Fatalf("Chat: got %q, %v", out, err)
+	}
+}
+
+func TestOpenAIProvider(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("missing API key header")
+		}
+		switch r.URL.Path {
+		case "/v1/chat/completions":
+			var req openAIChatRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			if req.Model != "mistral" || len(req.Messages) != 1 || req.Messages[0].Role != "user" {
+				t.Errorf("unexpected chat request: %+v", req)
+			}
+			w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"done"}}]}`))
+		case "/v1/models":
+			w.Write([]byte(`{"data":[{"id":"mistral"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":{"message":"The model does not exist"}}`))
+		}
+	}))
+	defer srv.Close()
+
+	c := &OpenAIClient{Endpoint: Endpoint{Host: srv.URL + "/v1", APIKey: "secret"}, Model: "mistral"}
+	if out, err := c.Generate(context.Background(), "summarize"); err != nil || out != "done" {
+		t.Fatalf("Generate: got %q, %v", out, err)
+	}
+	if models, err := c.Models(context.Background()); err != nil || len(models) != 1 || models[0] != "mistral" {
+		t.Fatalf("Models: got %v, %v", models, err)
+	}
+
+	bad := &OpenAIClient{Endpoint: Endpoint{Host: srv.URL + "/nested", APIKey: "secret", Timeout: time.Second}}
+	if _, err := bad.Generate(context.Background(), "x"); !errors.Is(err, ErrModelNotFound) {
+		t.Fatalf("expected ErrModelNotFound from an OpenAI-style error, got %v", err)
+	}
+}
+
+func TestGenerateJSONSendsSchema(t *testing.T) {
+	var body map[string]json.RawMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body = nil
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"response":"{}","content":"{}","choices":[{"message":{"content":"{}"}}]}`))
+	}))
+	defer srv.Close()
+
+	schema := json.RawMessage(`{"type":"object"}`)
+	providers := map[string]JSONGenerator{
+		"format":          &OllamaClient{Endpoint: Endpoint{Host: srv.URL}},
+		"json_schema":     &LlamaCppClient{Endpoint: Endpoint{Host: srv.URL}},
+		"response_format": &OpenAIClient{Endpoint: Endpoint{Host: srv.URL}},
+	}
+	for field, p := range providers {
+		if out, err := p.GenerateJSON(context.Background(), "x", schema); err != nil || out != "{}" {
+			t.Fatalf("%s: got %q, %v", field, out, err)
+		}
+		if !strings.Contains(string(body[field]), `"type":"object"`) {
+			t.Errorf("expected the schema in %q, got %s", field, body[field])
+		}
+	}
+}
@@ -0,0 +1,350 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/regiellis/chronos-go/chronos"
+)
+
+// Redaction categories accepted by NewRedactor.
+const (
+	RedactClients  = "clients"
+	RedactProjects = "projects"
+	RedactEmails   = "emails"
+	RedactURLs     = "urls"
+	RedactPattern  = "pattern"
+)
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	urlPattern   = regexp.MustCompile(`https?://[^\s)>\]"']+`)
+	aliasPattern = regexp.MustCompile(`\b(?:Client|Project)-[A-Z]+\b`)
+)
+
+// Redactor pseudonymises client and project names (stable aliases like
+// Client-A) and strips sensitive patterns from prompts, then maps the aliases
+// back in replies. A nil *Redactor passes text through unchanged.
+type Redactor struct {
+	clients, projects bool
+	strip             []stripRule
+
+	toAlias map[string]string // lower-cased name -> alias
+	toName  map[string]string // alias -> name
+	names   *regexp.Regexp
+}
+
+type stripRule struct {
+	re          *regexp.Regexp
+	replacement string
+}
+
+// NewRedactor builds a Redactor from a comma-separated list of categories
+// (clients, projects, emails, urls, pattern, or all). pattern is the regular
+// expression the "pattern" category strips. "", "off" and "none" disable
+// redaction and return nil.
+func NewRedactor(spec, pattern string) (*Redactor, error) {
+	spec = strings.ToLower(strings.TrimSpace(spec))
+	if spec == "" || spec == "off" || spec == "none" {
+		return nil, nil
+	}
+	r := &Redactor{toAlias: map[string]string{}, toName: map[string]string{}}
+	addPattern := func() error {
+		if pattern == "" {
+			return fmt.Errorf("redaction: %q needs LLM_REDACT_PATTERN", RedactPattern)
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("redaction: invalid LLM_REDACT_PATTERN: %w", err)
+		}
+		r.strip = append(r.strip, stripRule{re, "[redacted]"})
+		return nil
+	}
+	for _, category := range strings.Split(spec, ",") {
+		switch category = strings.TrimSpace(category); category {
+		case "all":
+			r.clients, r.projects = true, true
+			r.strip = append(r.strip, stripRule{emailPattern, "[email]"}, stripRule{urlPattern, "[url]"})
+			if pattern != "" {
+				if err := addPattern(); err != nil {
+					return nil, err
+				}
+			}
+		case RedactPattern:
+			if err := addPattern(); err != nil {
+				return nil, err
+			}
+		case RedactClients:
+			r.clients = true
+		case RedactProjects:
+			r.projects = true
+		case RedactEmails:
+			r.strip = append(r.strip, stripRule{emailPattern, "[email]"})
+		case RedactURLs:
+			r.strip = append(r.strip, stripRule{urlPattern, "[url]"})
+		case "":
+		default:
+			return nil, fmt.Errorf("redaction: unknown category %q (want clients, projects, emails, urls, pattern or all)", category)
+		}
+	}
+	return r, nil
+}
+
+// Setting describes what r redacts, e.g. "clients,projects,[email]", or
+// "off" for a nil Redactor.
+func (r *Redactor) Setting() string {
+	if r == nil {
+		return "off"
+	}
+	var parts []string
+	if r.clients {
+		parts = append(parts, RedactClients)
+	}
+	if r.projects {
+		parts = append(parts, RedactProjects)
+	}
+	for _, s := range r.strip {
+		parts = append(parts, s.replacement+s.re.String())
+	}
+	return strings.Join(parts, ",")
+}
+
+// Learn collects the client and project names in repo and assigns their
+// aliases. Names are aliased in sorted order, so the same data always gets
+// the same aliases. Client contact details are stripped outright.
+func (r *Redactor) Learn(repo chronos.Repository) error {
+	if r == nil || (!r.clients && !r.projects) {
+		return nil
+	}
+	var clients, projects []string
+	if r.clients {
+		list, err := chronos.ListClients(repo)
+		if err != nil {
+			return fmt.Errorf("redaction: list clients: %w", err)
+		}
+		for _, c := range list {
+			clients = append(clients, c.Name)
+			if contact := strings.TrimSpace(c.ContactInfo); contact != "" {
+				r.strip = append(r.strip, stripRule{regexp.MustCompile(`(?i)` + regexp.QuoteMeta(contact)), "[contact]"})
+			}
+		}
+	}
+	if r.projects {
+		list, err := repo.ListProjects(nil)
+		if err != nil {
+			return fmt.Errorf("redaction: list projects: %w", err)
+		}
+		for _, p := range list {
+			projects = append(projects, p.Name)
+		}
+	}
+	entries, err := chronos.ListEntries(repo, nil)
+	if err != nil {
+		return fmt.Errorf("redaction: list entries: %w", err)
+	}
+	for _, e := range entries {
+		clients, projects = append(clients, e.Client), append(projects, e.Project)
+	}
+	blocks, err := chronos.ListBlocks(repo, nil)
+	if err != nil {
+		return fmt.Errorf("redaction: list blocks: %w", err)
+	}
+	for _, b := range blocks {
+		clients, projects = append(clients, b.Client), append(projects, b.Project)
+	}
+	r.AddNames(clients, projects)
+	return nil
+}
+
+// AddNames assigns aliases to the given client and project names, for the
+// categories that are enabled.
+func (r *Redactor) AddNames(clients, projects []string) {
+	if r == nil {
+		return
+	}
+	if r.clients {
+		r.alias("Client", clients)
+	}
+	if r.projects {
+		r.alias("Project", projects)
+	}
+	var all []string
+	for name := range r.toAlias {
+		all = append(all, name)
+	}
+	if len(all) == 0 {
+		r.names = nil
+		return
+	}
+	// Longest first so "Acme Labs" wins over "Acme".
+	sort.Slice(all, func(i, j int) bool {
+		if len(all[i]) != len(all[j]) {
+			return len(all[i]) > len(all[j])
+		}
+		return all[i] < all[j]
+	})
+	parts := make([]string, len(all))
+	for i, name := range all {
+		parts[i] = wordBoundary(name, true) + regexp.QuoteMeta(name) + wordBoundary(name, false)
+	}
+	r.names = regexp.MustCompile(`(?i)(?:` + strings.Join(parts, "|") + `)`)
+}
+
+func (r *Redactor) alias(kind string, names []string) {
+	seen := map[string]bool{}
+	var fresh []string
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		key := strings.ToLower(name)
+		if name == "" || seen[key] || r.toAlias[key] != "" {
+			continue
+		}
+		seen[key] = true
+		fresh = append(fresh, name)
+	}
+	sort.Slice(fresh, func(i, j int) bool { return strings.ToLower(fresh[i]) < strings.ToLower(fresh[j]) })
+	n := 0
+	for alias := range r.toName {
+		if strings.HasPrefix(alias, kind+"-") {
+			n++
+		}
+	}
+	for _, name := range fresh {
+		alias := kind + "-" + aliasSuffix(n)
+		n++
+		r.toAlias[strings.ToLower(name)] = alias
+		r.toName[alias] = name
+	}
+}
+
+// aliasSuffix numbers aliases A..Z, AA, AB, ...
+func aliasSuffix(n int) string {
+	s := ""
+	for n >= 0 {
+		s = string(rune('A'+n%26)) + s
+		n = n/26 - 1
+	}
+	return s
+}
+
+// wordBoundary returns \b when the name starts (or ends) with a word
+// character, so "Apollo" does not match inside "Apollonia".
+func wordBoundary(name string, start bool) string {
+	var c rune
+	if start {
+		c, _ = utf8.DecodeRuneInString(name)
+	} else {
+		c, _ = utf8.DecodeLastRuneInString(name)
+	}
+	if c == '_' || unicode.IsLetter(c) || unicode.IsDigit(c) {
+		return `\b`
+	}
+	return ""
+}
+
+// Redact strips patterns and replaces names with their aliases.
+func (r *Redactor) Redact(text string) string {
+	if r == nil {
+		return text
+	}
+	for _, rule := range r.strip {
+		text = rule.re.ReplaceAllString(text, rule.replacement)
+	}
+	if r.names != nil {
+		text = r.names.ReplaceAllStringFunc(text, func(m string) string {
+			if alias, ok := r.toAlias[strings.ToLower(m)]; ok {
+				return alias
+			}
+			return m
+		})
+	}
+	return text
+}
+
+// Restore maps aliases in text back to the real names.
+func (r *Redactor) Restore(text string) string {
+	if r == nil || len(r.toName) == 0 {
+		return text
+	}
+	return aliasPattern.ReplaceAllStringFunc(text, func(alias string) string {
+		if name, ok := r.toName[alias]; ok {
+			return name
+		}
+		return alias
+	})
+}
+
+// RestoreJSON is Restore for JSON text, escaping the names it puts back.
+func (r *Redactor) RestoreJSON(raw json.RawMessage) json.RawMessage {
+	if r == nil || len(r.toName) == 0 {
+		return raw
+	}
+	return json.RawMessage(aliasPattern.ReplaceAllStringFunc(string(raw), func(alias string) string {
+		name, ok := r.toName[alias]
+		if !ok {
+			return alias
+		}
+		quoted, _ := json.Marshal(name)
+		return string(quoted[1 : len(quoted)-1])
+	}))
+}
+
+// RestoreStream wraps onToken so streamed text is restored as it arrives. Text
+// that might be the start of an alias is held back until it is complete;
+// flush emits whatever is left.
+func (r *Redactor) RestoreStream(onToken func(string)) (write func(string), flush func()) {
+	if r == nil || len(r.toName) == 0 {
+		return onToken, func() {}
+	}
+	var pending string
+	write = func(tok string) {
+		pending += tok
+		hold := partialAlias(pending)
+		if ready := pending[:len(pending)-hold]; ready != "" {
+			onToken(r.Restore(ready))
+			pending = pending[len(ready):]
+		}
+	}
+	flush = func() {
+		if pending != "" {
+			onToken(r.Restore(pending))
+			pending = ""
+		}
+	}
+	return write, flush
+}
+
+// partialAlias returns the length of the longest suffix of s that is, or
+// could still grow into, an alias.
+func partialAlias(s string) int {
+	from := len(s) - 16 // longer than any alias in practice
+	if from < 0 {
+		from = 0
+	}
+	for i := from; i < len(s); i++ {
+		if tail := s[i:]; isAliasPrefix(tail) {
+			return len(tail)
+		}
+	}
+	return 0
+}
+
+func isAliasPrefix(s string) bool {
+	for _, kind := range []string{"Client-", "Project-"} {
+		if len(s) <= len(kind) {
+			if strings.HasPrefix(kind, s) {
+				return true
+			}
+			continue
+		}
+		if strings.HasPrefix(s, kind) && strings.TrimLeft(s[len(kind):], "ABCDEFGHIJKLMNOPQRSTUVWXYZ") == "" {
+			return true
+		}
+	}
+	return false
+}
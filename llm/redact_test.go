@@ -0,0 +1,115 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/regiellis/chronos-go/chronos"
+)
+
+func testRedactor(t *testing.T, spec string) *Redactor {
+	t.Helper()
+	r, err := NewRedactor(spec, `JIRA-\d+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.AddNames([]string{"Globex", "Acme", "acme"}, []string{"Apollo", "Apollo Mobile"})
+	return r
+}
+
+func TestRedactor(t *testing.T) {
+	r := testRedactor(t, "all")
+	in := "Acme asked about Apollo Mobile and Apollo (JIRA-12, see https://jira.acme.io/x) - mail bob@acme.io. Apollonia is fine."
+	want := "Client-A asked about Project-B and Project-A ([redacted], see [url]) - mail [email]. Apollonia is fine."
+	if got := r.Redact(in); got != want {
+		t.Fatalf("Redact:\n got %q\nwant %q", got, want)
+	}
+	if got := r.Restore("Client-A spent 3h on Project-B; Client-Z is unknown."); got != "Acme spent 3h on Apollo Mobile; Client-Z is unknown." {
+		t.Errorf("Restore: %q", got)
+	}
+
+	// Aliases are stable across runs and new names get the next letter.
+	again := testRedactor(t, "all")
+	again.AddNames([]string{"Initech"}, nil)
+	if again.Redact("Globex, Initech") != "Client-B, Client-C" {
+		t.Errorf("aliases should be stable, got %q", again.Redact("Globex, Initech"))
+	}
+
+	var nilRedactor *Redactor
+	if nilRedactor.Redact(in) != in || nilRedactor.Restore("Client-A") != "Client-A" {
+		t.Error("a nil Redactor should pass text through")
+	}
+	if r, err := NewRedactor("off", ""); r != nil || err != nil {
+		t.Errorf("off should disable redaction, got %v, %v", r, err)
+	}
+	if _, err := NewRedactor("clients,secrets", ""); err == nil {
+		t.Error("unknown categories should fail")
+	}
+}
+
+func TestRedactorCategories(t *testing.T) {
+	r := testRedactor(t, "clients,emails")
+	if got := r.Redact("Acme / Apollo / a@b.co"); got != "Client-A / Apollo / [email]" {
+		t.Errorf("only clients and emails should be redacted, got %q", got)
+	}
+}
+
+func TestRestoreStream(t *testing.T) {
+	r := testRedactor(t, "all")
+	var got strings.Builder
+	write, flush := r.RestoreStream(func(tok string) { got.WriteString(tok) })
+	for _, tok := range []string{"You worked for Cli", "ent-", "A on Pro", "ject-B", " today. Client-"} {
+		write(tok)
+	}
+	flush()
+	if got.String() != "You worked for Acme on Apollo Mobile today. Client-" {
+		t.Errorf("streamed restore: %q", got.String())
+	}
+}
+
+func TestRestoreJSON(t *testing.T) {
+	r, _ := NewRedactor("clients", "")
+	r.AddNames([]string{`Say "Hi" Ltd`}, nil)
+	raw := r.RestoreJSON(json.RawMessage(`{"client":"Client-A"}`))
+	var args struct{ Client string }
+	if err := json.Unmarshal(raw, &args); err != nil || args.Client != `Say "Hi" Ltd` {
+		t.Errorf("RestoreJSON: %s, %v", raw, err)
+	}
+}
+
+func TestClientRedaction(t *testing.T) {
+	p := &scriptedProvider{replies: []string{"Client-A is over budget on Project-A."}}
+	c := testParser(p)
+	c.Redactor = testRedactor(t, "all")
+	blocks := []*chronos.Block{{ID: 1, Name: "Sprint", Client: "Acme", Project: "Apollo", StartTime: parseNow.AddDate(0, 0, -1), EndTime: parseNow.AddDate(0, 0, 7), Active: true}}
+	out, err := c.SmartReminder(context.Background(), nil, blocks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(p.prompts[0], "Acme") || strings.Contains(p.prompts[0], "Apollo") || !strings.Contains(p.prompts[0], "Client-A") {
+		t.Errorf("prompt not redacted:\n%s", p.prompts[0])
+	}
+	if out != "Acme is over budget on Apollo." {
+		t.Errorf("answer not restored: %q", out)
+	}
+
+	// Tool arguments come back with real names so the store can match them.
+	repo := chronos.NewMemoryRepository()
+	tools := &StoreTools{Repo: repo, Now: func() time.Time { return parseNow }}
+	p = &scriptedProvider{replies: []string{`{"tool":"sum_hours","arguments":{"client":"Client-A"}}`, `{"answer":"Client-A: 0h"}`}}
+	c = testParser(p)
+	c.Redactor = testRedactor(t, "all")
+	answer, trace, err := c.AnswerWithTools(context.Background(), "hours for acme?", tools)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trace) != 1 || !strings.Contains(string(trace[0].Arguments), `"Acme"`) || answer != "Acme: 0h" {
+		t.Errorf("tool call not restored: %+v, %q", trace, answer)
+	}
+	if strings.Contains(strings.ToLower(p.prompts[0]), "acme") {
+		t.Errorf("question not redacted:\n%s", p.prompts[0])
+	}
+}
@@ -0,0 +1,375 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/regiellis/chronos-go/chronos"
+)
+
+// StoreTools is the read-only ToolRunner behind `chronos ask`: every number
+// the model reports comes from these queries against the repository.
+// Rates, when set, fills in the effective rate of entries without their own,
+// Rounding their billed time and Currencies the currency amounts are in;
+// entries on an invoice are reported as that invoice billed them.
+type StoreTools struct {
+	Repo       chronos.Repository
+	Rates      *chronos.RateResolver
+	Rounding   *chronos.Rounder
+	Currencies *chronos.Currencies
+	Now        func() time.Time
+}
+
+const entryFilterProps = `
+    "from": {"type": "string", "description": "first day, YYYY-MM-DD"},
+    "to": {"type": "string", "description": "last day (inclusive), YYYY-MM-DD"},
+    "project": {"type": "string"},
+    "client": {"type": "string"},
+    "task": {"type": "string"},
+    "tag": {"type": "string"},
+    "billable": {"type": "boolean"},
+    "invoiced": {"type": "boolean"}`
+
+var storeTools = []Tool{
+	{
+		Name:        "sum_hours",
+		Description: "Total hours, billable hours and billable amount of the entries matching the filters, optionally grouped.",
+		Parameters: json.RawMessage(`{"type": "object", "properties": {` + entryFilterProps + `,
+    "group_by": {"type": "string", "enum": ["project", "client", "task", "tag", "day"]}}}`),
+	},
+	{
+		Name:        "list_entries",
+		Description: "The entries matching the filters, newest first.",
+		Parameters: json.RawMessage(`{"type": "object", "properties": {` + entryFilterProps + `,
+    "limit": {"type": "integer", "description": "at most 50, default 20"}}}`),
+	},
+	{
+		Name:        "block_progress",
+		Description: "Hours logged in a block (the active one unless a name is given), with its dates.",
+		Parameters:  json.RawMessage(`{"type": "object", "properties": {"name": {"type": "string"}}}`),
+	},
+	{
+		Name:        "unbilled_totals",
+		Description: "Billable hours and amount not yet invoiced, per client.",
+		Parameters:  json.RawMessage(`{"type": "object", "properties": {"client": {"type": "string"}}}`),
+	},
+}
+
+// Tools lists the tools offered to the model.
+func (s *StoreTools) Tools() []Tool { return storeTools }
+
+// entryArgs are the filters shared by sum_hours and list_entries.
+type entryArgs struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Project  string `json:"project"`
+	Client   string `json:"client"`
+	Task     string `json:"task"`
+	Tag      string `json:"tag"`
+	Billable *bool  `json:"billable"`
+	Invoiced *bool  `json:"invoiced"`
+	GroupBy  string `json:"group_by"`
+	Limit    int    `json:"limit"`
+}
+
+// Call runs a tool and returns its result as JSON.
+func (s *StoreTools) Call(ctx context.Context, name string, raw json.RawMessage) (string, error) {
+	var result interface{}
+	var err error
+	switch name {
+	case "sum_hours", "list_entries":
+		var args entryArgs
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+		var entries []*chronos.Entry
+		if entries, err = s.entries(args); err != nil {
+			break
+		}
+		if name == "sum_hours" {
+			result, err = sumHours(entries, args.GroupBy)
+		} else {
+			result = listEntries(entries, args.Limit)
+		}
+	case "block_progress":
+		var args struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+		result, err = s.blockProgress(args.Name)
+	case "unbilled_totals":
+		var args struct {
+			Client string `json:"client"`
+		}
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+		result, err = s.unbilledTotals(args.Client)
+	default:
+		return "", fmt.Errorf("unknown tool %q", name)
+	}
+	if err != nil {
+		return "", err
+	}
+	out, err := json.Marshal(result)
+	if err != nil {
+		return "", fmt.Errorf("encode %s result: %w", name, err)
+	}
+	return string(out), nil
+}
+
+// entries queries the store; names match case-insensitively since models
+// rarely keep the user's casing.
+func (s *StoreTools) entries(args entryArgs) ([]*chronos.Entry, error) {
+	filters := map[string]interface{}{}
+	for key, day := range map[string]string{"start_date": args.From, "end_date": args.To} {
+		if day == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", day); err != nil {
+			return nil, fmt.Errorf("%q is not a YYYY-MM-DD date", day)
+		}
+		filters[key] = day
+	}
+	if args.Billable != nil {
+		filters["billable"] = *args.Billable
+	}
+	if args.Invoiced != nil {
+		filters["invoiced"] = *args.Invoiced
+	}
+	if args.Tag != "" {
+		filters["tag"] = args.Tag
+	}
+	all, err := chronos.ListEntries(s.Repo, filters)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyBilling(all); err != nil {
+		return nil, err
+	}
+	var entries []*chronos.Entry
+	for _, e := range all {
+		if matchName(e.Project, args.Project) && matchName(e.Client, args.Client) && matchName(e.Task, args.Task) {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+func matchName(have, want string) bool {
+	return want == "" || strings.EqualFold(strings.TrimSpace(have), strings.TrimSpace(want))
+}
+
+type hoursGroup struct {
+	Name  string  `json:"name"`
+	Hours float64 `json:"hours"`
+}
+
+type hoursSum struct {
+	Entries       int          `json:"entries"`
+	Hours         float64      `json:"hours"`
+	BillableHours float64      `json:"billable_hours"`
+	Amount        float64      `json:"amount"`
+	BilledHours   float64      `json:"billed_hours"` // Billable hours after rounding
+	Groups        []hoursGroup `json:"groups,omitempty"`
+}
+
+func sumHours(entries []*chronos.Entry, groupBy string) (hoursSum, error) {
+	sum := hoursSum{Entries: len(entries)}
+	for _, e := range entries {
+		sum.Hours += e.Hours()
+		if e.Billable {
+			sum.BillableHours += e.Hours()
+			sum.BilledHours += e.BilledHours()
+		}
+		sum.Amount += e.Amount()
+	}
+	sum.Hours, sum.BillableHours, sum.BilledHours, sum.Amount = round2(sum.Hours), round2(sum.BillableHours), round2(sum.BilledHours), round2(sum.Amount)
+
+	var totals map[string]float64
+	switch groupBy {
+	case "":
+		return sum, nil
+	case "project":
+		totals = chronos.CalculateTotalsBy(entries, func(e *chronos.Entry) string { return e.Project })
+	case "client":
+		totals = chronos.CalculateTotalsBy(entries, func(e *chronos.Entry) string { return e.Client })
+	case "task":
+		totals = chronos.CalculateTotalsBy(entries, func(e *chronos.Entry) string { return e.Task })
+	case "day":
+		totals = chronos.CalculateTotalsBy(entries, func(e *chronos.Entry) string { return e.StartTime.Format("2006-01-02") })
+	case "tag":
+		totals = map[string]float64{}
+		for _, e := range entries {
+			for _, tag := range e.Tags {
+				totals[tag] += e.Minutes()
+			}
+		}
+	default:
+		return sum, fmt.Errorf("cannot group by %q", groupBy)
+	}
+	for name, minutes := range totals {
+		sum.Groups = append(sum.Groups, hoursGroup{Name: name, Hours: round2(minutes / 60)})
+	}
+	sort.Slice(sum.Groups, func(i, j int) bool {
+		if groupBy == "day" {
+			return sum.Groups[i].Name < sum.Groups[j].Name
+		}
+		return sum.Groups[i].Hours > sum.Groups[j].Hours
+	})
+	return sum, nil
+}
+
+type toolEntry struct {
+	ID       int64    `json:"id"`
+	Start    string   `json:"start"`
+	Hours    float64  `json:"hours"`
+	Project  string   `json:"project,omitempty"`
+	Client   string   `json:"client,omitempty"`
+	Task     string   `json:"task,omitempty"`
+	Summary  string   `json:"summary,omitempty"`
+	Billable bool     `json:"billable"`
+	Invoiced bool     `json:"invoiced"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+func listEntries(entries []*chronos.Entry, limit int) map[string]interface{} {
+	if limit <= 0 || limit > 50 {
+		limit = 20
+	}
+	chronos.SortEntriesByStartTime(entries, false)
+	list := []toolEntry{}
+	for i, e := range entries {
+		if i == limit {
+			break
+		}
+		list = append(list, toolEntry{
+			ID: e.ID, Start: e.StartTime.Format("2006-01-02 15:04"), Hours: round2(e.Hours()),
+			Project: e.Project, Client: e.Client, Task: e.Task, Summary: e.Summary,
+			Billable: e.Billable, Invoiced: e.Invoiced, Tags: e.Tags,
+		})
+	}
+	return map[string]interface{}{"total": len(entries), "entries": list}
+}
+
+func (s *StoreTools) blockProgress(name string) (map[string]interface{}, error) {
+	var block *chronos.Block
+	if name == "" {
+		active, err := chronos.GetActiveBlock(s.Repo)
+		if err != nil || active == nil {
+			return nil, fmt.Errorf("there is no active block")
+		}
+		block = active
+	} else {
+		blocks, err := chronos.ListBlocks(s.Repo, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range blocks {
+			if matchName(b.Name, name) {
+				block = b
+				break
+			}
+		}
+		if block == nil {
+			return nil, fmt.Errorf("no block named %q", name)
+		}
+	}
+	entries, err := chronos.ListEntries(s.Repo, map[string]interface{}{"block_id": block.ID})
+	if err != nil {
+		return nil, err
+	}
+	sum, _ := sumHours(entries, "")
+	progress := map[string]interface{}{
+		"name": block.Name, "client": block.Client, "project": block.Project, "active": block.Active,
+		"start": block.StartTime.Format("2006-01-02"), "entries": sum.Entries,
+		"hours": sum.Hours, "billable_hours": sum.BillableHours, "amount": sum.Amount,
+	}
+	if !block.EndTime.IsZero() {
+		now := time.Now()
+		if s.Now != nil {
+			now = s.Now()
+		}
+		progress["end"] = block.EndTime.Format("2006-01-02")
+		progress["days_left"] = int(math.Max(0, block.EndTime.Sub(now).Hours()/24))
+	}
+	return progress, nil
+}
+
+func (s *StoreTools) unbilledTotals(client string) (map[string]interface{}, error) {
+	entries, err := chronos.FindUnbilledEntries(s.Repo)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyBilling(entries); err != nil {
+		return nil, err
+	}
+	type clientTotal struct {
+		Client   string  `json:"client"`
+		Currency string  `json:"currency,omitempty"`
+		Hours    float64 `json:"hours"`
+		Amount   float64 `json:"amount"`
+	}
+	byClient := map[string]*clientTotal{}
+	var matched []*chronos.Entry
+	var hours, amount float64
+	for _, e := range entries {
+		if !matchName(e.Client, client) {
+			continue
+		}
+		matched = append(matched, e)
+		key := e.Client + "\x00" + e.Currency
+		t := byClient[key]
+		if t == nil {
+			t = &clientTotal{Client: e.Client, Currency: e.Currency}
+			byClient[key] = t
+		}
+		t.Hours += e.Hours()
+		t.Amount += e.Amount()
+		hours += e.Hours()
+		amount += e.Amount()
+	}
+	clients := []clientTotal{}
+	for _, t := range byClient {
+		clients = append(clients, clientTotal{Client: t.Client, Currency: t.Currency, Hours: round2(t.Hours), Amount: round2(t.Amount)})
+	}
+	sort.Slice(clients, func(i, j int) bool { return clients[i].Amount > clients[j].Amount })
+	totals := map[string]interface{}{"hours": round2(hours), "amount": round2(amount), "clients": clients}
+	if s.Currencies != nil {
+		// amount adds up different currencies; revenue keeps them apart.
+		totals["revenue"] = s.Currencies.Revenue(matched)
+	}
+	return totals, nil
+}
+
+// applyBilling fills in the rate, billed time and currency of entries from
+// any period: today's rates and rules, then what their invoices billed for
+// entries on one.
+func (s *StoreTools) applyBilling(entries []*chronos.Entry) error {
+	if s.Rates != nil {
+		s.Rates.Apply(entries)
+	}
+	if s.Rounding != nil {
+		s.Rounding.ApplyHistory(entries)
+	}
+	if s.Currencies != nil {
+		s.Currencies.Apply(entries)
+	}
+	invoices, err := chronos.ListInvoices(s.Repo, nil)
+	if err != nil {
+		return err
+	}
+	chronos.ApplyInvoiced(entries, invoices)
+	return nil
+}
+
+func round2(f float64) float64 {
+	return math.Round(f*100) / 100
+}
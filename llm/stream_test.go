@@ -0,0 +1,73 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerateStream(t *testing.T) {
+	bodies := map[string]string{
+		"/api/generate": `{"response":"Two ","done":false}` + "\n" + `{"response":"hours","done":false}` + "\n" + `{"response":"","done":true}` + "\n",
+		"/completion":   "data: {\"content\":\"Two \",\"stop\":false}\n\ndata: {\"content\":\"hours\",\"stop\":false}\n\ndata: {\"content\":\"\",\"stop\":true}\n\n",
+		"/v1/chat/completions": "data: {\"choices\":[{\"delta\":{\"role\":\"assistant\"}}]}\n\n" +
+			"data: {\"choices\":[{\"delta\":{\"content\":\"Two \"}}]}\n\n" +
+			"data: {\"choices\":[{\"delta\":{\"content\":\"hours\"}}]}\n\ndata: [DONE]\n\n",
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher := w.(http.Flusher)
+		for _, line := range strings.SplitAfter(bodies[r.URL.Path], "\n") {
+			fmt.Fprint(w, line)
+			flusher.Flush()
+		}
+	}))
+	defer srv.Close()
+
+	providers := map[string]Streamer{
+		"ollama":   &OllamaClient{Endpoint: Endpoint{Host: srv.URL}},
+		"llamacpp": &LlamaCppClient{Endpoint: Endpoint{Host: srv.URL}},
+		"openai":   &OpenAIClient{Endpoint: Endpoint{Host: srv.URL}},
+	}
+	for name, p := range providers {
+		var tokens []string
+		out, err := p.GenerateStream(context.Background(), "how long?", func(tok string) {
+			if tok != "" {
+				tokens = append(tokens, tok)
+			}
+		})
+		if err != nil || out != "Two hours" || len(tokens) != 2 {
+			t.Errorf("%s: got %q with tokens %q, %v", name, out, tokens, err)
+		}
+	}
+}
+
+func TestGenerateStream_Cancel(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"response":"Thinking","done":false}`)
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &OllamaClient{Endpoint: Endpoint{Host: srv.URL, Timeout: 20 * time.Millisecond}}
+	out, err := c.GenerateStream(ctx, "x", func(tok string) { cancel() })
+	if !errors.Is(err, context.Canceled) || out != "Thinking" {
+		t.Fatalf("expected context.Canceled after the first token, got %q, %v", out, err)
+	}
+}
+
+func TestClientOnTokenWithoutStreaming(t *testing.T) {
+	p := &scriptedProvider{replies: []string{"Log your standup."}}
+	var got string
+	c := &Client{Provider: p, OnToken: func(tok string) { got += tok }}
+	out, err := c.SmartReminder(context.Background(), nil, nil)
+	if err != nil || out != "Log your standup." || got != out {
+		t.Fatalf("expected the whole reply through OnToken, got %q / %q, %v", out, got, err)
+	}
+}
@@ -0,0 +1,213 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/charmbracelet/log"
+	"github.com/regiellis/chronos-go/chronos"
+)
+
+// DefaultMaxRepairs is how often ParseEntry re-prompts after an invalid reply.
+const DefaultMaxRepairs = 2
+
+// ErrInvalidEntry is returned (wrapped) when the model never produced an entry
+// that passed validation.
+var ErrInvalidEntry = errors.New("LLM did not return a valid entry")
+
+// entrySchema is the JSON schema the model's reply must follow. Providers that
+// support constrained output enforce it while sampling; validate checks the
+// rest.
+var entrySchema = json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "summary": {"type": "string"},
+    "project": {"type": "string"},
+    "new_project": {"type": "boolean"},
+    "client": {"type": "string"},
+    "task": {"type": "string"},
+    "start_time": {"type": "string", "description": "RFC3339"},
+    "end_time": {"type": "string", "description": "RFC3339"},
+    "duration_minutes": {"type": "integer", "minimum": 0},
+    "billable": {"type": "boolean"},
+    "rate": {"type": "number", "minimum": 0},
+    "tags": {"type": "array", "items": {"type": "string"}}
+  },
+  "required": ["summary", "project", "duration_minutes"]
+}`)
+
+// llmEntry is the model's reply, before validation.
+type llmEntry struct {
+	Summary         string   `json:"summary"`
+	Project         string   `json:"project"`
+	NewProject      bool     `json:"new_project"`
+	Client          string   `json:"client"`
+	Task            string   `json:"task"`
+	StartTime       string   `json:"start_time"`
+	EndTime         string   `json:"end_time"`
+	DurationMinutes int64    `json:"duration_minutes"`
+	Billable        *bool    `json:"billable"`
+	Rate            float64  `json:"rate"`
+	Tags            []string `json:"tags"`
+}
+
+// ParseEntry uses the LLM to parse a natural language time entry string.
+func (c *Client) ParseEntry(input string) (*chronos.Entry, error) {
+	return c.ParseEntryContext(context.Background(), input)
+}
+
+// ParseEntryContext asks the model for an entry as schema-constrained JSON and
+// validates it. Invalid replies are sent back with the validation error up to
+// MaxRepairs times; server errors are returned straight away.
+func (c *Client) ParseEntryContext(ctx context.Context, input string) (*chronos.Entry, error) {
+	now := time.Now()
+	if c.Now != nil {
+		now = c.Now()
+	}
+	prompt, err := c.Prompts.Render(PromptParse, struct {
+		Input, Schema, Now string
+		KnownProjects      []string
+	}{input, string(entrySchema), now.Format(time.RFC3339), c.KnownProjects})
+	if err != nil {
+		return nil, err
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= c.MaxRepairs; attempt++ {
+		output, err := c.generateJSON(ctx, prompt, entrySchema)
+		if err != nil {
+			log.Error("[LLM] ParseEntry request failed", "error", err)
+			return nil, fmt.Errorf("LLM parsing request failed: %w", err)
+		}
+		entry, err := c.decodeEntry(output, now)
+		if err == nil {
+			return entry, nil
+		}
+		log.Warn("[LLM] ParseEntry reply rejected", "attempt", attempt+1, "error", err)
+		lastErr = err
+		prompt = fmt.Sprintf("%s\n\nYour previous reply was:\n%s\nIt was rejected: %v\nReply with the corrected JSON object only.", prompt, output, err)
+	}
+	return nil, fmt.Errorf("%w after %d attempt(s): %v", ErrInvalidEntry, c.MaxRepairs+1, lastErr)
+}
+
+// generateJSON uses the provider's constrained output when it has one. Like
+// generate it redacts the prompt and restores the reply.
+func (c *Client) generateJSON(ctx context.Context, prompt string, schema json.RawMessage) (string, error) {
+	prompt = c.Redactor.Redact(prompt)
+	var out string
+	var err error
+	if g, ok := c.Provider.(JSONGenerator); ok {
+		out, err = g.GenerateJSON(ctx, prompt, schema)
+	} else {
+		out, err = c.Provider.Generate(ctx, prompt)
+	}
+	return string(c.Redactor.RestoreJSON(json.RawMessage(out))), err
+}
+
+// decodeEntry extracts, decodes and validates a reply.
+func (c *Client) decodeEntry(output string, now time.Time) (*chronos.Entry, error) {
+	// Unconstrained models like to wrap JSON in prose or code fences.
+	first, last := strings.IndexByte(output, '{'), strings.LastIndexByte(output, '}')
+	if first < 0 || last < first {
+		return nil, fmt.Errorf("reply contains no JSON object")
+	}
+	var resp llmEntry
+	if err := json.Unmarshal([]byte(output[first:last+1]), &resp); err != nil {
+		return nil, fmt.Errorf("reply does not match the schema: %v", err)
+	}
+	return resp.validate(now, c.KnownProjects)
+}
+
+// validate checks the reply and resolves it into an entry. A duration alone
+// means the work just finished.
+func (r *llmEntry) validate(now time.Time, known []string) (*chronos.Entry, error) {
+	parseTime := func(field, value string) (time.Time, error) {
+		if value == "" {
+			return time.Time{}, nil
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return t, fmt.Errorf("%s %q is not RFC3339", field, value)
+		}
+		if t.After(now.Add(5*time.Minute)) || t.Before(now.AddDate(-1, 0, 0)) {
+			return t, fmt.Errorf("%s %s is not within the past year", field, value)
+		}
+		return t, nil
+	}
+	start, err := parseTime("start_time", r.StartTime)
+	if err != nil {
+		return nil, err
+	}
+	end, err := parseTime("end_time", r.EndTime)
+	if err != nil {
+		return nil, err
+	}
+
+	duration := time.Duration(r.DurationMinutes) * time.Minute
+	switch {
+	case r.DurationMinutes < 0:
+		return nil, fmt.Errorf("duration_minutes must be positive, got %d", r.DurationMinutes)
+	case !start.IsZero() && !end.IsZero():
+		if !end.After(start) {
+			return nil, fmt.Errorf("end_time must be after start_time")
+		}
+		span := end.Sub(start)
+		if duration > 0 && (duration-span > time.Minute || span-duration > time.Minute) {
+			return nil, fmt.Errorf("duration_minutes %d does not match start_time to end_time (%.0f min)", r.DurationMinutes, span.Minutes())
+		}
+		duration = span
+	case duration == 0:
+		return nil, fmt.Errorf("duration_minutes must be positive, or start_time and end_time both set")
+	case !start.IsZero():
+		end = start.Add(duration)
+	case !end.IsZero():
+		start = end.Add(-duration)
+	default:
+		end = now
+		start = now.Add(-duration)
+	}
+	if duration > 24*time.Hour {
+		return nil, fmt.Errorf("duration of %.0f min is longer than a day", duration.Minutes())
+	}
+	if end.After(now.Add(5 * time.Minute)) {
+		return nil, fmt.Errorf("entry ends in the future (%s)", end.Format(time.RFC3339))
+	}
+	if r.Rate < 0 {
+		return nil, fmt.Errorf("rate must not be negative")
+	}
+
+	project := strings.TrimSpace(r.Project)
+	if project != "" && len(known) > 0 {
+		match := ""
+		for _, k := range known {
+			if strings.EqualFold(k, project) {
+				match = k
+				break
+			}
+		}
+		switch {
+		case match != "":
+			project = match
+		case !r.NewProject:
+			return nil, fmt.Errorf("project %q is not a known project (%s); use one of them or set new_project to true", project, strings.Join(known, ", "))
+		}
+	}
+
+	entry := &chronos.Entry{
+		Summary:   r.Summary,
+		Project:   project,
+		Client:    r.Client,
+		Task:      r.Task,
+		StartTime: start,
+		EndTime:   end,
+		Duration:  int64(duration.Round(time.Minute).Minutes()),
+		Billable:  r.Billable == nil || *r.Billable,
+		Rate:      r.Rate,
+		Tags:      r.Tags,
+	}
+	return entry, nil
+}
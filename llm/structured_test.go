@@ -0,0 +1,143 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// scriptedProvider replies with the given outputs in order and records prompts.
+type scriptedProvider struct {
+	replies []string
+	prompts []string
+	schemas []json.RawMessage
+}
+
+func (p *scriptedProvider) Generate(ctx context.Context, prompt string) (string, error) {
+	p.prompts = append(p.prompts, prompt)
+	if len(p.replies) == 0 {
+		return "", errors.New("no more replies")
+	}
+	reply := p.replies[0]
+	p.replies = p.replies[1:]
+	return reply, nil
+}
+
+func (p *scriptedProvider) Chat(ctx context.Context, messages []Message) (string, error) {
+	return p.Generate(ctx, flattenMessages(messages))
+}
+
+// constrainedProvider also implements JSONGenerator.
+type constrainedProvider struct{ scriptedProvider }
+
+func (p *constrainedProvider) GenerateJSON(ctx context.Context, prompt string, schema json.RawMessage) (string, error) {
+	p.schemas = append(p.schemas, schema)
+	return p.Generate(ctx, prompt)
+}
+
+var parseNow = time.Date(2025, 6, 11, 15, 0, 0, 0, time.UTC)
+
+func testParser(p Provider, known ...string) *Client {
+	return &Client{Provider: p, KnownProjects: known, MaxRepairs: DefaultMaxRepairs, Now: func() time.Time { return parseNow }}
+}
+
+func TestParseEntry_ConstrainedOutput(t *testing.T) {
+	p := &constrainedProvider{scriptedProvider{replies: []string{
+		`{"summary":"Standup","project":"apollo","duration_minutes":15,"tags":["meeting"]}`,
+	}}}
+	entry, err := testParser(p, "Apollo").ParseEntry("15m standup on apollo")
+	if err != nil {
+		t.Fatalf("ParseEntry failed: %v", err)
+	}
+	if len(p.schemas) != 1 || !json.Valid(p.schemas[0]) {
+		t.Fatalf("expected the entry schema to be sent, got %v", p.schemas)
+	}
+	if entry.Project != "Apollo" || entry.Duration != 15 || !entry.Billable || len(entry.Tags) != 1 {
+		t.Errorf("unexpected entry: %+v", entry)
+	}
+	if !entry.EndTime.Equal(parseNow) || !entry.StartTime.Equal(parseNow.Add(-15*time.Minute)) {
+		t.Errorf("a duration alone should end now, got %s - %s", entry.StartTime, entry.EndTime)
+	}
+}
+
+func TestParseEntry_Repair(t *testing.T) {
+	p := &scriptedProvider{replies: []string{
+		"Sure! Here you go:\n```json\n{\"summary\":\"Fix\",\"project\":\"Zeus\",\"duration_minutes\":-30}\n```",
+		`{"summary":"Fix","project":"Zeus","duration_minutes":30}`,
+		`{"summary":"Fix","project":"Zeus","new_project":true,"duration_minutes":30}`,
+	}}
+	entry, err := testParser(p, "Apollo").ParseEntry("30m fixing zeus")
+	if err != nil {
+		t.Fatalf("ParseEntry failed: %v", err)
+	}
+	if len(p.prompts) != 3 {
+		t.Fatalf("expected 3 attempts, got %d", len(p.prompts))
+	}
+	if !strings.Contains(p.prompts[1], "duration_minutes must be positive") || !strings.Contains(p.prompts[2], `"Zeus" is not a known project`) {
+		t.Errorf("repair prompts should carry the validation error:\n%s\n---\n%s", p.prompts[1], p.prompts[2])
+	}
+	if entry.Project != "Zeus" || entry.Duration != 30 {
+		t.Errorf("unexpected entry: %+v", entry)
+	}
+}
+
+func TestParseEntry_GivesUpAndFallsBack(t *testing.T) {
+	bad := `{"summary":"x","project":"","duration_minutes":0}`
+	p := &scriptedProvider{replies: []string{bad, bad, bad, bad}}
+	c := testParser(p)
+	if _, err := c.ParseEntry("2h on Apollo"); !errors.Is(err, ErrInvalidEntry) {
+		t.Fatalf("expected ErrInvalidEntry, got %v", err)
+	}
+	if len(p.prompts) != DefaultMaxRepairs+1 {
+		t.Errorf("expected %d attempts, got %d", DefaultMaxRepairs+1, len(p.prompts))
+	}
+
+	p.replies = []string{bad, bad, bad}
+	parser := FallbackParser{Primary: c, Fallback: &GrammarParser{Now: func() time.Time { return parseNow }}}
+	entry, err := parser.ParseEntry("2h on Apollo")
+	if err != nil || entry.Project != "Apollo" || entry.Duration != 120 {
+		t.Fatalf("expected the grammar to take over, got %+v, %v", entry, err)
+	}
+}
+
+func TestParseEntry_ServerErrorIsNotRepaired(t *testing.T) {
+	p := &scriptedProvider{}
+	if _, err := testParser(p).ParseEntry("2h"); err == nil || errors.Is(err, ErrInvalidEntry) {
+		t.Fatalf("expected the provider error, got %v", err)
+	}
+	if len(p.prompts) != 1 {
+		t.Errorf("provider errors should not be retried by the repair loop, got %d calls", len(p.prompts))
+	}
+}
+
+func TestLLMEntryValidate(t *testing.T) {
+	rfc := func(d time.Duration) string { return parseNow.Add(d).Format(time.RFC3339) }
+	tests := []struct {
+		name  string
+		reply llmEntry
+		want  string // substring of the error, or "" for valid
+	}{
+		{"range", llmEntry{StartTime: rfc(-2 * time.Hour), EndTime: rfc(-time.Hour)}, ""},
+		{"start and duration", llmEntry{StartTime: rfc(-2 * time.Hour), DurationMinutes: 60}, ""},
+		{"no duration", llmEntry{}, "must be positive"},
+		{"backwards", llmEntry{StartTime: rfc(-time.Hour), EndTime: rfc(-2 * time.Hour)}, "after start_time"},
+		{"mismatch", llmEntry{StartTime: rfc(-2 * time.Hour), EndTime: rfc(-time.Hour), DurationMinutes: 90}, "does not match"},
+		{"future", llmEntry{StartTime: rfc(time.Hour), DurationMinutes: 30}, "past year"},
+		{"ends in future", llmEntry{StartTime: rfc(-time.Hour), DurationMinutes: 120}, "future"},
+		{"too long", llmEntry{DurationMinutes: 25 * 60}, "longer than a day"},
+		{"bad time", llmEntry{StartTime: "yesterday 9am", DurationMinutes: 30}, "RFC3339"},
+		{"negative rate", llmEntry{DurationMinutes: 30, Rate: -5}, "rate"},
+	}
+	for _, tt := range tests {
+		_, err := tt.reply.validate(parseNow, nil)
+		switch {
+		case tt.want == "" && err != nil:
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+			t.Errorf("%s: expected error containing %q, got %v", tt.name, tt.want, err)
+		}
+	}
+}
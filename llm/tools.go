@@ -0,0 +1,291 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	log "github.com/charmbracelet/log"
+)
+
+// Tool is a function the model may call, described by a JSON schema.
+type Tool struct {
+	Name        string
+	Description string
+	Parameters  json.RawMessage
+}
+
+// ToolCall is a request from the model to run a tool.
+type ToolCall struct {
+	ID        string // set by OpenAI-compatible servers; echoed back with the result
+	Name      string
+	Arguments json.RawMessage
+}
+
+// ToolCaller is implemented by providers with native function calling. The
+// reply either carries ToolCalls or is the final answer.
+type ToolCaller interface {
+	ChatTools(ctx context.Context, messages []Message, tools []Tool) (Message, error)
+}
+
+// toolSpec is the tool format shared by Ollama and the OpenAI API.
+type toolSpec struct {
+	Type     string `json:"type"`
+	Function struct {
+		Name        string          `json:"name"`
+		Description string          `json:"description"`
+		Parameters  json.RawMessage `json:"parameters"`
+	} `json:"function"`
+}
+
+func toolSpecs(tools []Tool) []toolSpec {
+	specs := make([]toolSpec, len(tools))
+	for i, t := range tools {
+		specs[i].Type = "function"
+		specs[i].Function.Name = t.Name
+		specs[i].Function.Description = t.Description
+		specs[i].Function.Parameters = t.Parameters
+	}
+	return specs
+}
+
+// wireMessage is a chat message with tool calls as Ollama and the OpenAI API
+// exchange them. Ollama sends arguments as an object, OpenAI as a string.
+type wireMessage struct {
+	Role       string         `json:"role"`
+	Content    string         `json:"content"`
+	ToolCalls  []wireToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string         `json:"tool_call_id,omitempty"`
+	ToolName   string         `json:"tool_name,omitempty"`
+}
+
+type wireToolCall struct {
+	ID       string `json:"id,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Function struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	} `json:"function"`
+}
+
+// toWire converts messages for the server; stringArgs selects the OpenAI form.
+func toWire(messages []Message, stringArgs bool) []wireMessage {
+	out := make([]wireMessage, len(messages))
+	for i, m := range messages {
+		w := wireMessage{Role: m.Role, Content: m.Content, ToolCallID: m.ToolCallID}
+		if !stringArgs {
+			w.ToolCallID, w.ToolName = "", m.ToolName
+		}
+		for _, call := range m.ToolCalls {
+			var wc wireToolCall
+			wc.ID, wc.Function.Name, wc.Function.Arguments = call.ID, call.Name, call.Arguments
+			if stringArgs {
+				wc.Type = "function"
+				wc.Function.Arguments, _ = json.Marshal(string(call.Arguments))
+			}
+			w.ToolCalls = append(w.ToolCalls, wc)
+		}
+		out[i] = w
+	}
+	return out
+}
+
+// message converts a reply back, decoding string arguments.
+func (w wireMessage) message() Message {
+	m := Message{Role: w.Role, Content: w.Content}
+	for _, wc := range w.ToolCalls {
+		args := wc.Function.Arguments
+		var s string
+		if json.Unmarshal(args, &s) == nil {
+			args = json.RawMessage(s)
+		}
+		if len(args) == 0 {
+			args = json.RawMessage("{}")
+		}
+		m.ToolCalls = append(m.ToolCalls, ToolCall{ID: wc.ID, Name: wc.Function.Name, Arguments: args})
+	}
+	return m
+}
+
+type ollamaToolsRequest struct {
+	Model    string        `json:"model"`
+	Messages []wireMessage `json:"messages"`
+	Tools    []toolSpec    `json:"tools"`
+	Stream   bool          `json:"stream"`
+}
+
+// ChatTools sends the conversation to /api/chat with tools attached.
+func (c *OllamaClient) ChatTools(ctx context.Context, messages []Message, tools []Tool) (Message, error) {
+	var resp struct {
+		Message wireMessage `json:"message"`
+	}
+	req := ollamaToolsRequest{Model: c.Model, Messages: toWire(messages, false), Tools: toolSpecs(tools)}
+	if err := c.do(ctx, http.MethodPost, "/api/chat", req, &resp); err != nil {
+		return Message{}, c.wrap(err)
+	}
+	return resp.Message.message(), nil
+}
+
+type openAIToolsRequest struct {
+	Model    string        `json:"model,omitempty"`
+	Messages []wireMessage `json:"messages"`
+	Tools    []toolSpec    `json:"tools"`
+}
+
+// ChatTools sends the conversation to /v1/chat/completions with tools attached.
+func (c *OpenAIClient) ChatTools(ctx context.Context, messages []Message, tools []Tool) (Message, error) {
+	var resp struct {
+		Choices []struct {
+			Message wireMessage `json:"message"`
+		} `json:"choices"`
+	}
+	req := openAIToolsRequest{Model: c.Model, Messages: toWire(messages, true), Tools: toolSpecs(tools)}
+	if err := c.v1().do(ctx, http.MethodPost, "/v1/chat/completions", req, &resp); err != nil {
+		return Message{}, fmt.Errorf("openai-compatible (model %s): %w", c.Model, err)
+	}
+	if len(resp.Choices) == 0 {
+		return Message{}, fmt.Errorf("openai-compatible (model %s): response has no choices", c.Model)
+	}
+	return resp.Choices[0].Message.message(), nil
+}
+
+// DefaultMaxToolCalls bounds the tool rounds of AnswerWithTools.
+const DefaultMaxToolCalls = 8
+
+// ToolRunner executes the tools offered to the model.
+type ToolRunner interface {
+	Tools() []Tool
+	Call(ctx context.Context, name string, args json.RawMessage) (string, error)
+}
+
+// ToolTrace records one tool call made while answering.
+type ToolTrace struct {
+	Name      string
+	Arguments json.RawMessage
+	Result    string
+}
+
+// AnswerWithTools answers question by letting the model call the runner's
+// tools. Providers without native function calling, or models that reject
+// tools, are driven through a JSON protocol instead. The final answer goes to
+// OnToken in one piece.
+func (c *Client) AnswerWithTools(ctx context.Context, question string, runner ToolRunner) (string, []ToolTrace, error) {
+	system, err := c.Prompts.Render(PromptAskTools, struct{ Today string }{c.now().Format("Monday 2006-01-02")})
+	if err != nil {
+		return "", nil, err
+	}
+	var trace []ToolTrace
+	var answer string
+	var apiErr *APIError
+	if tc, ok := c.Provider.(ToolCaller); ok {
+		answer, err = c.nativeTools(ctx, tc, system, question, runner, &trace)
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusBadRequest && len(trace) == 0 {
+			log.Warn("[LLM] model rejected tools, using the JSON protocol", "error", err)
+			answer, err = c.promptedTools(ctx, system, question, runner, &trace)
+		}
+	} else {
+		answer, err = c.promptedTools(ctx, system, question, runner, &trace)
+	}
+	if err != nil {
+		log.Error("[LLM] AnswerWithTools failed", "error", err)
+		return "", trace, err
+	}
+	if c.OnToken != nil {
+		c.OnToken(answer)
+	}
+	return answer, trace, nil
+}
+
+func (c *Client) nativeTools(ctx context.Context, tc ToolCaller, system, question string, runner ToolRunner, trace *[]ToolTrace) (string, error) {
+	// The conversation stays redacted; only tool arguments and the answer
+	// are restored.
+	messages := []Message{{Role: "system", Content: system}, {Role: "user", Content: c.Redactor.Redact(question)}}
+	tools := runner.Tools()
+	for len(*trace) <= DefaultMaxToolCalls {
+		reply, err := tc.ChatTools(ctx, messages, tools)
+		if err != nil {
+			return "", err
+		}
+		if len(reply.ToolCalls) == 0 {
+			return c.Redactor.Restore(reply.Content), nil
+		}
+		messages = append(messages, reply)
+		for _, call := range reply.ToolCalls {
+			result := runTool(ctx, runner, call.Name, c.Redactor.RestoreJSON(call.Arguments), trace)
+			messages = append(messages, Message{Role: "tool", Content: c.Redactor.Redact(result), ToolCallID: call.ID, ToolName: call.Name})
+		}
+	}
+	return "", fmt.Errorf("gave up after %d tool calls", len(*trace))
+}
+
+// promptedReply is the JSON protocol for models without function calling.
+type promptedReply struct {
+	Tool      string          `json:"tool"`
+	Arguments json.RawMessage `json:"arguments"`
+	Answer    string          `json:"answer"`
+}
+
+var promptedSchema = json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "tool": {"type": "string"},
+    "arguments": {"type": "object"},
+    "answer": {"type": "string"}
+  }
+}`)
+
+func (c *Client) promptedTools(ctx context.Context, system, question string, runner ToolRunner, trace *[]ToolTrace) (string, error) {
+	var b strings.Builder
+	b.WriteString(system + "\n\nTools:\n")
+	for _, t := range runner.Tools() {
+		fmt.Fprintf(&b, "- %s: %s Arguments schema: %s\n", t.Name, t.Description, compactJSON(t.Parameters))
+	}
+	b.WriteString(`Reply with one JSON object only: {"tool": "<name>", "arguments": {...}} to call a tool, ` +
+		`or {"answer": "<text>"} once you can answer.` + "\n\nQuestion: " + question + "\n")
+	for len(*trace) <= DefaultMaxToolCalls {
+		output, err := c.generateJSON(ctx, b.String(), promptedSchema)
+		if err != nil {
+			return "", err
+		}
+		var reply promptedReply
+		first, last := strings.IndexByte(output, '{'), strings.LastIndexByte(output, '}')
+		if first < 0 || last < first || json.Unmarshal([]byte(output[first:last+1]), &reply) != nil {
+			b.WriteString("\nYour reply was not a JSON object: " + output + "\nReply with JSON only.\n")
+			*trace = append(*trace, ToolTrace{Name: "(invalid reply)", Result: output})
+			continue
+		}
+		if reply.Tool == "" {
+			return reply.Answer, nil
+		}
+		result := runTool(ctx, runner, reply.Tool, reply.Arguments, trace)
+		fmt.Fprintf(&b, "\nCalled %s(%s)\nResult: %s\n", reply.Tool, compactJSON(reply.Arguments), result)
+	}
+	return "", fmt.Errorf("gave up after %d tool calls", len(*trace))
+}
+
+// runTool executes a call and records it. Errors are handed to the model as
+// the result so it can correct its arguments.
+func runTool(ctx context.Context, runner ToolRunner, name string, args json.RawMessage, trace *[]ToolTrace) string {
+	if len(args) == 0 {
+		args = json.RawMessage("{}")
+	}
+	result, err := runner.Call(ctx, name, args)
+	if err != nil {
+		b, _ := json.Marshal(map[string]string{"error": err.Error()})
+		result = string(b)
+	}
+	*trace = append(*trace, ToolTrace{Name: name, Arguments: args, Result: result})
+	return result
+}
+
+func compactJSON(raw json.RawMessage) string {
+	var b bytes.Buffer
+	if err := json.Compact(&b, raw); err != nil {
+		return string(raw)
+	}
+	return b.String()
+}
@@ -0,0 +1,126 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/regiellis/chronos-go/chronos"
+)
+
+func toolStore(t *testing.T) *StoreTools {
+	t.Helper()
+	repo := chronos.NewMemoryRepository()
+	block := &chronos.Block{Name: "May sprint", Client: "Acme", StartTime: time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC), EndTime: time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)}
+	if err := chronos.CreateBlock(repo, block); err != nil {
+		t.Fatal(err)
+	}
+	if err := chronos.SetActiveBlock(repo, block.ID); err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range []*chronos.Entry{
+		{BlockID: block.ID, Project: "Apollo", Client: "Acme", StartTime: time.Date(2025, 5, 2, 9, 0, 0, 0, time.UTC), Duration: 90, Billable: true, Rate: 100, Tags: []string{"api"}},
+		{BlockID: block.ID, Project: "Apollo", Client: "Acme", StartTime: time.Date(2025, 5, 20, 9, 0, 0, 0, time.UTC), Duration: 120, Billable: true, Rate: 100, Invoiced: true},
+		{Project: "Zeus", Client: "Acme", StartTime: time.Date(2025, 5, 21, 9, 0, 0, 0, time.UTC), Duration: 60},
+		{Project: "Site", Client: "Globex", StartTime: time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC), Duration: 30, Billable: true, Rate: 80},
+	} {
+		if err := chronos.CreateEntry(repo, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return &StoreTools{Repo: repo, Now: func() time.Time { return parseNow }}
+}
+
+func TestStoreTools(t *testing.T) {
+	s := toolStore(t)
+	tests := []struct {
+		tool, args, want string
+	}{
+		{"sum_hours", `{"client":"acme","from":"2025-05-01","to":"2025-05-31","billable":true}`, `"entries":2,"hours":3.5,"billable_hours":3.5,"amount":350`},
+		{"sum_hours", `{"from":"2025-05-01","to":"2025-05-31","group_by":"project"}`, `"groups":[{"name":"Apollo","hours":3.5},{"name":"Zeus","hours":1}]`},
+		{"list_entries", `{"project":"zeus"}`, `"total":1`},
+		{"block_progress", `{}`, `"hours":3.5`},
+		{"block_progress", `{}`, `"days_left":18`},
+		{"unbilled_totals", `{}`, `"amount":190,"clients":[{"client":"Acme","hours":1.5,"amount":150},{"client":"Globex","hours":0.5,"amount":40}]`},
+	}
+	for _, tt := range tests {
+		out, err := s.Call(context.Background(), tt.tool, json.RawMessage(tt.args))
+		if err != nil || !strings.Contains(out, tt.want) {
+			t.Errorf("%s(%s) = %s, %v; want it to contain %s", tt.tool, tt.args, out, err, tt.want)
+		}
+	}
+	for _, bad := range []struct{ tool, args string }{
+		{"sum_hours", `{"from":"last may"}`},
+		{"sum_hours", `{"group_by":"week"}`},
+		{"drop_table", `{}`},
+	} {
+		if _, err := s.Call(context.Background(), bad.tool, json.RawMessage(bad.args)); err == nil {
+			t.Errorf("%s(%s) should fail", bad.tool, bad.args)
+		}
+	}
+}
+
+func TestAnswerWithTools_Native(t *testing.T) {
+	var requests []ollamaToolsRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ollamaToolsRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		requests = append(requests, req)
+		if len(requests) == 1 {
+			w.Write([]byte(`{"message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"sum_hours","arguments":{"client":"Acme","from":"2025-05-01","to":"2025-05-31","billable":true}}}]}}`))
+			return
+		}
+		w.Write([]byte(`{"message":{"role":"assistant","content":"You logged 3.5 billable hours for Acme in May."}}`))
+	}))
+	defer srv.Close()
+
+	c := &Client{Provider: &OllamaClient{Endpoint: Endpoint{Host: srv.URL}}, Now: func() time.Time { return parseNow }}
+	var streamed string
+	c.OnToken = func(tok string) { streamed += tok }
+	answer, trace, err := c.AnswerWithTools(context.Background(), "billable hours for Acme in May?", toolStore(t))
+	if err != nil || answer != streamed || !strings.Contains(answer, "3.5") {
+		t.Fatalf("got %q (streamed %q), %v", answer, streamed, err)
+	}
+	if len(trace) != 1 || trace[0].Name != "sum_hours" || !strings.Contains(trace[0].Result, `"billable_hours":3.5`) {
+		t.Fatalf("unexpected trace: %+v", trace)
+	}
+	if len(requests[0].Tools) != len(storeTools) {
+		t.Errorf("tools not offered: %+v", requests[0].Tools)
+	}
+	last := requests[1].Messages[len(requests[1].Messages)-1]
+	if last.Role != "tool" || last.ToolName != "sum_hours" || !strings.Contains(last.Content, "3.5") {
+		t.Errorf("tool result not sent back: %+v", last)
+	}
+}
+
+func TestAnswerWithTools_OpenAIArguments(t *testing.T) {
+	msgs := toWire([]Message{{Role: "assistant", ToolCalls: []ToolCall{{ID: "call_1", Name: "sum_hours", Arguments: json.RawMessage(`{"client":"Acme"}`)}}}}, true)
+	b, _ := json.Marshal(msgs[0])
+	if !strings.Contains(string(b), `"arguments":"{\"client\":\"Acme\"}"`) || !strings.Contains(string(b), `"id":"call_1"`) {
+		t.Errorf("OpenAI tool calls need string arguments and ids: %s", b)
+	}
+	var reply wireMessage
+	json.Unmarshal([]byte(`{"role":"assistant","tool_calls":[{"id":"call_1","type":"function","function":{"name":"list_entries","arguments":"{\"limit\":5}"}}]}`), &reply)
+	if m := reply.message(); len(m.ToolCalls) != 1 || string(m.ToolCalls[0].Arguments) != `{"limit":5}` {
+		t.Errorf("string arguments not decoded: %+v", m)
+	}
+}
+
+func TestAnswerWithTools_Prompted(t *testing.T) {
+	p := &scriptedProvider{replies: []string{
+		`{"tool":"unbilled_totals","arguments":{"client":"Globex"}}`,
+		`{"answer":"Globex owes $40."}`,
+	}}
+	c := &Client{Provider: p, Now: func() time.Time { return parseNow }}
+	answer, trace, err := c.AnswerWithTools(context.Background(), "what does Globex owe?", toolStore(t))
+	if err != nil || answer != "Globex owes $40." {
+		t.Fatalf("got %q, %v", answer, err)
+	}
+	if len(trace) != 1 || !strings.Contains(p.prompts[1], `Result: {"amount":40`) {
+		t.Errorf("tool result should be fed back:\n%s", p.prompts[1])
+	}
+}
@@ -7,8 +7,6 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/regiellis/chronos-go/chronos"
-	"github.com/regiellis/chronos-go/db"
-	"github.com/regiellis/chronos-go/utils"
 )
 
 type AppViewModel struct {
@@ -34,6 +32,7 @@ func (m *AppViewModel) View() string {
 }
 
 type PomodoroModel struct {
+	DB        chronos.Repository
 	Duration  time.Duration
 	Remaining time.Duration
 	Running   bool
@@ -41,8 +40,9 @@ type PomodoroModel struct {
 	StartTime time.Time
 }
 
-func NewPomodoroModel(dur time.Duration) *PomodoroModel {
+func NewPomodoroModel(repo chronos.Repository, dur time.Duration) *PomodoroModel {
 	return &PomodoroModel{
+		DB:        repo,
 		Duration:  dur,
 		Remaining: dur,
 		Running:   true,
@@ -65,7 +65,7 @@ func (m *PomodoroModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.Completed = true
 				m.Running = false
 				// Log the session as an entry (default project/task)
-				go logPomodoroEntry(m.Duration)
+				go logPomodoroEntry(m.DB, m.Duration)
 				return m, nil
 			}
 			return m, tea.Tick(time.Second, func(t time.Time) tea.Msg { return tickMsg(t) })
@@ -80,29 +80,27 @@ func (m *PomodoroModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-func logPomodoroEntry(dur time.Duration) {
-	dbStore, err := db.NewStore("chronos.db")
-	if err != nil {
+func logPomodoroEntry(repo chronos.Repository, dur time.Duration) {
+	if repo == nil {
 		return
 	}
-	dbStore.InitSchema()
 	entry := &chronos.Entry{
-		Project:     utils.SanitizeString("Pomodoro"),
-		Task:        utils.SanitizeString("Focus Session"),
-		Description: utils.SanitizeDescription("Pomodoro focus session"),
-		Duration:    int64(dur.Minutes()),
-		EntryTime:   time.Now(),
-		CreatedAt:   time.Now(),
-		Billable:    false,
-		Rate:        0,
+		Project:   "Pomodoro",
+		Task:      "Focus Session",
+		Summary:   "Pomodoro focus session",
+		StartTime: time.Now().Add(-dur),
+		EndTime:   time.Now(),
+		Duration:  int64(dur.Minutes()),
+		Billable:  false,
+		Rate:      0,
 	}
-	block, _ := dbStore.GetActiveBlock()
+	block, _ := chronos.GetActiveBlock(repo)
 	if block != nil {
 		entry.BlockID = block.ID
-		entry.Client = utils.SanitizeString(block.Client)
-		entry.Project = utils.SanitizeString(block.Project)
+		entry.Client = block.Client
+		entry.Project = block.Project
 	}
-	dbStore.AddEntry(entry)
+	chronos.CreateEntry(repo, entry)
 }
 
 func (m *PomodoroModel) View() string {
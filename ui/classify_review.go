@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/regiellis/chronos-go/chronos"
+	"github.com/regiellis/chronos-go/utils"
+)
+
+// ClassificationsPreview renders proposed classifications as a table of
+// entry, proposal, confidence and source. entries maps IDs to the entries
+// being classified.
+func ClassificationsPreview(proposals []chronos.Classification, entries map[int64]*chronos.Entry) string {
+	rows := []string{
+		utils.TitleStyle.Render("Proposed Classifications"),
+		utils.LabelStyle.Render(fmt.Sprintf("%-6s %-32s %-36s %5s  %s", "Entry", "Summary", "Project / Client / Task", "Conf", "Source")),
+	}
+	for _, p := range proposals {
+		summary := ""
+		if e := entries[p.EntryID]; e != nil {
+			summary = e.Summary
+		}
+		rows = append(rows, utils.ValueStyle.Render(fmt.Sprintf("%-6d %-32s %-36s %4.0f%%  %s",
+			p.EntryID, clip(summary, 32), clip(classificationLabel(p), 36), p.Confidence*100, p.Source)))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
+
+// ReviewClassifications shows the proposals and lets the user pick the ones
+// to apply; all are selected to begin with. A cancelled prompt (ctrl+c)
+// accepts none.
+func ReviewClassifications(proposals []chronos.Classification, entries map[int64]*chronos.Entry) ([]chronos.Classification, error) {
+	fmt.Println(ClassificationsPreview(proposals, entries))
+	options := make([]huh.Option[int], len(proposals))
+	selected := make([]int, len(proposals))
+	for i, p := range proposals {
+		summary := ""
+		if e := entries[p.EntryID]; e != nil {
+			summary = clip(e.Summary, 30)
+		}
+		options[i] = huh.NewOption(fmt.Sprintf("#%d %s → %s", p.EntryID, summary, classificationLabel(p)), i).Selected(true)
+		selected[i] = i
+	}
+	err := huh.NewMultiSelect[int]().
+		Title("Apply which classifications?").
+		Options(options...).
+		Value(&selected).
+		Run()
+	if errors.Is(err, huh.ErrUserAborted) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	accepted := make([]chronos.Classification, 0, len(selected))
+	for _, i := range selected {
+		accepted = append(accepted, proposals[i])
+	}
+	return accepted, nil
+}
+
+func classificationLabel(p chronos.Classification) string {
+	client := p.Client
+	if client == "" {
+		client = "-"
+	}
+	return p.Project + " / " + client + " / " + p.Task
+}
+
+// clip shortens s to n runes, marking the cut with an ellipsis.
+func clip(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
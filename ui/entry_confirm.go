@@ -0,0 +1,143 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/regiellis/chronos-go/chronos"
+	"github.com/regiellis/chronos-go/utils"
+)
+
+const previewTimeLayout = "2006-01-02 15:04"
+
+// EntryPreview renders a parsed entry, before it is saved, with every field
+// the parser filled in. block may be nil.
+func EntryPreview(entry *chronos.Entry, block *chronos.Block) string {
+	row := func(label, value string) string {
+		if value == "" {
+			value = "-"
+		}
+		return utils.LabelStyle.Render(fmt.Sprintf("%-10s", label)) + " " + utils.ValueStyle.Render(value)
+	}
+	end := entry.EndTime
+	if end.IsZero() {
+		end = entry.StartTime.Add(time.Duration(entry.Duration) * time.Minute)
+	}
+	rate := "-"
+	if entry.Rate > 0 {
+		rate = fmt.Sprintf("%.2f/h", entry.Rate)
+	}
+	blockName := ""
+	if block != nil {
+		blockName = fmt.Sprintf("%s (#%d)", block.Name, block.ID)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left,
+		utils.TitleStyle.Render("Entry Preview"),
+		row("Project", entry.Project),
+		row("Client", entry.Client),
+		row("Task", entry.Task),
+		row("Summary", entry.Summary),
+		row("Start", entry.StartTime.Format(previewTimeLayout)),
+		row("End", end.Format(previewTimeLayout)),
+		row("Duration", fmt.Sprintf("%.0f min", entry.Minutes())),
+		row("Billable", strconv.FormatBool(entry.Billable)),
+		row("Rate", rate),
+		row("Tags", strings.Join(entry.Tags, ", ")),
+		row("Block", blockName),
+	)
+}
+
+// ConfirmEntry shows the preview and asks whether to save it, edit it or
+// cancel. Edits are applied to entry in place. It reports whether the entry
+// should be saved; a cancelled prompt (ctrl+c) counts as no.
+func ConfirmEntry(entry *chronos.Entry, block *chronos.Block) (bool, error) {
+	for {
+		fmt.Println(EntryPreview(entry, block))
+		var choice string
+		err := huh.NewSelect[string]().
+			Title("Save this entry?").
+			Options(
+				huh.NewOption("Save", "save"),
+				huh.NewOption("Edit fields", "edit"),
+				huh.NewOption("Cancel", "cancel"),
+			).
+			Value(&choice).
+			Run()
+		if errors.Is(err, huh.ErrUserAborted) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		switch choice {
+		case "save":
+			return true, nil
+		case "cancel":
+			return false, nil
+		}
+		if err := editEntry(entry); err != nil {
+			if errors.Is(err, huh.ErrUserAborted) {
+				return false, nil
+			}
+			return false, err
+		}
+	}
+}
+
+// editEntry lets the user correct a parsed entry. The end time follows the
+// edited start and duration.
+func editEntry(entry *chronos.Entry) error {
+	startStr := entry.StartTime.Format(previewTimeLayout)
+	durationStr := strconv.FormatInt(int64(entry.Minutes()), 10)
+	rateStr := strconv.FormatFloat(entry.Rate, 'f', -1, 64)
+	tagsStr := strings.Join(entry.Tags, ", ")
+
+	err := huh.NewForm(
+		huh.NewGroup(
+			huh.NewInput().Title("Project").Value(&entry.Project),
+			huh.NewInput().Title("Client").Value(&entry.Client),
+			huh.NewInput().Title("Task").Value(&entry.Task),
+			huh.NewInput().Title("Summary").Value(&entry.Summary),
+			huh.NewInput().Title("Start (YYYY-MM-DD HH:MM)").Value(&startStr).Validate(func(s string) error {
+				_, err := time.ParseInLocation(previewTimeLayout, s, time.Local)
+				return err
+			}),
+			huh.NewInput().Title("Duration (min)").Value(&durationStr).Validate(func(s string) error {
+				if d, err := strconv.ParseInt(s, 10, 64); err != nil || d <= 0 {
+					return fmt.Errorf("enter a positive number of minutes")
+				}
+				return nil
+			}),
+			huh.NewConfirm().Title("Billable?").Value(&entry.Billable),
+			huh.NewInput().Title("Rate (per hour)").Value(&rateStr).Validate(func(s string) error {
+				if s == "" {
+					return nil
+				}
+				_, err := strconv.ParseFloat(s, 64)
+				return err
+			}),
+			huh.NewInput().Title("Tags (comma separated)").Value(&tagsStr),
+		),
+	).Run()
+	if err != nil {
+		return err
+	}
+
+	// The validators above guarantee these parse.
+	entry.StartTime, _ = time.ParseInLocation(previewTimeLayout, startStr, time.Local)
+	entry.Duration, _ = strconv.ParseInt(durationStr, 10, 64)
+	entry.EndTime = entry.StartTime.Add(time.Duration(entry.Duration) * time.Minute)
+	entry.Rate, _ = strconv.ParseFloat(rateStr, 64)
+	entry.Tags = entry.Tags[:0]
+	for _, tag := range strings.Split(tagsStr, ",") {
+		if tag = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(tag), "#")); tag != "" {
+			entry.Tags = append(entry.Tags, tag)
+		}
+	}
+	return nil
+}
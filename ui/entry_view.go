@@ -3,6 +3,7 @@ package ui
 import (
 	"fmt"
 	"strconv"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/huh"
@@ -43,9 +44,9 @@ func (m *EntryViewModel) View() string {
 		utils.LabelStyle.Render("Project: ")+utils.ValueStyle.Render(utils.SanitizeString(m.Entry.Project)),
 		utils.LabelStyle.Render("Client: ")+utils.ValueStyle.Render(utils.SanitizeString(m.Entry.Client)),
 		utils.LabelStyle.Render("Task: ")+utils.ValueStyle.Render(utils.SanitizeString(m.Entry.Task)),
-		utils.LabelStyle.Render("Description: ")+utils.ValueStyle.Render(utils.SanitizeDescription(m.Entry.Description)),
-		utils.LabelStyle.Render("Duration: ")+utils.ValueStyle.Render(fmt.Sprintf("%d min", m.Entry.Duration)),
-		utils.LabelStyle.Render("Date: ")+utils.ValueStyle.Render(m.Entry.EntryTime.Format("2006-01-02 15:04")),
+		utils.LabelStyle.Render("Description: ")+utils.ValueStyle.Render(utils.SanitizeDescription(m.Entry.Summary)),
+		utils.LabelStyle.Render("Duration: ")+utils.ValueStyle.Render(fmt.Sprintf("%.0f min", m.Entry.Minutes())),
+		utils.LabelStyle.Render("Date: ")+utils.ValueStyle.Render(m.Entry.StartTime.Format("2006-01-02 15:04")),
 		"",
 		utils.InactiveStyle.Render("Press q to quit"),
 	)
@@ -54,21 +55,23 @@ func (m *EntryViewModel) View() string {
 type EntryFormModel struct {
 	Form        *huh.Form
 	Entry       *chronos.Entry
+	DB          chronos.Repository
 	Completed   bool
+	Err         error
 	Suggestion  string
 	DurationStr string
 	RateStr     string
 }
 
-func NewEntryFormModel(suggestion string) *EntryFormModel {
-	entry := &chronos.Entry{}
-	model := &EntryFormModel{Entry: entry, Suggestion: suggestion}
+func NewEntryFormModel(dbStore chronos.Repository, suggestion string) *EntryFormModel {
+	entry := &chronos.Entry{Billable: true}
+	model := &EntryFormModel{Entry: entry, DB: dbStore, Suggestion: suggestion}
 	model.Form = huh.NewForm(
 		huh.NewGroup(
 			huh.NewInput().Title("Project").Value(&entry.Project),
 			huh.NewInput().Title("Client").Value(&entry.Client),
 			huh.NewInput().Title("Task").Value(&entry.Task),
-			huh.NewInput().Title("Description").Value(&entry.Description),
+			huh.NewInput().Title("Description").Value(&entry.Summary),
 			huh.NewInput().Title("Duration (min)").Value(&model.DurationStr),
 			huh.NewConfirm().Title("Billable?").Value(&entry.Billable),
 			huh.NewInput().Title("Rate (per hour)").Value(&model.RateStr),
@@ -86,7 +89,7 @@ func (m *EntryFormModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if f, ok := form.(*huh.Form); ok {
 		m.Form = f
 	}
-	if m.Form.State == huh.StateCompleted {
+	if m.Form.State == huh.StateCompleted && !m.Completed {
 		m.Completed = true
 		// Parse duration and rate
 		if d, err := strconv.ParseInt(m.DurationStr, 10, 64); err == nil {
@@ -99,7 +102,12 @@ func (m *EntryFormModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.Entry.Project = utils.SanitizeString(m.Entry.Project)
 		m.Entry.Client = utils.SanitizeString(m.Entry.Client)
 		m.Entry.Task = utils.SanitizeString(m.Entry.Task)
-		m.Entry.Description = utils.SanitizeDescription(m.Entry.Description)
+		m.Entry.Summary = utils.SanitizeDescription(m.Entry.Summary)
+		m.Entry.StartTime = time.Now().Add(-time.Duration(m.Entry.Duration) * time.Minute)
+		if block, err := chronos.GetActiveBlock(m.DB); err == nil && block != nil {
+			m.Entry.BlockID = block.ID
+		}
+		m.Err = chronos.CreateEntry(m.DB, m.Entry)
 	}
 	return m, cmd
 }
@@ -109,7 +117,9 @@ func (m *EntryFormModel) View() string {
 		utils.TitleStyle.Render("Add Entry (AI Suggestion: "+m.Suggestion+")"),
 		m.Form.View(),
 	)
-	if m.Completed {
+	if m.Err != nil {
+		v += utils.ErrorStyle.Render("Failed to save entry: " + m.Err.Error())
+	} else if m.Completed {
 		v += utils.ActiveStyle.Render("Entry saved!")
 	}
 	return v
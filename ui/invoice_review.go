@@ -0,0 +1,125 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/regiellis/chronos-go/chronos"
+	"github.com/regiellis/chronos-go/utils"
+)
+
+// InvoiceLinesPreview renders invoice lines with their descriptions, hours
+// and amounts. Tracked time is shown when rounding changed it.
+func InvoiceLinesPreview(lines []*chronos.InvoiceLine) string {
+	rows := []string{utils.TitleStyle.Render("Invoice Lines")}
+	for i, l := range lines {
+		detail := fmt.Sprintf("%.2f", l.Amount)
+		if l.Kind == "" {
+			hours := fmt.Sprintf("%.2fh", l.Hours())
+			if tracked := l.TrackedHours(); fmt.Sprintf("%.2f", tracked) != fmt.Sprintf("%.2f", l.Hours()) {
+				hours += fmt.Sprintf(" (tracked %.2fh)", tracked)
+			}
+			detail = fmt.Sprintf("%s  %.2f  (%d entries)", hours, l.Amount, len(l.EntryIDs))
+		}
+		rows = append(rows,
+			utils.LabelStyle.Render(fmt.Sprintf("%d. %s", i+1, l.Label()))+" "+
+				utils.InactiveStyle.Render(detail),
+			"   "+utils.ValueStyle.Render(l.Description),
+		)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
+
+// InvoicePreview renders an invoice's header, its lines and its totals,
+// with the subtotal, discounts and taxes when it has any, in its currency.
+func InvoicePreview(inv *chronos.Invoice) string {
+	row := func(label, value string) string {
+		if value == "" {
+			value = "-"
+		}
+		return utils.LabelStyle.Render(fmt.Sprintf("%-8s", label)) + " " + utils.ValueStyle.Render(value)
+	}
+	date := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format("2006-01-02")
+	}
+	totals := inv.Totals()
+	rows := []string{
+		utils.TitleStyle.Render("Invoice " + inv.Label()),
+		row("Status", inv.Status),
+		row("Client", inv.Client),
+		row("Issued", date(inv.IssueDate)),
+		row("Due", date(inv.DueDate)),
+		row("Paid", date(inv.PaidAt)),
+		"",
+		InvoiceLinesPreview(inv.Lines),
+		"",
+		row("Tracked", fmt.Sprintf("%.2f", inv.TrackedHours())),
+		row("Hours", fmt.Sprintf("%.2f", inv.Hours())),
+	}
+	if len(totals.Discounts) > 0 || len(totals.Taxes) > 0 {
+		rows = append(rows, row("Subtotal", chronos.FormatMoney(totals.Subtotal, inv.Currency)))
+		for _, a := range append(totals.Discounts, totals.Taxes...) {
+			rows = append(rows, utils.LabelStyle.Render(fmt.Sprintf("%-8s", a.Name))+" "+utils.ValueStyle.Render(chronos.FormatMoney(a.Amount, inv.Currency)))
+		}
+	}
+	rows = append(rows, row("Total", chronos.FormatMoney(totals.Total, inv.Currency)))
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
+
+// ReviewInvoiceLines shows the lines and asks whether to use them, edit the
+// descriptions or cancel. Edits are applied in place. It reports whether the
+// invoice should be finalised; a cancelled prompt (ctrl+c) counts as no.
+func ReviewInvoiceLines(lines []*chronos.InvoiceLine) (bool, error) {
+	for {
+		fmt.Println(InvoiceLinesPreview(lines))
+		var choice string
+		err := huh.NewSelect[string]().
+			Title("Use these descriptions?").
+			Options(
+				huh.NewOption("Finalise invoice", "save"),
+				huh.NewOption("Edit descriptions", "edit"),
+				huh.NewOption("Cancel", "cancel"),
+			).
+			Value(&choice).
+			Run()
+		if errors.Is(err, huh.ErrUserAborted) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		switch choice {
+		case "save":
+			return true, nil
+		case "cancel":
+			return false, nil
+		}
+		if err := editInvoiceLines(lines); err != nil {
+			if errors.Is(err, huh.ErrUserAborted) {
+				return false, nil
+			}
+			return false, err
+		}
+	}
+}
+
+func editInvoiceLines(lines []*chronos.InvoiceLine) error {
+	fields := make([]huh.Field, len(lines))
+	for i, l := range lines {
+		fields[i] = huh.NewText().Title(fmt.Sprintf("%s (%.2fh)", l.Label(), l.Hours())).Value(&l.Description).Lines(2)
+	}
+	if err := huh.NewForm(huh.NewGroup(fields...)).Run(); err != nil {
+		return err
+	}
+	for _, l := range lines {
+		l.Description = strings.TrimSpace(l.Description)
+	}
+	return nil
+}
@@ -53,7 +53,7 @@ func (m *ListViewModel) View() string {
 			cursor = "> "
 			style = utils.ActiveStyle
 		}
-		row := fmt.Sprintf("%s%s | %s | %s | %d min | %s", cursor, utils.SanitizeString(e.Project), utils.SanitizeString(e.Task), utils.SanitizeDescription(e.Description), e.Duration, e.EntryTime.Format("2006-01-02"))
+		row := fmt.Sprintf("%s%s | %s | %s | %.0f min | %s", cursor, utils.SanitizeString(e.Project), utils.SanitizeString(e.Task), utils.SanitizeDescription(e.Summary), e.Minutes(), e.StartTime.Format("2006-01-02"))
 		rows = append(rows, style.Render(row))
 	}
 	return lipgloss.JoinVertical(lipgloss.Left,
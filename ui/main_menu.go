@@ -3,16 +3,16 @@ package ui
 import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
-	"github.com/regiellis/chronos-go/db"
+	"github.com/regiellis/chronos-go/chronos"
 )
 
 type MainMenuModel struct {
 	Cursor  int
 	Choices []string
-	DB      *db.Store
+	DB      chronos.Repository
 }
 
-func NewMainMenuModel(dbStore *db.Store) *MainMenuModel {
+func NewMainMenuModel(dbStore chronos.Repository) *MainMenuModel {
 	return &MainMenuModel{
 		Choices: []string{"View Entries", "Add Entry", "View Blocks", "Summaries", "Quit"},
 		DB:      dbStore,
@@ -38,13 +38,13 @@ func (m *MainMenuModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "enter", " ":
 			switch m.Cursor {
 			case 0:
-				entries, _ := m.DB.ListEntries(nil)
+				entries, _ := chronos.ListEntries(m.DB, nil)
 				return NewListViewModel(entries), nil
 			case 1:
 				// Use the EntryFormModel from entry_view.go, pass empty suggestion for now
-				return NewEntryFormModel(""), nil
+				return NewEntryFormModel(m.DB, ""), nil
 			case 2:
-				blocks, _ := m.DB.ListBlocks(nil)
+				blocks, _ := chronos.ListBlocks(m.DB, nil)
 				if len(blocks) == 0 {
 					return NewSummaryViewModel("No blocks found."), nil
 				}
@@ -0,0 +1,146 @@
+package ui
+
+import (
+	"context"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/regiellis/chronos-go/utils"
+)
+
+// StreamFunc produces text, passing each piece to onToken as it arrives.
+type StreamFunc func(ctx context.Context, onToken func(string)) (string, error)
+
+type streamTokenMsg string
+
+type streamDoneMsg struct{}
+
+// StreamModel shows a spinner until the first token arrives, then renders the
+// text as markdown while it streams in. Ctrl+C or Esc cancels the request.
+type StreamModel struct {
+	Title     string
+	Err       error
+	Cancelled bool
+
+	ctx      context.Context
+	cancel   context.CancelFunc
+	run      StreamFunc
+	tokens   chan string
+	text     strings.Builder
+	spinner  spinner.Model
+	style    string
+	renderer *glamour.TermRenderer
+	done     bool
+}
+
+// NewStreamModel prepares a model that runs fn once started.
+func NewStreamModel(ctx context.Context, title string, fn StreamFunc) *StreamModel {
+	ctx, cancel := context.WithCancel(ctx)
+	style := "dark"
+	if !lipgloss.HasDarkBackground() {
+		style = "light"
+	}
+	m := &StreamModel{
+		Title:   title,
+		ctx:     ctx,
+		cancel:  cancel,
+		run:     fn,
+		tokens:  make(chan string),
+		spinner: spinner.New(spinner.WithSpinner(spinner.Dot), spinner.WithStyle(utils.LLMStyle)),
+		style:   style,
+	}
+	m.renderer = m.newRenderer(80)
+	return m
+}
+
+func (m *StreamModel) newRenderer(width int) *glamour.TermRenderer {
+	r, err := glamour.NewTermRenderer(glamour.WithStandardStyle(m.style), glamour.WithWordWrap(width))
+	if err != nil {
+		return nil
+	}
+	return r
+}
+
+func (m *StreamModel) Init() tea.Cmd {
+	go func() {
+		_, m.Err = m.run(m.ctx, func(tok string) {
+			select {
+			case m.tokens <- tok:
+			case <-m.ctx.Done():
+			}
+		})
+		close(m.tokens)
+	}()
+	return tea.Batch(m.spinner.Tick, m.next)
+}
+
+// next waits for the following token; a closed channel means the run is over.
+func (m *StreamModel) next() tea.Msg {
+	tok, ok := <-m.tokens
+	if !ok {
+		return streamDoneMsg{}
+	}
+	return streamTokenMsg(tok)
+}
+
+func (m *StreamModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "esc":
+			m.Cancelled = true
+			m.cancel()
+			return m, tea.Quit
+		}
+	case tea.WindowSizeMsg:
+		if msg.Width > 4 {
+			m.renderer = m.newRenderer(msg.Width - 4)
+		}
+	case streamTokenMsg:
+		m.text.WriteString(string(msg))
+		return m, m.next
+	case streamDoneMsg:
+		m.done = true
+		m.cancel()
+		return m, tea.Quit
+	case spinner.TickMsg:
+		if m.text.Len() == 0 && !m.done {
+			var cmd tea.Cmd
+			m.spinner, cmd = m.spinner.Update(msg)
+			return m, cmd
+		}
+	}
+	return m, nil
+}
+
+func (m *StreamModel) View() string {
+	if m.text.Len() == 0 {
+		if m.done || m.Cancelled {
+			return ""
+		}
+		return m.spinner.View() + " " + utils.InactiveStyle.Render(m.Title)
+	}
+	out := m.text.String()
+	if m.renderer != nil {
+		if rendered, err := m.renderer.Render(out); err == nil {
+			out = rendered
+		}
+	}
+	return strings.TrimRight(out, "\n")
+}
+
+// RunStream runs fn in a StreamModel on the terminal and returns the full
+// text. A cancelled stream returns context.Canceled.
+func RunStream(ctx context.Context, title string, fn StreamFunc) (string, error) {
+	m := NewStreamModel(ctx, title, fn)
+	if _, err := tea.NewProgram(m).Run(); err != nil {
+		return "", err
+	}
+	if m.Cancelled {
+		return m.text.String(), context.Canceled
+	}
+	return m.text.String(), m.Err
+}
@@ -42,6 +42,8 @@ var (
 // Themed user feedback styles
 var (
 	SuccessStyle = lipgloss.NewStyle().Foreground(Green).Bold(true)
+	InfoStyle    = lipgloss.NewStyle().Foreground(Blue)
+	WarningStyle = lipgloss.NewStyle().Foreground(Orange).Bold(true)
 	EntryStyle   = lipgloss.NewStyle().Foreground(Base0).Background(Base02).Padding(0, 1)
 	LLMStyle     = lipgloss.NewStyle().Foreground(Magenta).Italic(true)
 )